@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/client"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/errors"
+)
+
+// bootstrapAdmin gives the admin role to the client with the configured admin username, if any.
+// The client is registered with the configured admin password if it does not exist yet, which is always
+// the case on a new in-memory database. The password of an existing client is never changed.
+func bootstrapAdmin(ctx context.Context, conf config.ConfigInfo, db database.Database) (err error) {
+	username := conf.Server.AdminUsername
+	if username == "" {
+		return
+	}
+
+	authRepo, err := database.GetRepository[database.AuthRepository](db.Repositories, database.AUTH_REPOSITORY)
+	if err != nil {
+		return
+	}
+	clientRepo, err := database.GetRepository[database.ClientRepository](db.Repositories, database.CLIENT_REPOSITORY)
+	if err != nil {
+		return
+	}
+
+	// Look for the client, registering it if it does not exist.
+	id, _, err := authRepo.GetIdAndHashedPassword(ctx, auth.Auth{Username: username})
+	if isErrorType(err, errors.NOT_FOUND) {
+		id, err = registerAdmin(ctx, conf, authRepo)
+		// Another instance registered it at the same time.
+		if isErrorType(err, errors.ALREADY_EXISTS) {
+			id, _, err = authRepo.GetIdAndHashedPassword(ctx, auth.Auth{Username: username})
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	return clientRepo.UpdateRole(ctx, id, auth.ADMIN_ROLE)
+}
+
+// registerAdmin registers a new client with the configured admin username and password.
+func registerAdmin(ctx context.Context, conf config.ConfigInfo, repo database.AuthRepository) (id int, err error) {
+	if conf.Server.AdminPassword == "" {
+		err = fmt.Errorf("invalid SRV_ADMIN_PASSWORD env var: the admin %s does not exist and needs a password to be registered", conf.Server.AdminUsername)
+		return
+	}
+
+	// Validate the admin like any other new client.
+	c, err := client.New(client.Client{
+		Auth: auth.Auth{
+			Username: conf.Server.AdminUsername,
+			Password: conf.Server.AdminPassword,
+		},
+	})
+	if err != nil {
+		err = fmt.Errorf("invalid SRV_ADMIN_USERNAME env var: %s", err)
+		return
+	}
+	return repo.Register(ctx, c)
+}
+
+// isErrorType reports whether err is a database error of type t.
+func isErrorType(err error, t string) bool {
+	dbErr, ok := err.(errors.Error)
+	return ok && dbErr.Type == t
+}
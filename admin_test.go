@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/client"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrapAdmin(t *testing.T) {
+	ctx := context.Background()
+
+	newRepositories := func(t *testing.T) (db database.Database, authRepo database.AuthRepository) {
+		db, err := setUpMemoryDatabase(config.ConfigInfo{})
+		require.NoError(t, err)
+		authRepo, err = database.GetRepository[database.AuthRepository](db.Repositories, database.AUTH_REPOSITORY)
+		require.NoError(t, err)
+		return
+	}
+
+	t.Run("Register", func(t *testing.T) {
+		// A new in-memory database gets its admin registered.
+		db, authRepo := newRepositories(t)
+		conf := config.ConfigInfo{}
+		conf.Server.AdminUsername = "root"
+		conf.Server.AdminPassword = "secret"
+
+		require.NoError(t, bootstrapAdmin(ctx, conf, db))
+
+		id, hashed, err := authRepo.GetIdAndHashedPassword(ctx, auth.Auth{Username: "root"})
+		require.NoError(t, err)
+		assert.NoError(t, auth.CompareHashAndPassword(hashed, "secret"))
+		role, err := authRepo.GetRole(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, auth.ADMIN_ROLE, role)
+
+		// Bootstrapping again keeps the same admin.
+		require.NoError(t, bootstrapAdmin(ctx, conf, db))
+		again, _, err := authRepo.GetIdAndHashedPassword(ctx, auth.Auth{Username: "root"})
+		require.NoError(t, err)
+		assert.Equal(t, id, again)
+	})
+
+	t.Run("PromoteExisting", func(t *testing.T) {
+		// An existing client is promoted without a password, which is left unchanged.
+		db, authRepo := newRepositories(t)
+		c, err := client.New(client.Client{Auth: auth.Auth{Username: "root", Password: "old"}})
+		require.NoError(t, err)
+		id, err := authRepo.Register(ctx, c)
+		require.NoError(t, err)
+		conf := config.ConfigInfo{}
+		conf.Server.AdminUsername = "root"
+
+		require.NoError(t, bootstrapAdmin(ctx, conf, db))
+
+		role, err := authRepo.GetRole(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, auth.ADMIN_ROLE, role)
+		_, hashed, err := authRepo.GetIdAndHashedPassword(ctx, auth.Auth{Username: "root"})
+		require.NoError(t, err)
+		assert.NoError(t, auth.CompareHashAndPassword(hashed, "old"))
+	})
+
+	t.Run("MissingPassword", func(t *testing.T) {
+		db, _ := newRepositories(t)
+		conf := config.ConfigInfo{}
+		conf.Server.AdminUsername = "root"
+
+		err := bootstrapAdmin(ctx, conf, db)
+		assert.ErrorContains(t, err, "SRV_ADMIN_PASSWORD")
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		db, authRepo := newRepositories(t)
+
+		require.NoError(t, bootstrapAdmin(ctx, config.ConfigInfo{}, db))
+
+		_, _, err := authRepo.GetIdAndHashedPassword(ctx, auth.Auth{Username: "root"})
+		assert.Error(t, err)
+	})
+}
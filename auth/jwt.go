@@ -7,7 +7,13 @@ import (
 )
 
 // GenerateToken generates a JWT token with provided parameters.
-func GenerateToken(id, lifespan int, secretKey string) (string, error) {
+func GenerateToken(id int, role Role, lifespan int, secretKey string) (string, error) {
+	// Generate a unique token ID, used to revoke the token before it expires.
+	jti, err := randomString(16)
+	if err != nil {
+		return "", err
+	}
+
 	// Create a map to hold the JWT claims.
 	claims := jwt.MapClaims{}
 
@@ -17,10 +23,14 @@ func GenerateToken(id, lifespan int, secretKey string) (string, error) {
 	// Set the "client_id" claim to the provided account ID.
 	claims["client_id"] = id
 
-	// If a non-zero lifespan is provided, set the "exp" claim to the current time plus the specified duration.
-	if lifespan != 0 {
-		claims["exp"] = time.Now().Add(time.Hour * time.Duration(lifespan)).Unix()
-	}
+	// Set the "role" claim to the provided client role.
+	claims["role"] = role
+
+	// Set the "jti" claim to the generated token ID.
+	claims["jti"] = jti
+
+	// Set the "exp" claim to the current time plus the specified duration, so every token expires.
+	claims["exp"] = time.Now().Add(time.Hour * time.Duration(lifespan)).Unix()
 
 	// Create a new JWT token using the HS256 signing method and the claims map.
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -14,7 +14,7 @@ func TestGenerateToken(t *testing.T) {
 	lifespan := 1
 	secretKey := "my-secret-key"
 
-	token, err := GenerateToken(id, lifespan, secretKey)
+	token, err := GenerateToken(id, OPERATOR_ROLE, lifespan, secretKey)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
@@ -29,6 +29,8 @@ func TestGenerateToken(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, true, claims["authorized"])
 	assert.Equal(t, float64(id), claims["client_id"])
+	assert.Equal(t, string(OPERATOR_ROLE), claims["role"])
+	assert.NotEmpty(t, claims["jti"])
 	expClaim, ok := claims["exp"].(float64)
 	assert.True(t, ok)
 	assert.InDelta(t, float64(time.Now().Unix()+3600), expClaim, 5) // Within 5 seconds tolerance
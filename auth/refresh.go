@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// RefreshToken represents a stored refresh token. Only the hash of the token is persisted.
+type RefreshToken struct {
+	ID        int        // Unique identifier of the refresh token.
+	ClientID  int        // Identifier of the client owning the token.
+	FamilyID  string     // Identifier shared by every token issued from the same login.
+	Hash      string     // SHA-256 hash of the plain token.
+	ExpiresAt time.Time  // Timestamp after which the token can no longer be used.
+	RevokedAt *time.Time // Timestamp when the token was used or revoked, can be nil.
+	CreatedAt time.Time  // Timestamp when the token was issued.
+}
+
+// IsExpired reports whether the refresh token is expired at the given time.
+func (rt RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(rt.ExpiresAt)
+}
+
+// IsRevoked reports whether the refresh token was already used or revoked.
+func (rt RefreshToken) IsRevoked() bool {
+	return rt.RevokedAt != nil
+}
+
+// NewRefreshToken generates a new refresh token for the given client and family.
+// If familyID is empty, a new family is started.
+// It returns the plain token to be handed to the client and the record to be stored.
+func NewRefreshToken(clientID int, familyID string, lifespan int) (token string, rt RefreshToken, err error) {
+	token, err = randomString(32)
+	if err != nil {
+		return
+	}
+
+	if familyID == "" {
+		familyID, err = randomString(16)
+		if err != nil {
+			return
+		}
+	}
+
+	// The times are in UTC, like the times read back from the database.
+	now := time.Now().UTC()
+	rt = RefreshToken{
+		ClientID:  clientID,
+		FamilyID:  familyID,
+		Hash:      HashRefreshToken(token),
+		ExpiresAt: now.Add(time.Hour * time.Duration(lifespan)),
+		CreatedAt: now,
+	}
+	return
+}
+
+// HashRefreshToken returns the hex encoded SHA-256 hash of a plain refresh token.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomString generates a hex encoded random string of n random bytes.
+func randomString(n int) (s string, err error) {
+	b := make([]byte, n)
+	_, err = rand.Read(b)
+	if err != nil {
+		err = fmt.Errorf("failed to generate random string: %s", err)
+		return
+	}
+	s = hex.EncodeToString(b)
+	return
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRefreshToken(t *testing.T) {
+	t.Run("NewFamily", func(t *testing.T) {
+		token, rt, err := NewRefreshToken(123, "", 1)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+		assert.Equal(t, 123, rt.ClientID)
+		assert.NotEmpty(t, rt.FamilyID)
+		assert.Equal(t, HashRefreshToken(token), rt.Hash)
+		assert.NotEqual(t, token, rt.Hash)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), rt.ExpiresAt, 5*time.Second)
+		assert.Equal(t, time.UTC, rt.ExpiresAt.Location())
+		assert.False(t, rt.IsRevoked())
+		assert.False(t, rt.IsExpired(time.Now()))
+	})
+
+	t.Run("ExistingFamily", func(t *testing.T) {
+		tokenA, rtA, err := NewRefreshToken(123, "family", 1)
+		assert.NoError(t, err)
+		tokenB, rtB, err := NewRefreshToken(123, "family", 1)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "family", rtA.FamilyID)
+		assert.Equal(t, "family", rtB.FamilyID)
+		assert.NotEqual(t, tokenA, tokenB)
+	})
+}
+
+func TestRefreshToken_IsExpired(t *testing.T) {
+	rt := RefreshToken{ExpiresAt: time.Now().Add(-time.Minute)}
+	assert.True(t, rt.IsExpired(time.Now()))
+}
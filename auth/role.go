@@ -0,0 +1,28 @@
+package auth
+
+import "fmt"
+
+// Role represents the access level of a client.
+type Role string
+
+// Constants representing the supported roles.
+const (
+	ADMIN_ROLE    Role = "admin"    // Full access, including client management.
+	OPERATOR_ROLE Role = "operator" // Access to the data of every client.
+	CLIENT_ROLE   Role = "client"   // Access limited to the client's own data.
+)
+
+// IsPrivileged reports whether the role can act on the data of any client.
+func (r Role) IsPrivileged() bool {
+	return r == ADMIN_ROLE || r == OPERATOR_ROLE
+}
+
+// ValidateRole checks if the provided role is one of the supported roles.
+func ValidateRole(r Role) (err error) {
+	switch r {
+	case ADMIN_ROLE, OPERATOR_ROLE, CLIENT_ROLE:
+	default:
+		err = fmt.Errorf("invalid role: unknown role %s", r)
+	}
+	return
+}
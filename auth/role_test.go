@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRole(t *testing.T) {
+	t.Run("ValidRoles", func(t *testing.T) {
+		for _, r := range []Role{ADMIN_ROLE, OPERATOR_ROLE, CLIENT_ROLE} {
+			assert.NoError(t, ValidateRole(r))
+		}
+	})
+
+	t.Run("InvalidRole", func(t *testing.T) {
+		err := ValidateRole("root")
+		assert.Error(t, err)
+		assert.EqualError(t, err, "invalid role: unknown role root")
+	})
+}
+
+func TestRole_IsPrivileged(t *testing.T) {
+	assert.True(t, ADMIN_ROLE.IsPrivileged())
+	assert.True(t, OPERATOR_ROLE.IsPrivileged())
+	assert.False(t, CLIENT_ROLE.IsPrivileged())
+	assert.False(t, Role("").IsPrivileged())
+}
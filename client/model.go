@@ -13,6 +13,7 @@ type Client struct {
 	ID        int       `json:"id,omitempty"`
 	Name      string    `json:"name,omitempty"`
 	Surname   string    `json:"surname,omitempty"`
+	Role      auth.Role `json:"role,omitempty"`
 	CreatedAt time.Time `json:"created_at,omitempty"`
 }
 
@@ -44,6 +45,9 @@ func New(clientR Client) (client Client, err error) {
 	// Clean the surname by removing spaces and converting special characters.
 	client.Surname = utils.RemoveSpaceAndConvertSpecialChars(clientR.Surname)
 
+	// New clients always start with the least privileged role.
+	client.Role = auth.CLIENT_ROLE
+
 	// Set the CreatedAt field to the current time.
 	client.CreatedAt = time.Now()
 
@@ -17,6 +17,7 @@ func TestNewClient(t *testing.T) {
 		},
 		Name:    "John",
 		Surname: "Doe",
+		Role:    auth.ADMIN_ROLE,
 	}
 
 	client, err := New(clientR)
@@ -35,6 +36,9 @@ func TestNewClient(t *testing.T) {
 	// Verify cleaned name and surname
 	assert.Equal(t, "John", client.Name)
 	assert.Equal(t, "Doe", client.Surname)
+
+	// Verify the requested role is ignored
+	assert.Equal(t, auth.CLIENT_ROLE, client.Role)
 }
 
 func TestNewClient_InvalidUsername(t *testing.T) {
@@ -5,19 +5,41 @@ type Config interface {
 	Get() ConfigInfo
 }
 
+// Database drivers the application can store its data with.
+const (
+	POSTGRESQL_DRIVER = "postgres" // PostgreSQL server, the default
+	MEMORY_DRIVER     = "memory"   // In-memory database, lost when the server stops
+	SQLITE_DRIVER     = "sqlite"   // SQLite database file, no server needed
+)
+
 // ConfigInfo holds various configuration settings.
 type ConfigInfo struct {
-	Server               server               `yaml:"server"` // Server configuration
-	PostgreSQLProperties postgreSQLProperties `yaml:"psql"`   // PostgreSQL database properties
+	Server               server               `yaml:"server"`    // Server configuration
+	DatabaseDriver       string               `yaml:"db_driver"` // Database driver to use, one of the driver constants
+	PostgreSQLProperties postgreSQLProperties `yaml:"psql"`      // PostgreSQL database properties
+	SQLiteProperties     sqliteProperties     `yaml:"sqlite"`    // SQLite database properties
 }
 
 // server represents server configuration settings.
 type server struct {
-	Port           int      `yaml:"port"`            // Port the server should listen on
-	Host           string   `yaml:"host"`            // Host address for the server
-	AllowedOrigins []string `yaml:"allowed_origins"` // List of allowed origins for CORS
-	SecretKey      string   `yaml:"secret_key"`      // Secret key for JWT signing
-	JWTLifespan    int      `yaml:"jwt_lifespan"`    // Lifespan of JWT tokens
+	Port                 int      `yaml:"port"`                   // Port the server should listen on
+	Host                 string   `yaml:"host"`                   // Host address for the server
+	AllowedOrigins       []string `yaml:"allowed_origins"`        // List of allowed origins for CORS
+	SecretKey            string   `yaml:"secret_key"`             // Secret key for JWT signing
+	JWTLifespan          int      `yaml:"jwt_lifespan"`           // Lifespan of JWT tokens
+	RefreshTokenLifespan int      `yaml:"refresh_token_lifespan"` // Lifespan of refresh tokens
+	TrackRateLimit       int      `yaml:"track_rate_limit"`       // Public tracking requests allowed per minute and IP
+	TrustedProxies       []string `yaml:"trusted_proxies"`        // IPs and CIDRs of the proxies whose forwarded client IPs are trusted, none if empty
+	PricingRulesFile     string   `yaml:"pricing_rules_file"`     // YAML file with the pricing rules, the database ones are used if empty
+	TaxRate              float64  `yaml:"tax_rate"`               // Tax percentage applied to the quotes
+	QuoteLifespan        int      `yaml:"quote_lifespan"`         // Lifespan of the quotes, in hours
+	MaxPageSize          int      `yaml:"max_page_size"`          // Maximum number of results of a page, unlimited if zero
+	DigestInterval       int      `yaml:"digest_interval"`        // Seconds between the runs of the due saved searches, never run if zero
+	JobWorkers           int      `yaml:"job_workers"`            // Background jobs run at the same time, never run by this server if zero
+	JobInterval          int      `yaml:"job_interval"`           // Seconds between the checks for queued background jobs of an idle worker
+	TimeZone             string   `yaml:"time_zone"`              // IANA time zone the search dates without one are read in
+	AdminUsername        string   `yaml:"admin_username"`         // Username of the client made admin at startup, none if empty
+	AdminPassword        string   `yaml:"admin_password"`         // Password to register the admin with if it does not exist yet
 }
 
 // postgreSQLProperties holds properties for connecting to a PostgreSQL database.
@@ -28,4 +50,13 @@ type postgreSQLProperties struct {
 	Name     string `yaml:"name"`     // Name of the database
 	Host     string `yaml:"host"`     // Host address of the PostgreSQL server
 	Port     int    `yaml:"port"`     // Port number for PostgreSQL connection
+
+	StrictSchema bool `yaml:"strict_schema"` // Whether to refuse to start if the schema has pending migrations
+	QueryTimeout int  `yaml:"query_timeout"` // Maximum duration of each database operation, in seconds, unlimited if zero
+}
+
+// sqliteProperties holds properties for opening a SQLite database.
+type sqliteProperties struct {
+	Path         string `yaml:"path"`          // Path of the database file, created if it does not exist
+	QueryTimeout int    `yaml:"query_timeout"` // Maximum duration of each database operation, in seconds, unlimited if zero
 }
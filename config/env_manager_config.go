@@ -7,6 +7,21 @@ import (
 	"strings"
 )
 
+// Default values used when the optional environment variables are not set.
+const (
+	defaultJWTLifespan          = 1               // One hour
+	defaultRefreshTokenLifespan = 720             // Thirty days
+	defaultTrackRateLimit       = 30              // Thirty requests per minute
+	defaultQuoteLifespan        = 24              // One day
+	defaultQueryTimeout         = 5               // Five seconds
+	defaultMaxPageSize          = 100             // One hundred results
+	defaultDigestInterval       = 60              // One minute
+	defaultJobWorkers           = 2               // Two jobs at the same time
+	defaultJobInterval          = 5               // Five seconds
+	defaultSQLitePath           = "docucenter.db" // File in the working directory
+	defaultTimeZone             = "UTC"           // Coordinated Universal Time
+)
+
 // EnvManagerConfig is a struct that implements the Config interface.
 type EnvManagerConfig struct {
 	config ConfigInfo
@@ -31,20 +46,140 @@ func newConfigWithEnvVars() (conf ConfigInfo, err error) {
 		return
 	}
 
-	// Read database port from environment variable "DB_PORT"
-	dbPort, err := getEnvInt("DB_PORT")
+	// Read database driver from environment variable "DB_DRIVER"
+	dbDriver := os.Getenv("DB_DRIVER")
+	switch dbDriver {
+	case "":
+		dbDriver = POSTGRESQL_DRIVER
+	case POSTGRESQL_DRIVER, MEMORY_DRIVER, SQLITE_DRIVER:
+	default:
+		err = fmt.Errorf("invalid DB_DRIVER env var: unknown driver %s", dbDriver)
+		return
+	}
+
+	// Read database port from environment variable "DB_PORT", only required by PostgreSQL
+	var dbPort int
+	if dbDriver == POSTGRESQL_DRIVER {
+		dbPort, err = getEnvInt("DB_PORT")
+	} else {
+		dbPort, err = getEnvIntOrDefault("DB_PORT", 0)
+	}
+	if err != nil {
+		return
+	}
+
+	// Read JWT lifespan (in hours) from environment variable "SRV_JWT_LIFESPAN"
+	jwtLifespan, err := getEnvIntOrDefault("SRV_JWT_LIFESPAN", defaultJWTLifespan)
+	if err != nil {
+		return
+	}
+	if jwtLifespan <= 0 {
+		err = fmt.Errorf("invalid SRV_JWT_LIFESPAN env var: lifespan must be a positive number of hours %d", jwtLifespan)
+		return
+	}
+
+	// Read refresh token lifespan (in hours) from environment variable "SRV_REFRESH_TOKEN_LIFESPAN"
+	refreshTokenLifespan, err := getEnvIntOrDefault("SRV_REFRESH_TOKEN_LIFESPAN", defaultRefreshTokenLifespan)
+	if err != nil {
+		return
+	}
+
+	// Read public tracking rate limit (requests per minute) from environment variable "SRV_TRACK_RATE_LIMIT"
+	trackRateLimit, err := getEnvIntOrDefault("SRV_TRACK_RATE_LIMIT", defaultTrackRateLimit)
+	if err != nil {
+		return
+	}
+
+	// Read the proxies whose X-Forwarded-For headers are trusted from environment variable "SRV_TRUSTED_PROXIES",
+	// separated by semicolons. Without any, the client IP is always the address of the peer.
+	var trustedProxies []string
+	if v := os.Getenv("SRV_TRUSTED_PROXIES"); v != "" {
+		trustedProxies = strings.Split(v, ";")
+	}
+
+	// Read tax rate (percentage) from environment variable "SRV_TAX_RATE"
+	taxRate, err := getEnvFloatOrDefault("SRV_TAX_RATE", 0)
+	if err != nil {
+		return
+	}
+
+	// Read quote lifespan (in hours) from environment variable "SRV_QUOTE_LIFESPAN"
+	quoteLifespan, err := getEnvIntOrDefault("SRV_QUOTE_LIFESPAN", defaultQuoteLifespan)
+	if err != nil {
+		return
+	}
+
+	// Read maximum page size from environment variable "SRV_MAX_PAGE_SIZE", zero disables it
+	maxPageSize, err := getEnvIntOrDefault("SRV_MAX_PAGE_SIZE", defaultMaxPageSize)
+	if err != nil {
+		return
+	}
+
+	// Read the interval (in seconds) of the saved search runs from environment variable "SRV_DIGEST_INTERVAL", zero disables them
+	digestInterval, err := getEnvIntOrDefault("SRV_DIGEST_INTERVAL", defaultDigestInterval)
+	if err != nil {
+		return
+	}
+
+	// Read the number of background job workers from environment variable "SRV_JOB_WORKERS", zero disables them
+	jobWorkers, err := getEnvIntOrDefault("SRV_JOB_WORKERS", defaultJobWorkers)
+	if err != nil {
+		return
+	}
+
+	// Read the interval (in seconds) of the checks for queued background jobs from environment variable "SRV_JOB_INTERVAL"
+	jobInterval, err := getEnvIntOrDefault("SRV_JOB_INTERVAL", defaultJobInterval)
+	if err != nil {
+		return
+	}
+
+	// Read the time zone of the search dates from environment variable "SRV_TIME_ZONE"
+	timeZone := os.Getenv("SRV_TIME_ZONE")
+	if timeZone == "" {
+		timeZone = defaultTimeZone
+	}
+
+	// Read whether the schema must be current from environment variable "DB_STRICT_SCHEMA"
+	strictSchema, err := getEnvBoolOrDefault("DB_STRICT_SCHEMA", false)
+	if err != nil {
+		return
+	}
+
+	// Read query timeout (in seconds) from environment variable "DB_QUERY_TIMEOUT", zero disables it
+	queryTimeout, err := getEnvIntOrDefault("DB_QUERY_TIMEOUT", defaultQueryTimeout)
 	if err != nil {
 		return
 	}
 
+	// Read SQLite database path from environment variable "DB_SQLITE_PATH"
+	sqlitePath := os.Getenv("DB_SQLITE_PATH")
+	if sqlitePath == "" {
+		sqlitePath = defaultSQLitePath
+	}
+
 	// Create a new ConfigInfo instance using environment variables
 	conf = ConfigInfo{
 		Server: server{
-			Port:           srvPort,
-			Host:           os.Getenv("SRV_HOST"),
-			AllowedOrigins: strings.Split(os.Getenv("SRV_ALLOWED_ORIGINS"), ";"),
-			SecretKey:      os.Getenv("SRV_SECRET_KEY"),
+			Port:                 srvPort,
+			Host:                 os.Getenv("SRV_HOST"),
+			AllowedOrigins:       strings.Split(os.Getenv("SRV_ALLOWED_ORIGINS"), ";"),
+			SecretKey:            os.Getenv("SRV_SECRET_KEY"),
+			JWTLifespan:          jwtLifespan,
+			RefreshTokenLifespan: refreshTokenLifespan,
+			TrackRateLimit:       trackRateLimit,
+			TrustedProxies:       trustedProxies,
+			PricingRulesFile:     os.Getenv("SRV_PRICING_RULES_FILE"),
+			TaxRate:              taxRate,
+			QuoteLifespan:        quoteLifespan,
+			MaxPageSize:          maxPageSize,
+			DigestInterval:       digestInterval,
+			JobWorkers:           jobWorkers,
+			JobInterval:          jobInterval,
+			TimeZone:             timeZone,
+			AdminUsername:        os.Getenv("SRV_ADMIN_USERNAME"),
+			AdminPassword:        os.Getenv("SRV_ADMIN_PASSWORD"),
 		},
+		DatabaseDriver: dbDriver,
 		PostgreSQLProperties: postgreSQLProperties{
 			URL:      os.Getenv("DATABASE_URL"),
 			User:     os.Getenv("DB_USER"),
@@ -52,6 +187,13 @@ func newConfigWithEnvVars() (conf ConfigInfo, err error) {
 			Name:     os.Getenv("DB_NAME"),
 			Host:     os.Getenv("DB_HOST"),
 			Port:     dbPort,
+
+			StrictSchema: strictSchema,
+			QueryTimeout: queryTimeout,
+		},
+		SQLiteProperties: sqliteProperties{
+			Path:         sqlitePath,
+			QueryTimeout: queryTimeout,
 		},
 	}
 	return
@@ -65,3 +207,41 @@ func getEnvInt(n string) (i int, err error) {
 	}
 	return
 }
+
+// getEnvIntOrDefault retrieves an optional integer environment variable and converts it.
+// If the variable is not set, it returns the provided default value.
+func getEnvIntOrDefault(n string, d int) (i int, err error) {
+	if os.Getenv(n) == "" {
+		i = d
+		return
+	}
+	return getEnvInt(n)
+}
+
+// getEnvFloatOrDefault retrieves an optional float environment variable and converts it.
+// If the variable is not set, it returns the provided default value.
+func getEnvFloatOrDefault(n string, d float64) (f float64, err error) {
+	if os.Getenv(n) == "" {
+		f = d
+		return
+	}
+	f, err = strconv.ParseFloat(os.Getenv(n), 64)
+	if err != nil {
+		err = fmt.Errorf("failed to load env var float %s: %s", n, err)
+	}
+	return
+}
+
+// getEnvBoolOrDefault retrieves an optional boolean environment variable and converts it.
+// If the variable is not set, it returns the provided default value.
+func getEnvBoolOrDefault(n string, d bool) (b bool, err error) {
+	if os.Getenv(n) == "" {
+		b = d
+		return
+	}
+	b, err = strconv.ParseBool(os.Getenv(n))
+	if err != nil {
+		err = fmt.Errorf("failed to load env var bool %s: %s", n, err)
+	}
+	return
+}
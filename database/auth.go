@@ -1,6 +1,10 @@
 package database
 
 import (
+	"context"
+
+	"time"
+
 	"github.com/coffemanfp/docucentertest/auth"
 	"github.com/coffemanfp/docucentertest/client"
 )
@@ -11,8 +15,35 @@ const AUTH_REPOSITORY RepositoryID = "AUTH"
 // AuthRepository defines the behaviors to be used by a AuthRepository implementation.
 type AuthRepository interface {
 	// GetIdAndHashedPassword retrieves the user ID and hashed password for the given authentication data.
-	GetIdAndHashedPassword(auth auth.Auth) (id int, hash string, err error)
+	GetIdAndHashedPassword(ctx context.Context, auth auth.Auth) (id int, hash string, err error)
 
 	// Register registers a new client with authentication and returns the assigned ID.
-	Register(client client.Client) (id int, err error)
+	Register(ctx context.Context, client client.Client) (id int, err error)
+
+	// GetRole retrieves the role of the client with the given ID.
+	GetRole(ctx context.Context, id int) (role auth.Role, err error)
+
+	// SaveRefreshToken stores a new refresh token.
+	SaveRefreshToken(ctx context.Context, rt auth.RefreshToken) (err error)
+
+	// GetRefreshToken retrieves a refresh token by the hash of its plain value.
+	GetRefreshToken(ctx context.Context, hash string) (rt auth.RefreshToken, err error)
+
+	// RevokeRefreshToken marks an active refresh token as used.
+	// It returns a NOT_FOUND error if the token was already used or revoked.
+	RevokeRefreshToken(ctx context.Context, id int) (err error)
+
+	// RevokeRefreshTokenFamily revokes every active refresh token of the given family.
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) (err error)
+
+	// RevokeToken adds the ID of an access token to the revocation list.
+	// A nil expiresAt means the token never expires.
+	RevokeToken(ctx context.Context, jti string, expiresAt *time.Time) (err error)
+
+	// IsTokenRevoked checks if the ID of an access token is in the revocation list.
+	IsTokenRevoked(ctx context.Context, jti string) (revoked bool, err error)
+
+	// PurgeExpiredTokens deletes the refresh tokens and the revoked access tokens expired at the given time,
+	// which can not be used anymore anyway.
+	PurgeExpiredTokens(ctx context.Context, now time.Time) (err error)
 }
@@ -1,15 +1,29 @@
 package database
 
-import "github.com/coffemanfp/docucentertest/client"
+import (
+	"context"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/client"
+	"github.com/coffemanfp/docucentertest/search"
+)
 
 // Constant CLIENT_REPOSITORY is used to uniquely identify the client repository.
 const CLIENT_REPOSITORY RepositoryID = "CLIENT_REPOSITORYY"
 
+// ANY_CLIENT is the client ID to be used when an operation must not be restricted to a single client.
+// It is used for the privileged roles, which can act on the data of every client.
+const ANY_CLIENT = 0
+
 // ClientRepository defines the methods for working with client data in the database.
 type ClientRepository interface {
-	// Get retrieves a list of clients based on the given page number.
-	Get(page int) (clients []*client.Client, err error)
+	// Get retrieves a page of clients based on the given pagination.
+	// It also returns the total number of clients, regardless of the pagination.
+	Get(ctx context.Context, pagination search.Pagination) (clients []*client.Client, total int, err error)
 
 	// GetOne retrieves a specific client based on the provided ID.
-	GetOne(id int) (client client.Client, err error)
+	GetOne(ctx context.Context, id int) (client client.Client, err error)
+
+	// UpdateRole updates the role of the client with the provided ID.
+	UpdateRole(ctx context.Context, id int, role auth.Role) (err error)
 }
@@ -1,14 +1,39 @@
 package database
 
+import (
+	"context"
+	"errors"
+)
+
 // Database is the Database manager for connections and repository instancies.
 type Database struct {
 	Conn         DatabaseConnector
 	Repositories Repositories
 }
 
+// WithTx runs fn with repositories sharing a single transaction.
+// The transaction is committed if fn succeeds and rolled back if it returns an error.
+// It fails if the connector does not support transactions.
+func (d Database) WithTx(ctx context.Context, fn func(tx Repositories) error) (err error) {
+	txConn, ok := d.Conn.(TxConnector)
+	if !ok {
+		err = errors.New("unsupported transactions: the database connector does not support transactions")
+		return
+	}
+	return txConn.WithTx(ctx, fn)
+}
+
 // DatabaseConnector defines a database connector handler.
 type DatabaseConnector interface {
 
 	// Connect creates new connection of the database implementation.
 	Connect() error
 }
+
+// TxConnector defines a database connector able to run a unit of work in a transaction.
+type TxConnector interface {
+	DatabaseConnector
+
+	// WithTx runs fn with repositories sharing a single transaction.
+	WithTx(ctx context.Context, fn func(tx Repositories) error) error
+}
@@ -0,0 +1,76 @@
+package databasetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuthRepository checks that the database.AuthRepository of the databases created by newDB fulfils its contract.
+func TestAuthRepository(t *testing.T, newDB Factory) {
+	t.Run("RefreshTokenInLocation", func(t *testing.T) {
+		db := newDB(t)
+		repo, err := database.GetRepository[database.AuthRepository](db.Repositories, database.AUTH_REPOSITORY)
+		require.NoError(t, err)
+		ctx := context.Background()
+
+		// The times of other locations are stored as the same instants.
+		location := time.FixedZone("UTC-5", -5*60*60)
+		rt := auth.RefreshToken{
+			ClientID:  1,
+			FamilyID:  "family",
+			Hash:      "hash",
+			ExpiresAt: baseTime.Add(time.Hour).In(location),
+			CreatedAt: baseTime.In(location),
+		}
+		require.NoError(t, repo.SaveRefreshToken(ctx, rt))
+
+		got, err := repo.GetRefreshToken(ctx, "hash")
+		require.NoError(t, err)
+		assert.True(t, rt.ExpiresAt.Equal(got.ExpiresAt), "expires at %s, expected %s", got.ExpiresAt, rt.ExpiresAt)
+		assert.True(t, rt.CreatedAt.Equal(got.CreatedAt), "created at %s, expected %s", got.CreatedAt, rt.CreatedAt)
+		assert.False(t, got.IsExpired(baseTime.Add(59*time.Minute)))
+	})
+
+	t.Run("PurgeExpiredTokens", func(t *testing.T) {
+		db := newDB(t)
+		repo, err := database.GetRepository[database.AuthRepository](db.Repositories, database.AUTH_REPOSITORY)
+		require.NoError(t, err)
+		ctx := context.Background()
+
+		// The first token of each table expired before the purge, and the second one expires after it.
+		expired, valid := baseTime.Add(-time.Hour), baseTime.Add(time.Hour)
+		for n, expiresAt := range []time.Time{expired, valid} {
+			require.NoError(t, repo.SaveRefreshToken(ctx, auth.RefreshToken{
+				ClientID:  1,
+				FamilyID:  "family",
+				Hash:      fmt.Sprintf("hash %d", n),
+				ExpiresAt: expiresAt,
+				CreatedAt: baseTime.Add(-2 * time.Hour),
+			}))
+		}
+		require.NoError(t, repo.RevokeToken(ctx, "expired", &expired))
+		require.NoError(t, repo.RevokeToken(ctx, "valid", &valid))
+		require.NoError(t, repo.RevokeToken(ctx, "endless", nil))
+
+		require.NoError(t, repo.PurgeExpiredTokens(ctx, baseTime))
+
+		_, err = repo.GetRefreshToken(ctx, "hash 0")
+		assertErrorType(t, errors.NOT_FOUND, err)
+		_, err = repo.GetRefreshToken(ctx, "hash 1")
+		assert.NoError(t, err)
+
+		for jti, expected := range map[string]bool{"expired": false, "valid": true, "endless": true} {
+			revoked, err := repo.IsTokenRevoked(ctx, jti)
+			require.NoError(t, err)
+			assert.Equal(t, expected, revoked, jti)
+		}
+	})
+}
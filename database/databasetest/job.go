@@ -0,0 +1,215 @@
+package databasetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/coffemanfp/docucentertest/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestJobRepository checks that the database.JobRepository of the databases created by newDB fulfils its contract.
+func TestJobRepository(t *testing.T, newDB Factory) {
+	t.Run("CreateAndGetOne", func(t *testing.T) {
+		repo := jobRepository(t, newDB)
+		ctx := context.Background()
+
+		j := newJob(t, 1, 1, baseTime)
+		id, err := repo.Create(ctx, j)
+		require.NoError(t, err)
+		assert.Positive(t, id)
+		j.ID = id
+
+		// The parameters and input are not retrieved with the job.
+		j.Params, j.Input = nil, nil
+		got, err := repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		assertJob(t, j, got)
+
+		got, err = repo.GetOne(ctx, id, database.ANY_CLIENT)
+		require.NoError(t, err)
+		assertJob(t, j, got)
+
+		// The jobs of other clients are not found.
+		_, err = repo.GetOne(ctx, id, 2)
+		assertErrorType(t, errors.NOT_FOUND, err)
+		_, err = repo.GetOne(ctx, id+1, database.ANY_CLIENT)
+		assertErrorType(t, errors.NOT_FOUND, err)
+	})
+
+	t.Run("Claim", func(t *testing.T) {
+		repo := jobRepository(t, newDB)
+		ctx := context.Background()
+
+		// The second job is the earliest due, and the third one is not due yet.
+		ids := make([]int, 0)
+		for n, minutes := range []int{2, 1, 10} {
+			id, err := repo.Create(ctx, newJob(t, 1, n+1, baseTime.Add(time.Duration(minutes)*time.Minute)))
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+
+		now := baseTime.Add(5 * time.Minute)
+		j, err := repo.Claim(ctx, now, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, ids[1], j.ID)
+		assert.Equal(t, jobs.RUNNING_STATUS, j.Status)
+		assert.Equal(t, 1, j.Attempts)
+		assert.JSONEq(t, `{"n": 2}`, string(j.Params))
+		assert.Equal(t, []byte("input 2"), j.Input)
+		require.NotNil(t, j.LockedUntil)
+		assert.True(t, now.Add(time.Minute).Equal(*j.LockedUntil))
+
+		j, err = repo.Claim(ctx, now, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, ids[0], j.ID)
+
+		// The running jobs are not claimed again before their lease expires.
+		_, err = repo.Claim(ctx, now, time.Minute)
+		assertErrorType(t, errors.NOT_FOUND, err)
+
+		got, err := repo.GetOne(ctx, ids[1], 1)
+		require.NoError(t, err)
+		assert.Equal(t, jobs.RUNNING_STATUS, got.Status)
+		assert.Equal(t, 1, got.Attempts)
+		require.NotNil(t, got.StartedAt)
+		assert.True(t, now.Equal(*got.StartedAt))
+	})
+
+	t.Run("HeartbeatAndFinish", func(t *testing.T) {
+		repo := jobRepository(t, newDB)
+		ctx := context.Background()
+
+		id, err := repo.Create(ctx, newJob(t, 1, 1, baseTime))
+		require.NoError(t, err)
+
+		j, err := repo.Claim(ctx, baseTime, time.Minute)
+		require.NoError(t, err)
+
+		// The heartbeats store the progress and renew the lease.
+		j.Progress = 40
+		lockedUntil := baseTime.Add(2 * time.Minute)
+		j.LockedUntil = &lockedUntil
+		require.NoError(t, repo.Heartbeat(ctx, j))
+
+		_, err = repo.Claim(ctx, baseTime.Add(90*time.Second), time.Minute)
+		assertErrorType(t, errors.NOT_FOUND, err)
+
+		got, err := repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 40, got.Progress)
+
+		// No result can be downloaded until the job succeeds.
+		_, err = repo.GetResult(ctx, id, 1)
+		assertErrorType(t, errors.NOT_FOUND, err)
+
+		result := &jobs.Result{Name: "products.csv", ContentType: "text/csv", Data: []byte("id\n1\n")}
+		done := j.Succeeded(result, baseTime.Add(time.Minute))
+		require.NoError(t, repo.Finish(ctx, done, result))
+
+		got, err = repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		assert.Equal(t, jobs.SUCCEEDED_STATUS, got.Status)
+		assert.Equal(t, 100, got.Progress)
+		assert.Equal(t, "products.csv", got.ResultName)
+		assert.Equal(t, "text/csv", got.ResultType)
+		require.NotNil(t, got.FinishedAt)
+		assert.True(t, baseTime.Add(time.Minute).Equal(*got.FinishedAt))
+
+		r, err := repo.GetResult(ctx, id, 1)
+		require.NoError(t, err)
+		assert.Equal(t, *result, r)
+
+		// The results of other clients are not found.
+		_, err = repo.GetResult(ctx, id, 2)
+		assertErrorType(t, errors.NOT_FOUND, err)
+
+		// The finished attempt can not be updated anymore.
+		assertErrorType(t, errors.CONFLICT, repo.Heartbeat(ctx, j))
+		assertErrorType(t, errors.CONFLICT, repo.Finish(ctx, done, nil))
+
+		// The finished jobs are not claimed again.
+		_, err = repo.Claim(ctx, baseTime.Add(time.Hour), time.Minute)
+		assertErrorType(t, errors.NOT_FOUND, err)
+	})
+
+	t.Run("RetryAndExpiredLease", func(t *testing.T) {
+		repo := jobRepository(t, newDB)
+		ctx := context.Background()
+
+		id, err := repo.Create(ctx, newJob(t, 1, 1, baseTime))
+		require.NoError(t, err)
+
+		// A failed attempt is queued again after its backoff.
+		j, err := repo.Claim(ctx, baseTime, time.Minute)
+		require.NoError(t, err)
+		failed := j.Failed(fmt.Errorf("connection reset"), baseTime)
+		require.NoError(t, repo.Finish(ctx, failed, nil))
+
+		got, err := repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		assert.Equal(t, jobs.QUEUED_STATUS, got.Status)
+		assert.Equal(t, "connection reset", got.Error)
+		assert.True(t, baseTime.Add(jobs.BASE_BACKOFF).Equal(got.RunAt))
+
+		_, err = repo.Claim(ctx, baseTime.Add(jobs.BASE_BACKOFF-time.Second), time.Minute)
+		assertErrorType(t, errors.NOT_FOUND, err)
+
+		now := baseTime.Add(jobs.BASE_BACKOFF)
+		first, err := repo.Claim(ctx, now, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, 2, first.Attempts)
+		assert.Empty(t, first.Error)
+
+		// Once its lease expires, the job is claimed by another worker, and the first one can not update it anymore.
+		second, err := repo.Claim(ctx, now.Add(2*time.Minute), time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, id, second.ID)
+		assert.Equal(t, 3, second.Attempts)
+
+		assertErrorType(t, errors.CONFLICT, repo.Heartbeat(ctx, first))
+		assertErrorType(t, errors.CONFLICT, repo.Finish(ctx, first.Succeeded(nil, now), nil))
+
+		require.NoError(t, repo.Finish(ctx, second.Succeeded(nil, now.Add(2*time.Minute)), nil))
+		got, err = repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		assert.Equal(t, jobs.SUCCEEDED_STATUS, got.Status)
+		assert.Equal(t, 3, got.Attempts)
+
+		// A job that succeeded without a result has none to download.
+		_, err = repo.GetResult(ctx, id, 1)
+		assertErrorType(t, errors.NOT_FOUND, err)
+	})
+}
+
+// jobRepository creates a fresh database with newDB and returns its job repository.
+func jobRepository(t *testing.T, newDB Factory) database.JobRepository {
+	t.Helper()
+	db := newDB(t)
+	repo, err := database.GetRepository[database.JobRepository](db.Repositories, database.JOB_REPOSITORY)
+	require.NoError(t, err)
+	return repo
+}
+
+// newJob creates the fixture export job number n of a client, queued to run at the given time.
+func newJob(t *testing.T, clientID, n int, runAt time.Time) jobs.Job {
+	t.Helper()
+	j, err := jobs.New(clientID, jobs.EXPORT_PRODUCTS_KIND, map[string]int{"n": n}, []byte(fmt.Sprintf("input %d", n)), runAt)
+	require.NoError(t, err)
+	return j
+}
+
+// assertJob asserts that a job read from a backend is the expected one, comparing the times as instants.
+func assertJob(t *testing.T, expected, actual jobs.Job) {
+	t.Helper()
+	assert.True(t, expected.RunAt.Equal(actual.RunAt), "run at %s, expected %s", actual.RunAt, expected.RunAt)
+	assert.True(t, expected.CreatedAt.Equal(actual.CreatedAt), "created at %s, expected %s", actual.CreatedAt, expected.CreatedAt)
+	expected.RunAt, actual.RunAt = time.Time{}, time.Time{}
+	expected.CreatedAt, actual.CreatedAt = time.Time{}, time.Time{}
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,901 @@
+// Package databasetest implements conformance tests for the implementations of the database repositories.
+// Every backend is expected to run them from its own tests, so they all behave like the PostgreSQL one.
+package databasetest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory creates a fresh and empty database for a test, with every repository registered.
+// Any cleanup must be registered with t.Cleanup.
+type Factory func(t *testing.T) database.Database
+
+// pageSize is the number of products expected in every full page.
+const pageSize = 20
+
+// baseTime is the time every fixture date is relative to.
+var baseTime = time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+// TestProductRepository checks that the database.ProductRepository of the databases created by newDB fulfils its contract.
+func TestProductRepository(t *testing.T, newDB Factory) {
+	t.Run("CreateAndGetOne", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		p := newProduct(1, 1)
+		id, err := repo.Create(ctx, p)
+		require.NoError(t, err)
+		assert.Positive(t, id)
+		p.ID = id
+
+		got, err := repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		assert.Equal(t, normalize(p), normalize(got))
+
+		got, err = repo.GetOne(ctx, id, database.ANY_CLIENT)
+		require.NoError(t, err)
+		assert.Equal(t, normalize(p), normalize(got))
+
+		got, err = repo.GetByGuideNumber(ctx, *p.GuideNumber)
+		require.NoError(t, err)
+		assert.Equal(t, normalize(p), normalize(got))
+	})
+
+	t.Run("CreateWithoutOptionalFields", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		p := newProduct(1, 1)
+		p.Port, p.Vault = nil, nil
+		id, err := repo.Create(ctx, p)
+		require.NoError(t, err)
+
+		got, err := repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		assert.Nil(t, got.Port)
+		assert.Nil(t, got.Vault)
+		assert.Nil(t, got.QuoteID)
+	})
+
+	t.Run("CreateDuplicatedGuideNumber", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		_, err := repo.Create(ctx, newProduct(1, 1))
+		require.NoError(t, err)
+
+		// The guide number is unique across every client.
+		_, err = repo.Create(ctx, newProduct(2, 1))
+		assertErrorType(t, errors.ALREADY_EXISTS, err)
+	})
+
+	t.Run("CreateBatch", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		ps := []product.Product{newProduct(1, 3), newProduct(2, 1), newProduct(1, 2)}
+		ids, err := repo.CreateBatch(ctx, ps)
+		require.NoError(t, err)
+		if assert.Len(t, ids, len(ps)) {
+			for i, p := range ps {
+				p.ID = ids[i]
+				got, err := repo.GetOne(ctx, ids[i], database.ANY_CLIENT)
+				require.NoError(t, err)
+				assert.Equal(t, normalize(p), normalize(got))
+			}
+		}
+
+		ids, err = repo.CreateBatch(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("CreateBatchDuplicatedGuideNumber", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		_, err := repo.Create(ctx, newProduct(1, 2))
+		require.NoError(t, err)
+
+		// No product of the batch is inserted if any guide number exists.
+		_, err = repo.CreateBatch(ctx, []product.Product{newProduct(1, 1), newProduct(1, 2)})
+		assertErrorType(t, errors.ALREADY_EXISTS, err)
+		_, err = repo.GetByGuideNumber(ctx, *newProduct(1, 1).GuideNumber)
+		assertErrorType(t, errors.NOT_FOUND, err)
+
+		// Nor if the batch repeats one.
+		_, err = repo.CreateBatch(ctx, []product.Product{newProduct(1, 3), newProduct(2, 3)})
+		assertErrorType(t, errors.ALREADY_EXISTS, err)
+		_, err = repo.GetByGuideNumber(ctx, *newProduct(1, 3).GuideNumber)
+		assertErrorType(t, errors.NOT_FOUND, err)
+	})
+
+	t.Run("GetOneNotFound", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		id, err := repo.Create(ctx, newProduct(1, 1))
+		require.NoError(t, err)
+
+		_, err = repo.GetOne(ctx, id+1, database.ANY_CLIENT)
+		assertErrorType(t, errors.NOT_FOUND, err)
+
+		// Products of another client are not found.
+		_, err = repo.GetOne(ctx, id, 2)
+		assertErrorType(t, errors.NOT_FOUND, err)
+
+		_, err = repo.GetByGuideNumber(ctx, *newProduct(1, 2).GuideNumber)
+		assertErrorType(t, errors.NOT_FOUND, err)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		p := newProduct(1, 1)
+		id, err := repo.Create(ctx, p)
+		require.NoError(t, err)
+		p.ID = id
+
+		// Only the non-nil fields are updated.
+		productType := "pallet"
+		quantity := 99
+		update := product.Product{
+			ID:       id,
+			ClientID: 1,
+			Type:     &productType,
+			Quantity: &quantity,
+			Status:   product.DELIVERED_STATUS,
+		}
+		err = repo.Update(ctx, update)
+		require.NoError(t, err)
+
+		p.Type = &productType
+		p.Quantity = &quantity
+		got, err := repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		assert.Equal(t, normalize(p), normalize(got))
+
+		// Updating every field at once replaces all of them, the nil port keeps the previous one.
+		updated := newProduct(1, 2)
+		updated.ID = id
+		err = repo.Update(ctx, updated)
+		require.NoError(t, err)
+
+		updated.Port = p.Port
+		got, err = repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		assert.Equal(t, normalize(updated), normalize(got))
+
+		// An update without fields changes nothing.
+		err = repo.Update(ctx, product.Product{ID: id, ClientID: 1})
+		require.NoError(t, err)
+
+		got, err = repo.GetOne(ctx, id, database.ANY_CLIENT)
+		require.NoError(t, err)
+		assert.Equal(t, normalize(updated), normalize(got))
+	})
+
+	t.Run("UpdateAnyClient", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		id, err := repo.Create(ctx, newProduct(1, 1))
+		require.NoError(t, err)
+
+		quantity := 7
+		err = repo.Update(ctx, product.Product{ID: id, ClientID: database.ANY_CLIENT, Quantity: &quantity})
+		require.NoError(t, err)
+
+		got, err := repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, got.ClientID)
+		assert.Equal(t, quantity, *got.Quantity)
+	})
+
+	t.Run("UpdateErrors", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		id, err := repo.Create(ctx, newProduct(1, 1))
+		require.NoError(t, err)
+		_, err = repo.Create(ctx, newProduct(1, 2))
+		require.NoError(t, err)
+
+		quantity := 7
+		err = repo.Update(ctx, product.Product{ID: id + 100, ClientID: 1, Quantity: &quantity})
+		assertErrorType(t, errors.NOT_FOUND, err)
+
+		// Products of another client can not be updated, and are left untouched.
+		err = repo.Update(ctx, product.Product{ID: id, ClientID: 2, Quantity: &quantity})
+		assertErrorType(t, errors.NOT_FOUND, err)
+
+		got, err := repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		assert.Equal(t, *newProduct(1, 1).Quantity, *got.Quantity)
+
+		// The guide number is still unique after updating.
+		err = repo.Update(ctx, product.Product{ID: id, ClientID: 1, GuideNumber: newProduct(1, 2).GuideNumber})
+		assertErrorType(t, errors.ALREADY_EXISTS, err)
+	})
+
+	t.Run("UpdateStatus", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		id, err := repo.Create(ctx, newProduct(1, 1))
+		require.NoError(t, err)
+
+		err = repo.UpdateStatus(ctx, id, 1, product.REGISTERED_STATUS, product.IN_TRANSIT_STATUS)
+		require.NoError(t, err)
+
+		got, err := repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		assert.Equal(t, product.IN_TRANSIT_STATUS, got.Status)
+
+		// The product is no longer in the from status.
+		err = repo.UpdateStatus(ctx, id, 1, product.REGISTERED_STATUS, product.CANCELLED_STATUS)
+		assertErrorType(t, errors.CONFLICT, err)
+
+		err = repo.UpdateStatus(ctx, id, 2, product.IN_TRANSIT_STATUS, product.AT_PORT_STATUS)
+		assertErrorType(t, errors.NOT_FOUND, err)
+
+		err = repo.UpdateStatus(ctx, id+1, database.ANY_CLIENT, product.IN_TRANSIT_STATUS, product.AT_PORT_STATUS)
+		assertErrorType(t, errors.NOT_FOUND, err)
+
+		err = repo.UpdateStatus(ctx, id, database.ANY_CLIENT, product.IN_TRANSIT_STATUS, product.AT_PORT_STATUS)
+		require.NoError(t, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		id, err := repo.Create(ctx, newProduct(1, 1))
+		require.NoError(t, err)
+
+		// Products of another client can not be deleted, and are left untouched.
+		err = repo.Delete(ctx, id, 2)
+		assertErrorType(t, errors.NOT_FOUND, err)
+
+		_, err = repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+
+		err = repo.Delete(ctx, id, 1)
+		require.NoError(t, err)
+
+		_, err = repo.GetOne(ctx, id, 1)
+		assertErrorType(t, errors.NOT_FOUND, err)
+
+		// Deleting it again fails.
+		err = repo.Delete(ctx, id, 1)
+		assertErrorType(t, errors.NOT_FOUND, err)
+
+		// The guide number can be reused once deleted.
+		_, err = repo.Create(ctx, newProduct(1, 1))
+		require.NoError(t, err)
+	})
+
+	t.Run("DeleteAnyClient", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		id, err := repo.Create(ctx, newProduct(1, 1))
+		require.NoError(t, err)
+
+		err = repo.Delete(ctx, id, database.ANY_CLIENT)
+		require.NoError(t, err)
+
+		_, err = repo.GetOne(ctx, id, database.ANY_CLIENT)
+		assertErrorType(t, errors.NOT_FOUND, err)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		// Two and a half pages of products of client 1, and a few of client 2.
+		ids := make([]int, 0)
+		for i := 1; i <= 2*pageSize+pageSize/2; i++ {
+			id, err := repo.Create(ctx, newProduct(1, i))
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+		others := make([]int, 0)
+		for i := 1; i <= 3; i++ {
+			id, err := repo.Create(ctx, newProduct(2, 1000+i))
+			require.NoError(t, err)
+			others = append(others, id)
+		}
+
+		// Every product of the client is returned once, and only in one page.
+		seen := make([]int, 0)
+		for page, expected := range []int{pageSize, pageSize, pageSize / 2, 0} {
+			ps, total, err := repo.Get(ctx, pageOf(page), 1)
+			require.NoError(t, err)
+			require.Len(t, ps, expected, "page %d", page)
+			assert.Equal(t, len(ids), total, "page %d", page)
+			for _, p := range ps {
+				assert.Equal(t, 1, p.ClientID)
+				seen = append(seen, p.ID)
+			}
+		}
+		assert.ElementsMatch(t, ids, seen)
+
+		// Products of another client are never returned.
+		ps, total, err := repo.Get(ctx, pageOf(0), 2)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, others, productIDs(ps))
+		assert.Equal(t, len(others), total)
+
+		// Every client is returned without a client.
+		n := 0
+		for page := 0; page < 4; page++ {
+			ps, total, err = repo.Get(ctx, pageOf(page), database.ANY_CLIENT)
+			require.NoError(t, err)
+			assert.Equal(t, len(ids)+len(others), total)
+			n += len(ps)
+		}
+		assert.Equal(t, len(ids)+len(others), n)
+
+		// Any window can be requested, and a zero limit returns every product from the offset.
+		ps, _, err = repo.Get(ctx, search.Pagination{Limit: 3, Offset: 5}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, ids[5:8], productIDs(ps))
+		ps, _, err = repo.Get(ctx, search.Pagination{Offset: 5}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, ids[5:], productIDs(ps))
+
+		// A cursor walks every product once, even when products before it are deleted, which would shift an offset.
+		seen = make([]int, 0)
+		var cursor *search.Cursor
+		for {
+			ps, _, err = repo.Get(ctx, search.Pagination{Limit: pageSize, After: cursor}, 1)
+			require.NoError(t, err)
+			if len(ps) == 0 {
+				break
+			}
+			if cursor == nil {
+				err = repo.Delete(ctx, ps[0].ID, 1)
+				require.NoError(t, err)
+			}
+			seen = append(seen, productIDs(ps)...)
+			cursor = &search.Cursor{ID: ps[len(ps)-1].ID}
+		}
+		assert.Equal(t, ids, seen)
+
+		// An empty database has empty pages, not nil ones.
+		ps, total, err = repo.Get(ctx, pageOf(0), 3)
+		require.NoError(t, err)
+		assert.NotNil(t, ps)
+		assert.Empty(t, ps)
+		assert.Zero(t, total)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		// Five products with increasing values, the first three of client 1.
+		ids := make(map[int]int)
+		for i := 1; i <= 5; i++ {
+			clientID := 1
+			if i > 3 {
+				clientID = 2
+			}
+			id, err := repo.Create(ctx, newProduct(clientID, i))
+			require.NoError(t, err)
+			ids[i] = id
+		}
+		err := repo.UpdateStatus(ctx, ids[5], database.ANY_CLIENT, product.REGISTERED_STATUS, product.IN_TRANSIT_STATUS)
+		require.NoError(t, err)
+
+		for _, tc := range searchCases() {
+			t.Run(tc.name, func(t *testing.T) {
+				ps, total, err := repo.Search(ctx, tc.search)
+				require.NoError(t, err)
+				assert.NotNil(t, ps)
+				assert.Equal(t, len(tc.expected), total)
+
+				expected := make([]int, 0)
+				for _, i := range tc.expected {
+					expected = append(expected, ids[i])
+				}
+				assert.ElementsMatch(t, expected, productIDs(ps))
+			})
+		}
+
+		// Only the window of the search pagination is returned, ordered by ID, along with every match.
+		ps, total, err := repo.Search(ctx, search.Search{ClientID: 1, Pagination: search.Pagination{Limit: 2, Offset: 1}})
+		require.NoError(t, err)
+		assert.Equal(t, []int{ids[2], ids[3]}, productIDs(ps))
+		assert.Equal(t, 3, total)
+
+		// Only the matches after the cursor are returned, still counting every match.
+		ps, total, err = repo.Search(ctx, search.Search{Type: search.StringFilter{Values: []string{"pallet"}}, Pagination: search.Pagination{Limit: 1, After: &search.Cursor{ID: ids[2]}}})
+		require.NoError(t, err)
+		assert.Equal(t, []int{ids[4]}, productIDs(ps))
+		assert.Equal(t, 2, total)
+	})
+
+	t.Run("SearchInLocation", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		ids := make(map[int]int)
+		for i := 1; i <= 3; i++ {
+			id, err := repo.Create(ctx, newProduct(1, i))
+			require.NoError(t, err)
+			ids[i] = id
+		}
+
+		// The calendar dates are read fourteen hours ahead of UTC, so the second product, delivered at noon in UTC
+		// of the 13th of March, is delivered on the 14th, and the first one on the 13th.
+		search.SetLocation(time.FixedZone("UTC+14", 14*60*60))
+		t.Cleanup(func() {
+			search.SetLocation(time.UTC)
+		})
+
+		srch, err := search.Criteria{Delivered: "2023-03-13"}.Search(database.ANY_CLIENT)
+		require.NoError(t, err)
+		ps, total, err := repo.Search(ctx, srch)
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, []int{ids[1]}, productIDs(ps))
+
+		// The 4th of March starts at 10 o'clock in UTC of the 3rd, before the second product joined.
+		srch, err = search.Criteria{StartJoinedAt: "2023-03-04"}.Search(database.ANY_CLIENT)
+		require.NoError(t, err)
+		ps, _, err = repo.Search(ctx, srch)
+		require.NoError(t, err)
+		assert.Equal(t, []int{ids[2], ids[3]}, productIDs(ps))
+	})
+
+	t.Run("SearchPeriodEnd", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		// Delivered less than a millisecond before April, which is still March. SQLite rounds the timestamps
+		// it compares to milliseconds, so the time is closer to the last millisecond of March than to April.
+		p := newProduct(1, 1)
+		deliveredAt := time.Date(2024, time.March, 31, 23, 59, 59, 999400000, time.UTC)
+		p.DeliveredAt = &deliveredAt
+		id, err := repo.Create(ctx, p)
+		require.NoError(t, err)
+
+		for delivered, expected := range map[string][]int{"2024-03": {id}, "2024-04": {}} {
+			srch, err := search.Criteria{Delivered: delivered}.Search(database.ANY_CLIENT)
+			require.NoError(t, err)
+			ps, _, err := repo.Search(ctx, srch)
+			require.NoError(t, err)
+			assert.Equal(t, expected, productIDs(ps), delivered)
+		}
+	})
+
+	t.Run("FullTextRank", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		// A product whose type has the words of the plate of another one, created first.
+		crate := newProduct(1, 9)
+		crateType := "aaa-003 crate"
+		crate.Type = &crateType
+		crateID, err := repo.Create(ctx, crate)
+		require.NoError(t, err)
+		boxID, err := repo.Create(ctx, newProduct(1, 3))
+		require.NoError(t, err)
+		_, err = repo.Create(ctx, newProduct(1, 4))
+		require.NoError(t, err)
+
+		// The product with the exact plate is more relevant than the one with its words.
+		query, err := search.NewQuery("AAA-003")
+		require.NoError(t, err)
+		ps, total, err := repo.Search(ctx, search.Search{Query: query, Pagination: search.Pagination{Limit: 10}})
+		require.NoError(t, err)
+		assert.Equal(t, []int{boxID, crateID}, productIDs(ps))
+		assert.Equal(t, 2, total)
+
+		// An explicit sort replaces the relevance.
+		ps, _, err = repo.Search(ctx, search.Search{Query: query, Pagination: search.Pagination{Sort: search.Sort{{Name: "guide_number", Desc: true}}}})
+		require.NoError(t, err)
+		assert.Equal(t, []int{crateID, boxID}, productIDs(ps))
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		ids := make([]int, 0)
+		for i := 1; i <= 5; i++ {
+			id, err := repo.Create(ctx, newProduct(1+i%2, i))
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+		// export collects the batches of an export of srch, failing with err after the given number of batches.
+		export := func(srch search.Search, size, failAfter int, err error) (batches [][]int, exportErr error) {
+			exportErr = repo.Export(ctx, srch, size, func(ps []*product.Product) error {
+				batches = append(batches, productIDs(ps))
+				if len(batches) == failAfter {
+					return err
+				}
+				return nil
+			})
+			return
+		}
+
+		// Every matching product is exported in batches, regardless of the pagination.
+		batches, err := export(search.Search{ClientID: 2, Pagination: search.Pagination{Limit: 1, Offset: 1}}, 1, 0, nil)
+		require.NoError(t, err)
+		assert.Equal(t, [][]int{{ids[0]}, {ids[2]}, {ids[4]}}, batches)
+
+		// A ranked search is exported in the order of relevance.
+		query, err := search.NewQuery("AAA-00")
+		require.NoError(t, err)
+		batches, err = export(search.Search{Query: query}, 2, 0, nil)
+		require.NoError(t, err)
+		assert.Equal(t, [][]int{{ids[0], ids[1]}, {ids[2], ids[3]}, {ids[4]}}, batches)
+
+		// The export stops at the first error of fn.
+		stop := fmt.Errorf("stop")
+		batches, err = export(search.Search{}, 2, 1, stop)
+		assert.Equal(t, stop, err)
+		assert.Len(t, batches, 1)
+
+		// No batch is exported without matching products.
+		batches, err = export(search.Search{ClientID: 3}, 2, 0, nil)
+		require.NoError(t, err)
+		assert.Empty(t, batches)
+	})
+
+	t.Run("Facets", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		// Five products with increasing values, the first three of client 1.
+		for i := 1; i <= 5; i++ {
+			clientID := 1
+			if i > 3 {
+				clientID = 2
+			}
+			_, err := repo.Create(ctx, newProduct(clientID, i))
+			require.NoError(t, err)
+		}
+
+		// Every product of the client is counted, regardless of the pagination.
+		facets := []search.Facet{
+			{Field: "type"},
+			{Field: "port"},
+			{Field: "vault"},
+			{Field: "shipping_price", Interval: 20},
+			{Field: "quantity", Interval: 2},
+		}
+		results, err := repo.Facets(ctx, search.Search{ClientID: 1, Pagination: search.Pagination{Limit: 1}}, facets)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]search.FacetResult{
+			"type":           {Buckets: []search.FacetBucket{{Value: "box", Count: 2}, {Value: "pallet", Count: 1}}},
+			"port":           {Buckets: []search.FacetBucket{{Value: 1, Count: 1}, {Value: 3, Count: 1}}, Missing: 1},
+			"vault":          {Buckets: []search.FacetBucket{{Value: 2, Count: 1}}, Missing: 2},
+			"shipping_price": {Buckets: []search.FacetBucket{{Value: 0.0, Count: 1}, {Value: 20.0, Count: 2}}, Interval: 20},
+			"quantity":       {Buckets: []search.FacetBucket{{Value: 0, Count: 1}, {Value: 2, Count: 2}}, Interval: 2},
+		}, results)
+
+		// Only the products matching the criteria are counted.
+		results, err = repo.Facets(ctx, search.Search{Type: search.StringFilter{Values: []string{"pallet"}}}, facets[1:3])
+		require.NoError(t, err)
+		assert.Equal(t, map[string]search.FacetResult{
+			"port":  {Buckets: []search.FacetBucket{}, Missing: 2},
+			"vault": {Buckets: []search.FacetBucket{{Value: 2, Count: 1}, {Value: 4, Count: 1}}},
+		}, results)
+	})
+
+	t.Run("Sort", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx := context.Background()
+
+		// Six products of client 1, boxes with a port when odd and pallets in a vault when even.
+		ids := make(map[int]int)
+		for i := 1; i <= 6; i++ {
+			id, err := repo.Create(ctx, newProduct(1, i))
+			require.NoError(t, err)
+			ids[i] = id
+		}
+
+		for _, tc := range []struct {
+			sort     string
+			expected []int
+		}{
+			{sort: "", expected: []int{1, 2, 3, 4, 5, 6}},
+			{sort: "-delivered_at", expected: []int{6, 5, 4, 3, 2, 1}},
+			{sort: "type", expected: []int{1, 3, 5, 2, 4, 6}},
+			{sort: "type,-shipping_price", expected: []int{5, 3, 1, 6, 4, 2}},
+			{sort: "-type,quantity", expected: []int{2, 4, 6, 1, 3, 5}},
+			{sort: "port", expected: []int{1, 3, 5, 2, 4, 6}},
+			{sort: "-port", expected: []int{5, 3, 1, 2, 4, 6}},
+			{sort: "-vault,guide_number", expected: []int{6, 4, 2, 1, 3, 5}},
+		} {
+			t.Run(tc.sort, func(t *testing.T) {
+				sort, err := search.NewProductSort(tc.sort)
+				require.NoError(t, err)
+				expected := make([]int, 0)
+				for _, i := range tc.expected {
+					expected = append(expected, ids[i])
+				}
+
+				// Every page is in the order of the sort, with ties broken by ID and missing values last.
+				ps, _, err := repo.Get(ctx, search.Pagination{Sort: sort}, 1)
+				require.NoError(t, err)
+				assert.Equal(t, expected, productIDs(ps))
+
+				ps, _, err = repo.Search(ctx, search.Search{ClientID: 1, Pagination: search.Pagination{Limit: 3, Offset: 3, Sort: sort}})
+				require.NoError(t, err)
+				assert.Equal(t, expected[3:], productIDs(ps))
+
+				// A cursor keyed on the sort walks every product in the same order.
+				seen := make([]int, 0)
+				var cursor *search.Cursor
+				for {
+					ps, _, err = repo.Search(ctx, search.Search{Pagination: search.Pagination{Limit: 2, After: cursor, Sort: sort}})
+					require.NoError(t, err)
+					if len(ps) == 0 {
+						break
+					}
+					seen = append(seen, productIDs(ps)...)
+					next := search.NewCursor(sort, *ps[len(ps)-1])
+					cursor = &next
+				}
+				assert.Equal(t, expected, seen)
+
+				// An export walks every product in the same order too, in batches.
+				seen = make([]int, 0)
+				err = repo.Export(ctx, search.Search{ClientID: 1, Pagination: search.Pagination{Sort: sort}}, 4, func(ps []*product.Product) error {
+					seen = append(seen, productIDs(ps)...)
+					return nil
+				})
+				require.NoError(t, err)
+				assert.Equal(t, expected, seen)
+			})
+		}
+	})
+
+	t.Run("CancelledContext", func(t *testing.T) {
+		repo := productRepository(t, newDB)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := repo.Create(ctx, newProduct(1, 1))
+		assertErrorType(t, errors.TIMEOUT, err)
+	})
+}
+
+// searchCase represents a search and the indexes of the fixture products it must return.
+type searchCase struct {
+	name     string
+	search   search.Search
+	expected []int
+}
+
+// searchCases returns a search case for every kind of criteria of every filter, and every combination of bounds of every range.
+func searchCases() []searchCase {
+	day := func(n int) time.Time {
+		return baseTime.AddDate(0, 0, n)
+	}
+	return []searchCase{
+		{name: "NoCriteria", expected: []int{1, 2, 3, 4, 5}},
+		{name: "Client", search: search.Search{ClientID: 1}, expected: []int{1, 2, 3}},
+		{name: "GuideNumber", search: search.Search{GuideNumber: search.StringFilter{Values: []string{*newProduct(1, 2).GuideNumber}}}, expected: []int{2}},
+		{name: "GuideNumbers", search: search.Search{GuideNumber: search.StringFilter{Values: []string{*newProduct(1, 1).GuideNumber, *newProduct(2, 4).GuideNumber}}}, expected: []int{1, 4}},
+		{name: "GuideNumberPrefix", search: search.Search{GuideNumber: search.StringFilter{Prefixes: []string{"GUIDE"}}}, expected: []int{1, 2, 3, 4, 5}},
+		{name: "GuideNumberPrefixes", search: search.Search{GuideNumber: search.StringFilter{Prefixes: []string{"GUIDE00003", "GUIDE00005"}}}, expected: []int{3, 5}},
+		{name: "GuideNumberPrefixIsCaseSensitive", search: search.Search{GuideNumber: search.StringFilter{Prefixes: []string{"guide"}}}, expected: []int{}},
+		{name: "Type", search: search.Search{Type: search.StringFilter{Values: []string{"pallet"}}}, expected: []int{2, 4}},
+		{name: "Types", search: search.Search{Type: search.StringFilter{Values: []string{"box", "pallet"}}}, expected: []int{1, 2, 3, 4, 5}},
+		{name: "NotType", search: search.Search{Type: search.StringFilter{Values: []string{"box"}, Not: true}}, expected: []int{2, 4}},
+		{name: "VehiclePlate", search: search.Search{VehiclePlate: search.StringFilter{Values: []string{"AAA-003"}}}, expected: []int{3}},
+		{name: "VehiclePlatePrefix", search: search.Search{VehiclePlate: search.StringFilter{Prefixes: []string{"AAA-00"}}}, expected: []int{1, 2, 3, 4, 5}},
+		{name: "VehiclePlateOrPrefix", search: search.Search{VehiclePlate: search.StringFilter{Values: []string{"AAA-001"}, Prefixes: []string{"AAA-005"}}}, expected: []int{1, 5}},
+		{name: "Port", search: search.Search{Port: search.IntFilter{Values: []int{3}}}, expected: []int{3}},
+		{name: "Ports", search: search.Search{Port: search.IntFilter{Values: []int{1, 3, 4}}}, expected: []int{1, 3}},
+		{name: "PortOfProductInVault", search: search.Search{Port: search.IntFilter{Values: []int{2}}}, expected: []int{}},
+		{name: "PortOrMissing", search: search.Search{Port: search.IntFilter{Values: []int{3}, Missing: true}}, expected: []int{2, 3, 4}},
+		{name: "NotPort", search: search.Search{Port: search.IntFilter{Values: []int{1}, Not: true}}, expected: []int{2, 3, 4, 5}},
+		{name: "Vault", search: search.Search{Vault: search.IntFilter{Values: []int{4}}}, expected: []int{4}},
+		{name: "VaultMissing", search: search.Search{Vault: search.IntFilter{Missing: true}}, expected: []int{1, 3, 5}},
+		{name: "VaultNotMissing", search: search.Search{Vault: search.IntFilter{Missing: true, Not: true}}, expected: []int{2, 4}},
+		{name: "Status", search: search.Search{Status: search.StringFilter{Values: []string{string(product.IN_TRANSIT_STATUS)}}}, expected: []int{5}},
+		{name: "NotStatus", search: search.Search{Status: search.StringFilter{Values: []string{string(product.IN_TRANSIT_STATUS)}, Not: true}}, expected: []int{1, 2, 3, 4}},
+		{name: "ClientAndType", search: search.Search{ClientID: 1, Type: search.StringFilter{Values: []string{"box"}}}, expected: []int{1, 3}},
+		{name: "ClientWithoutMatches", search: search.Search{ClientID: 2, GuideNumber: search.StringFilter{Values: []string{*newProduct(1, 1).GuideNumber}}}, expected: []int{}},
+		{name: "FilterEqual", search: search.Search{Filter: mustFilter(`{"field": "type", "op": "eq", "value": "pallet"}`)}, expected: []int{2, 4}},
+		{name: "FilterNotEqualMatchesMissing", search: search.Search{Filter: mustFilter(`{"field": "port", "op": "ne", "value": 1}`)}, expected: []int{2, 3, 4, 5}},
+		{name: "FilterIn", search: search.Search{Filter: mustFilter(`{"field": "quantity", "op": "in", "value": [1, 5]}`)}, expected: []int{1, 5}},
+		{name: "FilterNotIn", search: search.Search{Filter: mustFilter(`{"field": "vault", "op": "nin", "value": [2]}`)}, expected: []int{1, 3, 4, 5}},
+		{name: "FilterRange", search: search.Search{Filter: mustFilter(`{"and": [{"field": "shipping_price", "op": "gte", "value": 20}, {"field": "shipping_price", "op": "lt", "value": 40}]}`)}, expected: []int{2, 3}},
+		{name: "FilterTime", search: search.Search{Filter: mustFilter(fmt.Sprintf(`{"field": "joined_at", "op": "gt", "value": %q}`, day(3).Format(time.RFC3339)))}, expected: []int{4, 5}},
+		{name: "FilterTimeEqual", search: search.Search{Filter: mustFilter(fmt.Sprintf(`{"field": "delivered_at", "op": "in", "value": [%q]}`, day(12).Format(time.RFC3339)))}, expected: []int{2}},
+		{name: "FilterPrefix", search: search.Search{Filter: mustFilter(`{"field": "guide_number", "op": "prefix", "value": "GUIDE00002"}`)}, expected: []int{2}},
+		{name: "FilterPresent", search: search.Search{Filter: mustFilter(`{"field": "vault", "op": "missing", "value": false}`)}, expected: []int{2, 4}},
+		{
+			name: "FilterTree",
+			search: search.Search{Filter: mustFilter(`{"or": [
+				{"and": [{"field": "type", "op": "eq", "value": "box"}, {"not": {"field": "port", "op": "eq", "value": 1}}]},
+				{"field": "vault", "op": "eq", "value": 4}
+			]}`)},
+			expected: []int{3, 4, 5},
+		},
+		{name: "FilterAndClient", search: search.Search{ClientID: 1, Filter: mustFilter(`{"not": {"field": "status", "op": "eq", "value": "IN_TRANSIT"}}`)}, expected: []int{1, 2, 3}},
+
+		{name: "QueryTypeWord", search: search.Search{Query: "pallet"}, expected: []int{2, 4}},
+		{name: "QueryWordPrefix", search: search.Search{Query: "pal"}, expected: []int{2, 4}},
+		{name: "QueryNotAWordStart", search: search.Search{Query: "let"}, expected: []int{}},
+		{name: "QueryGuideNumberPart", search: search.Search{Query: "00003"}, expected: []int{3}},
+		{name: "QueryVehiclePlate", search: search.Search{Query: "aaa-004"}, expected: []int{4}},
+		{name: "QueryVehiclePlatePart", search: search.Search{Query: "aa-00"}, expected: []int{1, 2, 3, 4, 5}},
+		{name: "QueryEveryTerm", search: search.Search{Query: "box 003"}, expected: []int{3}},
+		{name: "QueryWithoutMatches", search: search.Search{Query: "box zzz"}, expected: []int{}},
+		{name: "QueryAndClient", search: search.Search{ClientID: 2, Query: "box"}, expected: []int{5}},
+		{name: "QueryAndFilters", search: search.Search{Query: "guide", Vault: search.IntFilter{Missing: true}, QuantityRange: search.RangeInt{End: 3}}, expected: []int{1, 3}},
+
+		{
+			name: "EveryFilter",
+			search: search.Search{
+				GuideNumber:  search.StringFilter{Prefixes: []string{"GUIDE"}},
+				Type:         search.StringFilter{Values: []string{"box"}},
+				VehiclePlate: search.StringFilter{Values: []string{"AAA-001"}, Not: true},
+				Port:         search.IntFilter{Values: []int{1, 3, 5}},
+				Vault:        search.IntFilter{Missing: true},
+				Status:       search.StringFilter{Values: []string{string(product.IN_TRANSIT_STATUS)}, Not: true},
+			},
+			expected: []int{3},
+		},
+
+		{name: "PriceStart", search: search.Search{PriceRange: search.RangeFloat64{Start: 30}}, expected: []int{3, 4, 5}},
+		{name: "PriceEnd", search: search.Search{PriceRange: search.RangeFloat64{End: 20}}, expected: []int{1, 2}},
+		{name: "PriceStartAndEnd", search: search.Search{PriceRange: search.RangeFloat64{Start: 20, End: 40}}, expected: []int{2, 3, 4}},
+		{name: "PriceSingleValue", search: search.Search{PriceRange: search.RangeFloat64{Start: 30, End: 30}}, expected: []int{3}},
+		{name: "PriceBetweenValues", search: search.Search{PriceRange: search.RangeFloat64{Start: 31, End: 39}}, expected: []int{}},
+		{name: "PriceAboveEvery", search: search.Search{PriceRange: search.RangeFloat64{Start: 60}}, expected: []int{}},
+
+		{name: "QuantityStart", search: search.Search{QuantityRange: search.RangeInt{Start: 3}}, expected: []int{3, 4, 5}},
+		{name: "QuantityEnd", search: search.Search{QuantityRange: search.RangeInt{End: 2}}, expected: []int{1, 2}},
+		{name: "QuantityStartAndEnd", search: search.Search{QuantityRange: search.RangeInt{Start: 2, End: 4}}, expected: []int{2, 3, 4}},
+		{name: "QuantitySingleValue", search: search.Search{QuantityRange: search.RangeInt{Start: 3, End: 3}}, expected: []int{3}},
+		{name: "QuantityAboveEvery", search: search.Search{QuantityRange: search.RangeInt{Start: 6}}, expected: []int{}},
+
+		{name: "JoinedAtStart", search: search.Search{JoinedAtRange: search.RangeTime{Start: day(3)}}, expected: []int{3, 4, 5}},
+		{name: "JoinedAtEnd", search: search.Search{JoinedAtRange: search.RangeTime{End: day(2)}}, expected: []int{1, 2}},
+		{name: "JoinedAtStartAndEnd", search: search.Search{JoinedAtRange: search.RangeTime{Start: day(2), End: day(4)}}, expected: []int{2, 3, 4}},
+		{name: "JoinedAtSingleValue", search: search.Search{JoinedAtRange: search.RangeTime{Start: day(3), End: day(3)}}, expected: []int{3}},
+		{name: "JoinedAtBetweenValues", search: search.Search{JoinedAtRange: search.RangeTime{Start: day(3).Add(time.Hour), End: day(4).Add(-time.Hour)}}, expected: []int{}},
+
+		{name: "DeliveredAtStart", search: search.Search{DeliveredAtRange: search.RangeTime{Start: day(13)}}, expected: []int{3, 4, 5}},
+		{name: "DeliveredAtEnd", search: search.Search{DeliveredAtRange: search.RangeTime{End: day(12)}}, expected: []int{1, 2}},
+		{name: "DeliveredAtStartAndEnd", search: search.Search{DeliveredAtRange: search.RangeTime{Start: day(12), End: day(14)}}, expected: []int{2, 3, 4}},
+		{name: "DeliveredAtSingleValue", search: search.Search{DeliveredAtRange: search.RangeTime{Start: day(13), End: day(13)}}, expected: []int{3}},
+		{name: "DeliveredAtAfterEvery", search: search.Search{DeliveredAtRange: search.RangeTime{Start: day(16)}}, expected: []int{}},
+
+		{
+			name: "EveryRange",
+			search: search.Search{
+				PriceRange:       search.RangeFloat64{Start: 20, End: 50},
+				QuantityRange:    search.RangeInt{Start: 1, End: 4},
+				JoinedAtRange:    search.RangeTime{Start: day(1), End: day(3)},
+				DeliveredAtRange: search.RangeTime{Start: day(12)},
+			},
+			expected: []int{2, 3},
+		},
+		{
+			name: "EveryRangeAndClient",
+			search: search.Search{
+				ClientID:         2,
+				PriceRange:       search.RangeFloat64{Start: 20, End: 50},
+				QuantityRange:    search.RangeInt{Start: 1, End: 4},
+				JoinedAtRange:    search.RangeTime{Start: day(1), End: day(5)},
+				DeliveredAtRange: search.RangeTime{Start: day(12)},
+			},
+			expected: []int{4},
+		},
+	}
+}
+
+// mustFilter decodes and validates the JSON filter tree v, panicking if it is invalid.
+func mustFilter(v string) *search.Filter {
+	var f search.Filter
+	err := json.Unmarshal([]byte(v), &f)
+	if err != nil {
+		panic(err)
+	}
+	f, err = search.NewFilter(f)
+	if err != nil {
+		panic(err)
+	}
+	return &f
+}
+
+// productRepository creates a fresh database with newDB and returns its product repository.
+func productRepository(t *testing.T, newDB Factory) database.ProductRepository {
+	t.Helper()
+	db := newDB(t)
+	repo, err := database.GetRepository[database.ProductRepository](db.Repositories, database.PRODUCT_REPOSITORY)
+	require.NoError(t, err)
+	return repo
+}
+
+// newProduct creates the fixture product number n of a client.
+// Every value grows with n: the guide number, quantity n, shipping price n*10, joined n days and delivered n+10 days after baseTime.
+// Odd products are boxes stored in port n, even ones are pallets stored in vault n.
+func newProduct(clientID, n int) product.Product {
+	guideNumber := fmt.Sprintf("GUIDE%05d", n)
+	productType := "box"
+	quantity := n
+	joinedAt := baseTime.AddDate(0, 0, n)
+	deliveredAt := baseTime.AddDate(0, 0, n+10)
+	shippingPrice := float64(n * 10)
+	vehiclePlate := fmt.Sprintf("AAA-%03d", n%1000)
+	location := n
+
+	p := product.Product{
+		ClientID:      clientID,
+		GuideNumber:   &guideNumber,
+		Type:          &productType,
+		Quantity:      &quantity,
+		JoinedAt:      &joinedAt,
+		DeliveredAt:   &deliveredAt,
+		ShippingPrice: &shippingPrice,
+		VehiclePlate:  &vehiclePlate,
+		Status:        product.REGISTERED_STATUS,
+	}
+	if n%2 == 0 {
+		productType = "pallet"
+		p.Vault = &location
+	} else {
+		p.Port = &location
+	}
+	return p
+}
+
+// normalize returns a copy of a product with its times in UTC, so products read from any backend can be compared.
+func normalize(p product.Product) product.Product {
+	if p.JoinedAt != nil {
+		joinedAt := p.JoinedAt.UTC()
+		p.JoinedAt = &joinedAt
+	}
+	if p.DeliveredAt != nil {
+		deliveredAt := p.DeliveredAt.UTC()
+		p.DeliveredAt = &deliveredAt
+	}
+	return p
+}
+
+// pageOf returns the pagination of the given 0-based page of pageSize products.
+func pageOf(page int) search.Pagination {
+	return search.Pagination{Limit: pageSize, Offset: page * pageSize}
+}
+
+// productIDs returns the IDs of the products.
+func productIDs(ps []*product.Product) (ids []int) {
+	ids = make([]int, 0, len(ps))
+	for _, p := range ps {
+		ids = append(ids, p.ID)
+	}
+	return
+}
+
+// assertErrorType asserts that err is a database error of the expected type.
+func assertErrorType(t *testing.T, expected string, err error) {
+	t.Helper()
+	var dbErr errors.Error
+	if assert.ErrorAs(t, err, &dbErr) {
+		assert.Equal(t, expected, dbErr.Type)
+	}
+}
@@ -0,0 +1,290 @@
+package databasetest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSavedSearchRepository checks that the database.SavedSearchRepository of the databases created by newDB fulfils its contract.
+func TestSavedSearchRepository(t *testing.T, newDB Factory) {
+	t.Run("CreateAndGetOne", func(t *testing.T) {
+		repo := savedSearchRepository(t, newDB)
+		ctx := context.Background()
+
+		// The filter tree is saved as it was decoded from the request, before being validated.
+		s := newSavedSearch(t, 1, 1)
+		s.Criteria.Filter = new(search.Filter)
+		err := json.Unmarshal([]byte(`{"or": [{"field": "port", "op": "eq", "value": 1}, {"field": "vehicle_plate", "op": "prefix", "value": "AAA"}]}`), s.Criteria.Filter)
+		require.NoError(t, err)
+		id, err := repo.Create(ctx, s)
+		require.NoError(t, err)
+		assert.Positive(t, id)
+		s.ID = id
+
+		got, err := repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		assertSavedSearch(t, s, got)
+
+		got, err = repo.GetOne(ctx, id, database.ANY_CLIENT)
+		require.NoError(t, err)
+		assertSavedSearch(t, s, got)
+
+		// The saved searches of other clients are not found.
+		_, err = repo.GetOne(ctx, id, 2)
+		assertErrorType(t, errors.NOT_FOUND, err)
+	})
+
+	t.Run("CreateAfterProducts", func(t *testing.T) {
+		db := newDB(t)
+		ctx := context.Background()
+		products, err := database.GetRepository[database.ProductRepository](db.Repositories, database.PRODUCT_REPOSITORY)
+		require.NoError(t, err)
+		repo, err := database.GetRepository[database.SavedSearchRepository](db.Repositories, database.SAVED_SEARCH_REPOSITORY)
+		require.NoError(t, err)
+
+		lastID := 0
+		for n := 1; n <= 2; n++ {
+			lastID, err = products.Create(ctx, newProduct(1, n))
+			require.NoError(t, err)
+		}
+
+		// The products existing when the search is saved are never reported by its runs.
+		id, err := repo.Create(ctx, newSavedSearch(t, 1, 1))
+		require.NoError(t, err)
+		got, err := repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		assert.Equal(t, lastID, got.LastProductID)
+
+		srch, err := got.Search()
+		require.NoError(t, err)
+		assert.Equal(t, lastID, srch.AfterID)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		repo := savedSearchRepository(t, newDB)
+		ctx := context.Background()
+
+		ids := make([]int, 0)
+		for n := 1; n <= 5; n++ {
+			id, err := repo.Create(ctx, newSavedSearch(t, n%2+1, n))
+			require.NoError(t, err)
+			if n%2+1 == 2 {
+				ids = append(ids, id)
+			}
+		}
+
+		ss, total, err := repo.Get(ctx, search.Pagination{Limit: 2, Offset: 1}, 2)
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		assert.Equal(t, ids[1:], savedSearchIDs(ss))
+
+		ss, total, err = repo.Get(ctx, search.Pagination{}, database.ANY_CLIENT)
+		require.NoError(t, err)
+		assert.Equal(t, 5, total)
+		assert.Len(t, ss, 5)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := savedSearchRepository(t, newDB)
+		ctx := context.Background()
+
+		s := newSavedSearch(t, 1, 1)
+		id, err := repo.Create(ctx, s)
+		require.NoError(t, err)
+
+		updated := newSavedSearch(t, 1, 2)
+		updated.ID = id
+		updated.ScopeID = database.ANY_CLIENT
+		updated.Schedule = ""
+		updated.NextRunAt = nil
+
+		// The saved searches of other clients are not found.
+		updated.ClientID = 2
+		assertErrorType(t, errors.NOT_FOUND, repo.Update(ctx, updated))
+
+		updated.ClientID = 1
+		require.NoError(t, repo.Update(ctx, updated))
+
+		got, err := repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		assertSavedSearch(t, updated, got)
+
+		updated.ID = id + 1
+		assertErrorType(t, errors.NOT_FOUND, repo.Update(ctx, updated))
+	})
+
+	t.Run("RunsAndDigests", func(t *testing.T) {
+		repo := savedSearchRepository(t, newDB)
+		ctx := context.Background()
+
+		// The second saved search is the most overdue, and the third one is not scheduled.
+		ids := make([]int, 0)
+		for n, hours := range []int{2, 1, 0} {
+			s := newSavedSearch(t, 1, n+1)
+			if hours == 0 {
+				s.Schedule, s.NextRunAt = "", nil
+			} else {
+				nextRunAt := baseTime.Add(time.Duration(hours) * time.Hour)
+				s.NextRunAt = &nextRunAt
+			}
+			id, err := repo.Create(ctx, s)
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+
+		due, err := repo.GetDue(ctx, baseTime.Add(time.Hour), 0)
+		require.NoError(t, err)
+		assert.Equal(t, []int{ids[1]}, savedSearchIDs(due))
+
+		due, err = repo.GetDue(ctx, baseTime.Add(3*time.Hour), 0)
+		require.NoError(t, err)
+		assert.Equal(t, []int{ids[1], ids[0]}, savedSearchIDs(due))
+
+		due, err = repo.GetDue(ctx, baseTime.Add(3*time.Hour), 1)
+		require.NoError(t, err)
+		assert.Equal(t, []int{ids[1]}, savedSearchIDs(due))
+
+		// Run the most overdue one twice, saving the state after every run.
+		s := due[0]
+		now := baseTime.Add(3 * time.Hour)
+		ran, d := s.Ran([]int{3, 7}, 2, 7, now)
+		d.ID, err = repo.SaveRun(ctx, ran, d)
+		require.NoError(t, err)
+		assert.Positive(t, d.ID)
+
+		// The same run can not be saved twice.
+		_, err = repo.SaveRun(ctx, ran, d)
+		assertErrorType(t, errors.CONFLICT, err)
+
+		got, err := repo.GetOne(ctx, s.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, got.Runs)
+		assert.Equal(t, 7, got.LastProductID)
+		require.NotNil(t, got.LastRunAt)
+		assert.True(t, now.Equal(*got.LastRunAt))
+		require.NotNil(t, got.NextRunAt)
+		assert.True(t, ran.NextRunAt.Equal(*got.NextRunAt))
+
+		ran, second := got.Ran([]int{}, 0, 0, now.Add(time.Hour))
+		second.ID, err = repo.SaveRun(ctx, ran, second)
+		require.NoError(t, err)
+
+		// The digests are listed from the latest run, and only to the client of the saved search.
+		ds, total, err := repo.GetDigests(ctx, s.ID, search.Pagination{}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		if assert.Len(t, ds, 2) {
+			assert.Equal(t, []int{second.ID, d.ID}, []int{ds[0].ID, ds[1].ID})
+			assert.Equal(t, []int{}, ds[0].ProductIDs)
+			assert.Equal(t, []int{3, 7}, ds[1].ProductIDs)
+			assert.Equal(t, 2, ds[1].Total)
+			assert.Equal(t, 1, ds[1].Run)
+			assert.True(t, now.Equal(ds[1].RanAt))
+		}
+
+		ds, total, err = repo.GetDigests(ctx, s.ID, search.Pagination{Limit: 1, Offset: 1}, database.ANY_CLIENT)
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Equal(t, []int{d.ID}, digestIDs(ds))
+
+		ds, total, err = repo.GetDigests(ctx, s.ID, search.Pagination{}, 2)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+		assert.Empty(t, ds)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := savedSearchRepository(t, newDB)
+		ctx := context.Background()
+
+		id, err := repo.Create(ctx, newSavedSearch(t, 1, 1))
+		require.NoError(t, err)
+		s, err := repo.GetOne(ctx, id, 1)
+		require.NoError(t, err)
+		ran, d := s.Ran([]int{1}, 1, 1, baseTime)
+		_, err = repo.SaveRun(ctx, ran, d)
+		require.NoError(t, err)
+
+		// The saved searches of other clients are not found.
+		assertErrorType(t, errors.NOT_FOUND, repo.Delete(ctx, id, 2))
+
+		require.NoError(t, repo.Delete(ctx, id, 1))
+		_, err = repo.GetOne(ctx, id, 1)
+		assertErrorType(t, errors.NOT_FOUND, err)
+		assertErrorType(t, errors.NOT_FOUND, repo.Delete(ctx, id, 1))
+
+		// The digests are deleted along with their saved search.
+		ds, total, err := repo.GetDigests(ctx, id, search.Pagination{}, database.ANY_CLIENT)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+		assert.Empty(t, ds)
+	})
+}
+
+// savedSearchRepository creates a fresh database with newDB and returns its saved search repository.
+func savedSearchRepository(t *testing.T, newDB Factory) database.SavedSearchRepository {
+	t.Helper()
+	db := newDB(t)
+	repo, err := database.GetRepository[database.SavedSearchRepository](db.Repositories, database.SAVED_SEARCH_REPOSITORY)
+	require.NoError(t, err)
+	return repo
+}
+
+// newSavedSearch creates the fixture saved search number n of a client, searching its own products.
+// It is run every day at n o'clock, and posts its digests to a webhook.
+func newSavedSearch(t *testing.T, clientID, n int) savedsearch.SavedSearch {
+	t.Helper()
+	criteria := search.Criteria{
+		Type:          "box,pallet",
+		Port:          "!" + fmt.Sprint(n),
+		StartQuantity: n,
+		EndQuantity:   n + 10,
+		Query:         "guide",
+	}
+	s, err := savedsearch.New(clientID, clientID, fmt.Sprintf("Search %d", n), criteria, fmt.Sprintf("0 %d * * *", n%24),
+		"https://example.com/hooks", baseTime)
+	require.NoError(t, err)
+	return s
+}
+
+// assertSavedSearch asserts that a saved search read from a backend is the expected one, comparing the times as instants.
+func assertSavedSearch(t *testing.T, expected, actual savedsearch.SavedSearch) {
+	t.Helper()
+	assert.True(t, expected.CreatedAt.Equal(actual.CreatedAt), "created at %s, expected %s", actual.CreatedAt, expected.CreatedAt)
+	if expected.NextRunAt == nil {
+		assert.Nil(t, actual.NextRunAt)
+	} else if assert.NotNil(t, actual.NextRunAt) {
+		assert.True(t, expected.NextRunAt.Equal(*actual.NextRunAt), "next run at %s, expected %s", actual.NextRunAt, expected.NextRunAt)
+	}
+	expected.CreatedAt, actual.CreatedAt = time.Time{}, time.Time{}
+	expected.NextRunAt, actual.NextRunAt = nil, nil
+	assert.Equal(t, expected, actual)
+}
+
+// savedSearchIDs returns the IDs of the saved searches.
+func savedSearchIDs(ss []savedsearch.SavedSearch) (ids []int) {
+	ids = make([]int, 0, len(ss))
+	for _, s := range ss {
+		ids = append(ids, s.ID)
+	}
+	return
+}
+
+// digestIDs returns the IDs of the digests.
+func digestIDs(ds []savedsearch.Digest) (ids []int) {
+	ids = make([]int, 0, len(ds))
+	for _, d := range ds {
+		ids = append(ids, d.ID)
+	}
+	return
+}
@@ -3,6 +3,8 @@ package errors
 // Constants representing different error types.
 const (
 	ALREADY_EXISTS = "ALREADY_EXISTS" // Error type for indicating an entity already exists
+	CONFLICT       = "CONFLICT"       // Error type for indicating an entity was changed concurrently
 	NOT_FOUND      = "NOT_FOUND"      // Error type for indicating an entity was not found
+	TIMEOUT        = "TIMEOUT"        // Error type for indicating an operation took too long or was cancelled
 	UNKNOWN        = "UNKNOWN"        // Error type for indicating an unknown error
 )
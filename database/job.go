@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/jobs"
+)
+
+// JOB_REPOSITORY is the key to be used when creating the repositories hashmap.
+const JOB_REPOSITORY RepositoryID = "JOB_REPOSITORY"
+
+// JobRepository defines the methods for working with the queue of background jobs in the database.
+// It implements jobs.Queue, and every clientID parameter can be ANY_CLIENT to not restrict the operation to a single client.
+type JobRepository interface {
+	// Create inserts a new job into the queue and returns its ID.
+	Create(ctx context.Context, job jobs.Job) (id int, err error)
+
+	// GetOne retrieves a specific job based on the provided ID and client ID, without its parameters, input nor result.
+	GetOne(ctx context.Context, id, clientID int) (job jobs.Job, err error)
+
+	// GetResult retrieves the result of a specific job based on the provided ID and client ID.
+	// It returns a NOT_FOUND error if the job has no result, because it did not succeed or produced none.
+	GetResult(ctx context.Context, id, clientID int) (result jobs.Result, err error)
+
+	// Claim locks the next job due at the given time for the duration of the lease, see jobs.Job.Claimed.
+	// Queued jobs are due after their run at time, and running jobs after their lease expired, the earliest first.
+	// Every job is claimed by a single caller, even if several ones claim at once. It returns a NOT_FOUND error if no job is due.
+	Claim(ctx context.Context, now time.Time, lease time.Duration) (job jobs.Job, err error)
+
+	// Heartbeat stores the progress and the lease of the running attempt of a job.
+	// It returns a CONFLICT error if the attempt is no longer running, because the job was claimed again.
+	Heartbeat(ctx context.Context, job jobs.Job) (err error)
+
+	// Finish stores the status, progress, error, next run and finish time of the running attempt of a job, and its result if any.
+	// It returns a CONFLICT error if the attempt is no longer running, because the job was claimed again.
+	Finish(ctx context.Context, job jobs.Job, result *jobs.Result) (err error)
+}
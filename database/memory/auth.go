@@ -0,0 +1,232 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/client"
+	"github.com/coffemanfp/docucentertest/database"
+)
+
+// AuthRepository is a struct representing a repository for authentication-related in-memory operations.
+type AuthRepository struct {
+	s *store
+}
+
+// NewAuthRepository creates a new AuthRepository instance.
+func NewAuthRepository(conn *Connector) (repo database.AuthRepository, err error) {
+	repo = AuthRepository{
+		s: conn.s,
+	}
+	return
+}
+
+// GetIdAndHashedPassword retrieves the client's ID and hashed password based on the provided auth credentials.
+func (ar AuthRepository) GetIdAndHashedPassword(ctx context.Context, auth auth.Auth) (id int, hashed string, err error) {
+	table := "client"
+	err = ar.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	defer ar.s.mu.Unlock()
+
+	// Look for the client with the provided username.
+	for _, c := range ar.s.data.clients {
+		if c.Auth.Username == auth.Username {
+			id, hashed = c.ID, c.Auth.Password
+			return
+		}
+	}
+	err = errorInRow(table, "get", errNoRows)
+	return
+}
+
+// Register registers a new client and returns the assigned ID.
+func (ar AuthRepository) Register(ctx context.Context, client client.Client) (id int, err error) {
+	table := "client"
+	err = ar.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+		return
+	}
+	defer ar.s.mu.Unlock()
+
+	// Usernames are unique.
+	for _, c := range ar.s.data.clients {
+		if c.Auth.Username == client.Auth.Username {
+			err = errorInRow(table, "insert", uniqueViolation("username"))
+			return
+		}
+	}
+
+	id = ar.s.data.nextID(table)
+	client.ID = id
+	ar.s.data.clients[id] = client
+	return
+}
+
+// GetRole retrieves the role of the client with the given ID.
+func (ar AuthRepository) GetRole(ctx context.Context, id int) (role auth.Role, err error) {
+	table := "client"
+	err = ar.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	defer ar.s.mu.Unlock()
+
+	c, ok := ar.s.data.clients[id]
+	if !ok {
+		err = errorInRow(table, "get", errNoRows)
+		return
+	}
+	role = c.Role
+	return
+}
+
+// SaveRefreshToken stores a new refresh token.
+func (ar AuthRepository) SaveRefreshToken(ctx context.Context, rt auth.RefreshToken) (err error) {
+	table := "refresh_token"
+	err = ar.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+		return
+	}
+	defer ar.s.mu.Unlock()
+
+	// Token hashes are unique.
+	for _, t := range ar.s.data.refreshTokens {
+		if t.Hash == rt.Hash {
+			err = errorInRow(table, "insert", uniqueViolation("token_hash"))
+			return
+		}
+	}
+
+	rt.ID = ar.s.data.nextID(table)
+	rt.RevokedAt = nil
+	ar.s.data.refreshTokens[rt.ID] = rt
+	return
+}
+
+// GetRefreshToken retrieves a refresh token based on the hash of its plain value.
+func (ar AuthRepository) GetRefreshToken(ctx context.Context, hash string) (rt auth.RefreshToken, err error) {
+	table := "refresh_token"
+	err = ar.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	defer ar.s.mu.Unlock()
+
+	for _, t := range ar.s.data.refreshTokens {
+		if t.Hash == hash {
+			rt = t
+			rt.RevokedAt = clonePtr(t.RevokedAt)
+			return
+		}
+	}
+	err = errorInRow(table, "get", errNoRows)
+	return
+}
+
+// RevokeRefreshToken marks an active refresh token as used.
+// Only tokens not revoked yet are matched, so concurrent uses of the same token are detected.
+func (ar AuthRepository) RevokeRefreshToken(ctx context.Context, id int) (err error) {
+	table := "refresh_token"
+	err = ar.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	defer ar.s.mu.Unlock()
+
+	rt, ok := ar.s.data.refreshTokens[id]
+	if !ok || rt.RevokedAt != nil {
+		err = errorInRow(table, "update", errNoRows)
+		return
+	}
+	now := time.Now()
+	rt.RevokedAt = &now
+	ar.s.data.refreshTokens[id] = rt
+	return
+}
+
+// RevokeRefreshTokenFamily revokes every active refresh token of the given family.
+func (ar AuthRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) (err error) {
+	table := "refresh_token"
+	err = ar.s.lock(ctx)
+	if err != nil {
+		err = errorInRows(table, "update", err)
+		return
+	}
+	defer ar.s.mu.Unlock()
+
+	now := time.Now()
+	for id, rt := range ar.s.data.refreshTokens {
+		if rt.FamilyID == familyID && rt.RevokedAt == nil {
+			rt.RevokedAt = &now
+			ar.s.data.refreshTokens[id] = rt
+		}
+	}
+	return
+}
+
+// RevokeToken adds the ID of an access token to the revocation list.
+// Revoking an already revoked token does nothing.
+func (ar AuthRepository) RevokeToken(ctx context.Context, jti string, expiresAt *time.Time) (err error) {
+	table := "revoked_token"
+	err = ar.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+		return
+	}
+	defer ar.s.mu.Unlock()
+
+	if _, ok := ar.s.data.revokedTokens[jti]; ok {
+		return
+	}
+	ar.s.data.revokedTokens[jti] = revokedToken{
+		ExpiresAt: clonePtr(expiresAt),
+		RevokedAt: time.Now(),
+	}
+	return
+}
+
+// IsTokenRevoked checks if the ID of an access token is in the revocation list.
+func (ar AuthRepository) IsTokenRevoked(ctx context.Context, jti string) (revoked bool, err error) {
+	table := "revoked_token"
+	err = ar.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	defer ar.s.mu.Unlock()
+
+	_, revoked = ar.s.data.revokedTokens[jti]
+	return
+}
+
+// PurgeExpiredTokens deletes the refresh tokens and the revoked access tokens expired at the given time.
+func (ar AuthRepository) PurgeExpiredTokens(ctx context.Context, now time.Time) (err error) {
+	err = ar.s.lock(ctx)
+	if err != nil {
+		err = errorInRows("refresh_token", "delete", err)
+		return
+	}
+	defer ar.s.mu.Unlock()
+
+	for id, rt := range ar.s.data.refreshTokens {
+		if !now.Before(rt.ExpiresAt) {
+			delete(ar.s.data.refreshTokens, id)
+		}
+	}
+	// The revoked access tokens without expiration are kept.
+	for jti, rt := range ar.s.data.revokedTokens {
+		if rt.ExpiresAt != nil && !now.Before(*rt.ExpiresAt) {
+			delete(ar.s.data.revokedTokens, jti)
+		}
+	}
+	return
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/client"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/search"
+)
+
+// ClientRepository is a struct representing a repository for client-related in-memory operations.
+type ClientRepository struct {
+	s *store
+}
+
+// NewClientRepository creates a new ClientRepository instance.
+func NewClientRepository(conn *Connector) (repo database.ClientRepository, err error) {
+	repo = ClientRepository{
+		s: conn.s,
+	}
+	return
+}
+
+// GetOne retrieves a single client based on the provided ID.
+func (cr ClientRepository) GetOne(ctx context.Context, id int) (c client.Client, err error) {
+	table := "client"
+	err = cr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	defer cr.s.mu.Unlock()
+
+	c, ok := cr.s.data.clients[id]
+	if !ok {
+		err = errorInRow(table, "get", errNoRows)
+		return
+	}
+	// The password is never read back.
+	c.Auth.Password = ""
+	return
+}
+
+// Get retrieves a page of clients based on the provided pagination, in the order of its sort, and the total number of clients.
+func (cr ClientRepository) Get(ctx context.Context, pagination search.Pagination) (cs []*client.Client, total int, err error) {
+	table := "client"
+	err = cr.s.lock(ctx)
+	if err != nil {
+		err = errorInRows(table, "get", err)
+		return
+	}
+	defer cr.s.mu.Unlock()
+
+	cs = make([]*client.Client, 0)
+	keys := make([]sortKey, 0)
+	for _, id := range sortedIDs(cr.s.data.clients) {
+		c := cr.s.data.clients[id]
+		// The password is never read back.
+		c.Auth.Password = ""
+		cs = append(cs, &c)
+		keys = append(keys, sortKey{values: pagination.Sort.ClientValues(c), id: id})
+	}
+	total = len(cs)
+	sortByKeys(cs, keys, pagination.Sort)
+	cs = paginate(cs, pagination)
+	return
+}
+
+// UpdateRole updates the role of the client with the provided ID.
+func (cr ClientRepository) UpdateRole(ctx context.Context, id int, role auth.Role) (err error) {
+	table := "client"
+	err = cr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	defer cr.s.mu.Unlock()
+
+	c, ok := cr.s.data.clients[id]
+	if !ok {
+		err = errorInRow(table, "update", errNoRows)
+		return
+	}
+	c.Role = role
+	cr.s.data.clients[id] = c
+	return
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/search"
+)
+
+// paginate returns the rows of rs in the given pagination, or every row from its offset if it has no limit.
+func paginate[T any](rs []T, p search.Pagination) []T {
+	if p.Offset >= len(rs) {
+		return rs[:0]
+	}
+	end := len(rs)
+	if p.Limit > 0 && p.Offset+p.Limit < end {
+		end = p.Offset + p.Limit
+	}
+	return rs[p.Offset:end]
+}
+
+// sortKey represents the position of a row in a sort: the values of its sort fields and its ID.
+type sortKey struct {
+	values []interface{}
+	id     int
+}
+
+// sortByKeys sorts the rows rs in the order of s, given the keys of every row.
+func sortByKeys[T any](rs []T, keys []sortKey, s search.Sort) {
+	sort.Sort(keyedRows[T]{rs: rs, keys: keys, s: s})
+}
+
+// keyedRows implements sort.Interface for rows sorted by their keys.
+type keyedRows[T any] struct {
+	rs   []T
+	keys []sortKey
+	s    search.Sort
+}
+
+func (k keyedRows[T]) Len() int { return len(k.rs) }
+
+func (k keyedRows[T]) Less(i, j int) bool { return compareKeys(k.s, k.keys[i], k.keys[j]) < 0 }
+
+func (k keyedRows[T]) Swap(i, j int) {
+	k.rs[i], k.rs[j] = k.rs[j], k.rs[i]
+	k.keys[i], k.keys[j] = k.keys[j], k.keys[i]
+}
+
+// afterCursor returns the rows following the cursor of the pagination, given the keys of the rows sorted by its sort.
+func afterCursor[T any](rs []T, keys []sortKey, p search.Pagination) []T {
+	if p.After == nil {
+		return rs
+	}
+	cursor := sortKey{values: p.After.Values, id: p.After.ID}
+	first := sort.Search(len(rs), func(i int) bool {
+		return compareKeys(p.Sort, keys[i], cursor) > 0
+	})
+	return rs[first:]
+}
+
+// compareKeys compares the positions of two rows in the sort s, breaking ties by ascending ID.
+// It returns a negative number if a sorts before b, zero if they are equal and a positive number otherwise.
+func compareKeys(s search.Sort, a, b sortKey) int {
+	for i, f := range s {
+		if c := compareValues(a.values[i], b.values[i], f.Desc); c != 0 {
+			return c
+		}
+	}
+	return compareOrdered(a.id, b.id)
+}
+
+// compareValues compares two values of the same sort field, in descending order if desc is true.
+// Missing values are sorted after every other value, in both directions.
+func compareValues(a, b interface{}, desc bool) (c int) {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	switch a := a.(type) {
+	case string:
+		c = strings.Compare(a, b.(string))
+	case int:
+		c = compareOrdered(a, b.(int))
+	case float64:
+		c = compareOrdered(a, b.(float64))
+	case time.Time:
+		c = a.Compare(b.(time.Time))
+	}
+	if desc {
+		c = -c
+	}
+	return
+}
+
+// compareOrdered compares two ordered values.
+func compareOrdered[T int | float64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/errors"
+)
+
+// Connector is a database connector keeping every row in memory.
+// It needs no server, so it is meant for tests, demos and local development.
+// Everything stored is lost when the process exits.
+type Connector struct {
+	s *store // Rows shared by every repository created with the connector
+}
+
+// NewConnector creates a new Connector with an empty database.
+// The pricing rules are seeded with the default ones, like the PostgreSQL migrations do.
+func NewConnector() (conn *Connector) {
+	return &Connector{
+		s: &store{
+			data: newTables(),
+		},
+	}
+}
+
+// Connect does nothing, the in-memory database is always available.
+func (c *Connector) Connect() (err error) {
+	return
+}
+
+// WithTx runs fn with repositories sharing a single transaction.
+// fn works on a copy of the rows, which replaces them if it succeeds and is discarded if it returns an error or panics.
+// The database is locked for the whole transaction, so transactions are serializable.
+func (c *Connector) WithTx(ctx context.Context, fn func(tx database.Repositories) error) (err error) {
+	err = c.s.lock(ctx)
+	if err != nil {
+		return errors.NewError(parseErrorType(err), "failed to begin transaction", err.Error())
+	}
+	defer c.s.mu.Unlock()
+
+	tx := &store{
+		data: c.s.data.clone(),
+	}
+	err = fn(newRepositories(tx))
+	if err != nil {
+		return
+	}
+	c.s.data = tx.data
+	return
+}
+
+// store holds the rows of the in-memory database.
+type store struct {
+	mu   sync.Mutex // Lock held by every operation, and by a transaction until it ends
+	data *tables    // Rows of every table
+}
+
+// lock checks the context of an operation and locks the store for it.
+// The caller is responsible for unlocking the store when done.
+func (s *store) lock(ctx context.Context) (err error) {
+	err = ctx.Err()
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	return
+}
+
+// newRepositories creates every in-memory repository on top of the given store.
+func newRepositories(s *store) database.Repositories {
+	return database.Repositories{
+		database.AUTH_REPOSITORY:         AuthRepository{s: s},
+		database.CLIENT_REPOSITORY:       ClientRepository{s: s},
+		database.PRODUCT_REPOSITORY:      ProductRepository{s: s},
+		database.TRACKING_REPOSITORY:     TrackingRepository{s: s},
+		database.PRICING_REPOSITORY:      PricingRepository{s: s},
+		database.QUOTE_REPOSITORY:        QuoteRepository{s: s},
+		database.SAVED_SEARCH_REPOSITORY: SavedSearchRepository{s: s},
+		database.JOB_REPOSITORY:          JobRepository{s: s},
+	}
+}
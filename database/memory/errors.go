@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+
+	"github.com/coffemanfp/docucentertest/database/errors"
+)
+
+// Errors raised by the in-memory tables, equivalent to the ones of a SQL database.
+var (
+	errNoRows          = stdErrors.New("no rows in result set")
+	errUniqueViolation = stdErrors.New("duplicate key value violates unique constraint")
+)
+
+// uniqueViolation returns an error for a duplicated value of a unique column.
+func uniqueViolation(column string) error {
+	return fmt.Errorf("%w: %s", errUniqueViolation, column)
+}
+
+// parseErrorType identifies the error type based on the error details.
+func parseErrorType(err error) (r string) {
+	switch {
+	case stdErrors.Is(err, errUniqueViolation):
+		r = errors.ALREADY_EXISTS // Set error type to ALREADY_EXISTS for unique violation.
+	case stdErrors.Is(err, errNoRows):
+		r = errors.NOT_FOUND // Set error type to NOT_FOUND for no rows found.
+	case stdErrors.Is(err, context.DeadlineExceeded) || stdErrors.Is(err, context.Canceled):
+		r = errors.TIMEOUT // Set error type to TIMEOUT for expired operations.
+	default:
+		r = errors.UNKNOWN // Set error type to UNKNOWN for any other error.
+	}
+	return
+}
+
+// errorInRow generates a formatted error message for a single row operation failure.
+func errorInRow(table, action string, err error) error {
+	return errors.NewError(
+		parseErrorType(err), // Get the appropriate error type based on the error.
+		fmt.Sprintf("failed to %s a row in %s table", action, table), // Construct error message.
+		err.Error(), // Include the original error content.
+	)
+}
+
+// errorInRows generates a formatted error message for multiple rows operation failure.
+func errorInRows(table, action string, err error) error {
+	return errors.NewError(
+		parseErrorType(err), // Get the appropriate error type based on the error.
+		fmt.Sprintf("failed to %s rows in %s table", action, table), // Construct error message.
+		err.Error(), // Include the original error content.
+	)
+}
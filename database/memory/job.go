@@ -0,0 +1,188 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/coffemanfp/docucentertest/jobs"
+)
+
+// JobRepository represents a repository for managing the queue of background jobs in memory.
+type JobRepository struct {
+	s *store
+}
+
+// NewJobRepository creates a new JobRepository instance using an in-memory connector.
+func NewJobRepository(conn *Connector) (repo database.JobRepository, err error) {
+	repo = JobRepository{
+		s: conn.s,
+	}
+	return
+}
+
+// Create inserts a new job into the queue and returns its ID.
+func (jr JobRepository) Create(ctx context.Context, j jobs.Job) (id int, err error) {
+	table := "job"
+	err = jr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+		return
+	}
+	defer jr.s.mu.Unlock()
+
+	id = jr.s.data.nextID(table)
+	j = copyJob(j)
+	j.ID = id
+	jr.s.data.jobs[id] = j
+	return
+}
+
+// GetOne retrieves a single job by its ID and clientID, without its parameters, input nor result.
+func (jr JobRepository) GetOne(ctx context.Context, id, clientID int) (j jobs.Job, err error) {
+	table := "job"
+	err = jr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	defer jr.s.mu.Unlock()
+
+	stored, err := checkJobOwner(jr.s.data, id, clientID)
+	if err != nil {
+		return
+	}
+	j = copyJob(stored)
+	j.Params, j.Input = nil, nil
+	return
+}
+
+// GetResult retrieves the result of a succeeded job by its ID and clientID.
+func (jr JobRepository) GetResult(ctx context.Context, id, clientID int) (r jobs.Result, err error) {
+	table := "job"
+	err = jr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	defer jr.s.mu.Unlock()
+
+	_, err = checkJobOwner(jr.s.data, id, clientID)
+	if err != nil {
+		return
+	}
+	stored, ok := jr.s.data.jobResults[id]
+	if !ok {
+		err = errorInRow(table, "get", errNoRows)
+		return
+	}
+	r = stored
+	r.Data = append([]byte(nil), stored.Data...)
+	return
+}
+
+// Claim locks the next job due at the given time for the duration of the lease, returning it with its parameters and input.
+// The store is locked while the job is selected and updated, so every job is claimed by a single worker.
+func (jr JobRepository) Claim(ctx context.Context, now time.Time, lease time.Duration) (j jobs.Job, err error) {
+	table := "job"
+	err = jr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "claim", err)
+		return
+	}
+	defer jr.s.mu.Unlock()
+
+	// Find the earliest due job, queued or whose lease expired.
+	found := false
+	for _, id := range sortedIDs(jr.s.data.jobs) {
+		stored := jr.s.data.jobs[id]
+		due := (stored.Status == jobs.QUEUED_STATUS && !stored.RunAt.After(now)) ||
+			(stored.Status == jobs.RUNNING_STATUS && stored.LockedUntil != nil && stored.LockedUntil.Before(now))
+		if due && (!found || stored.RunAt.Before(j.RunAt)) {
+			j, found = stored, true
+		}
+	}
+	if !found {
+		err = errorInRow(table, "claim", errNoRows)
+		return
+	}
+
+	j = copyJob(j.Claimed(now, lease))
+	jr.s.data.jobs[j.ID] = j
+	j = copyJob(j)
+	return
+}
+
+// Heartbeat stores the progress and the lease of the running attempt of a job.
+func (jr JobRepository) Heartbeat(ctx context.Context, j jobs.Job) (err error) {
+	table := "job"
+	err = jr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	defer jr.s.mu.Unlock()
+
+	stored, err := checkAttempt(jr.s.data, j)
+	if err != nil {
+		return
+	}
+	stored.Progress = j.Progress
+	stored.LockedUntil = clonePtr(j.LockedUntil)
+	jr.s.data.jobs[j.ID] = stored
+	return
+}
+
+// Finish stores the outcome of the running attempt of a job, and its result if any.
+func (jr JobRepository) Finish(ctx context.Context, j jobs.Job, result *jobs.Result) (err error) {
+	table := "job"
+	err = jr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	defer jr.s.mu.Unlock()
+
+	stored, err := checkAttempt(jr.s.data, j)
+	if err != nil {
+		return
+	}
+	stored.Status = j.Status
+	stored.Progress = j.Progress
+	stored.Error = j.Error
+	stored.RunAt = j.RunAt
+	stored.LockedUntil = clonePtr(j.LockedUntil)
+	stored.FinishedAt = clonePtr(j.FinishedAt)
+	stored.ResultName = j.ResultName
+	stored.ResultType = j.ResultType
+	jr.s.data.jobs[j.ID] = stored
+
+	delete(jr.s.data.jobResults, j.ID)
+	if result != nil {
+		r := *result
+		r.Data = append([]byte(nil), result.Data...)
+		jr.s.data.jobResults[j.ID] = r
+	}
+	return
+}
+
+// checkJobOwner returns the stored job with the given ID, checking it belongs to the client.
+func checkJobOwner(t *tables, id, clientID int) (j jobs.Job, err error) {
+	j, ok := t.jobs[id]
+	if !ok || (clientID != database.ANY_CLIENT && j.ClientID != clientID) {
+		err = errorInRow("job", "get", errNoRows)
+	}
+	return
+}
+
+// checkAttempt returns the stored job of a running attempt, reporting a CONFLICT error if it is no longer running.
+func checkAttempt(t *tables, j jobs.Job) (stored jobs.Job, err error) {
+	stored, ok := t.jobs[j.ID]
+	if !ok || stored.Status != jobs.RUNNING_STATUS || stored.Attempts != j.Attempts {
+		err = errors.NewError(errors.CONFLICT, "failed to update a row in job table",
+			fmt.Sprintf("attempt %d of job %d is no longer running", j.Attempts, j.ID))
+	}
+	return
+}
@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/product"
+)
+
+// PricingRepository represents a repository for reading the pricing rules stored in memory.
+// The rules are the default ones, the same the PostgreSQL migrations seed.
+type PricingRepository struct {
+	s *store
+}
+
+// NewPricingRepository creates a new PricingRepository instance using an in-memory connector.
+func NewPricingRepository(conn *Connector) (repo database.PricingRepository, err error) {
+	repo = PricingRepository{
+		s: conn.s,
+	}
+	return
+}
+
+// GetRules retrieves the pricing rules, in evaluation order.
+func (pr PricingRepository) GetRules(ctx context.Context) (rules []product.PricingRule, err error) {
+	table := "pricing_rule"
+	err = pr.s.lock(ctx)
+	if err != nil {
+		err = errorInRows(table, "get", err)
+		return
+	}
+	defer pr.s.mu.Unlock()
+
+	rules = make([]product.PricingRule, len(pr.s.data.rules))
+	copy(rules, pr.s.data.rules)
+	return
+}
@@ -0,0 +1,420 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/search"
+)
+
+// ProductRepository represents a repository for managing products in memory.
+type ProductRepository struct {
+	s *store
+}
+
+// NewProductRepository creates a new ProductRepository instance using an in-memory connector.
+func NewProductRepository(conn *Connector) (repo database.ProductRepository, err error) {
+	repo = ProductRepository{
+		s: conn.s,
+	}
+	return
+}
+
+// Create inserts a new product and returns its ID.
+func (pr ProductRepository) Create(ctx context.Context, p product.Product) (id int, err error) {
+	table := "product"
+	err = pr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+		return
+	}
+	defer pr.s.mu.Unlock()
+
+	// Guide numbers are unique.
+	err = checkGuideNumber(pr.s.data, p.GuideNumber, 0)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+		return
+	}
+
+	id = pr.s.data.nextID(table)
+	p.ID = id
+	pr.s.data.products[id] = copyProduct(p)
+	return
+}
+
+// CreateBatch inserts several products and returns their IDs, in the same order.
+// Every product is checked before inserting any, so either every product is inserted or none is.
+func (pr ProductRepository) CreateBatch(ctx context.Context, ps []product.Product) (ids []int, err error) {
+	table := "product"
+	err = pr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+		return
+	}
+	defer pr.s.mu.Unlock()
+
+	// Guide numbers are unique, also among the products of the batch.
+	batch := make(map[string]bool, len(ps))
+	for _, p := range ps {
+		err = checkGuideNumber(pr.s.data, p.GuideNumber, 0)
+		if err == nil && p.GuideNumber != nil && batch[*p.GuideNumber] {
+			err = uniqueViolation("guide_number")
+		}
+		if err != nil {
+			err = errorInRow(table, "insert", err)
+			return
+		}
+		if p.GuideNumber != nil {
+			batch[*p.GuideNumber] = true
+		}
+	}
+
+	ids = make([]int, 0, len(ps))
+	for _, p := range ps {
+		p.ID = pr.s.data.nextID(table)
+		pr.s.data.products[p.ID] = copyProduct(p)
+		ids = append(ids, p.ID)
+	}
+	return
+}
+
+// GetOne retrieves a single product by its ID and clientID.
+func (pr ProductRepository) GetOne(ctx context.Context, id, clientID int) (p product.Product, err error) {
+	table := "product"
+	err = pr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	defer pr.s.mu.Unlock()
+
+	stored, ok := pr.s.data.products[id]
+	if !ok || (clientID != database.ANY_CLIENT && stored.ClientID != clientID) {
+		err = errorInRow(table, "get", errNoRows)
+		return
+	}
+	p = copyProduct(stored)
+	return
+}
+
+// GetByGuideNumber retrieves a single product by its guide number.
+// It is not restricted to any client, the caller is responsible for redacting the result.
+func (pr ProductRepository) GetByGuideNumber(ctx context.Context, guideNumber string) (p product.Product, err error) {
+	table := "product"
+	err = pr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	defer pr.s.mu.Unlock()
+
+	for _, stored := range pr.s.data.products {
+		if stored.GuideNumber != nil && *stored.GuideNumber == guideNumber {
+			p = copyProduct(stored)
+			return
+		}
+	}
+	err = errorInRow(table, "get", errNoRows)
+	return
+}
+
+// Get retrieves a page of products for a given clientID, in the order of the pagination sort, and the total number of products of the client.
+func (pr ProductRepository) Get(ctx context.Context, pagination search.Pagination, clientID int) (ps []*product.Product, total int, err error) {
+	return pr.find(ctx, pagination, func(p product.Product) bool {
+		return clientID == database.ANY_CLIENT || p.ClientID == clientID
+	}, nil)
+}
+
+// Search searches for products based on the provided search criteria, in the order of the pagination sort.
+// Empty criteria match any product, and the ranges are inclusive and can be open on either end.
+// A search with a full-text query and no sort is sorted by relevance instead, ignoring the pagination cursor.
+// Only the page of the search pagination is returned, along with the total number of matching products.
+func (pr ProductRepository) Search(ctx context.Context, srch search.Search) (ps []*product.Product, total int, err error) {
+	var rank func(p product.Product) int
+	if srch.Ranked() {
+		rank = srch.Rank
+	}
+	return pr.find(ctx, srch.Pagination, func(p product.Product) bool {
+		return matches(p, srch)
+	}, rank)
+}
+
+// Export calls fn with every product matching the provided search criteria, in batches of up to size products.
+// The store is not locked while fn runs, so it can use the other repositories.
+func (pr ProductRepository) Export(ctx context.Context, srch search.Search, size int, fn func(ps []*product.Product) error) (err error) {
+	return database.ExportBatches(ctx, srch, size, func(ctx context.Context, srch search.Search) (ps []*product.Product, err error) {
+		ps, _, err = pr.Search(ctx, srch)
+		return
+	}, fn)
+}
+
+// Facets counts the products matching the provided search criteria per value of every facet, keyed by its field.
+// The products are counted per range of values for histograms, and the pagination of the search is ignored.
+func (pr ProductRepository) Facets(ctx context.Context, srch search.Search, facets []search.Facet) (results map[string]search.FacetResult, err error) {
+	table := "product"
+	err = pr.s.lock(ctx)
+	if err != nil {
+		err = errorInRows(table, "count", err)
+		return
+	}
+	defer pr.s.mu.Unlock()
+
+	// Count the matching products per value of every facet, and the ones missing it.
+	counts := make([]map[interface{}]int, len(facets))
+	missing := make([]int, len(facets))
+	for i := range facets {
+		counts[i] = make(map[interface{}]int)
+	}
+	for _, p := range pr.s.data.products {
+		if !matches(p, srch) {
+			continue
+		}
+		for i, f := range facets {
+			v := f.ProductValue(p)
+			if v == nil {
+				missing[i]++
+				continue
+			}
+			counts[i][v]++
+		}
+	}
+
+	results = make(map[string]search.FacetResult, len(facets))
+	for i, f := range facets {
+		buckets := make([]search.FacetBucket, 0, len(counts[i]))
+		for v, n := range counts[i] {
+			buckets = append(buckets, search.FacetBucket{Value: v, Count: n})
+		}
+		results[f.Field] = f.NewResult(buckets, missing[i])
+	}
+	return
+}
+
+// rankSort sorts the products by descending relevance, the only value of their keys when ranked.
+var rankSort = search.Sort{{Name: "rank", Desc: true}}
+
+// find retrieves the products accepted by the filter in the given pagination, in the order of its sort.
+// If rank is not nil, the products are sorted by their descending rank instead.
+// It also returns the total number of products accepted by the filter.
+func (pr ProductRepository) find(ctx context.Context, pagination search.Pagination, filter func(p product.Product) bool, rank func(p product.Product) int) (ps []*product.Product, total int, err error) {
+	table := "product"
+	err = pr.s.lock(ctx)
+	if err != nil {
+		err = errorInRows(table, "get", err)
+		return
+	}
+	defer pr.s.mu.Unlock()
+
+	ps = make([]*product.Product, 0)
+	keys := make([]sortKey, 0)
+	for _, id := range sortedIDs(pr.s.data.products) {
+		if p := pr.s.data.products[id]; filter(p) {
+			ps = append(ps, &p)
+			if rank != nil {
+				keys = append(keys, sortKey{values: []interface{}{rank(p)}, id: id})
+				continue
+			}
+			keys = append(keys, sortKey{values: pagination.Sort.ProductValues(p), id: id})
+		}
+	}
+	total = len(ps)
+	if rank != nil {
+		// The ranks are not part of the cursors, so a ranked search ignores them.
+		pagination.Sort = rankSort
+		pagination.After = nil
+	}
+	sortByKeys(ps, keys, pagination.Sort)
+
+	// Skip the products up to the pagination cursor, which are counted but never returned.
+	ps = paginate(afterCursor(ps, keys, pagination), pagination)
+	for i, p := range ps {
+		copied := copyProduct(*p)
+		ps[i] = &copied
+	}
+	return
+}
+
+// Update updates the non-nil fields of a product.
+// Its status, client and quote are never updated.
+func (pr ProductRepository) Update(ctx context.Context, p product.Product) (err error) {
+	table := "product"
+	err = pr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	defer pr.s.mu.Unlock()
+
+	// Check if the user has ownership of the product before updating.
+	stored, err := checkProductOwner(pr.s.data, p.ID, p.ClientID)
+	if err != nil {
+		return
+	}
+
+	// Guide numbers are unique.
+	err = checkGuideNumber(pr.s.data, p.GuideNumber, p.ID)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+
+	p = copyProduct(p)
+	stored.GuideNumber = coalesce(p.GuideNumber, stored.GuideNumber)
+	stored.Type = coalesce(p.Type, stored.Type)
+	stored.JoinedAt = coalesce(p.JoinedAt, stored.JoinedAt)
+	stored.DeliveredAt = coalesce(p.DeliveredAt, stored.DeliveredAt)
+	stored.ShippingPrice = coalesce(p.ShippingPrice, stored.ShippingPrice)
+	stored.VehiclePlate = coalesce(p.VehiclePlate, stored.VehiclePlate)
+	stored.Port = coalesce(p.Port, stored.Port)
+	stored.Vault = coalesce(p.Vault, stored.Vault)
+	stored.Quantity = coalesce(p.Quantity, stored.Quantity)
+	pr.s.data.products[p.ID] = stored
+	return
+}
+
+// UpdateStatus moves a product from one status to another.
+// It fails with a conflict if the product is no longer in the from status.
+func (pr ProductRepository) UpdateStatus(ctx context.Context, id, clientID int, from, to product.Status) (err error) {
+	table := "product"
+	err = pr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	defer pr.s.mu.Unlock()
+
+	// Check if the user has ownership of the product before updating.
+	stored, err := checkProductOwner(pr.s.data, id, clientID)
+	if err != nil {
+		return
+	}
+	if stored.Status != from {
+		err = errors.NewError(errors.CONFLICT, fmt.Sprintf("failed to update a row in %s table", table),
+			fmt.Sprintf("product %d is no longer in status %s", id, from))
+		return
+	}
+	stored.Status = to
+	pr.s.data.products[id] = stored
+	return
+}
+
+// Delete removes a product.
+func (pr ProductRepository) Delete(ctx context.Context, id, clientID int) (err error) {
+	table := "product"
+	err = pr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "delete", err)
+		return
+	}
+	defer pr.s.mu.Unlock()
+
+	// Check if the user has ownership of the product before deleting.
+	_, err = checkProductOwner(pr.s.data, id, clientID)
+	if err != nil {
+		return
+	}
+	delete(pr.s.data.products, id)
+	return
+}
+
+// checkProductOwner verifies if the user has ownership of the product with the given ID, and returns it.
+// Any product is considered owned when clientID is database.ANY_CLIENT.
+// Products owned by another client are reported as not found, to not reveal their existence.
+func checkProductOwner(t *tables, id, clientID int) (p product.Product, err error) {
+	table := "product"
+	p, ok := t.products[id]
+	if !ok {
+		err = errorInRow(table, "get", errNoRows)
+		return
+	}
+	if clientID != database.ANY_CLIENT && p.ClientID != clientID {
+		err = errors.NewError(errors.NOT_FOUND, fmt.Sprintf("failed to get a row in %s table", table),
+			"invalid client id: client id is not the same as the data to deal with")
+	}
+	return
+}
+
+// checkGuideNumber checks that no product other than the one with the given ID uses the guide number.
+// A nil guide number never conflicts.
+func checkGuideNumber(t *tables, guideNumber *string, id int) (err error) {
+	if guideNumber == nil {
+		return
+	}
+	for _, p := range t.products {
+		if p.ID != id && p.GuideNumber != nil && *p.GuideNumber == *guideNumber {
+			err = uniqueViolation("guide_number")
+			return
+		}
+	}
+	return
+}
+
+// coalesce returns v, or def if v is nil.
+func coalesce[T any](v, def *T) *T {
+	if v == nil {
+		return def
+	}
+	return v
+}
+
+// matches reports whether a product meets every search criteria.
+// Like in SQL, a product missing a column never matches a range on it.
+func matches(p product.Product, srch search.Search) bool {
+	status := string(p.Status)
+	switch {
+	case srch.ClientID != database.ANY_CLIENT && p.ClientID != srch.ClientID:
+		return false
+	case p.ID <= srch.AfterID:
+		return false
+	case !srch.GuideNumber.Matches(p.GuideNumber):
+		return false
+	case !srch.Type.Matches(p.Type):
+		return false
+	case !srch.VehiclePlate.Matches(p.VehiclePlate):
+		return false
+	case !srch.Port.Matches(p.Port):
+		return false
+	case !srch.Vault.Matches(p.Vault):
+		return false
+	case !srch.Status.Matches(&status):
+		return false
+	case srch.Filter != nil && !srch.Filter.Matches(p):
+		return false
+	case !srch.MatchesQuery(p):
+		return false
+	}
+	return inRange(p.ShippingPrice, srch.PriceRange.Start, srch.PriceRange.End, 0) &&
+		inRange(p.Quantity, srch.QuantityRange.Start, srch.QuantityRange.End, 0) &&
+		inTimeRange(p.JoinedAt, srch.JoinedAtRange) &&
+		inTimeRange(p.DeliveredAt, srch.DeliveredAtRange)
+}
+
+// inRange reports whether v is within the inclusive range from start to end.
+// A bound equal to unset leaves the range open on that end.
+func inRange[T int | float64](v *T, start, end, unset T) bool {
+	if start == unset && end == unset {
+		return true
+	}
+	if v == nil {
+		return false
+	}
+	return (start == unset || start <= *v) && (end == unset || end >= *v)
+}
+
+// inTimeRange reports whether v is within the time range, whose end is excluded if the range says so.
+// A zero bound leaves the range open on that end.
+func inTimeRange(v *time.Time, r search.RangeTime) bool {
+	if r.Start.IsZero() && r.End.IsZero() {
+		return true
+	}
+	if v == nil {
+		return false
+	}
+	return (r.Start.IsZero() || !v.Before(r.Start)) &&
+		(r.End.IsZero() || v.Before(r.End) || (!r.EndExcluded && v.Equal(r.End)))
+}
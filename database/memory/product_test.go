@@ -0,0 +1,21 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/databasetest"
+)
+
+func TestProductRepository(t *testing.T) {
+	databasetest.TestProductRepository(t, newTestDatabase)
+}
+
+// newTestDatabase creates a database on an empty in-memory store.
+func newTestDatabase(t *testing.T) database.Database {
+	conn := NewConnector()
+	return database.Database{
+		Conn:         conn,
+		Repositories: newRepositories(conn.s),
+	}
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/quote"
+)
+
+// QuoteRepository represents a repository for managing the issued quotes in memory.
+type QuoteRepository struct {
+	s *store
+}
+
+// NewQuoteRepository creates a new QuoteRepository instance using an in-memory connector.
+func NewQuoteRepository(conn *Connector) (repo database.QuoteRepository, err error) {
+	repo = QuoteRepository{
+		s: conn.s,
+	}
+	return
+}
+
+// Create inserts a new quote and returns its ID.
+func (qr QuoteRepository) Create(ctx context.Context, q quote.Quote) (id int, err error) {
+	table := "quote"
+	err = qr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+		return
+	}
+	defer qr.s.mu.Unlock()
+
+	id = qr.s.data.nextID(table)
+	q.ID = id
+	q.UsedAt = nil
+	qr.s.data.quotes[id] = copyQuote(q)
+	return
+}
+
+// GetOne retrieves a single quote by its ID and clientID.
+func (qr QuoteRepository) GetOne(ctx context.Context, id, clientID int) (q quote.Quote, err error) {
+	table := "quote"
+	err = qr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	defer qr.s.mu.Unlock()
+
+	stored, ok := qr.s.data.quotes[id]
+	if !ok || (clientID != database.ANY_CLIENT && stored.ClientID != clientID) {
+		err = errorInRow(table, "get", errNoRows)
+		return
+	}
+	q = copyQuote(stored)
+	return
+}
+
+// GetPricings retrieves the prices locked by the quotes with the given IDs, by quote ID.
+func (qr QuoteRepository) GetPricings(ctx context.Context, ids []int) (pricings map[int]product.Pricing, err error) {
+	table := "quote"
+	err = qr.s.lock(ctx)
+	if err != nil {
+		err = errorInRows(table, "get", err)
+		return
+	}
+	defer qr.s.mu.Unlock()
+
+	pricings = make(map[int]product.Pricing)
+	for _, id := range ids {
+		if q, ok := qr.s.data.quotes[id]; ok {
+			pricings[id] = copyQuote(q).Pricing()
+		}
+	}
+	return
+}
+
+// Use marks a quote as used by a product.
+// Only an unused and unexpired quote can be used, so a quote can only be used once.
+func (qr QuoteRepository) Use(ctx context.Context, id, clientID int) (err error) {
+	table := "quote"
+	err = qr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	defer qr.s.mu.Unlock()
+
+	now := time.Now()
+	q, ok := qr.s.data.quotes[id]
+	if !ok || (clientID != database.ANY_CLIENT && q.ClientID != clientID) || q.UsedAt != nil || !q.ExpiresAt.After(now) {
+		err = errors.NewError(errors.CONFLICT, fmt.Sprintf("failed to update a row in %s table", table),
+			fmt.Sprintf("quote %d was already used or is expired", id))
+		return
+	}
+	q.UsedAt = &now
+	qr.s.data.quotes[id] = q
+	return
+}
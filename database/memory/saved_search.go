@@ -0,0 +1,255 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/coffemanfp/docucentertest/search"
+)
+
+// SavedSearchRepository represents a repository for managing the saved searches and their digests in memory.
+type SavedSearchRepository struct {
+	s *store
+}
+
+// NewSavedSearchRepository creates a new SavedSearchRepository instance using an in-memory connector.
+func NewSavedSearchRepository(conn *Connector) (repo database.SavedSearchRepository, err error) {
+	repo = SavedSearchRepository{
+		s: conn.s,
+	}
+	return
+}
+
+// Create inserts a new saved search and returns its ID.
+func (sr SavedSearchRepository) Create(ctx context.Context, s savedsearch.SavedSearch) (id int, err error) {
+	table := "saved_search"
+	err = sr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+		return
+	}
+	defer sr.s.mu.Unlock()
+
+	s, err = copySavedSearch(s)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+		return
+	}
+	// Its runs only report the products created after it, so it starts from the greatest existing product ID.
+	s.LastProductID = 0
+	for productID := range sr.s.data.products {
+		if productID > s.LastProductID {
+			s.LastProductID = productID
+		}
+	}
+	id = sr.s.data.nextID(table)
+	s.ID = id
+	sr.s.data.savedSearches[id] = s
+	return
+}
+
+// GetOne retrieves a single saved search by its ID and clientID.
+func (sr SavedSearchRepository) GetOne(ctx context.Context, id, clientID int) (s savedsearch.SavedSearch, err error) {
+	table := "saved_search"
+	err = sr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	defer sr.s.mu.Unlock()
+
+	stored, err := checkSavedSearchOwner(sr.s.data, id, clientID)
+	if err != nil {
+		return
+	}
+	s, err = copySavedSearch(stored)
+	if err != nil {
+		err = errorInRow(table, "scan", err)
+	}
+	return
+}
+
+// Get retrieves a page of saved searches for a given clientID, ordered by ID, and the total number of saved searches of the client.
+func (sr SavedSearchRepository) Get(ctx context.Context, pagination search.Pagination, clientID int) (ss []savedsearch.SavedSearch, total int, err error) {
+	table := "saved_search"
+	err = sr.s.lock(ctx)
+	if err != nil {
+		err = errorInRows(table, "get", err)
+		return
+	}
+	defer sr.s.mu.Unlock()
+
+	ss = make([]savedsearch.SavedSearch, 0)
+	for _, id := range sortedIDs(sr.s.data.savedSearches) {
+		stored := sr.s.data.savedSearches[id]
+		if clientID != database.ANY_CLIENT && stored.ClientID != clientID {
+			continue
+		}
+		var s savedsearch.SavedSearch
+		s, err = copySavedSearch(stored)
+		if err != nil {
+			ss = nil
+			err = errorInRow(table, "scan", err)
+			return
+		}
+		ss = append(ss, s)
+	}
+	total = len(ss)
+	ss = paginate(ss, pagination)
+	return
+}
+
+// Update replaces the scope, name, criteria, schedule, webhook URL and next run of a saved search of its client.
+func (sr SavedSearchRepository) Update(ctx context.Context, s savedsearch.SavedSearch) (err error) {
+	table := "saved_search"
+	err = sr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	defer sr.s.mu.Unlock()
+
+	stored, err := checkSavedSearchOwner(sr.s.data, s.ID, s.ClientID)
+	if err != nil {
+		return
+	}
+
+	s, err = copySavedSearch(s)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	stored.ScopeID = s.ScopeID
+	stored.Name = s.Name
+	stored.Criteria = s.Criteria
+	stored.Schedule = s.Schedule
+	stored.WebhookURL = s.WebhookURL
+	stored.NextRunAt = s.NextRunAt
+	sr.s.data.savedSearches[stored.ID] = stored
+	return
+}
+
+// Delete removes a saved search and its digests.
+func (sr SavedSearchRepository) Delete(ctx context.Context, id, clientID int) (err error) {
+	table := "saved_search"
+	err = sr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "delete", err)
+		return
+	}
+	defer sr.s.mu.Unlock()
+
+	_, err = checkSavedSearchOwner(sr.s.data, id, clientID)
+	if err != nil {
+		return
+	}
+
+	delete(sr.s.data.savedSearches, id)
+	for digestID, d := range sr.s.data.digests {
+		if d.SavedSearchID == id {
+			delete(sr.s.data.digests, digestID)
+		}
+	}
+	return
+}
+
+// GetDue retrieves up to limit saved searches whose next run is due at the given time, the most overdue first.
+func (sr SavedSearchRepository) GetDue(ctx context.Context, now time.Time, limit int) (ss []savedsearch.SavedSearch, err error) {
+	table := "saved_search"
+	err = sr.s.lock(ctx)
+	if err != nil {
+		err = errorInRows(table, "get", err)
+		return
+	}
+	defer sr.s.mu.Unlock()
+
+	ss = make([]savedsearch.SavedSearch, 0)
+	for _, id := range sortedIDs(sr.s.data.savedSearches) {
+		stored := sr.s.data.savedSearches[id]
+		if stored.NextRunAt == nil || stored.NextRunAt.After(now) {
+			continue
+		}
+		var s savedsearch.SavedSearch
+		s, err = copySavedSearch(stored)
+		if err != nil {
+			ss = nil
+			err = errorInRow(table, "scan", err)
+			return
+		}
+		ss = append(ss, s)
+	}
+	sort.SliceStable(ss, func(i, j int) bool {
+		return ss[i].NextRunAt.Before(*ss[j].NextRunAt)
+	})
+	ss = paginate(ss, search.Pagination{Limit: limit})
+	return
+}
+
+// SaveRun stores the digest of a run and the state of the saved search after it.
+// The saved search must still have the runs it had before the run, so a run can only be saved once.
+func (sr SavedSearchRepository) SaveRun(ctx context.Context, s savedsearch.SavedSearch, d savedsearch.Digest) (id int, err error) {
+	table := "saved_search"
+	err = sr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	defer sr.s.mu.Unlock()
+
+	stored, ok := sr.s.data.savedSearches[s.ID]
+	if !ok || stored.Runs != s.Runs-1 {
+		err = errors.NewError(errors.CONFLICT, fmt.Sprintf("failed to update a row in %s table", table),
+			fmt.Sprintf("saved search %d was already run or deleted", s.ID))
+		return
+	}
+	stored.Runs = s.Runs
+	stored.LastRunAt = clonePtr(s.LastRunAt)
+	stored.NextRunAt = clonePtr(s.NextRunAt)
+	stored.LastProductID = s.LastProductID
+	sr.s.data.savedSearches[s.ID] = stored
+
+	id = sr.s.data.nextID("saved_search_digest")
+	d = copyDigest(d)
+	d.ID = id
+	sr.s.data.digests[id] = d
+	return
+}
+
+// GetDigests retrieves a page of the digests of a saved search for a given clientID, the latest first,
+// and the total number of digests of the saved search.
+func (sr SavedSearchRepository) GetDigests(ctx context.Context, savedSearchID int, pagination search.Pagination, clientID int) (ds []savedsearch.Digest, total int, err error) {
+	table := "saved_search_digest"
+	err = sr.s.lock(ctx)
+	if err != nil {
+		err = errorInRows(table, "get", err)
+		return
+	}
+	defer sr.s.mu.Unlock()
+
+	ds = make([]savedsearch.Digest, 0)
+	for _, d := range sr.s.data.digests {
+		if d.SavedSearchID == savedSearchID && (clientID == database.ANY_CLIENT || d.ClientID == clientID) {
+			ds = append(ds, copyDigest(d))
+		}
+	}
+	sort.Slice(ds, func(i, j int) bool {
+		return ds[i].Run > ds[j].Run
+	})
+	total = len(ds)
+	ds = paginate(ds, pagination)
+	return
+}
+
+// checkSavedSearchOwner returns the stored saved search with the given ID, checking it belongs to the client.
+func checkSavedSearchOwner(t *tables, id, clientID int) (s savedsearch.SavedSearch, err error) {
+	s, ok := t.savedSearches[id]
+	if !ok || (clientID != database.ANY_CLIENT && s.ClientID != clientID) {
+		err = errorInRow("saved_search", "get", errNoRows)
+	}
+	return
+}
@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/client"
+	"github.com/coffemanfp/docucentertest/jobs"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/quote"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/coffemanfp/docucentertest/tracking"
+)
+
+// tables holds the rows of every table of the in-memory database, by ID.
+// Stored rows are never modified in place, they are replaced by updated copies,
+// so a shallow copy of the maps is enough to snapshot them.
+type tables struct {
+	clients       map[int]client.Client
+	refreshTokens map[int]auth.RefreshToken
+	revokedTokens map[string]revokedToken
+	products      map[int]product.Product
+	events        map[int]tracking.Event
+	quotes        map[int]quote.Quote
+	savedSearches map[int]savedsearch.SavedSearch
+	digests       map[int]savedsearch.Digest
+	jobs          map[int]jobs.Job
+	jobResults    map[int]jobs.Result // Results of the succeeded jobs, by job ID
+	rules         []product.PricingRule
+	lastIDs       map[string]int // Last ID generated for each table, like a serial column
+}
+
+// revokedToken represents a row of the access token revocation list.
+type revokedToken struct {
+	ExpiresAt *time.Time // Timestamp after which the token is expired anyway, can be nil.
+	RevokedAt time.Time  // Timestamp when the token was revoked.
+}
+
+// newTables creates empty tables, with the default pricing rules.
+func newTables() *tables {
+	t := &tables{
+		clients:       make(map[int]client.Client),
+		refreshTokens: make(map[int]auth.RefreshToken),
+		revokedTokens: make(map[string]revokedToken),
+		products:      make(map[int]product.Product),
+		events:        make(map[int]tracking.Event),
+		quotes:        make(map[int]quote.Quote),
+		savedSearches: make(map[int]savedsearch.SavedSearch),
+		digests:       make(map[int]savedsearch.Digest),
+		jobs:          make(map[int]jobs.Job),
+		jobResults:    make(map[int]jobs.Result),
+		lastIDs:       make(map[string]int),
+	}
+	for _, r := range product.DefaultPricingRules() {
+		r.ID = t.nextID("pricing_rule")
+		t.rules = append(t.rules, r)
+	}
+	return t
+}
+
+// nextID generates the ID of a new row of the given table.
+func (t *tables) nextID(table string) int {
+	t.lastIDs[table]++
+	return t.lastIDs[table]
+}
+
+// clone returns a snapshot of the tables that can be changed without affecting them.
+func (t *tables) clone() *tables {
+	return &tables{
+		clients:       cloneMap(t.clients),
+		refreshTokens: cloneMap(t.refreshTokens),
+		revokedTokens: cloneMap(t.revokedTokens),
+		products:      cloneMap(t.products),
+		events:        cloneMap(t.events),
+		quotes:        cloneMap(t.quotes),
+		savedSearches: cloneMap(t.savedSearches),
+		digests:       cloneMap(t.digests),
+		jobs:          cloneMap(t.jobs),
+		jobResults:    cloneMap(t.jobResults),
+		rules:         append([]product.PricingRule(nil), t.rules...),
+		lastIDs:       cloneMap(t.lastIDs),
+	}
+}
+
+// cloneMap returns a shallow copy of m.
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// clonePtr returns a pointer to a copy of the value pointed by v, or nil if v is nil.
+func clonePtr[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+// copyProduct returns a copy of the stored columns of a product, not sharing any pointer with it.
+func copyProduct(p product.Product) product.Product {
+	return product.Product{
+		ID:            p.ID,
+		ClientID:      p.ClientID,
+		GuideNumber:   clonePtr(p.GuideNumber),
+		Type:          clonePtr(p.Type),
+		Quantity:      clonePtr(p.Quantity),
+		JoinedAt:      clonePtr(p.JoinedAt),
+		DeliveredAt:   clonePtr(p.DeliveredAt),
+		ShippingPrice: clonePtr(p.ShippingPrice),
+		VehiclePlate:  clonePtr(p.VehiclePlate),
+		Port:          clonePtr(p.Port),
+		Vault:         clonePtr(p.Vault),
+		Status:        p.Status,
+		QuoteID:       clonePtr(p.QuoteID),
+	}
+}
+
+// copyEvent returns a copy of a tracking event, not sharing any pointer with it.
+func copyEvent(e tracking.Event) tracking.Event {
+	e.Port = clonePtr(e.Port)
+	e.Vault = clonePtr(e.Vault)
+	e.VehiclePlate = clonePtr(e.VehiclePlate)
+	return e
+}
+
+// copyQuote returns a copy of a quote, not sharing any pointer or slice with it.
+func copyQuote(q quote.Quote) quote.Quote {
+	q.Product = copyProduct(q.Product)
+	q.Rules = append([]string(nil), q.Rules...)
+	q.Lines = append([]product.PriceLine(nil), q.Lines...)
+	q.UsedAt = clonePtr(q.UsedAt)
+	return q
+}
+
+// copySavedSearch returns a copy of a saved search, not sharing any pointer with it.
+// The criteria are copied through JSON, the way the SQL databases store them, so its filter tree has the decoded values.
+func copySavedSearch(s savedsearch.SavedSearch) (copied savedsearch.SavedSearch, err error) {
+	copied = s
+	copied.Criteria = search.Criteria{}
+	b, err := json.Marshal(s.Criteria)
+	if err == nil {
+		err = json.Unmarshal(b, &copied.Criteria)
+	}
+	copied.NextRunAt = clonePtr(s.NextRunAt)
+	copied.LastRunAt = clonePtr(s.LastRunAt)
+	return
+}
+
+// copyDigest returns a copy of a digest, not sharing any slice with it.
+func copyDigest(d savedsearch.Digest) savedsearch.Digest {
+	d.ProductIDs = append(make([]int, 0, len(d.ProductIDs)), d.ProductIDs...)
+	return d
+}
+
+// copyJob returns a copy of a job, not sharing any pointer or slice with it.
+func copyJob(j jobs.Job) jobs.Job {
+	j.Params = append([]byte(nil), j.Params...)
+	j.Input = append([]byte(nil), j.Input...)
+	j.LockedUntil = clonePtr(j.LockedUntil)
+	j.StartedAt = clonePtr(j.StartedAt)
+	j.FinishedAt = clonePtr(j.FinishedAt)
+	return j
+}
+
+// sortedIDs returns the keys of m in ascending order.
+func sortedIDs[V any](m map[int]V) (ids []int) {
+	ids = make([]int, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/tracking"
+)
+
+// TrackingRepository represents a repository for managing the tracking events of the products in memory.
+type TrackingRepository struct {
+	s *store
+}
+
+// NewTrackingRepository creates a new TrackingRepository instance using an in-memory connector.
+func NewTrackingRepository(conn *Connector) (repo database.TrackingRepository, err error) {
+	repo = TrackingRepository{
+		s: conn.s,
+	}
+	return
+}
+
+// Get retrieves the tracking events of a product, ordered by the time they were recorded.
+func (tr TrackingRepository) Get(ctx context.Context, productID, clientID int) (es []*tracking.Event, err error) {
+	table := "product_event"
+	err = tr.s.lock(ctx)
+	if err != nil {
+		err = errorInRows(table, "get", err)
+		return
+	}
+	defer tr.s.mu.Unlock()
+
+	// Check if the user has ownership of the product before reading its events.
+	_, err = checkProductOwner(tr.s.data, productID, clientID)
+	if err != nil {
+		return
+	}
+
+	es = make([]*tracking.Event, 0)
+	for _, id := range sortedIDs(tr.s.data.events) {
+		if e := tr.s.data.events[id]; e.ProductID == productID {
+			e = copyEvent(e)
+			es = append(es, &e)
+		}
+	}
+	// Order by the recording time, events recorded at the same time keep the ID order.
+	sort.SliceStable(es, func(i, j int) bool {
+		return es[i].RecordedAt.Before(es[j].RecordedAt)
+	})
+	return
+}
+
+// Create inserts a new tracking event for a product and returns its ID.
+func (tr TrackingRepository) Create(ctx context.Context, e tracking.Event, clientID int) (id int, err error) {
+	table := "product_event"
+	err = tr.s.lock(ctx)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+		return
+	}
+	defer tr.s.mu.Unlock()
+
+	// Check if the user has ownership of the product before recording an event.
+	_, err = checkProductOwner(tr.s.data, e.ProductID, clientID)
+	if err != nil {
+		return
+	}
+
+	id = tr.s.data.nextID(table)
+	e.ID = id
+	tr.s.data.events[id] = copyEvent(e)
+	return
+}
@@ -0,0 +1,16 @@
+package database
+
+import (
+	"context"
+
+	"github.com/coffemanfp/docucentertest/product"
+)
+
+// PRICING_REPOSITORY is the key to be used when creating the repositories hashmap.
+const PRICING_REPOSITORY RepositoryID = "PRICING_REPOSITORY"
+
+// PricingRepository defines the methods for reading the pricing rules of the products.
+type PricingRepository interface {
+	// GetRules retrieves the active pricing rules, in evaluation order.
+	GetRules(ctx context.Context) (rules []product.PricingRule, err error)
+}
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"context"
+
 	"github.com/coffemanfp/docucentertest/product"
 	"github.com/coffemanfp/docucentertest/search"
 )
@@ -9,22 +11,77 @@ import (
 const PRODUCT_REPOSITORY RepositoryID = "PRODUCT_REPOSITORY"
 
 // ProductRepository defines the methods for working with product data in the database.
+// Every clientID parameter can be ANY_CLIENT to not restrict the operation to a single client.
 type ProductRepository interface {
-	// Get retrieves a list of products based on the given page and client ID.
-	Get(page, clientID int) (products []*product.Product, err error)
+	// Get retrieves a page of products based on the given pagination and client ID, ordered by ID.
+	// Only the products after the pagination cursor are retrieved, if it has one.
+	// It also returns the total number of products of the client, regardless of the pagination.
+	Get(ctx context.Context, pagination search.Pagination, clientID int) (products []*product.Product, total int, err error)
 
 	// GetOne retrieves a specific product based on the provided ID and client ID.
-	GetOne(id, clientID int) (product product.Product, err error)
+	GetOne(ctx context.Context, id, clientID int) (product product.Product, err error)
+
+	// GetByGuideNumber retrieves a specific product based on its guide number, without any client restriction.
+	GetByGuideNumber(ctx context.Context, guideNumber string) (product product.Product, err error)
 
 	// Create inserts a new product into the database and returns its ID.
-	Create(product product.Product) (id int, err error)
+	Create(ctx context.Context, product product.Product) (id int, err error)
+
+	// CreateBatch inserts several products into the database at once and returns their IDs, in the same order.
+	// Either every product is inserted or none is.
+	CreateBatch(ctx context.Context, products []product.Product) (ids []int, err error)
+
+	// Search retrieves a page of products based on the provided search criteria and its pagination, in the order of its sort.
+	// Only the products after the pagination cursor are retrieved, if it has one.
+	// A search with a full-text query and no sort is ranked by relevance instead, ignoring the cursor (see search.Search.Ranked).
+	// It also returns the total number of products matching the criteria, regardless of the pagination.
+	Search(ctx context.Context, search search.Search) (products []*product.Product, total int, err error)
+
+	// Export calls fn with every product matching the provided search criteria, in batches of up to size products and
+	// in the order of its sort, ignoring its pagination. Only a batch is retrieved at a time, after the previous one
+	// is handled by fn, so the products are never held in memory all at once.
+	// It stops at the first error, either retrieving a batch or returned by fn.
+	Export(ctx context.Context, search search.Search, size int, fn func(products []*product.Product) error) (err error)
 
-	// Search retrieves a list of products based on the provided search criteria.
-	Search(search search.Search) (products []*product.Product, err error)
+	// Facets counts the products matching the provided search criteria per value of every facet, keyed by its field.
+	// The pagination of the search is ignored, so every matching product is counted.
+	Facets(ctx context.Context, search search.Search, facets []search.Facet) (results map[string]search.FacetResult, err error)
 
 	// Update updates the details of a product in the database.
-	Update(product product.Product) (err error)
+	Update(ctx context.Context, product product.Product) (err error)
+
+	// UpdateStatus moves a product from one status to another.
+	// It returns a CONFLICT error if the product is no longer in the from status.
+	UpdateStatus(ctx context.Context, id, clientID int, from, to product.Status) (err error)
 
 	// Delete removes a product from the database based on the provided ID and client ID.
-	Delete(id, clientID int) (err error)
+	Delete(ctx context.Context, id, clientID int) (err error)
+}
+
+// ExportBatches calls fn with every product matching srch in batches of up to size products, for the implementations of
+// ProductRepository.Export. Every batch is retrieved with find, paginated after the last product of the previous one,
+// or by its offset when srch is ranked since the ranks are not part of the cursors.
+func ExportBatches(ctx context.Context, srch search.Search, size int, find func(ctx context.Context, srch search.Search) ([]*product.Product, error), fn func(products []*product.Product) error) (err error) {
+	// Start from the first product, in the order of the search.
+	srch.Pagination = search.Pagination{Limit: size, Sort: srch.Pagination.Sort}
+	for {
+		var ps []*product.Product
+		ps, err = find(ctx, srch)
+		if err != nil || len(ps) == 0 {
+			return
+		}
+
+		// Follow the last product of the batch, before fn can change it.
+		if srch.Ranked() {
+			srch.Pagination.Offset += len(ps)
+		} else {
+			after := search.NewCursor(srch.Pagination.Sort, *ps[len(ps)-1])
+			srch.Pagination.After = &after
+		}
+
+		err = fn(ps)
+		if err != nil || len(ps) < size {
+			return
+		}
+	}
 }
@@ -1,8 +1,10 @@
 package psql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/coffemanfp/docucentertest/auth"
 	"github.com/coffemanfp/docucentertest/client"
@@ -11,7 +13,8 @@ import (
 
 // AuthRepository is a struct representing a repository for authentication-related database operations.
 type AuthRepository struct {
-	db *sql.DB
+	db      querier
+	timeout time.Duration // Maximum duration of each operation, unlimited if zero
 }
 
 // NewAuthRepository creates a new AuthRepository instance.
@@ -23,20 +26,24 @@ func NewAuthRepository(conn *PostgreSQLConnector) (repo database.AuthRepository,
 	}
 	// Initialize and return the AuthRepository.
 	repo = AuthRepository{
-		db: db,
+		db:      db,
+		timeout: conn.queryTimeout,
 	}
 	return
 }
 
 // GetIdAndHashedPassword retrieves the client's ID and hashed password from the database based on the provided auth credentials.
-func (ar AuthRepository) GetIdAndHashedPassword(auth auth.Auth) (id int, hashed string, err error) {
+func (ar AuthRepository) GetIdAndHashedPassword(ctx context.Context, auth auth.Auth) (id int, hashed string, err error) {
+	ctx, cancel := withTimeout(ctx, ar.timeout)
+	defer cancel()
+
 	table := "client"
 	query := `
 		select id, password from client where username = $1
 	`
 
 	// Query the database for the ID and hashed password based on the provided username.
-	err = ar.db.QueryRow(query, auth.Username).Scan(&id, &hashed)
+	err = ar.db.QueryRowContext(ctx, query, auth.Username).Scan(&id, &hashed)
 	if err != nil {
 		err = errorInRow(table, "get", err)
 	}
@@ -44,21 +51,206 @@ func (ar AuthRepository) GetIdAndHashedPassword(auth auth.Auth) (id int, hashed
 }
 
 // Register registers a new client in the database and returns the assigned ID.
-func (ar AuthRepository) Register(client client.Client) (id int, err error) {
+func (ar AuthRepository) Register(ctx context.Context, client client.Client) (id int, err error) {
+	ctx, cancel := withTimeout(ctx, ar.timeout)
+	defer cancel()
+
 	table := "client"
 	query := fmt.Sprintf(`
 		insert into
-			%s(name, surname, username, password, created_at)
+			%s(name, surname, username, password, role, created_at)
 		values
-			($1, $2, $3, $4, $5)
+			($1, $2, $3, $4, $5, $6)
 		returning
 			id
 	`, table)
 
 	// Insert the new client's details into the database and retrieve the assigned ID.
-	err = ar.db.QueryRow(query, client.Name, client.Surname, client.Auth.Username, client.Auth.Password, client.CreatedAt).Scan(&id)
+	err = ar.db.QueryRowContext(ctx, query, client.Name, client.Surname, client.Auth.Username, client.Auth.Password, client.Role, inUTC(client.CreatedAt)).Scan(&id)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+	}
+	return
+}
+
+// GetRole retrieves the role of the client with the given ID.
+func (ar AuthRepository) GetRole(ctx context.Context, id int) (role auth.Role, err error) {
+	ctx, cancel := withTimeout(ctx, ar.timeout)
+	defer cancel()
+
+	table := "client"
+	query := fmt.Sprintf(`
+		select role from %s where id = $1
+	`, table)
+
+	// Query the database for the role based on the provided client ID.
+	err = ar.db.QueryRowContext(ctx, query, id).Scan(&role)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+	}
+	return
+}
+
+// SaveRefreshToken stores a new refresh token in the database.
+func (ar AuthRepository) SaveRefreshToken(ctx context.Context, rt auth.RefreshToken) (err error) {
+	ctx, cancel := withTimeout(ctx, ar.timeout)
+	defer cancel()
+
+	table := "refresh_token"
+	query := fmt.Sprintf(`
+		insert into
+			%s(client_id, family_id, token_hash, expires_at, created_at)
+		values
+			($1, $2, $3, $4, $5)
+	`, table)
+
+	// Insert the refresh token details into the database.
+	_, err = ar.db.ExecContext(ctx, query, rt.ClientID, rt.FamilyID, rt.Hash, inUTC(rt.ExpiresAt), inUTC(rt.CreatedAt))
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+	}
+	return
+}
+
+// GetRefreshToken retrieves a refresh token from the database based on the hash of its plain value.
+func (ar AuthRepository) GetRefreshToken(ctx context.Context, hash string) (rt auth.RefreshToken, err error) {
+	ctx, cancel := withTimeout(ctx, ar.timeout)
+	defer cancel()
+
+	table := "refresh_token"
+	query := fmt.Sprintf(`
+		select
+			id, client_id, family_id, token_hash, expires_at, revoked_at, created_at
+		from
+			%s
+		where
+			token_hash = $1
+	`, table)
+
+	// Query the database for the refresh token details based on the provided hash.
+	err = ar.db.QueryRowContext(ctx, query, hash).Scan(&rt.ID, &rt.ClientID, &rt.FamilyID, &rt.Hash, &rt.ExpiresAt, &rt.RevokedAt, &rt.CreatedAt)
+	if err != nil {
+		rt = auth.RefreshToken{}
+		err = errorInRow(table, "get", err)
+	}
+	return
+}
+
+// RevokeRefreshToken marks an active refresh token as used.
+// The update only matches tokens not revoked yet, so concurrent uses of the same token are detected.
+func (ar AuthRepository) RevokeRefreshToken(ctx context.Context, id int) (err error) {
+	ctx, cancel := withTimeout(ctx, ar.timeout)
+	defer cancel()
+
+	table := "refresh_token"
+	query := fmt.Sprintf(`
+		update
+			%s
+		set
+			revoked_at = $2
+		where
+			id = $1 and revoked_at is null
+	`, table)
+
+	// Execute the update query and check if an active token was revoked.
+	res, err := ar.db.ExecContext(ctx, query, id, time.Now().UTC())
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	if n == 0 {
+		err = errorInRow(table, "update", sql.ErrNoRows)
+	}
+	return
+}
+
+// RevokeRefreshTokenFamily revokes every active refresh token of the given family.
+func (ar AuthRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) (err error) {
+	ctx, cancel := withTimeout(ctx, ar.timeout)
+	defer cancel()
+
+	table := "refresh_token"
+	query := fmt.Sprintf(`
+		update
+			%s
+		set
+			revoked_at = $2
+		where
+			family_id = $1 and revoked_at is null
+	`, table)
+
+	// Execute the update query for every token of the family.
+	_, err = ar.db.ExecContext(ctx, query, familyID, time.Now().UTC())
+	if err != nil {
+		err = errorInRows(table, "update", err)
+	}
+	return
+}
+
+// RevokeToken adds the ID of an access token to the revocation list.
+func (ar AuthRepository) RevokeToken(ctx context.Context, jti string, expiresAt *time.Time) (err error) {
+	ctx, cancel := withTimeout(ctx, ar.timeout)
+	defer cancel()
+
+	table := "revoked_token"
+	query := fmt.Sprintf(`
+		insert into
+			%s(jti, expires_at, revoked_at)
+		values
+			($1, $2, $3)
+		on conflict (jti) do nothing
+	`, table)
+
+	// Insert the token ID into the revocation list.
+	_, err = ar.db.ExecContext(ctx, query, jti, inUTC(expiresAt), time.Now().UTC())
 	if err != nil {
 		err = errorInRow(table, "insert", err)
 	}
 	return
 }
+
+// IsTokenRevoked checks if the ID of an access token is in the revocation list.
+func (ar AuthRepository) IsTokenRevoked(ctx context.Context, jti string) (revoked bool, err error) {
+	ctx, cancel := withTimeout(ctx, ar.timeout)
+	defer cancel()
+
+	table := "revoked_token"
+	query := fmt.Sprintf(`
+		select exists(select 1 from %s where jti = $1)
+	`, table)
+
+	// Query the database to check if the token ID was revoked.
+	err = ar.db.QueryRowContext(ctx, query, jti).Scan(&revoked)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+	}
+	return
+}
+
+// PurgeExpiredTokens deletes the refresh tokens and the revoked access tokens expired at the given time.
+func (ar AuthRepository) PurgeExpiredTokens(ctx context.Context, now time.Time) (err error) {
+	ctx, cancel := withTimeout(ctx, ar.timeout)
+	defer cancel()
+
+	// Delete the expired rows of both tables, the revoked access tokens without expiration are kept.
+	for _, table := range []string{"refresh_token", "revoked_token"} {
+		query := fmt.Sprintf(`
+			delete from
+				%s
+			where
+				expires_at <= $1
+		`, table)
+
+		_, err = ar.db.ExecContext(ctx, query, inUTC(now))
+		if err != nil {
+			err = errorInRows(table, "delete", err)
+			return
+		}
+	}
+	return
+}
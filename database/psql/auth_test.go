@@ -0,0 +1,11 @@
+package psql
+
+import (
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/database/databasetest"
+)
+
+func TestAuthRepository(t *testing.T) {
+	databasetest.TestAuthRepository(t, newTestDatabase)
+}
@@ -1,16 +1,21 @@
 package psql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
+	"github.com/coffemanfp/docucentertest/auth"
 	"github.com/coffemanfp/docucentertest/client"
 	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/search"
 )
 
 // ClientRepository is a struct representing a repository for client-related database operations.
 type ClientRepository struct {
-	db *sql.DB
+	db      querier
+	timeout time.Duration // Maximum duration of each operation, unlimited if zero
 }
 
 // NewClientRepository creates a new ClientRepository instance.
@@ -22,18 +27,22 @@ func NewClientRepository(conn *PostgreSQLConnector) (repo database.ClientReposit
 	}
 	// Initialize and return the ClientRepository.
 	repo = ClientRepository{
-		db: db,
+		db:      db,
+		timeout: conn.queryTimeout,
 	}
 	return
 }
 
 // GetOne retrieves a single client from the database based on the provided ID.
-func (cr ClientRepository) GetOne(id int) (c client.Client, err error) {
+func (cr ClientRepository) GetOne(ctx context.Context, id int) (c client.Client, err error) {
+	ctx, cancel := withTimeout(ctx, cr.timeout)
+	defer cancel()
+
 	table := "client"
 	// SQL query to select client details based on ID.
 	query := fmt.Sprintf(`
 		select
-			id, title, description, list_id, reminder, due_date, repeat, is_done, is_added_to_my_day, is_important, created_at, created_by
+			id, name, surname, created_at, username, role
 		from
 			%s
 		where
@@ -41,7 +50,7 @@ func (cr ClientRepository) GetOne(id int) (c client.Client, err error) {
 	`, table)
 
 	// Query the database for the client details based on the provided ID.
-	err = cr.db.QueryRow(query, id).Scan(&c.ID, &c.Name, &c.Surname, &c.CreatedAt, &c.Auth.Username)
+	err = cr.db.QueryRowContext(ctx, query, id).Scan(&c.ID, &c.Name, &c.Surname, &c.CreatedAt, &c.Auth.Username, &c.Role)
 	if err != nil {
 		// In case of an error, create an empty client and generate a detailed error message.
 		c = client.Client{}
@@ -50,37 +59,48 @@ func (cr ClientRepository) GetOne(id int) (c client.Client, err error) {
 	return
 }
 
-// Get retrieves a list of clients from the database based on the provided page number.
-func (cr ClientRepository) Get(page int) (cs []*client.Client, err error) {
+// Get retrieves a page of clients from the database based on the provided pagination, in the order of its sort.
+// It also returns the total number of clients.
+func (cr ClientRepository) Get(ctx context.Context, pagination search.Pagination) (cs []*client.Client, total int, err error) {
+	ctx, cancel := withTimeout(ctx, cr.timeout)
+	defer cancel()
+
 	table := "client"
+	// Count every client, regardless of the pagination.
+	total, err = count(ctx, cr.db, table, "true")
+	if err != nil {
+		return
+	}
+
 	// SQL query to select a list of client details with pagination.
 	query := fmt.Sprintf(`
 		select
-			id, name, surname, created_at, username
+			id, name, surname, created_at, username, role
 		from
 			%s
+		order by
+			%s
 		limit
-			$1
+			nullif($1, 0)
 		offset
 			$2
-	`, table)
-
-	// Parse pagination parameters from the provided page number.
-	limit, offset := parsePagination(page)
+	`, table, orderBy(pagination.Sort))
 
 	// Query the database for a list of clients with pagination.
-	rows, err := cr.db.Query(query, limit, offset)
+	rows, err := cr.db.QueryContext(ctx, query, pagination.Limit, pagination.Offset)
 	if err != nil {
 		// In case of an error, generate a detailed error message.
 		err = errorInRow(table, "get", err)
 		return
 	}
+	// Release the rows when done, so the connection can be reused.
+	defer rows.Close()
 
 	cs = make([]*client.Client, 0)
 	for rows.Next() {
 		c := new(client.Client)
 		// Scan the row's data into the client structure.
-		err = rows.Scan(&c.ID, &c.Name, &c.Surname, &c.CreatedAt, &c.Auth.Username)
+		err = rows.Scan(&c.ID, &c.Name, &c.Surname, &c.CreatedAt, &c.Auth.Username, &c.Role)
 		if err != nil {
 			// In case of an error during scanning, set the list to nil and return the error.
 			err = errorInRow(table, "scan", err)
@@ -99,3 +119,36 @@ func (cr ClientRepository) Get(page int) (cs []*client.Client, err error) {
 	}
 	return
 }
+
+// UpdateRole updates the role of the client with the provided ID.
+func (cr ClientRepository) UpdateRole(ctx context.Context, id int, role auth.Role) (err error) {
+	ctx, cancel := withTimeout(ctx, cr.timeout)
+	defer cancel()
+
+	table := "client"
+	// SQL query to update the role of a client based on ID.
+	query := fmt.Sprintf(`
+		update
+			%s
+		set
+			role = $2
+		where
+			id = $1
+	`, table)
+
+	// Execute the update query and check if the client exists.
+	res, err := cr.db.ExecContext(ctx, query, id, role)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	if n == 0 {
+		err = errorInRow(table, "update", sql.ErrNoRows)
+	}
+	return
+}
@@ -1,10 +1,193 @@
 package psql
 
-// parsePagination calculates the limit and offset for pagination based on the provided page number.
-func parsePagination(page int) (limit, offset int) {
-	// Calculate the limit for the current page (adding 1 to the page and multiplying by 20).
-	limit = (page + 1) * 20
-	// Calculate the offset for the current page (multiplying the page by 20).
-	offset = page * 20
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/lib/pq"
+)
+
+// count returns the number of rows of table matching the where condition, with args as its placeholder values.
+func count(ctx context.Context, db querier, table, where string, args ...interface{}) (n int, err error) {
+	query := fmt.Sprintf(`
+		select
+			count(*)
+		from
+			%s
+		where
+			%s
+	`, table, where)
+
+	err = db.QueryRowContext(ctx, query, args...).Scan(&n)
+	if err != nil {
+		err = errorInRow(table, "count", err)
+	}
 	return
 }
+
+// placeholders collects the values of the placeholders of a query being built.
+type placeholders []interface{}
+
+// add appends v to the values of the placeholders and returns its placeholder.
+// The times are added in UTC, like every other time written to the database.
+func (ph *placeholders) add(v interface{}) string {
+	*ph = append(*ph, inUTC(v))
+	return fmt.Sprintf("$%d", len(*ph))
+}
+
+// inUTC returns v in UTC if it is a time or a non-nil pointer to one, and v itself otherwise.
+// The timestamp columns store the times without a time zone, and PostgreSQL would drop their offset instead of converting them.
+func inUTC(v interface{}) interface{} {
+	switch t := v.(type) {
+	case time.Time:
+		return t.UTC()
+	case *time.Time:
+		if t != nil {
+			u := t.UTC()
+			return &u
+		}
+	}
+	return v
+}
+
+// orderBy returns the order of the rows sorted by s, breaking ties by ascending ID.
+// Missing values are sorted after every other value, in both directions.
+func orderBy(s search.Sort) string {
+	keys := make([]string, 0, len(s)+1)
+	for _, f := range s {
+		col := pq.QuoteIdentifier(f.Name)
+		dir := "asc"
+		if f.Desc {
+			dir = "desc"
+		}
+		keys = append(keys, fmt.Sprintf("%s %s nulls last", col, dir))
+	}
+	keys = append(keys, "id asc")
+	return strings.Join(keys, ", ")
+}
+
+// afterCursor returns the condition of the rows following the cursor of the pagination in the order of its sort.
+// The values of the cursor are added to ph. Every row follows a pagination without a cursor.
+func afterCursor(p search.Pagination, ph *placeholders) string {
+	if p.After == nil {
+		return "true"
+	}
+
+	// A row follows the cursor if it is equal on the first fields and follows it on the next one, for any number of fields.
+	equal := make([]string, 0, len(p.Sort))
+	following := make([]string, 0, len(p.Sort)+1)
+	for i, f := range p.Sort {
+		col := pq.QuoteIdentifier(f.Name)
+		v := p.After.Values[i]
+		if v == nil {
+			// No value follows a missing one, which are sorted last.
+			equal = append(equal, col+" is null")
+			continue
+		}
+		value := ph.add(v)
+		op := ">"
+		if f.Desc {
+			op = "<"
+		}
+		follows := fmt.Sprintf("(%s is null or %s %s %s)", col, col, op, value)
+		following = append(following, "("+strings.Join(append(equal[:len(equal):len(equal)], follows), " and ")+")")
+		equal = append(equal, fmt.Sprintf("%s = %s", col, value))
+	}
+	// Rows with the same values on every field follow the cursor after its ID.
+	follows := "id > " + ph.add(p.After.ID)
+	following = append(following, "("+strings.Join(append(equal, follows), " and ")+")")
+	return "(" + strings.Join(following, " or ") + ")"
+}
+
+// likeEscaper escapes the wildcards of a pattern of the like operator, with its default escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// stringFilter returns the condition of the rows whose column meets the filter, adding its values to ph.
+// An empty filter has no condition.
+func stringFilter(column string, f search.StringFilter, ph *placeholders) string {
+	if f.IsZero() {
+		return ""
+	}
+
+	col := pq.QuoteIdentifier(column)
+	alternatives := make([]string, 0, len(f.Prefixes)+2)
+	if len(f.Values) > 0 {
+		alternatives = append(alternatives, fmt.Sprintf("%s = any(%s::text[])", col, ph.add(pq.Array(f.Values))))
+	}
+	for _, prefix := range f.Prefixes {
+		alternatives = append(alternatives, fmt.Sprintf("%s like %s", col, ph.add(likeEscaper.Replace(prefix)+"%")))
+	}
+	if f.Missing {
+		alternatives = append(alternatives, col+" is null")
+	}
+	return filterCondition(alternatives, f.Not)
+}
+
+// intFilter returns the condition of the rows whose column meets the filter, adding its values to ph.
+// An empty filter has no condition.
+func intFilter(column string, f search.IntFilter, ph *placeholders) string {
+	if f.IsZero() {
+		return ""
+	}
+
+	col := pq.QuoteIdentifier(column)
+	alternatives := make([]string, 0, 2)
+	if len(f.Values) > 0 {
+		// The driver only encodes arrays of sized integers.
+		values := make([]int64, len(f.Values))
+		for i, v := range f.Values {
+			values[i] = int64(v)
+		}
+		alternatives = append(alternatives, fmt.Sprintf("%s = any(%s::integer[])", col, ph.add(pq.Array(values))))
+	}
+	if f.Missing {
+		alternatives = append(alternatives, col+" is null")
+	}
+	return filterCondition(alternatives, f.Not)
+}
+
+// filterCondition returns the condition of the rows meeting any of the alternatives, or none of them if not is set.
+// The comparisons of a missing value are unknown, so a negated filter is met by the rows missing the column.
+func filterCondition(alternatives []string, not bool) string {
+	cond := "(" + strings.Join(alternatives, " or ") + ")"
+	if not {
+		cond = "not coalesce(" + cond + ", false)"
+	}
+	return cond
+}
+
+// inRange returns the conditions of the rows whose column is between start and end, adding the bounds to ph.
+// A zero bound leaves the range open on its side.
+func inRange[T comparable](column string, start, end T, ph *placeholders) (conds []string) {
+	var zero T
+	col := pq.QuoteIdentifier(column)
+	if start != zero {
+		conds = append(conds, fmt.Sprintf("%s >= %s", col, ph.add(start)))
+	}
+	if end != zero {
+		conds = append(conds, fmt.Sprintf("%s <= %s", col, ph.add(end)))
+	}
+	return
+}
+
+// inTimeRange returns the conditions of a timestamp column within a time range,
+// comparing it with the end of the range as an excluded bound if it is.
+func inTimeRange(column string, r search.RangeTime, ph *placeholders) (conds []string) {
+	if !r.EndExcluded || r.End.IsZero() {
+		return inRange(column, r.Start, r.End, ph)
+	}
+	conds = inRange(column, r.Start, time.Time{}, ph)
+	return append(conds, fmt.Sprintf("%s < %s", pq.QuoteIdentifier(column), ph.add(r.End)))
+}
+
+// withTimeout returns a copy of ctx cancelled after the given timeout.
+// A zero or negative timeout leaves the operation unlimited, so only the cancellation of ctx applies.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -3,6 +3,7 @@ package psql
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	_ "github.com/lib/pq" // Import the PostgreSQL driver package (underscore indicates import for its side effects).
 )
@@ -19,8 +20,9 @@ type properties struct {
 
 // PostgreSQLConnector is a struct representing a PostgreSQL database connector.
 type PostgreSQLConnector struct {
-	props properties // Connection properties
-	db    *sql.DB    // Database connection instance
+	props        properties    // Connection properties
+	db           *sql.DB       // Database connection instance
+	queryTimeout time.Duration // Maximum duration of each repository operation, unlimited if zero
 }
 
 // Connect establishes a connection to the PostgreSQL database.
@@ -61,7 +63,8 @@ func (p PostgreSQLConnector) getConn() (conn *sql.DB, err error) {
 }
 
 // NewPostgreSQLConnector creates a new PostgreSQLConnector instance with the provided connection details.
-func NewPostgreSQLConnector(url, user, pass, name, host string, port int) (conn *PostgreSQLConnector) {
+// Every operation of the repositories created with it is cancelled after queryTimeout, unless it is zero.
+func NewPostgreSQLConnector(url, user, pass, name, host string, port int, queryTimeout time.Duration) (conn *PostgreSQLConnector) {
 	return &PostgreSQLConnector{
 		props: properties{
 			url:  url,
@@ -71,6 +74,7 @@ func NewPostgreSQLConnector(url, user, pass, name, host string, port int) (conn
 			host: host,
 			port: port,
 		},
+		queryTimeout: queryTimeout,
 	}
 }
 
@@ -1,7 +1,9 @@
 package psql
 
 import (
+	"context"
 	"database/sql"
+	stdErrors "errors"
 	"fmt"
 
 	"github.com/coffemanfp/docucentertest/database/errors"
@@ -15,6 +17,8 @@ func parseErrorType(err error) (r string) {
 		switch pqErr.Code.Name() {
 		case "unique_violation":
 			r = errors.ALREADY_EXISTS // Set error type to ALREADY_EXISTS for unique violation.
+		case "query_canceled":
+			r = errors.TIMEOUT // Set error type to TIMEOUT for statements cancelled by a context.
 		default:
 			r = errors.UNKNOWN // Set error type to UNKNOWN for other PostgreSQL errors.
 		}
@@ -23,6 +27,10 @@ func parseErrorType(err error) (r string) {
 	if err == sql.ErrNoRows {
 		r = errors.NOT_FOUND // Set error type to NOT_FOUND for no rows found.
 	}
+	// Check if the context of the operation expired or was cancelled before the database answered.
+	if stdErrors.Is(err, context.DeadlineExceeded) || stdErrors.Is(err, context.Canceled) {
+		r = errors.TIMEOUT // Set error type to TIMEOUT for expired operations.
+	}
 	return
 }
 
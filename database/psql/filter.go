@@ -0,0 +1,70 @@
+package psql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/lib/pq"
+)
+
+// comparisons maps the ordering operators of a filter tree to their SQL operators.
+var comparisons = map[search.Operator]string{
+	search.LT_OPERATOR:  "<",
+	search.LTE_OPERATOR: "<=",
+	search.GT_OPERATOR:  ">",
+	search.GTE_OPERATOR: ">=",
+}
+
+// compileFilter returns the condition of the rows meeting the filter tree, adding its values to ph.
+// The tree must be validated by search.NewFilter, which whitelists its fields so they are safe to be written in the query.
+func compileFilter(f search.Filter, ph *placeholders) string {
+	switch {
+	case f.And != nil:
+		return compileFilters(f.And, " and ", ph)
+	case f.Or != nil:
+		return compileFilters(f.Or, " or ", ph)
+	case f.Not != nil:
+		return filterCondition([]string{compileFilter(*f.Not, ph)}, true)
+	}
+
+	col := pq.QuoteIdentifier(f.Field)
+	switch f.Op {
+	case search.EQ_OPERATOR:
+		return fmt.Sprintf("(%s = %s)", col, ph.add(f.Value))
+	case search.NE_OPERATOR:
+		return filterCondition([]string{fmt.Sprintf("%s = %s", col, ph.add(f.Value))}, true)
+	case search.IN_OPERATOR:
+		return fmt.Sprintf("(%s in (%s))", col, addValues(f.Value.([]interface{}), ph))
+	case search.NIN_OPERATOR:
+		return filterCondition([]string{fmt.Sprintf("%s in (%s)", col, addValues(f.Value.([]interface{}), ph))}, true)
+	case search.LT_OPERATOR, search.LTE_OPERATOR, search.GT_OPERATOR, search.GTE_OPERATOR:
+		return fmt.Sprintf("(%s %s %s)", col, comparisons[f.Op], ph.add(f.Value))
+	case search.PREFIX_OPERATOR:
+		return fmt.Sprintf("(%s like %s)", col, ph.add(likeEscaper.Replace(f.Value.(string))+"%"))
+	case search.MISSING_OPERATOR:
+		if f.Value.(bool) {
+			return fmt.Sprintf("(%s is null)", col)
+		}
+		return fmt.Sprintf("(%s is not null)", col)
+	}
+	return "false"
+}
+
+// compileFilters returns the conditions of the filter trees joined by the logical operator op.
+func compileFilters(fs []search.Filter, op string, ph *placeholders) string {
+	conds := make([]string, len(fs))
+	for i, f := range fs {
+		conds[i] = compileFilter(f, ph)
+	}
+	return "(" + strings.Join(conds, op) + ")"
+}
+
+// addValues adds every value to ph and returns their comma-separated placeholders.
+func addValues(values []interface{}, ph *placeholders) string {
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholders[i] = ph.add(v)
+	}
+	return strings.Join(placeholders, ", ")
+}
@@ -0,0 +1,21 @@
+package psql
+
+import "github.com/coffemanfp/docucentertest/migrations"
+
+// NewMigrator creates a new migrations.Migrator of the embedded migrations using a PostgreSQL connector.
+func NewMigrator(conn *PostgreSQLConnector) (m migrations.Migrator, err error) {
+	// Establish a database connection using the provided connector.
+	db, err := conn.getConn()
+	if err != nil {
+		return
+	}
+
+	// Load the migrations embedded into the binary.
+	ms, err := migrations.Load()
+	if err != nil {
+		return
+	}
+
+	m = migrations.NewMigrator(db, ms)
+	return
+}
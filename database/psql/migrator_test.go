@@ -0,0 +1,38 @@
+package psql
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrator_ConcurrentUp(t *testing.T) {
+	conn := newTestDatabase(t).Conn.(*PostgreSQLConnector)
+	m, err := NewMigrator(conn)
+	require.NoError(t, err)
+
+	reverted, err := m.Down()
+	require.NoError(t, err)
+	require.NotNil(t, reverted)
+
+	// The migrators of two instances apply the reverted migration only once.
+	var wg sync.WaitGroup
+	applied := make([][]migrations.Migration, 2)
+	errs := make([]error, 2)
+	for i := range applied {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			applied[i], errs[i] = m.Up()
+		}(i)
+	}
+	wg.Wait()
+
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	assert.Equal(t, 1, len(applied[0])+len(applied[1]))
+	require.NoError(t, m.CheckCurrent())
+}
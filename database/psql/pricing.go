@@ -0,0 +1,101 @@
+package psql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/lib/pq"
+)
+
+// PricingRepository represents a repository for reading the pricing rules stored in PostgreSQL.
+type PricingRepository struct {
+	db      querier
+	timeout time.Duration // Maximum duration of each operation, unlimited if zero
+}
+
+// NewPricingRepository creates a new PricingRepository instance using a PostgreSQL connector.
+func NewPricingRepository(conn *PostgreSQLConnector) (repo database.PricingRepository, err error) {
+	// Establish a database connection using the provided connector.
+	db, err := conn.getConn()
+	if err != nil {
+		return
+	}
+	// Create and return a new PricingRepository with the established connection.
+	repo = PricingRepository{
+		db:      db,
+		timeout: conn.queryTimeout,
+	}
+	return
+}
+
+// GetRules retrieves the active pricing rules, ordered by priority.
+func (pr PricingRepository) GetRules(ctx context.Context) (rules []product.PricingRule, err error) {
+	ctx, cancel := withTimeout(ctx, pr.timeout)
+	defer cancel()
+
+	table := "pricing_rule"
+	// Define the SQL query for retrieving the active rules in evaluation order.
+	query := fmt.Sprintf(`
+		select
+			id, name, kind, percentage, amount, min_quantity, max_quantity, location,
+			ports, vaults, types, client_ids, valid_from, valid_until, stop
+		from
+			%s
+		where
+			active
+		order by
+			priority, id
+	`, table)
+
+	// Execute the query and retrieve rows from the database.
+	rows, err := pr.db.QueryContext(ctx, query)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	// Release the rows when done, so the connection can be reused.
+	defer rows.Close()
+
+	// Initialize a slice to store the retrieved rules.
+	rules = make([]product.PricingRule, 0)
+	for rows.Next() {
+		var r product.PricingRule
+		var ports, vaults, clientIDs pq.Int64Array
+		// Scan the row's columns into the 'r' variable, the array columns are converted below.
+		err = rows.Scan(&r.ID, &r.Name, &r.Kind, &r.Percentage, &r.Amount, &r.MinQuantity, &r.MaxQuantity, &r.Location,
+			&ports, &vaults, pq.Array(&r.Types), &clientIDs, &r.ValidFrom, &r.ValidUntil, &r.Stop)
+		if err != nil {
+			err = errorInRow(table, "scan", err)
+			rules = nil
+			return
+		}
+		r.Ports = toInts(ports)
+		r.Vaults = toInts(vaults)
+		r.ClientIDs = toInts(clientIDs)
+
+		// Append the scanned rule to the 'rules' slice.
+		rules = append(rules, r)
+	}
+	// Check for any error that occurred during iteration.
+	err = rows.Err()
+	if err != nil {
+		rules = nil
+		err = errorInRows(table, "scanning", err)
+	}
+	return
+}
+
+// toInts converts a PostgreSQL integer array into a slice of ints.
+func toInts(a pq.Int64Array) (is []int) {
+	if len(a) == 0 {
+		return
+	}
+	is = make([]int, len(a))
+	for i, v := range a {
+		is[i] = int(v)
+	}
+	return
+}
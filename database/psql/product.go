@@ -1,18 +1,21 @@
 package psql
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/errors"
 	"github.com/coffemanfp/docucentertest/product"
 	"github.com/coffemanfp/docucentertest/search"
 )
 
 // ProductRepository represents a repository for managing products in PostgreSQL.
 type ProductRepository struct {
-	db *sql.DB
+	db      querier
+	timeout time.Duration // Maximum duration of each operation, unlimited if zero
 }
 
 // NewProductRepository creates a new ProductRepository instance using a PostgreSQL connector.
@@ -24,25 +27,29 @@ func NewProductRepository(conn *PostgreSQLConnector) (repo database.ProductRepos
 	}
 	// Create and return a new ProductRepository with the established connection.
 	repo = ProductRepository{
-		db: db,
+		db:      db,
+		timeout: conn.queryTimeout,
 	}
 	return
 }
 
 // Create inserts a new product into the database and returns its ID.
-func (pr ProductRepository) Create(p product.Product) (id int, err error) {
+func (pr ProductRepository) Create(ctx context.Context, p product.Product) (id int, err error) {
+	ctx, cancel := withTimeout(ctx, pr.timeout)
+	defer cancel()
+
 	table := "product"
 	// Define the SQL query for inserting a new product.
 	query := fmt.Sprintf(`
 		insert into
-			%s(client_id, guide_number, type, joined_at, delivered_at, shipping_price, vehicle_plate, port, vault, quantity)
+			%s(client_id, guide_number, type, joined_at, delivered_at, shipping_price, vehicle_plate, port, vault, quantity, status, quote_id)
 		values
-			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		returning
 			id
 	`, table)
 	// Execute the query and scan the result into the 'id' variable.
-	err = pr.db.QueryRow(query, p.ClientID, p.GuideNumber, p.Type, p.JoinedAt, p.DeliveredAt, p.ShippingPrice, p.VehiclePlate, p.Port, p.Vault, p.Quantity).Scan(&id)
+	err = pr.db.QueryRowContext(ctx, query, p.ClientID, p.GuideNumber, p.Type, p.JoinedAt, p.DeliveredAt, p.ShippingPrice, p.VehiclePlate, p.Port, p.Vault, p.Quantity, p.Status, p.QuoteID).Scan(&id)
 	if err != nil {
 		// If an error occurs, wrap it with a descriptive error message and code.
 		err = errorInRow(table, "insert", err)
@@ -50,22 +57,91 @@ func (pr ProductRepository) Create(p product.Product) (id int, err error) {
 	return
 }
 
+// CreateBatch inserts several products into the database with a single statement and returns their IDs, in the same order.
+// The statement is atomic, so either every product is inserted or none is.
+func (pr ProductRepository) CreateBatch(ctx context.Context, ps []product.Product) (ids []int, err error) {
+	ids = make([]int, 0, len(ps))
+	if len(ps) == 0 {
+		return
+	}
+
+	ctx, cancel := withTimeout(ctx, pr.timeout)
+	defer cancel()
+
+	table := "product"
+	// Add the values of every product to the placeholders.
+	var ph placeholders
+	rows := make([]string, 0, len(ps))
+	for _, p := range ps {
+		values := []interface{}{p.ClientID, p.GuideNumber, p.Type, p.JoinedAt, p.DeliveredAt, p.ShippingPrice, p.VehiclePlate, p.Port, p.Vault, p.Quantity, p.Status, p.QuoteID}
+		row := make([]string, 0, len(values))
+		for _, v := range values {
+			row = append(row, ph.add(v))
+		}
+		rows = append(rows, "("+strings.Join(row, ", ")+")")
+	}
+
+	// Define the SQL query for inserting every product.
+	// The inserted rows are matched to the products by their unique guide number, as the order they are returned in is not guaranteed.
+	query := fmt.Sprintf(`
+		insert into
+			%s(client_id, guide_number, type, joined_at, delivered_at, shipping_price, vehicle_plate, port, vault, quantity, status, quote_id)
+		values
+			%s
+		returning
+			id, guide_number
+	`, table, strings.Join(rows, ",\n\t\t\t"))
+
+	// Execute the query and scan the ID of every inserted product.
+	rs, err := pr.db.QueryContext(ctx, query, ph...)
+	if err != nil {
+		err = errorInRows(table, "insert", err)
+		return
+	}
+	defer rs.Close()
+
+	inserted := make(map[string]int, len(ps))
+	for rs.Next() {
+		var id int
+		var guideNumber string
+		err = rs.Scan(&id, &guideNumber)
+		if err != nil {
+			err = errorInRows(table, "insert", err)
+			return
+		}
+		inserted[guideNumber] = id
+	}
+	err = rs.Err()
+	if err != nil {
+		err = errorInRows(table, "insert", err)
+		return
+	}
+
+	for _, p := range ps {
+		ids = append(ids, inserted[*p.GuideNumber])
+	}
+	return
+}
+
 // GetOne retrieves a single product by its ID and clientID from the database.
-func (pr ProductRepository) GetOne(id, clientID int) (p product.Product, err error) {
+func (pr ProductRepository) GetOne(ctx context.Context, id, clientID int) (p product.Product, err error) {
+	ctx, cancel := withTimeout(ctx, pr.timeout)
+	defer cancel()
+
 	table := "product"
 	// Define the SQL query for retrieving a product by ID and clientID.
 	query := fmt.Sprintf(`
 		select
-			id, client_id, guide_number, type, joined_at, delivered_at, shipping_price, vehicle_plate, port, vault, quantity
+			id, client_id, guide_number, type, joined_at, delivered_at, shipping_price, vehicle_plate, port, vault, quantity, status, quote_id
 		from
 			%s
 		where
-			id = $1 and client_id = $2
+			id = $1 and ($2 = 0 or client_id = $2)
 	`, table)
 
 	// Execute the query and scan the result into the 'p' variable.
-	err = pr.db.QueryRow(query, id, clientID).Scan(&p.ID, &p.ClientID, &p.GuideNumber, &p.Type, &p.JoinedAt,
-		&p.DeliveredAt, &p.ShippingPrice, &p.VehiclePlate, &p.Port, &p.Vault, &p.Quantity)
+	err = pr.db.QueryRowContext(ctx, query, id, clientID).Scan(&p.ID, &p.ClientID, &p.GuideNumber, &p.Type, &p.JoinedAt,
+		&p.DeliveredAt, &p.ShippingPrice, &p.VehiclePlate, &p.Port, &p.Vault, &p.Quantity, &p.Status, &p.QuoteID)
 	if err != nil {
 		// If an error occurs, set 'p' to a default product and wrap the error with additional information.
 		p = product.Product{}
@@ -74,33 +150,63 @@ func (pr ProductRepository) GetOne(id, clientID int) (p product.Product, err err
 	return
 }
 
-// Get retrieves a list of products for a given page and clientID from the database.
-func (pr ProductRepository) Get(page, clientID int) (ps []*product.Product, err error) {
+// GetByGuideNumber retrieves a single product by its guide number from the database.
+// It is not restricted to any client, the caller is responsible for redacting the result.
+func (pr ProductRepository) GetByGuideNumber(ctx context.Context, guideNumber string) (p product.Product, err error) {
+	ctx, cancel := withTimeout(ctx, pr.timeout)
+	defer cancel()
+
 	table := "product"
-	// Define the SQL query for retrieving products for a specific client, with pagination.
+	// Define the SQL query for retrieving a product by its guide number, backed by the unique index on guide_number.
 	query := fmt.Sprintf(`
 		select
-			id, client_id, guide_number, type, joined_at, delivered_at, shipping_price, vehicle_plate, port, vault, quantity
+			id, client_id, guide_number, type, joined_at, delivered_at, shipping_price, vehicle_plate, port, vault, quantity, status, quote_id
 		from
 			%s
 		where
-			client_id = $3
-		limit
-			$1
-		offset
-			$2
+			guide_number = $1
 	`, table)
 
-	// Calculate the 'limit' and 'offset' values based on the page number.
-	limit, offset := parsePagination(page)
+	// Execute the query and scan the result into the 'p' variable.
+	err = pr.db.QueryRowContext(ctx, query, guideNumber).Scan(&p.ID, &p.ClientID, &p.GuideNumber, &p.Type, &p.JoinedAt,
+		&p.DeliveredAt, &p.ShippingPrice, &p.VehiclePlate, &p.Port, &p.Vault, &p.Quantity, &p.Status, &p.QuoteID)
+	if err != nil {
+		// If an error occurs, set 'p' to a default product and wrap the error with additional information.
+		p = product.Product{}
+		err = errorInRow(table, "get", err)
+	}
+	return
+}
+
+// Get retrieves a page of products for a given clientID from the database, in the order of the pagination sort.
+// It also returns the total number of products of the client.
+func (pr ProductRepository) Get(ctx context.Context, pagination search.Pagination, clientID int) (ps []*product.Product, total int, err error) {
+	ctx, cancel := withTimeout(ctx, pr.timeout)
+	defer cancel()
+
+	table := "product"
+	// Define the condition of the products of the client, shared by the count and the page queries.
+	where := "($1 = 0 or client_id = $1)"
+
+	// Count every product of the client, regardless of the pagination.
+	total, err = count(ctx, pr.db, table, where, clientID)
+	if err != nil {
+		return
+	}
+
+	// Define the SQL query for retrieving products for a specific client, with pagination.
+	ph := placeholders{clientID}
+	query := pageQuery(table, where, orderBy(pagination.Sort), pagination, &ph)
 
 	// Execute the query and retrieve rows from the database.
-	rows, err := pr.db.Query(query, limit, offset, clientID)
+	rows, err := pr.db.QueryContext(ctx, query, ph...)
 	if err != nil {
 		// If an error occurs while querying, wrap it with a meaningful error message and code.
 		err = errorInRow(table, "get", err)
 		return
 	}
+	// Release the rows when done, so the connection can be reused.
+	defer rows.Close()
 
 	// Initialize a slice to store the retrieved products.
 	ps = make([]*product.Product, 0)
@@ -108,7 +214,7 @@ func (pr ProductRepository) Get(page, clientID int) (ps []*product.Product, err
 	for rows.Next() {
 		p := new(product.Product)
 		// Scan the row's columns into the 'p' variable.
-		err = rows.Scan(&p.ID, &p.ClientID, &p.GuideNumber, &p.Type, &p.JoinedAt, &p.DeliveredAt, &p.ShippingPrice, &p.VehiclePlate, &p.Port, &p.Vault, &p.Quantity)
+		err = rows.Scan(&p.ID, &p.ClientID, &p.GuideNumber, &p.Type, &p.JoinedAt, &p.DeliveredAt, &p.ShippingPrice, &p.VehiclePlate, &p.Port, &p.Vault, &p.Quantity, &p.Status, &p.QuoteID)
 		if err != nil {
 			// If an error occurs during scanning, wrap it with additional error information.
 			err = errorInRow(table, "scan", err)
@@ -129,65 +235,66 @@ func (pr ProductRepository) Get(page, clientID int) (ps []*product.Product, err
 	return
 }
 
-// Search searches for products based on the provided search criteria.
-func (pr ProductRepository) Search(srch search.Search) (ps []*product.Product, err error) {
+// Search searches for products based on the provided search criteria, in the order of the pagination sort.
+// A search with a full-text query and no sort is sorted by relevance instead.
+// Only the page of the search pagination is returned, along with the total number of matching products.
+func (pr ProductRepository) Search(ctx context.Context, srch search.Search) (ps []*product.Product, total int, err error) {
+	ctx, cancel := withTimeout(ctx, pr.timeout)
+	defer cancel()
+
 	table := "product"
-	// Define the SQL query for searching products based on the provided criteria.
-	query := fmt.Sprintf(`
-		select
-			id, client_id, guide_number, type, joined_at, delivered_at, shipping_price, vehicle_plate, port, vault, quantity
-		from
-			%s
-		where
-			(nullif($1, '') is null or guide_number = $1) and
-			(nullif($2, '') is null or type = $2) and
-			(nullif($3, '') is null or vehicle_plate = $3) and
-			(nullif($4, 0) is null or port = $4) and
-			(nullif($5, 0) is null or vault = $5) and
-
-			((nullif($6, 0.00) is null or nullif($7, 0.00) is null) or ($6 <= shipping_price and $7 >= shipping_price)) and
-			(nullif($6, 0.00) is null or $6 <= shipping_price) and
-			(nullif($7, 0.00) is null or $7 >= shipping_price) and
-
-			(($8::timestamp is null or $9::timestamp is null) or ($8::timestamp <= joined_at and $9::timestamp >= joined_at)) and
-			($8::timestamp is null or $8::timestamp <= joined_at) and
-			($9::timestamp is null or $9::timestamp >= joined_at) and
-
-			(($10::timestamp is null or $11::timestamp is null) or ($10::timestamp <= delivered_at and $11::timestamp >= delivered_at)) and
-			($10::timestamp is null or $10::timestamp <= delivered_at) and
-			($11::timestamp is null or $11::timestamp >= delivered_at) and
-			
-			((nullif($12, 0) is null or nullif($13, 0) is null) or ($12 <= quantity and $13 >= quantity)) and
-			(nullif($12, 0) is null or $12 <= quantity) and
-			(nullif($13, 0) is null or $13 >= quantity) and
-			client_id = $14
-	`, table)
+	// Build the condition of the products matching the search criteria, for the count.
+	ph := placeholders{}
+	where := searchWhere(srch, &ph)
+
+	// Count every matching product, regardless of the pagination.
+	total, err = count(ctx, pr.db, table, where, ph...)
+	if err != nil {
+		return
+	}
+
+	// Retrieve the page of matching products.
+	ps, err = pr.find(ctx, srch)
+	return
+}
+
+// Export calls fn with every product matching the provided search criteria, in batches of up to size products.
+// Every batch is retrieved by its own query, with its own timeout, so no connection is held while fn runs.
+func (pr ProductRepository) Export(ctx context.Context, srch search.Search, size int, fn func(ps []*product.Product) error) (err error) {
+	return database.ExportBatches(ctx, srch, size, func(ctx context.Context, srch search.Search) (ps []*product.Product, err error) {
+		ctx, cancel := withTimeout(ctx, pr.timeout)
+		defer cancel()
+		return pr.find(ctx, srch)
+	}, fn)
+}
+
+// find retrieves the page of products matching the search criteria, in the order of the pagination sort.
+// A search with a full-text query and no sort is sorted by relevance instead, ignoring the pagination cursor.
+func (pr ProductRepository) find(ctx context.Context, srch search.Search) (ps []*product.Product, err error) {
+	table := "product"
+	// Build the condition of the products matching the search criteria.
+	ph := placeholders{}
+	where := searchWhere(srch, &ph)
+
+	// Sort a ranked full-text search by relevance, ignoring the cursor since the ranks are not part of it.
+	pagination, order := srch.Pagination, orderBy(srch.Pagination.Sort)
+	if srch.Ranked() {
+		pagination.After = nil
+		order = rankOrder(srch, &ph)
+	}
+
+	// Define the SQL query for searching products based on the provided criteria, with pagination.
+	query := pageQuery(table, where, order, pagination, &ph)
 
 	// Execute the query with the provided search criteria and retrieve rows from the database.
-	rows, err := pr.db.Query(query, srch.GuideNumber, srch.Type, srch.VehiclePlate, srch.Port, srch.Vault, srch.PriceRange.Start, srch.PriceRange.End,
-		sql.NullTime{
-			Time:  srch.JoinedAtRange.Start,
-			Valid: srch.JoinedAtRange.Start != time.Time{},
-		},
-		sql.NullTime{
-			Time:  srch.JoinedAtRange.End,
-			Valid: srch.JoinedAtRange.End != time.Time{},
-		},
-		sql.NullTime{
-			Time:  srch.DeliveredAtRange.Start,
-			Valid: srch.DeliveredAtRange.Start != time.Time{},
-		},
-		sql.NullTime{
-			Time:  srch.DeliveredAtRange.End,
-			Valid: srch.DeliveredAtRange.End != time.Time{},
-		},
-		srch.QuantityRange.Start, srch.QuantityRange.End, srch.ClientID,
-	)
+	rows, err := pr.db.QueryContext(ctx, query, ph...)
 	if err != nil {
 		// If an error occurs while querying, wrap it with a meaningful error message and code.
 		err = errorInRow(table, "get", err)
 		return
 	}
+	// Release the rows when done, so the connection can be reused.
+	defer rows.Close()
 
 	// Initialize a slice to store the retrieved products.
 	ps = make([]*product.Product, 0)
@@ -195,7 +302,7 @@ func (pr ProductRepository) Search(srch search.Search) (ps []*product.Product, e
 	for rows.Next() {
 		p := new(product.Product)
 		// Scan the row's columns into the 'p' variable.
-		err = rows.Scan(&p.ID, &p.ClientID, &p.GuideNumber, &p.Type, &p.JoinedAt, &p.DeliveredAt, &p.ShippingPrice, &p.VehiclePlate, &p.Port, &p.Vault, &p.Quantity)
+		err = rows.Scan(&p.ID, &p.ClientID, &p.GuideNumber, &p.Type, &p.JoinedAt, &p.DeliveredAt, &p.ShippingPrice, &p.VehiclePlate, &p.Port, &p.Vault, &p.Quantity, &p.Status, &p.QuoteID)
 		if err != nil {
 			// If an error occurs during scanning, wrap it with additional error information.
 			err = errorInRow(table, "scan", err)
@@ -216,10 +323,166 @@ func (pr ProductRepository) Search(srch search.Search) (ps []*product.Product, e
 	return
 }
 
+// Facets counts the products matching the provided search criteria per value of every facet, keyed by its field.
+// The products are counted per range of values for histograms, and the pagination of the search is ignored.
+func (pr ProductRepository) Facets(ctx context.Context, srch search.Search, facets []search.Facet) (results map[string]search.FacetResult, err error) {
+	ctx, cancel := withTimeout(ctx, pr.timeout)
+	defer cancel()
+
+	results = make(map[string]search.FacetResult, len(facets))
+	for _, f := range facets {
+		results[f.Field], err = pr.facet(ctx, srch, f)
+		if err != nil {
+			results = nil
+			return
+		}
+	}
+	return
+}
+
+// facet counts the products matching the search criteria per value of the facet.
+func (pr ProductRepository) facet(ctx context.Context, srch search.Search, f search.Facet) (r search.FacetResult, err error) {
+	table := "product"
+	// Build the condition of the products matching the search criteria, and the value they are grouped by.
+	ph := placeholders{}
+	where := searchWhere(srch, &ph)
+	value := f.Field
+	if f.Histogram() {
+		// Group the values by the start of their ranges, as a float so every driver scans the same type.
+		interval := ph.add(f.Interval)
+		value = fmt.Sprintf("(floor(%s / %s::numeric) * %s::numeric)::float8", f.Field, interval, interval)
+	}
+
+	// Define the SQL query for counting the matching products per value.
+	query := fmt.Sprintf(`
+		select
+			%s, count(*)
+		from
+			%s
+		where
+			%s
+		group by
+			1
+	`, value, table, where)
+
+	// Execute the query and retrieve the number of products of every value.
+	rows, err := pr.db.QueryContext(ctx, query, ph...)
+	if err != nil {
+		// If an error occurs while querying, wrap it with a meaningful error message and code.
+		err = errorInRow(table, "count", err)
+		return
+	}
+	// Release the rows when done, so the connection can be reused.
+	defer rows.Close()
+
+	buckets := make([]search.FacetBucket, 0)
+	missing := 0
+	for rows.Next() {
+		var v interface{}
+		var n int
+		err = rows.Scan(&v, &n)
+		if err != nil {
+			// If an error occurs during scanning, wrap it with additional error information.
+			err = errorInRow(table, "scan", err)
+			return
+		}
+
+		// The products missing the column are grouped together under a null value.
+		if v == nil {
+			missing = n
+			continue
+		}
+		buckets = append(buckets, search.FacetBucket{Value: f.Value(v), Count: n})
+	}
+	// Check for any error that occurred during iteration.
+	err = rows.Err()
+	if err != nil {
+		err = errorInRows(table, "scanning", err)
+		return
+	}
+
+	r = f.NewResult(buckets, missing)
+	return
+}
+
+// pageQuery returns the query of the page of products matching the where condition, sorted by order and paginated by p.
+// The values of the placeholders of the page are added to ph, which must have the ones of the condition.
+func pageQuery(table, where, order string, p search.Pagination, ph *placeholders) string {
+	after := afterCursor(p, ph)
+	limit := ph.add(p.Limit)
+	offset := ph.add(p.Offset)
+	return fmt.Sprintf(`
+		select
+			id, client_id, guide_number, type, joined_at, delivered_at, shipping_price, vehicle_plate, port, vault, quantity, status, quote_id
+		from
+			%s
+		where
+			(%s) and %s
+		order by
+			%s
+		limit
+			nullif(%s, 0)
+		offset
+			%s
+	`, table, where, after, order, limit, offset)
+}
+
+// searchWhere returns the condition of the products matching the search criteria, adding their values to ph.
+// Only the criteria set in the search are part of the condition, so every product matches an empty search.
+func searchWhere(srch search.Search, ph *placeholders) string {
+	conds := make([]string, 0)
+	if srch.ClientID != database.ANY_CLIENT {
+		conds = append(conds, "client_id = "+ph.add(srch.ClientID))
+	}
+	if srch.AfterID != 0 {
+		conds = append(conds, "id > "+ph.add(srch.AfterID))
+	}
+
+	filters := []string{
+		stringFilter("guide_number", srch.GuideNumber, ph),
+		stringFilter("type", srch.Type, ph),
+		stringFilter("vehicle_plate", srch.VehiclePlate, ph),
+		intFilter("port", srch.Port, ph),
+		intFilter("vault", srch.Vault, ph),
+		stringFilter("status", srch.Status, ph),
+	}
+	for _, cond := range filters {
+		if cond != "" {
+			conds = append(conds, cond)
+		}
+	}
+
+	conds = append(conds, inRange("shipping_price", srch.PriceRange.Start, srch.PriceRange.End, ph)...)
+	conds = append(conds, inRange("quantity", srch.QuantityRange.Start, srch.QuantityRange.End, ph)...)
+	conds = append(conds, inTimeRange("joined_at", srch.JoinedAtRange, ph)...)
+	conds = append(conds, inTimeRange("delivered_at", srch.DeliveredAtRange, ph)...)
+
+	if srch.Filter != nil {
+		conds = append(conds, compileFilter(*srch.Filter, ph))
+	}
+	conds = append(conds, queryConditions(srch, ph)...)
+
+	if len(conds) == 0 {
+		return "true"
+	}
+	return strings.Join(conds, " and ")
+}
+
 // Update updates a product in the database.
-func (pr ProductRepository) Update(p product.Product) (err error) {
+// The ownership check and the update run in a single transaction.
+func (pr ProductRepository) Update(ctx context.Context, p product.Product) (err error) {
+	ctx, cancel := withTimeout(ctx, pr.timeout)
+	defer cancel()
+
+	return inTx(ctx, pr.db, func(tx querier) error {
+		return ProductRepository{db: tx}.update(ctx, p)
+	})
+}
+
+// update checks the ownership of a product and updates it.
+func (pr ProductRepository) update(ctx context.Context, p product.Product) (err error) {
 	// Check if the user has ownership of the product before updating.
-	err = pr.checkProductOwner(p.ID, p.ClientID)
+	err = pr.checkProductOwner(ctx, p.ID, p.ClientID)
 	if err != nil {
 		return
 	}
@@ -244,7 +507,7 @@ func (pr ProductRepository) Update(p product.Product) (err error) {
 	`, table)
 
 	// Execute the update query with the provided product details and ID.
-	_, err = pr.db.Exec(query, &p.GuideNumber, &p.Type, &p.JoinedAt, &p.DeliveredAt, &p.ShippingPrice, &p.VehiclePlate, &p.Port, &p.Vault, &p.Quantity, p.ID)
+	_, err = pr.db.ExecContext(ctx, query, &p.GuideNumber, &p.Type, &p.JoinedAt, &p.DeliveredAt, &p.ShippingPrice, &p.VehiclePlate, &p.Port, &p.Vault, &p.Quantity, p.ID)
 	if err != nil {
 		// If an error occurs during the update query, wrap it with additional error information.
 		err = errorInRow(table, "update", err)
@@ -252,10 +515,70 @@ func (pr ProductRepository) Update(p product.Product) (err error) {
 	return
 }
 
+// UpdateStatus moves a product from one status to another.
+// The update only matches the product while it is still in the from status, so concurrent transitions are detected.
+// The ownership check and the update run in a single transaction.
+func (pr ProductRepository) UpdateStatus(ctx context.Context, id, clientID int, from, to product.Status) (err error) {
+	ctx, cancel := withTimeout(ctx, pr.timeout)
+	defer cancel()
+
+	return inTx(ctx, pr.db, func(tx querier) error {
+		return ProductRepository{db: tx}.updateStatus(ctx, id, clientID, from, to)
+	})
+}
+
+// updateStatus checks the ownership of a product and moves it from one status to another.
+func (pr ProductRepository) updateStatus(ctx context.Context, id, clientID int, from, to product.Status) (err error) {
+	// Check if the user has ownership of the product before updating.
+	err = pr.checkProductOwner(ctx, id, clientID)
+	if err != nil {
+		return
+	}
+
+	table := "product"
+	// Define the SQL query for updating the status of a product in the database.
+	query := fmt.Sprintf(`
+		update
+			%s
+		set
+			status = $3
+		where
+			id = $1 and status = $2
+	`, table)
+
+	// Execute the update query and check if the product was still in the expected status.
+	res, err := pr.db.ExecContext(ctx, query, id, from, to)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	if n == 0 {
+		err = errors.NewError(errors.CONFLICT, fmt.Sprintf("failed to update a row in %s table", table),
+			fmt.Sprintf("product %d is no longer in status %s", id, from))
+	}
+	return
+}
+
 // Delete removes a product from the database.
-func (pr ProductRepository) Delete(id, clientID int) (err error) {
+// The ownership check and the removal run in a single transaction.
+func (pr ProductRepository) Delete(ctx context.Context, id, clientID int) (err error) {
+	ctx, cancel := withTimeout(ctx, pr.timeout)
+	defer cancel()
+
+	return inTx(ctx, pr.db, func(tx querier) error {
+		return ProductRepository{db: tx}.delete(ctx, id, clientID)
+	})
+}
+
+// delete checks the ownership of a product and removes it.
+func (pr ProductRepository) delete(ctx context.Context, id, clientID int) (err error) {
 	// Check if the user has ownership of the product before deleting.
-	err = pr.checkProductOwner(id, clientID)
+	err = pr.checkProductOwner(ctx, id, clientID)
 	if err != nil {
 		return
 	}
@@ -270,7 +593,7 @@ func (pr ProductRepository) Delete(id, clientID int) (err error) {
 	`, table)
 
 	// Execute the delete query with the provided product ID.
-	_, err = pr.db.Exec(query, id)
+	_, err = pr.db.ExecContext(ctx, query, id)
 	if err != nil {
 		// If an error occurs during the delete query, wrap it with additional error information.
 		err = errorInRow(table, "delete", err)
@@ -279,21 +602,30 @@ func (pr ProductRepository) Delete(id, clientID int) (err error) {
 }
 
 // checkProductOwner verifies if the user has ownership of the product with the given ID.
-func (pr ProductRepository) checkProductOwner(id, clientID int) (err error) {
+func (pr ProductRepository) checkProductOwner(ctx context.Context, id, clientID int) (err error) {
+	return checkProductOwner(ctx, pr.db, id, clientID)
+}
+
+// checkProductOwner verifies if the user has ownership of the product with the given ID.
+// Any product is considered owned when clientID is database.ANY_CLIENT.
+// Products owned by another client are reported as not found, to not reveal their existence.
+// The product row is locked until the end of the transaction, if any, so it can not change in between.
+func checkProductOwner(ctx context.Context, db querier, id, clientID int) (err error) {
 	table := "product"
 	// Define the SQL query for checking product ownership by comparing the client ID.
 	query := fmt.Sprintf(`
 		select
-			client_id = $2
+			$2 = 0 or client_id = $2
 		from
 			%s
 		where
 			id = $1
+		for update
 	`, table)
 
 	var isSame bool
 	// Execute the query to check if the client ID matches the product's client ID.
-	err = pr.db.QueryRow(query, id, clientID).Scan(&isSame)
+	err = db.QueryRowContext(ctx, query, id, clientID).Scan(&isSame)
 	if err != nil {
 		// If an error occurs during the query, wrap it with additional error information.
 		err = errorInRow(table, "get", err)
@@ -301,7 +633,8 @@ func (pr ProductRepository) checkProductOwner(id, clientID int) (err error) {
 	}
 	if !isSame {
 		// If the client ID does not match, return an error indicating invalid ownership.
-		err = fmt.Errorf("invalid client id: client id is not the same as the data to deal with")
+		err = errors.NewError(errors.NOT_FOUND, fmt.Sprintf("failed to get a row in %s table", table),
+			"invalid client id: client id is not the same as the data to deal with")
 	}
 	return
 }
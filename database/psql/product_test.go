@@ -0,0 +1,46 @@
+package psql
+
+import (
+	"os"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/databasetest"
+	"github.com/stretchr/testify/require"
+)
+
+// testURLEnv is the environment variable with the URL of the PostgreSQL database the tests run against.
+// Every table of that database is emptied by the tests.
+const testURLEnv = "PSQL_TEST_URL"
+
+func TestProductRepository(t *testing.T) {
+	databasetest.TestProductRepository(t, newTestDatabase)
+}
+
+// newTestDatabase migrates and empties the database of the PSQL_TEST_URL environment variable,
+// skipping the test if it is not set.
+func newTestDatabase(t *testing.T) database.Database {
+	url := os.Getenv(testURLEnv)
+	if url == "" {
+		t.Skipf("%s is not set", testURLEnv)
+	}
+
+	conn := NewPostgreSQLConnector(url, "", "", "", "", 0, 0)
+	require.NoError(t, conn.Connect())
+	t.Cleanup(func() {
+		conn.db.Close()
+	})
+
+	// Bring the schema up to date and remove the rows of the previous tests.
+	m, err := NewMigrator(conn)
+	require.NoError(t, err)
+	_, err = m.Up()
+	require.NoError(t, err)
+	_, err = conn.db.Exec(`truncate client, refresh_token, revoked_token, product, product_event, quote, saved_search, saved_search_digest, job restart identity`)
+	require.NoError(t, err)
+
+	return database.Database{
+		Conn:         conn,
+		Repositories: newRepositories(conn.db, 0),
+	}
+}
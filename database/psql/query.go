@@ -0,0 +1,50 @@
+package psql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coffemanfp/docucentertest/search"
+)
+
+// queryConditions returns the conditions of the products matching every term of the full-text query of the search,
+// adding their values to ph. A term matches the words of the search_vector column by prefix,
+// or a part of the guide number or vehicle plate through their trigram indexes.
+func queryConditions(srch search.Search, ph *placeholders) (conds []string) {
+	for _, term := range srch.Terms() {
+		contains := ph.add("%" + likeEscaper.Replace(term) + "%")
+		cond := fmt.Sprintf("guide_number ilike %s or vehicle_plate ilike %s", contains, contains)
+		if words := search.TermWords(term); len(words) > 0 {
+			cond += fmt.Sprintf(" or search_vector @@ to_tsquery('simple', %s)", ph.add(prefixQuery(words, " & ")))
+		}
+		conds = append(conds, "("+cond+")")
+	}
+	return
+}
+
+// rankOrder returns the order of the products by descending relevance for the full-text query of the search,
+// breaking ties by ascending ID. The relevance adds the rank of the words of the query in the search_vector column
+// to the similarity of the query with the guide number or vehicle plate.
+func rankOrder(srch search.Search, ph *placeholders) string {
+	query := ph.add(srch.Query)
+	rank := fmt.Sprintf("greatest(similarity(coalesce(guide_number, ''), %s), similarity(coalesce(vehicle_plate, ''), %s))", query, query)
+
+	words := make([]string, 0)
+	for _, term := range srch.Terms() {
+		words = append(words, search.TermWords(term)...)
+	}
+	if len(words) > 0 {
+		rank = fmt.Sprintf("ts_rank(search_vector, to_tsquery('simple', %s)) + %s", ph.add(prefixQuery(words, " | ")), rank)
+	}
+	return fmt.Sprintf("%s desc, id asc", rank)
+}
+
+// prefixQuery returns the text search query matching the words by prefix, joined by the operator op.
+// The words of a full-text query only have letters and digits, so they have no special meaning in it.
+func prefixQuery(words []string, op string) string {
+	prefixes := make([]string, len(words))
+	for i, w := range words {
+		prefixes[i] = w + ":*"
+	}
+	return strings.Join(prefixes, op)
+}
@@ -0,0 +1,215 @@
+package psql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/quote"
+	"github.com/lib/pq"
+)
+
+// QuoteRepository represents a repository for managing the issued quotes in PostgreSQL.
+type QuoteRepository struct {
+	db      querier
+	timeout time.Duration // Maximum duration of each operation, unlimited if zero
+}
+
+// NewQuoteRepository creates a new QuoteRepository instance using a PostgreSQL connector.
+func NewQuoteRepository(conn *PostgreSQLConnector) (repo database.QuoteRepository, err error) {
+	// Establish a database connection using the provided connector.
+	db, err := conn.getConn()
+	if err != nil {
+		return
+	}
+	// Create and return a new QuoteRepository with the established connection.
+	repo = QuoteRepository{
+		db:      db,
+		timeout: conn.queryTimeout,
+	}
+	return
+}
+
+// Create inserts a new quote into the database and returns its ID.
+// The quoted product and the applied rules are stored as JSON documents.
+func (qr QuoteRepository) Create(ctx context.Context, q quote.Quote) (id int, err error) {
+	ctx, cancel := withTimeout(ctx, qr.timeout)
+	defer cancel()
+
+	table := "quote"
+	// Encode the quoted product and the applied rules.
+	p, err := json.Marshal(q.Product)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+		return
+	}
+	lines, err := json.Marshal(q.Lines)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+		return
+	}
+
+	// Define the SQL query for inserting a new quote.
+	query := fmt.Sprintf(`
+		insert into
+			%s(client_id, product, shipping_price, discount, surcharge, taxes, total, lines, expires_at, created_at)
+		values
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		returning
+			id
+	`, table)
+
+	// Execute the query and scan the result into the 'id' variable.
+	err = qr.db.QueryRowContext(ctx, query, q.ClientID, p, q.ShippingPrice, q.Discount, q.Surcharge, q.Taxes, q.Total, lines, inUTC(q.ExpiresAt), inUTC(q.CreatedAt)).Scan(&id)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+	}
+	return
+}
+
+// GetOne retrieves a single quote by its ID and clientID from the database.
+func (qr QuoteRepository) GetOne(ctx context.Context, id, clientID int) (q quote.Quote, err error) {
+	ctx, cancel := withTimeout(ctx, qr.timeout)
+	defer cancel()
+
+	table := "quote"
+	// Define the SQL query for retrieving a quote by ID and clientID.
+	query := fmt.Sprintf(`
+		select
+			id, client_id, product, shipping_price, discount, surcharge, taxes, total, lines, expires_at, used_at, created_at
+		from
+			%s
+		where
+			id = $1 and ($2 = 0 or client_id = $2)
+	`, table)
+
+	// Execute the query and scan the result into the 'q' variable, decoding the JSON documents.
+	var p, lines []byte
+	err = qr.db.QueryRowContext(ctx, query, id, clientID).Scan(&q.ID, &q.ClientID, &p, &q.ShippingPrice, &q.Discount, &q.Surcharge,
+		&q.Taxes, &q.Total, &lines, &q.ExpiresAt, &q.UsedAt, &q.CreatedAt)
+	if err != nil {
+		q = quote.Quote{}
+		err = errorInRow(table, "get", err)
+		return
+	}
+	err = decodeQuote(&q, p, lines)
+	if err != nil {
+		q = quote.Quote{}
+		err = errorInRow(table, "scan", err)
+	}
+	return
+}
+
+// GetPricings retrieves the prices locked by the quotes with the given IDs, by quote ID.
+func (qr QuoteRepository) GetPricings(ctx context.Context, ids []int) (pricings map[int]product.Pricing, err error) {
+	ctx, cancel := withTimeout(ctx, qr.timeout)
+	defer cancel()
+
+	table := "quote"
+	// Define the SQL query for retrieving the prices of several quotes.
+	query := fmt.Sprintf(`
+		select
+			id, shipping_price, discount, surcharge, lines
+		from
+			%s
+		where
+			id = any($1)
+	`, table)
+
+	// Execute the query and retrieve rows from the database.
+	rows, err := qr.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	// Release the rows when done, so the connection can be reused.
+	defer rows.Close()
+
+	// Initialize a map to store the retrieved prices.
+	pricings = make(map[int]product.Pricing)
+	for rows.Next() {
+		var q quote.Quote
+		var lines []byte
+		// Scan the row's columns into the 'q' variable.
+		err = rows.Scan(&q.ID, &q.ShippingPrice, &q.Discount, &q.Surcharge, &lines)
+		if err == nil {
+			err = decodeQuote(&q, nil, lines)
+		}
+		if err != nil {
+			err = errorInRow(table, "scan", err)
+			pricings = nil
+			return
+		}
+
+		// Add the locked price of the quote to the 'pricings' map.
+		pricings[q.ID] = q.Pricing()
+	}
+	// Check for any error that occurred during iteration.
+	err = rows.Err()
+	if err != nil {
+		pricings = nil
+		err = errorInRows(table, "scanning", err)
+	}
+	return
+}
+
+// Use marks a quote as used by a product.
+// The update only matches an unused and unexpired quote, so a quote can only be used once.
+func (qr QuoteRepository) Use(ctx context.Context, id, clientID int) (err error) {
+	ctx, cancel := withTimeout(ctx, qr.timeout)
+	defer cancel()
+
+	table := "quote"
+	// Define the SQL query for marking a quote as used.
+	query := fmt.Sprintf(`
+		update
+			%s
+		set
+			used_at = $3
+		where
+			id = $1 and ($2 = 0 or client_id = $2) and used_at is null and expires_at > $3
+	`, table)
+
+	// Execute the update query and check if the quote could be used.
+	// The current time is bound in UTC, like the stored expiration, instead of the time zone of the session.
+	res, err := qr.db.ExecContext(ctx, query, id, clientID, time.Now().UTC())
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	if n == 0 {
+		err = errors.NewError(errors.CONFLICT, fmt.Sprintf("failed to update a row in %s table", table),
+			fmt.Sprintf("quote %d was already used or is expired", id))
+	}
+	return
+}
+
+// decodeQuote decodes the JSON documents of a quote and fills the names of the applied rules.
+// A nil product document is skipped.
+func decodeQuote(q *quote.Quote, p, lines []byte) (err error) {
+	if p != nil {
+		err = json.Unmarshal(p, &q.Product)
+		if err != nil {
+			return
+		}
+	}
+	err = json.Unmarshal(lines, &q.Lines)
+	if err != nil {
+		return
+	}
+
+	q.Rules = make([]string, 0, len(q.Lines))
+	for _, l := range q.Lines {
+		q.Rules = append(q.Rules, l.Rule)
+	}
+	return
+}
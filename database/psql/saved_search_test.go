@@ -0,0 +1,11 @@
+package psql
+
+import (
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/database/databasetest"
+)
+
+func TestSavedSearchRepository(t *testing.T) {
+	databasetest.TestSavedSearchRepository(t, newTestDatabase)
+}
@@ -0,0 +1,128 @@
+package psql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/tracking"
+)
+
+// TrackingRepository represents a repository for managing the tracking events of the products in PostgreSQL.
+type TrackingRepository struct {
+	db      querier
+	timeout time.Duration // Maximum duration of each operation, unlimited if zero
+}
+
+// NewTrackingRepository creates a new TrackingRepository instance using a PostgreSQL connector.
+func NewTrackingRepository(conn *PostgreSQLConnector) (repo database.TrackingRepository, err error) {
+	// Establish a database connection using the provided connector.
+	db, err := conn.getConn()
+	if err != nil {
+		return
+	}
+	// Create and return a new TrackingRepository with the established connection.
+	repo = TrackingRepository{
+		db:      db,
+		timeout: conn.queryTimeout,
+	}
+	return
+}
+
+// Get retrieves the tracking events of a product, ordered by the time they were recorded.
+func (tr TrackingRepository) Get(ctx context.Context, productID, clientID int) (es []*tracking.Event, err error) {
+	ctx, cancel := withTimeout(ctx, tr.timeout)
+	defer cancel()
+
+	// Check if the user has ownership of the product before reading its events.
+	err = checkProductOwner(ctx, tr.db, productID, clientID)
+	if err != nil {
+		return
+	}
+
+	table := "product_event"
+	// Define the SQL query for retrieving the events of a product.
+	query := fmt.Sprintf(`
+		select
+			id, product_id, recorded_at, port, vault, vehicle_plate, note, recorded_by
+		from
+			%s
+		where
+			product_id = $1
+		order by
+			recorded_at, id
+	`, table)
+
+	// Execute the query and retrieve rows from the database.
+	rows, err := tr.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	// Release the rows when done, so the connection can be reused.
+	defer rows.Close()
+
+	// Initialize a slice to store the retrieved events.
+	es = make([]*tracking.Event, 0)
+	for rows.Next() {
+		e := new(tracking.Event)
+		// Scan the row's columns into the 'e' variable.
+		err = rows.Scan(&e.ID, &e.ProductID, &e.RecordedAt, &e.Port, &e.Vault, &e.VehiclePlate, &e.Note, &e.RecordedBy)
+		if err != nil {
+			err = errorInRow(table, "scan", err)
+			es = nil
+			return
+		}
+
+		// Append the scanned event to the 'es' slice.
+		es = append(es, e)
+	}
+	// Check for any error that occurred during iteration.
+	err = rows.Err()
+	if err != nil {
+		es = nil
+		err = errorInRows(table, "scanning", err)
+	}
+	return
+}
+
+// Create inserts a new tracking event for a product and returns its ID.
+// The ownership check and the insert run in a single transaction.
+func (tr TrackingRepository) Create(ctx context.Context, e tracking.Event, clientID int) (id int, err error) {
+	ctx, cancel := withTimeout(ctx, tr.timeout)
+	defer cancel()
+
+	err = inTx(ctx, tr.db, func(tx querier) (err error) {
+		id, err = TrackingRepository{db: tx}.create(ctx, e, clientID)
+		return
+	})
+	return
+}
+
+// create checks the ownership of a product and inserts a new tracking event for it.
+func (tr TrackingRepository) create(ctx context.Context, e tracking.Event, clientID int) (id int, err error) {
+	// Check if the user has ownership of the product before recording an event.
+	err = checkProductOwner(ctx, tr.db, e.ProductID, clientID)
+	if err != nil {
+		return
+	}
+
+	table := "product_event"
+	// Define the SQL query for inserting a new event.
+	query := fmt.Sprintf(`
+		insert into
+			%s(product_id, recorded_at, port, vault, vehicle_plate, note, recorded_by)
+		values
+			($1, $2, $3, $4, $5, $6, $7)
+		returning
+			id
+	`, table)
+
+	// Execute the query and scan the result into the 'id' variable.
+	err = tr.db.QueryRowContext(ctx, query, e.ProductID, e.RecordedAt, e.Port, e.Vault, e.VehiclePlate, e.Note, e.RecordedBy).Scan(&id)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+	}
+	return
+}
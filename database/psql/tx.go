@@ -0,0 +1,91 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+)
+
+// querier is the subset of methods shared by *sql.DB and *sql.Tx used by the repositories,
+// so the same repository can run either on the connection pool or inside a transaction.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// WithTx runs fn with repositories sharing a single transaction.
+// The transaction is committed if fn succeeds and rolled back if it returns an error or panics.
+func (p *PostgreSQLConnector) WithTx(ctx context.Context, fn func(tx database.Repositories) error) (err error) {
+	db, err := p.getConn()
+	if err != nil {
+		return
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("failed to begin transaction: %s", err)
+		return
+	}
+	return runTx(tx, func() error {
+		return fn(newRepositories(tx, p.queryTimeout))
+	})
+}
+
+// inTx runs fn in a transaction of q, bound to ctx.
+// If q is already a transaction, fn joins it and the caller is responsible for committing it.
+func inTx(ctx context.Context, q querier, fn func(tx querier) error) (err error) {
+	db, ok := q.(*sql.DB)
+	if !ok {
+		return fn(q)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("failed to begin transaction: %s", err)
+		return
+	}
+	return runTx(tx, func() error {
+		return fn(tx)
+	})
+}
+
+// runTx runs fn and commits tx if it succeeds, or rolls tx back if it returns an error or panics.
+func runTx(tx *sql.Tx, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	err = fn()
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		err = fmt.Errorf("failed to commit transaction: %s", err)
+	}
+	return
+}
+
+// newRepositories creates every PostgreSQL repository on top of the given querier,
+// limiting each of their operations to the given timeout.
+func newRepositories(q querier, timeout time.Duration) database.Repositories {
+	return database.Repositories{
+		database.AUTH_REPOSITORY:         AuthRepository{db: q, timeout: timeout},
+		database.CLIENT_REPOSITORY:       ClientRepository{db: q, timeout: timeout},
+		database.PRODUCT_REPOSITORY:      ProductRepository{db: q, timeout: timeout},
+		database.TRACKING_REPOSITORY:     TrackingRepository{db: q, timeout: timeout},
+		database.PRICING_REPOSITORY:      PricingRepository{db: q, timeout: timeout},
+		database.QUOTE_REPOSITORY:        QuoteRepository{db: q, timeout: timeout},
+		database.SAVED_SEARCH_REPOSITORY: SavedSearchRepository{db: q, timeout: timeout},
+		database.JOB_REPOSITORY:          JobRepository{db: q, timeout: timeout},
+	}
+}
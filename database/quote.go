@@ -0,0 +1,28 @@
+package database
+
+import (
+	"context"
+
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/quote"
+)
+
+// QUOTE_REPOSITORY is the key to be used when creating the repositories hashmap.
+const QUOTE_REPOSITORY RepositoryID = "QUOTE_REPOSITORY"
+
+// QuoteRepository defines the methods for working with the issued quotes in the database.
+// Every clientID parameter can be ANY_CLIENT to not restrict the operation to a single client.
+type QuoteRepository interface {
+	// Create inserts a new quote into the database and returns its ID.
+	Create(ctx context.Context, quote quote.Quote) (id int, err error)
+
+	// GetOne retrieves a specific quote based on the provided ID and client ID.
+	GetOne(ctx context.Context, id, clientID int) (quote quote.Quote, err error)
+
+	// GetPricings retrieves the prices locked by the quotes with the given IDs, by quote ID.
+	GetPricings(ctx context.Context, ids []int) (pricings map[int]product.Pricing, err error)
+
+	// Use marks a quote as used by a product.
+	// It returns a CONFLICT error if the quote was already used or is expired.
+	Use(ctx context.Context, id, clientID int) (err error)
+}
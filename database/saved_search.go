@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/coffemanfp/docucentertest/search"
+)
+
+// SAVED_SEARCH_REPOSITORY is the key to be used when creating the repositories hashmap.
+const SAVED_SEARCH_REPOSITORY RepositoryID = "SAVED_SEARCH_REPOSITORY"
+
+// SavedSearchRepository defines the methods for working with the saved searches and their digests in the database.
+// Every clientID parameter can be ANY_CLIENT to not restrict the operation to a single client.
+type SavedSearchRepository interface {
+	// Create inserts a new saved search into the database and returns its ID.
+	// Its last product ID is the greatest ID of the existing products, so its first run only reports the newer ones.
+	Create(ctx context.Context, savedSearch savedsearch.SavedSearch) (id int, err error)
+
+	// GetOne retrieves a specific saved search based on the provided ID and client ID.
+	GetOne(ctx context.Context, id, clientID int) (savedSearch savedsearch.SavedSearch, err error)
+
+	// Get retrieves a page of saved searches based on the given pagination and client ID, ordered by ID.
+	// It also returns the total number of saved searches of the client, regardless of the pagination.
+	Get(ctx context.Context, pagination search.Pagination, clientID int) (savedSearches []savedsearch.SavedSearch, total int, err error)
+
+	// Update replaces the scope, name, criteria, schedule, webhook URL and next run of a saved search of its client.
+	Update(ctx context.Context, savedSearch savedsearch.SavedSearch) (err error)
+
+	// Delete removes a saved search and its digests based on the provided ID and client ID.
+	Delete(ctx context.Context, id, clientID int) (err error)
+
+	// GetDue retrieves up to limit saved searches whose next run is due at the given time, the most overdue first.
+	GetDue(ctx context.Context, now time.Time, limit int) (savedSearches []savedsearch.SavedSearch, err error)
+
+	// SaveRun stores the digest of a run and the state of the saved search after it, see savedsearch.SavedSearch.Ran.
+	// It returns a CONFLICT error if another run of the saved search was saved since it was retrieved.
+	SaveRun(ctx context.Context, savedSearch savedsearch.SavedSearch, digest savedsearch.Digest) (id int, err error)
+
+	// GetDigests retrieves a page of the digests of a saved search based on the given pagination and client ID,
+	// the latest first. It also returns the total number of digests of the saved search, regardless of the pagination.
+	GetDigests(ctx context.Context, savedSearchID int, pagination search.Pagination, clientID int) (digests []savedsearch.Digest, total int, err error)
+}
@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/client"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/search"
+)
+
+// ClientRepository is a struct representing a repository for client-related database operations.
+type ClientRepository struct {
+	db      querier
+	timeout time.Duration // Maximum duration of each operation, unlimited if zero
+}
+
+// NewClientRepository creates a new ClientRepository instance.
+func NewClientRepository(conn *SQLiteConnector) (repo database.ClientRepository, err error) {
+	// Get a database connection from the SQLiteConnector.
+	db, err := conn.getConn()
+	if err != nil {
+		return
+	}
+	// Initialize and return the ClientRepository.
+	repo = ClientRepository{
+		db:      db,
+		timeout: conn.queryTimeout,
+	}
+	return
+}
+
+// GetOne retrieves a single client from the database based on the provided ID.
+func (cr ClientRepository) GetOne(ctx context.Context, id int) (c client.Client, err error) {
+	ctx, cancel := withTimeout(ctx, cr.timeout)
+	defer cancel()
+
+	table := "client"
+	// SQL query to select client details based on ID.
+	query := fmt.Sprintf(`
+		select
+			id, name, surname, created_at, username, role
+		from
+			%s
+		where
+			id = ?1
+	`, table)
+
+	// Query the database for the client details based on the provided ID.
+	err = cr.db.QueryRowContext(ctx, query, id).Scan(&c.ID, &c.Name, &c.Surname, &c.CreatedAt, &c.Auth.Username, &c.Role)
+	if err != nil {
+		// In case of an error, create an empty client and generate a detailed error message.
+		c = client.Client{}
+		err = errorInRow(table, "get", err)
+	}
+	return
+}
+
+// Get retrieves a page of clients from the database based on the provided pagination, in the order of its sort.
+// It also returns the total number of clients.
+func (cr ClientRepository) Get(ctx context.Context, pagination search.Pagination) (cs []*client.Client, total int, err error) {
+	ctx, cancel := withTimeout(ctx, cr.timeout)
+	defer cancel()
+
+	table := "client"
+	// Count every client, regardless of the pagination.
+	total, err = count(ctx, cr.db, table, "true")
+	if err != nil {
+		return
+	}
+
+	// SQL query to select a list of client details with pagination.
+	query := fmt.Sprintf(`
+		select
+			id, name, surname, created_at, username, role
+		from
+			%s
+		order by
+			%s
+		limit
+			coalesce(nullif(?1, 0), -1)
+		offset
+			?2
+	`, table, orderBy(pagination.Sort))
+
+	// Query the database for a list of clients with pagination.
+	rows, err := cr.db.QueryContext(ctx, query, pagination.Limit, pagination.Offset)
+	if err != nil {
+		// In case of an error, generate a detailed error message.
+		err = errorInRow(table, "get", err)
+		return
+	}
+	// Release the rows when done, so the connection can be reused.
+	defer rows.Close()
+
+	cs = make([]*client.Client, 0)
+	for rows.Next() {
+		c := new(client.Client)
+		// Scan the row's data into the client structure.
+		err = rows.Scan(&c.ID, &c.Name, &c.Surname, &c.CreatedAt, &c.Auth.Username, &c.Role)
+		if err != nil {
+			// In case of an error during scanning, set the list to nil and return the error.
+			err = errorInRow(table, "scan", err)
+			cs = nil
+			return
+		}
+
+		// Append the scanned client to the list.
+		cs = append(cs, c)
+	}
+	err = rows.Err()
+	if err != nil {
+		// In case of an error during rows iteration, set the list to nil and return the error.
+		cs = nil
+		err = errorInRows(table, "scanning", err)
+	}
+	return
+}
+
+// UpdateRole updates the role of the client with the provided ID.
+func (cr ClientRepository) UpdateRole(ctx context.Context, id int, role auth.Role) (err error) {
+	ctx, cancel := withTimeout(ctx, cr.timeout)
+	defer cancel()
+
+	table := "client"
+	// SQL query to update the role of a client based on ID.
+	query := fmt.Sprintf(`
+		update
+			%s
+		set
+			role = ?2
+		where
+			id = ?1
+	`, table)
+
+	// Execute the update query and check if the client exists.
+	res, err := cr.db.ExecContext(ctx, query, id, role)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	if n == 0 {
+		err = errorInRow(table, "update", sql.ErrNoRows)
+	}
+	return
+}
@@ -0,0 +1,206 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/search"
+)
+
+// count returns the number of rows of table matching the where condition, with args as its placeholder values.
+func count(ctx context.Context, db querier, table, where string, args ...interface{}) (n int, err error) {
+	query := fmt.Sprintf(`
+		select
+			count(*)
+		from
+			%s
+		where
+			%s
+	`, table, where)
+
+	err = db.QueryRowContext(ctx, query, args...).Scan(&n)
+	if err != nil {
+		err = errorInRow(table, "count", err)
+	}
+	return
+}
+
+// placeholders collects the values of the placeholders of a query being built.
+type placeholders []interface{}
+
+// add appends v to the values of the placeholders and returns its placeholder.
+func (ph *placeholders) add(v interface{}) string {
+	*ph = append(*ph, v)
+	return fmt.Sprintf("?%d", len(*ph))
+}
+
+// orderBy returns the order of the rows sorted by s, breaking ties by ascending ID.
+// Missing values are sorted after every other value, in both directions.
+func orderBy(s search.Sort) string {
+	keys := make([]string, 0, len(s)+1)
+	for _, f := range s {
+		col := sortColumn(f)
+		dir := "asc"
+		if f.Desc {
+			dir = "desc"
+		}
+		keys = append(keys, fmt.Sprintf("%s %s nulls last", col, dir))
+	}
+	keys = append(keys, "id asc")
+	return strings.Join(keys, ", ")
+}
+
+// afterCursor returns the condition of the rows following the cursor of the pagination in the order of its sort.
+// The values of the cursor are added to ph. Every row follows a pagination without a cursor.
+func afterCursor(p search.Pagination, ph *placeholders) string {
+	if p.After == nil {
+		return "true"
+	}
+
+	// A row follows the cursor if it is equal on the first fields and follows it on the next one, for any number of fields.
+	equal := make([]string, 0, len(p.Sort))
+	following := make([]string, 0, len(p.Sort)+1)
+	for i, f := range p.Sort {
+		col := sortColumn(f)
+		v := p.After.Values[i]
+		if v == nil {
+			// No value follows a missing one, which are sorted last.
+			equal = append(equal, col+" is null")
+			continue
+		}
+		value := ph.add(v)
+		if f.Time {
+			value = "julianday(" + value + ")"
+		}
+		op := ">"
+		if f.Desc {
+			op = "<"
+		}
+		follows := fmt.Sprintf("(%s is null or %s %s %s)", col, col, op, value)
+		following = append(following, "("+strings.Join(append(equal[:len(equal):len(equal)], follows), " and ")+")")
+		equal = append(equal, fmt.Sprintf("%s = %s", col, value))
+	}
+	// Rows with the same values on every field follow the cursor after its ID.
+	follows := "id > " + ph.add(p.After.ID)
+	following = append(following, "("+strings.Join(append(equal, follows), " and ")+")")
+	return "(" + strings.Join(following, " or ") + ")"
+}
+
+// sortColumn returns the expression of the column of the sort field.
+// Timestamps are stored as text, so they are compared as Julian days.
+func sortColumn(f search.SortField) string {
+	col := quoteIdentifier(f.Name)
+	if f.Time {
+		col = "julianday(" + col + ")"
+	}
+	return col
+}
+
+// quoteIdentifier quotes the name of a column to be written in a query.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// stringFilter returns the condition of the rows whose column meets the filter, adding its values to ph.
+// Prefixes are compared with substr, since the like operator ignores the case in SQLite.
+// An empty filter has no condition.
+func stringFilter(column string, f search.StringFilter, ph *placeholders) string {
+	if f.IsZero() {
+		return ""
+	}
+
+	col := quoteIdentifier(column)
+	alternatives := make([]string, 0, len(f.Prefixes)+2)
+	if len(f.Values) > 0 {
+		values := make([]string, len(f.Values))
+		for i, v := range f.Values {
+			values[i] = ph.add(v)
+		}
+		alternatives = append(alternatives, fmt.Sprintf("%s in (%s)", col, strings.Join(values, ", ")))
+	}
+	for _, prefix := range f.Prefixes {
+		value := ph.add(prefix)
+		alternatives = append(alternatives, fmt.Sprintf("substr(%s, 1, length(%s)) = %s", col, value, value))
+	}
+	if f.Missing {
+		alternatives = append(alternatives, col+" is null")
+	}
+	return filterCondition(alternatives, f.Not)
+}
+
+// intFilter returns the condition of the rows whose column meets the filter, adding its values to ph.
+// An empty filter has no condition.
+func intFilter(column string, f search.IntFilter, ph *placeholders) string {
+	if f.IsZero() {
+		return ""
+	}
+
+	col := quoteIdentifier(column)
+	alternatives := make([]string, 0, 2)
+	if len(f.Values) > 0 {
+		values := make([]string, len(f.Values))
+		for i, v := range f.Values {
+			values[i] = ph.add(v)
+		}
+		alternatives = append(alternatives, fmt.Sprintf("%s in (%s)", col, strings.Join(values, ", ")))
+	}
+	if f.Missing {
+		alternatives = append(alternatives, col+" is null")
+	}
+	return filterCondition(alternatives, f.Not)
+}
+
+// filterCondition returns the condition of the rows meeting any of the alternatives, or none of them if not is set.
+// The comparisons of a missing value are unknown, so a negated filter is met by the rows missing the column.
+func filterCondition(alternatives []string, not bool) string {
+	cond := "(" + strings.Join(alternatives, " or ") + ")"
+	if not {
+		cond = "not coalesce(" + cond + ", false)"
+	}
+	return cond
+}
+
+// inRange returns the conditions of the rows whose column is between start and end, adding the bounds to ph.
+// A zero bound leaves the range open on its side. Timestamps are stored as text, so they are compared as Julian days.
+func inRange[T comparable](column string, start, end T, ph *placeholders) (conds []string) {
+	var zero T
+	col := quoteIdentifier(column)
+	bound := func(v T) string {
+		return ph.add(v)
+	}
+	if _, ok := interface{}(zero).(time.Time); ok {
+		col = "julianday(" + col + ")"
+		bound = func(v T) string {
+			return "julianday(" + ph.add(v) + ")"
+		}
+	}
+
+	if start != zero {
+		conds = append(conds, fmt.Sprintf("%s >= %s", col, bound(start)))
+	}
+	if end != zero {
+		conds = append(conds, fmt.Sprintf("%s <= %s", col, bound(end)))
+	}
+	return
+}
+
+// inTimeRange returns the conditions of a timestamp column within a time range,
+// comparing it with the end of the range as an excluded bound if it is.
+func inTimeRange(column string, r search.RangeTime, ph *placeholders) (conds []string) {
+	if !r.EndExcluded || r.End.IsZero() {
+		return inRange(column, r.Start, r.End, ph)
+	}
+	conds = inRange(column, r.Start, time.Time{}, ph)
+	return append(conds, fmt.Sprintf("julianday(%s) < julianday(%s)", quoteIdentifier(column), ph.add(r.End)))
+}
+
+// withTimeout returns a copy of ctx cancelled after the given timeout.
+// A zero or negative timeout leaves the operation unlimited, so only the cancellation of ctx applies.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
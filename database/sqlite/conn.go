@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite" // Import the SQLite driver package (underscore indicates import for its side effects).
+)
+
+// schema creates every table of the database if it does not exist yet.
+// SQLite databases are local files owned by a single server, so they are not versioned with the migrations.
+//
+//go:embed schema.sql
+var schema string
+
+// SQLiteConnector is a struct representing a SQLite database connector.
+type SQLiteConnector struct {
+	path         string        // Path of the database file, or ":memory:" for a private in-memory database
+	db           *sql.DB       // Database connection instance
+	queryTimeout time.Duration // Maximum duration of each repository operation, unlimited if zero
+}
+
+// Connect opens the SQLite database, creating its file and tables if they do not exist.
+func (s *SQLiteConnector) Connect() (err error) {
+	// Open a new database connection using the "sqlite" driver and connection URL.
+	db, err := sql.Open("sqlite", connURL(s.path))
+	if err != nil {
+		return
+	}
+	// SQLite allows a single writer, so a single connection avoids busy errors between the repositories.
+	// It also keeps a ":memory:" database alive, as every connection would open a different one.
+	db.SetMaxOpenConns(1)
+
+	// Create the tables missing in the database.
+	_, err = db.Exec(schema)
+	if err != nil {
+		db.Close()
+		err = fmt.Errorf("failed to create database schema: %s", err)
+		return
+	}
+	s.db = db
+	return
+}
+
+// getConn returns the existing database connection or establishes a new one if not available.
+func (s *SQLiteConnector) getConn() (conn *sql.DB, err error) {
+	if s.db == nil {
+		err = s.Connect()
+		if err != nil {
+			return
+		}
+	}
+	conn = s.db
+	return
+}
+
+// NewSQLiteConnector creates a new SQLiteConnector instance for the database file at the given path.
+// Every operation of the repositories created with it is cancelled after queryTimeout, unless it is zero.
+func NewSQLiteConnector(path string, queryTimeout time.Duration) (conn *SQLiteConnector) {
+	return &SQLiteConnector{
+		path:         path,
+		queryTimeout: queryTimeout,
+	}
+}
+
+// connURL generates the connection URL for the SQLite database.
+// Times are written in a format the SQLite date functions understand, and foreign keys are enforced.
+func connURL(path string) string {
+	return fmt.Sprintf("file:%s?_time_format=sqlite&_pragma=foreign_keys(1)", path)
+}
@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"fmt"
+
+	"github.com/coffemanfp/docucentertest/database/errors"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// parseErrorType identifies the error type based on the error details.
+func parseErrorType(err error) (r string) {
+	// Check if the error is a sqlite.Error (SQLite-specific error).
+	var sqliteErr *sqlite.Error
+	if stdErrors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			r = errors.ALREADY_EXISTS // Set error type to ALREADY_EXISTS for unique violation.
+		case sqlite3.SQLITE_INTERRUPT:
+			r = errors.TIMEOUT // Set error type to TIMEOUT for statements interrupted by a context.
+		default:
+			r = errors.UNKNOWN // Set error type to UNKNOWN for other SQLite errors.
+		}
+	}
+	// Check if the error is sql.ErrNoRows (indicating no rows found).
+	if err == sql.ErrNoRows {
+		r = errors.NOT_FOUND // Set error type to NOT_FOUND for no rows found.
+	}
+	// Check if the context of the operation expired or was cancelled before the database answered.
+	if stdErrors.Is(err, context.DeadlineExceeded) || stdErrors.Is(err, context.Canceled) {
+		r = errors.TIMEOUT // Set error type to TIMEOUT for expired operations.
+	}
+	return
+}
+
+// errorInRow generates a formatted error message for a single row operation failure.
+func errorInRow(table, action string, err error) error {
+	return errors.NewError(
+		parseErrorType(err), // Get the appropriate error type based on the error.
+		fmt.Sprintf("failed to %s a row in %s table", action, table), // Construct error message.
+		err.Error(), // Include the original error content.
+	)
+}
+
+// errorInRows generates a formatted error message for multiple rows operation failure.
+func errorInRows(table, action string, err error) error {
+	return errors.NewError(
+		parseErrorType(err), // Get the appropriate error type based on the error.
+		fmt.Sprintf("failed to %s rows in %s table", action, table), // Construct error message.
+		err.Error(), // Include the original error content.
+	)
+}
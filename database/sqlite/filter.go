@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/search"
+)
+
+// comparisons maps the ordering operators of a filter tree to their SQL operators.
+var comparisons = map[search.Operator]string{
+	search.LT_OPERATOR:  "<",
+	search.LTE_OPERATOR: "<=",
+	search.GT_OPERATOR:  ">",
+	search.GTE_OPERATOR: ">=",
+}
+
+// compileFilter returns the condition of the rows meeting the filter tree, adding its values to ph.
+// The tree must be validated by search.NewFilter, which whitelists its fields so they are safe to be written in the query.
+// Timestamps are stored as text, so they are compared as Julian days.
+func compileFilter(f search.Filter, ph *placeholders) string {
+	switch {
+	case f.And != nil:
+		return compileFilters(f.And, " and ", ph)
+	case f.Or != nil:
+		return compileFilters(f.Or, " or ", ph)
+	case f.Not != nil:
+		return filterCondition([]string{compileFilter(*f.Not, ph)}, true)
+	}
+
+	col := quoteIdentifier(f.Field)
+	if f.Op == search.MISSING_OPERATOR {
+		if f.Value.(bool) {
+			return fmt.Sprintf("(%s is null)", col)
+		}
+		return fmt.Sprintf("(%s is not null)", col)
+	}
+	if f.Op == search.PREFIX_OPERATOR {
+		// The like operator ignores the case in SQLite.
+		value := ph.add(f.Value.(string))
+		return fmt.Sprintf("(substr(%s, 1, length(%s)) = %s)", col, value, value)
+	}
+
+	if isTime(f.Value) {
+		col = "julianday(" + col + ")"
+	}
+	switch f.Op {
+	case search.EQ_OPERATOR:
+		return fmt.Sprintf("(%s = %s)", col, addValue(f.Value, ph))
+	case search.NE_OPERATOR:
+		return filterCondition([]string{fmt.Sprintf("%s = %s", col, addValue(f.Value, ph))}, true)
+	case search.IN_OPERATOR:
+		return fmt.Sprintf("(%s in (%s))", col, addValues(f.Value.([]interface{}), ph))
+	case search.NIN_OPERATOR:
+		return filterCondition([]string{fmt.Sprintf("%s in (%s)", col, addValues(f.Value.([]interface{}), ph))}, true)
+	case search.LT_OPERATOR, search.LTE_OPERATOR, search.GT_OPERATOR, search.GTE_OPERATOR:
+		return fmt.Sprintf("(%s %s %s)", col, comparisons[f.Op], addValue(f.Value, ph))
+	}
+	return "false"
+}
+
+// compileFilters returns the conditions of the filter trees joined by the logical operator op.
+func compileFilters(fs []search.Filter, op string, ph *placeholders) string {
+	conds := make([]string, len(fs))
+	for i, f := range fs {
+		conds[i] = compileFilter(f, ph)
+	}
+	return "(" + strings.Join(conds, op) + ")"
+}
+
+// addValue adds v to ph and returns its placeholder, as a Julian day if v is a timestamp.
+func addValue(v interface{}, ph *placeholders) string {
+	if isTime(v) {
+		return "julianday(" + ph.add(v) + ")"
+	}
+	return ph.add(v)
+}
+
+// addValues adds every value to ph and returns their comma-separated placeholders.
+func addValues(values []interface{}, ph *placeholders) string {
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholders[i] = addValue(v, ph)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// isTime reports whether v is a timestamp, or a list of timestamps.
+func isTime(v interface{}) bool {
+	if values, ok := v.([]interface{}); ok && len(values) > 0 {
+		v = values[0]
+	}
+	_, ok := v.(time.Time)
+	return ok
+}
@@ -0,0 +1,239 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/coffemanfp/docucentertest/jobs"
+)
+
+// jobColumns are the columns of a job read by every query, without its parameters, input nor result.
+const jobColumns = "id, client_id, kind, status, progress, attempts, max_attempts, error, result_name, result_type, run_at, locked_until, created_at, started_at, finished_at"
+
+// JobRepository represents a repository for managing the queue of background jobs in SQLite.
+type JobRepository struct {
+	db      querier
+	timeout time.Duration // Maximum duration of each operation, unlimited if zero
+}
+
+// NewJobRepository creates a new JobRepository instance using a SQLite connector.
+func NewJobRepository(conn *SQLiteConnector) (repo database.JobRepository, err error) {
+	// Establish a database connection using the provided connector.
+	db, err := conn.getConn()
+	if err != nil {
+		return
+	}
+	// Create and return a new JobRepository with the established connection.
+	repo = JobRepository{
+		db:      db,
+		timeout: conn.queryTimeout,
+	}
+	return
+}
+
+// Create inserts a new job into the queue and returns its ID.
+func (jr JobRepository) Create(ctx context.Context, j jobs.Job) (id int, err error) {
+	ctx, cancel := withTimeout(ctx, jr.timeout)
+	defer cancel()
+
+	table := "job"
+	// Define the SQL query for inserting a new job.
+	query := fmt.Sprintf(`
+		insert into
+			%s(client_id, kind, status, params, input, max_attempts, run_at, created_at)
+		values
+			(?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)
+		returning
+			id
+	`, table)
+
+	// Execute the query and scan the result into the 'id' variable.
+	err = jr.db.QueryRowContext(ctx, query, j.ClientID, j.Kind, j.Status, string(j.Params), j.Input, j.MaxAttempts, j.RunAt, j.CreatedAt).Scan(&id)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+	}
+	return
+}
+
+// GetOne retrieves a single job by its ID and clientID from the database, without its parameters, input nor result.
+func (jr JobRepository) GetOne(ctx context.Context, id, clientID int) (j jobs.Job, err error) {
+	ctx, cancel := withTimeout(ctx, jr.timeout)
+	defer cancel()
+
+	table := "job"
+	// Define the SQL query for retrieving a job by ID and clientID.
+	query := fmt.Sprintf(`
+		select
+			%s
+		from
+			%s
+		where
+			id = ?1 and (?2 = 0 or client_id = ?2)
+	`, jobColumns, table)
+
+	// Execute the query and scan the result into the 'j' variable.
+	j, err = scanJob(jr.db.QueryRowContext(ctx, query, id, clientID))
+	if err != nil {
+		err = errorInRow(table, "get", err)
+	}
+	return
+}
+
+// GetResult retrieves the result of a succeeded job by its ID and clientID from the database.
+func (jr JobRepository) GetResult(ctx context.Context, id, clientID int) (r jobs.Result, err error) {
+	ctx, cancel := withTimeout(ctx, jr.timeout)
+	defer cancel()
+
+	table := "job"
+	// Define the SQL query for retrieving the result, a job without one is not found.
+	query := fmt.Sprintf(`
+		select
+			result_name, result_type, result
+		from
+			%s
+		where
+			id = ?1 and (?2 = 0 or client_id = ?2) and status = ?3 and result is not null
+	`, table)
+
+	err = jr.db.QueryRowContext(ctx, query, id, clientID, jobs.SUCCEEDED_STATUS).Scan(&r.Name, &r.ContentType, &r.Data)
+	if err != nil {
+		r = jobs.Result{}
+		err = errorInRow(table, "get", err)
+	}
+	return
+}
+
+// Claim locks the next job due at the given time for the duration of the lease, returning it with its parameters and input.
+// SQLite serializes the writes, so the selection and the update of the job are atomic, and every job is claimed by a single worker.
+func (jr JobRepository) Claim(ctx context.Context, now time.Time, lease time.Duration) (j jobs.Job, err error) {
+	ctx, cancel := withTimeout(ctx, jr.timeout)
+	defer cancel()
+
+	table := "job"
+	// Define the SQL query for claiming the earliest due job, comparing the timestamps as julian days.
+	query := fmt.Sprintf(`
+		update
+			%s
+		set
+			status = ?3,
+			attempts = attempts + 1,
+			progress = 0,
+			error = '',
+			started_at = ?1,
+			locked_until = ?2
+		where
+			id = (
+				select
+					id
+				from
+					%s
+				where
+					(status = ?4 and julianday(run_at) <= julianday(?1)) or (status = ?3 and julianday(locked_until) < julianday(?1))
+				order by
+					julianday(run_at), id
+				limit
+					1
+			)
+		returning
+			%s, params, input
+	`, table, table, jobColumns)
+
+	var params []byte
+	var input []byte
+	row := jr.db.QueryRowContext(ctx, query, now, now.Add(lease), jobs.RUNNING_STATUS, jobs.QUEUED_STATUS)
+	j, err = scanJob(row, &params, &input)
+	if err != nil {
+		err = errorInRow(table, "claim", err)
+		return
+	}
+	j.Params = params
+	j.Input = input
+	return
+}
+
+// Heartbeat stores the progress and the lease of the running attempt of a job.
+func (jr JobRepository) Heartbeat(ctx context.Context, j jobs.Job) (err error) {
+	ctx, cancel := withTimeout(ctx, jr.timeout)
+	defer cancel()
+
+	table := "job"
+	// Define the SQL query for updating the running attempt, only matching it while it was not claimed again.
+	query := fmt.Sprintf(`
+		update
+			%s
+		set
+			progress = ?4,
+			locked_until = ?5
+		where
+			id = ?1 and attempts = ?2 and status = ?3
+	`, table)
+
+	res, err := jr.db.ExecContext(ctx, query, j.ID, j.Attempts, jobs.RUNNING_STATUS, j.Progress, j.LockedUntil)
+	err = checkAttempt(table, j, res, err)
+	return
+}
+
+// Finish stores the outcome of the running attempt of a job, and its result if any.
+func (jr JobRepository) Finish(ctx context.Context, j jobs.Job, result *jobs.Result) (err error) {
+	ctx, cancel := withTimeout(ctx, jr.timeout)
+	defer cancel()
+
+	table := "job"
+	// Define the SQL query for updating the running attempt, only matching it while it was not claimed again.
+	query := fmt.Sprintf(`
+		update
+			%s
+		set
+			status = ?4,
+			progress = ?5,
+			error = ?6,
+			run_at = ?7,
+			locked_until = ?8,
+			finished_at = ?9,
+			result_name = ?10,
+			result_type = ?11,
+			result = ?12
+		where
+			id = ?1 and attempts = ?2 and status = ?3
+	`, table)
+
+	var data []byte
+	if result != nil {
+		data = result.Data
+	}
+	res, err := jr.db.ExecContext(ctx, query, j.ID, j.Attempts, jobs.RUNNING_STATUS, j.Status, j.Progress, j.Error, j.RunAt,
+		j.LockedUntil, j.FinishedAt, j.ResultName, j.ResultType, data)
+	err = checkAttempt(table, j, res, err)
+	return
+}
+
+// checkAttempt checks that an update of the running attempt of a job succeeded and affected it,
+// reporting a CONFLICT error otherwise.
+func checkAttempt(table string, j jobs.Job, res sql.Result, err error) error {
+	if err != nil {
+		return errorInRow(table, "update", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errorInRow(table, "update", err)
+	}
+	if n == 0 {
+		return errors.NewError(errors.CONFLICT, fmt.Sprintf("failed to update a row in %s table", table),
+			fmt.Sprintf("attempt %d of job %d is no longer running", j.Attempts, j.ID))
+	}
+	return nil
+}
+
+// scanJob scans a job from a row of the jobColumns, followed by the extra columns scanned into dest.
+func scanJob(row interface{ Scan(dest ...any) error }, dest ...any) (j jobs.Job, err error) {
+	err = row.Scan(append([]any{&j.ID, &j.ClientID, &j.Kind, &j.Status, &j.Progress, &j.Attempts, &j.MaxAttempts, &j.Error,
+		&j.ResultName, &j.ResultType, &j.RunAt, &j.LockedUntil, &j.CreatedAt, &j.StartedAt, &j.FinishedAt}, dest...)...)
+	if err != nil {
+		j = jobs.Job{}
+	}
+	return
+}
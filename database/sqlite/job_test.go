@@ -0,0 +1,11 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/database/databasetest"
+)
+
+func TestJobRepository(t *testing.T) {
+	databasetest.TestJobRepository(t, newTestDatabase)
+}
@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/product"
+)
+
+// PricingRepository represents a repository for reading the pricing rules stored in SQLite.
+type PricingRepository struct {
+	db      querier
+	timeout time.Duration // Maximum duration of each operation, unlimited if zero
+}
+
+// NewPricingRepository creates a new PricingRepository instance using a SQLite connector.
+func NewPricingRepository(conn *SQLiteConnector) (repo database.PricingRepository, err error) {
+	// Establish a database connection using the provided connector.
+	db, err := conn.getConn()
+	if err != nil {
+		return
+	}
+	// Create and return a new PricingRepository with the established connection.
+	repo = PricingRepository{
+		db:      db,
+		timeout: conn.queryTimeout,
+	}
+	return
+}
+
+// GetRules retrieves the active pricing rules, ordered by priority.
+func (pr PricingRepository) GetRules(ctx context.Context) (rules []product.PricingRule, err error) {
+	ctx, cancel := withTimeout(ctx, pr.timeout)
+	defer cancel()
+
+	table := "pricing_rule"
+	// Define the SQL query for retrieving the active rules in evaluation order.
+	query := fmt.Sprintf(`
+		select
+			id, name, kind, percentage, amount, min_quantity, max_quantity, location,
+			ports, vaults, types, client_ids, valid_from, valid_until, stop
+		from
+			%s
+		where
+			active = 1
+		order by
+			priority, id
+	`, table)
+
+	// Execute the query and retrieve rows from the database.
+	rows, err := pr.db.QueryContext(ctx, query)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	// Release the rows when done, so the connection can be reused.
+	defer rows.Close()
+
+	// Initialize a slice to store the retrieved rules.
+	rules = make([]product.PricingRule, 0)
+	for rows.Next() {
+		var r product.PricingRule
+		var ports, vaults, types, clientIDs sql.NullString
+		// Scan the row's columns into the 'r' variable, the JSON array columns are decoded below.
+		err = rows.Scan(&r.ID, &r.Name, &r.Kind, &r.Percentage, &r.Amount, &r.MinQuantity, &r.MaxQuantity, &r.Location,
+			&ports, &vaults, &types, &clientIDs, &r.ValidFrom, &r.ValidUntil, &r.Stop)
+		if err == nil {
+			err = decodeArrays(map[*sql.NullString]any{
+				&ports:     &r.Ports,
+				&vaults:    &r.Vaults,
+				&types:     &r.Types,
+				&clientIDs: &r.ClientIDs,
+			})
+		}
+		if err != nil {
+			err = errorInRow(table, "scan", err)
+			rules = nil
+			return
+		}
+
+		// Append the scanned rule to the 'rules' slice.
+		rules = append(rules, r)
+	}
+	// Check for any error that occurred during iteration.
+	err = rows.Err()
+	if err != nil {
+		rules = nil
+		err = errorInRows(table, "scanning", err)
+	}
+	return
+}
+
+// decodeArrays decodes the JSON arrays of a row into their destinations, skipping the null ones.
+// SQLite has no array type, so the array columns are stored as JSON.
+func decodeArrays(arrays map[*sql.NullString]any) (err error) {
+	for a, v := range arrays {
+		if !a.Valid {
+			continue
+		}
+		err = json.Unmarshal([]byte(a.String), v)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
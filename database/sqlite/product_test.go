@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/databasetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductRepository(t *testing.T) {
+	databasetest.TestProductRepository(t, newTestDatabase)
+}
+
+// newTestDatabase creates a database on a private in-memory SQLite database, closed when the test finishes.
+func newTestDatabase(t *testing.T) database.Database {
+	// Every connector opens its own private in-memory database.
+	conn := NewSQLiteConnector(":memory:", 0)
+	require.NoError(t, conn.Connect())
+	t.Cleanup(func() {
+		conn.db.Close()
+	})
+
+	return database.Database{
+		Conn:         conn,
+		Repositories: newRepositories(conn.db, 0),
+	}
+}
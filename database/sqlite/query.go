@@ -0,0 +1,49 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coffemanfp/docucentertest/search"
+)
+
+// searchDocument is the expression of the lowercase words of the type, guide number and vehicle plate of a product,
+// separated and surrounded by spaces, so the words can be matched with the like operator.
+const searchDocument = `(' ' || replace(replace(replace(replace(lower(coalesce(type, '') || ' ' || coalesce(guide_number, '') || ' ' || coalesce(vehicle_plate, '')), '-', ' '), '_', ' '), '/', ' '), '.', ' ') || ' ')`
+
+// queryConditions returns the conditions of the products matching every term of the full-text query of the search,
+// adding their values to ph. A term matches if every word of it starts a word of the search document,
+// or if it is part of the guide number or vehicle plate. The like operator ignores the case in SQLite.
+func queryConditions(srch search.Search, ph *placeholders) (conds []string) {
+	for _, term := range srch.Terms() {
+		contains := ph.add("%" + term + "%")
+		cond := fmt.Sprintf("guide_number like %s or vehicle_plate like %s", contains, contains)
+		if words := search.TermWords(term); len(words) > 0 {
+			prefixes := make([]string, len(words))
+			for i, w := range words {
+				prefixes[i] = fmt.Sprintf("%s like %s", searchDocument, ph.add("% "+w+"%"))
+			}
+			cond += " or (" + strings.Join(prefixes, " and ") + ")"
+		}
+		conds = append(conds, "("+cond+")")
+	}
+	return
+}
+
+// rankOrder returns the order of the products by descending relevance for the full-text query of the search,
+// breaking ties by ascending ID. Like search.Search.Rank, a term equal to the guide number or vehicle plate adds one,
+// and so does every word of a term equal to a word of the search document.
+func rankOrder(srch search.Search, ph *placeholders) string {
+	ranks := make([]string, 0)
+	for _, term := range srch.Terms() {
+		equal := ph.add(term)
+		ranks = append(ranks, fmt.Sprintf("(lower(coalesce(guide_number, '')) = %s or lower(coalesce(vehicle_plate, '')) = %s)", equal, equal))
+		for _, w := range search.TermWords(term) {
+			ranks = append(ranks, fmt.Sprintf("(%s like %s)", searchDocument, ph.add("% "+w+" %")))
+		}
+	}
+	if len(ranks) == 0 {
+		return "id asc"
+	}
+	return fmt.Sprintf("%s desc, id asc", strings.Join(ranks, " + "))
+}
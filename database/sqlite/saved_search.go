@@ -0,0 +1,434 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/coffemanfp/docucentertest/search"
+)
+
+// SavedSearchRepository represents a repository for managing the saved searches and their digests in SQLite.
+type SavedSearchRepository struct {
+	db      querier
+	timeout time.Duration // Maximum duration of each operation, unlimited if zero
+}
+
+// NewSavedSearchRepository creates a new SavedSearchRepository instance using a SQLite connector.
+func NewSavedSearchRepository(conn *SQLiteConnector) (repo database.SavedSearchRepository, err error) {
+	// Establish a database connection using the provided connector.
+	db, err := conn.getConn()
+	if err != nil {
+		return
+	}
+	// Create and return a new SavedSearchRepository with the established connection.
+	repo = SavedSearchRepository{
+		db:      db,
+		timeout: conn.queryTimeout,
+	}
+	return
+}
+
+// Create inserts a new saved search into the database and returns its ID.
+// The criteria are stored as a JSON document.
+func (sr SavedSearchRepository) Create(ctx context.Context, s savedsearch.SavedSearch) (id int, err error) {
+	ctx, cancel := withTimeout(ctx, sr.timeout)
+	defer cancel()
+
+	table := "saved_search"
+	// Encode the search criteria.
+	criteria, err := json.Marshal(s.Criteria)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+		return
+	}
+
+	// Define the SQL query for inserting a new saved search.
+	// Its runs only report the products created after it, so it starts from the greatest existing product ID.
+	query := fmt.Sprintf(`
+		insert into
+			%s(client_id, scope_id, name, criteria, schedule, webhook_url, next_run_at, created_at, last_product_id)
+		values
+			(?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, (select coalesce(max(id), 0) from product))
+		returning
+			id
+	`, table)
+
+	// Execute the query and scan the result into the 'id' variable.
+	err = sr.db.QueryRowContext(ctx, query, s.ClientID, s.ScopeID, s.Name, criteria, s.Schedule, s.WebhookURL, s.NextRunAt, s.CreatedAt).Scan(&id)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+	}
+	return
+}
+
+// GetOne retrieves a single saved search by its ID and clientID from the database.
+func (sr SavedSearchRepository) GetOne(ctx context.Context, id, clientID int) (s savedsearch.SavedSearch, err error) {
+	ctx, cancel := withTimeout(ctx, sr.timeout)
+	defer cancel()
+
+	table := "saved_search"
+	// Define the SQL query for retrieving a saved search by ID and clientID.
+	query := fmt.Sprintf(`
+		select
+			id, client_id, scope_id, name, criteria, schedule, webhook_url, next_run_at, last_run_at, runs, last_product_id, created_at
+		from
+			%s
+		where
+			id = ?1 and (?2 = 0 or client_id = ?2)
+	`, table)
+
+	// Execute the query and scan the result into the 's' variable.
+	s, err = scanSavedSearch(sr.db.QueryRowContext(ctx, query, id, clientID))
+	if err != nil {
+		err = errorInRow(table, "get", err)
+	}
+	return
+}
+
+// Get retrieves a page of saved searches for a given clientID from the database, ordered by ID.
+// It also returns the total number of saved searches of the client.
+func (sr SavedSearchRepository) Get(ctx context.Context, pagination search.Pagination, clientID int) (ss []savedsearch.SavedSearch, total int, err error) {
+	ctx, cancel := withTimeout(ctx, sr.timeout)
+	defer cancel()
+
+	table := "saved_search"
+	// Define the condition of the saved searches of the client, shared by the count and the page queries.
+	where := "(?1 = 0 or client_id = ?1)"
+
+	// Count every saved search of the client, regardless of the pagination.
+	total, err = count(ctx, sr.db, table, where, clientID)
+	if err != nil {
+		return
+	}
+
+	// Define the SQL query for retrieving the saved searches of the client, with pagination.
+	query := fmt.Sprintf(`
+		select
+			id, client_id, scope_id, name, criteria, schedule, webhook_url, next_run_at, last_run_at, runs, last_product_id, created_at
+		from
+			%s
+		where
+			%s
+		order by
+			id
+		limit
+			coalesce(nullif(?2, 0), -1)
+		offset
+			?3
+	`, table, where)
+
+	ss, err = sr.query(ctx, query, clientID, pagination.Limit, pagination.Offset)
+	return
+}
+
+// Update replaces the scope, name, criteria, schedule, webhook URL and next run of a saved search of its client.
+func (sr SavedSearchRepository) Update(ctx context.Context, s savedsearch.SavedSearch) (err error) {
+	ctx, cancel := withTimeout(ctx, sr.timeout)
+	defer cancel()
+
+	table := "saved_search"
+	// Encode the search criteria.
+	criteria, err := json.Marshal(s.Criteria)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+
+	// Define the SQL query for updating a saved search of the client.
+	query := fmt.Sprintf(`
+		update
+			%s
+		set
+			scope_id = ?3,
+			name = ?4,
+			criteria = ?5,
+			schedule = ?6,
+			webhook_url = ?7,
+			next_run_at = ?8
+		where
+			id = ?1 and (?2 = 0 or client_id = ?2)
+	`, table)
+
+	// Execute the update query, a saved search of another client is not found.
+	res, err := sr.db.ExecContext(ctx, query, s.ID, s.ClientID, s.ScopeID, s.Name, criteria, s.Schedule, s.WebhookURL, s.NextRunAt)
+	err = checkAffected(table, "update", res, err)
+	return
+}
+
+// Delete removes a saved search and its digests from the database.
+// Both removals run in a single transaction.
+func (sr SavedSearchRepository) Delete(ctx context.Context, id, clientID int) (err error) {
+	ctx, cancel := withTimeout(ctx, sr.timeout)
+	defer cancel()
+
+	return inTx(ctx, sr.db, func(tx querier) error {
+		return SavedSearchRepository{db: tx}.delete(ctx, id, clientID)
+	})
+}
+
+// delete removes a saved search of the client and its digests.
+func (sr SavedSearchRepository) delete(ctx context.Context, id, clientID int) (err error) {
+	table := "saved_search"
+	// Define the SQL query for deleting a saved search of the client.
+	query := fmt.Sprintf(`
+		delete from
+			%s
+		where
+			id = ?1 and (?2 = 0 or client_id = ?2)
+	`, table)
+
+	// Execute the delete query, a saved search of another client is not found.
+	res, err := sr.db.ExecContext(ctx, query, id, clientID)
+	err = checkAffected(table, "delete", res, err)
+	if err != nil {
+		return
+	}
+
+	table = "saved_search_digest"
+	// Define the SQL query for deleting the digests of the saved search.
+	query = fmt.Sprintf(`
+		delete from
+			%s
+		where
+			saved_search_id = ?1
+	`, table)
+
+	_, err = sr.db.ExecContext(ctx, query, id)
+	if err != nil {
+		err = errorInRows(table, "delete", err)
+	}
+	return
+}
+
+// GetDue retrieves up to limit saved searches whose next run is due at the given time, the most overdue first.
+func (sr SavedSearchRepository) GetDue(ctx context.Context, now time.Time, limit int) (ss []savedsearch.SavedSearch, err error) {
+	ctx, cancel := withTimeout(ctx, sr.timeout)
+	defer cancel()
+
+	table := "saved_search"
+	// Define the SQL query for retrieving the due saved searches, comparing the timestamps as julian days.
+	query := fmt.Sprintf(`
+		select
+			id, client_id, scope_id, name, criteria, schedule, webhook_url, next_run_at, last_run_at, runs, last_product_id, created_at
+		from
+			%s
+		where
+			next_run_at is not null and julianday(next_run_at) <= julianday(?1)
+		order by
+			julianday(next_run_at), id
+		limit
+			coalesce(nullif(?2, 0), -1)
+	`, table)
+
+	ss, err = sr.query(ctx, query, now, limit)
+	return
+}
+
+// SaveRun stores the digest of a run and the state of the saved search after it.
+// The saved search must still have the runs it had before the run, so a run can only be saved once.
+// The update and the insertion run in a single transaction.
+func (sr SavedSearchRepository) SaveRun(ctx context.Context, s savedsearch.SavedSearch, d savedsearch.Digest) (id int, err error) {
+	ctx, cancel := withTimeout(ctx, sr.timeout)
+	defer cancel()
+
+	err = inTx(ctx, sr.db, func(tx querier) (err error) {
+		id, err = SavedSearchRepository{db: tx}.saveRun(ctx, s, d)
+		return
+	})
+	return
+}
+
+// saveRun updates the state of the saved search if it was not run since, and inserts the digest of the run.
+func (sr SavedSearchRepository) saveRun(ctx context.Context, s savedsearch.SavedSearch, d savedsearch.Digest) (id int, err error) {
+	table := "saved_search"
+	// Define the SQL query for updating the saved search, only matching it while it has the runs before this one.
+	query := fmt.Sprintf(`
+		update
+			%s
+		set
+			runs = ?2,
+			last_run_at = ?3,
+			next_run_at = ?4,
+			last_product_id = ?5
+		where
+			id = ?1 and runs = ?2 - 1
+	`, table)
+
+	res, err := sr.db.ExecContext(ctx, query, s.ID, s.Runs, s.LastRunAt, s.NextRunAt, s.LastProductID)
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		err = errorInRow(table, "update", err)
+		return
+	}
+	if n == 0 {
+		err = errors.NewError(errors.CONFLICT, fmt.Sprintf("failed to update a row in %s table", table),
+			fmt.Sprintf("saved search %d was already run or deleted", s.ID))
+		return
+	}
+
+	table = "saved_search_digest"
+	// Define the SQL query for inserting the digest of the run.
+	query = fmt.Sprintf(`
+		insert into
+			%s(saved_search_id, client_id, run, product_ids, total, ran_at)
+		values
+			(?1, ?2, ?3, ?4, ?5, ?6)
+		returning
+			id
+	`, table)
+
+	// SQLite has no array type, so the product IDs are stored as a JSON array.
+	ids, err := json.Marshal(d.ProductIDs)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+		return
+	}
+
+	err = sr.db.QueryRowContext(ctx, query, d.SavedSearchID, d.ClientID, d.Run, string(ids), d.Total, d.RanAt).Scan(&id)
+	if err != nil {
+		err = errorInRow(table, "insert", err)
+	}
+	return
+}
+
+// GetDigests retrieves a page of the digests of a saved search for a given clientID from the database, the latest first.
+// It also returns the total number of digests of the saved search.
+func (sr SavedSearchRepository) GetDigests(ctx context.Context, savedSearchID int, pagination search.Pagination, clientID int) (ds []savedsearch.Digest, total int, err error) {
+	ctx, cancel := withTimeout(ctx, sr.timeout)
+	defer cancel()
+
+	table := "saved_search_digest"
+	// Define the condition of the digests of the saved search, shared by the count and the page queries.
+	where := "saved_search_id = ?1 and (?2 = 0 or client_id = ?2)"
+
+	// Count every digest of the saved search, regardless of the pagination.
+	total, err = count(ctx, sr.db, table, where, savedSearchID, clientID)
+	if err != nil {
+		return
+	}
+
+	// Define the SQL query for retrieving the digests of the saved search, with pagination.
+	query := fmt.Sprintf(`
+		select
+			id, saved_search_id, client_id, run, product_ids, total, ran_at
+		from
+			%s
+		where
+			%s
+		order by
+			run desc
+		limit
+			coalesce(nullif(?3, 0), -1)
+		offset
+			?4
+	`, table, where)
+
+	// Execute the query and retrieve rows from the database.
+	rows, err := sr.db.QueryContext(ctx, query, savedSearchID, clientID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	// Release the rows when done, so the connection can be reused.
+	defer rows.Close()
+
+	// Initialize a slice to store the retrieved digests.
+	ds = make([]savedsearch.Digest, 0)
+	for rows.Next() {
+		var d savedsearch.Digest
+		var ids []byte
+		// Scan the row's columns into the 'd' variable, decoding the JSON array of product IDs.
+		err = rows.Scan(&d.ID, &d.SavedSearchID, &d.ClientID, &d.Run, &ids, &d.Total, &d.RanAt)
+		if err == nil {
+			err = json.Unmarshal(ids, &d.ProductIDs)
+		}
+		if err != nil {
+			err = errorInRow(table, "scan", err)
+			ds = nil
+			return
+		}
+
+		// Append the scanned digest to the 'ds' slice.
+		ds = append(ds, d)
+	}
+	// Check for any error that occurred during iteration.
+	err = rows.Err()
+	if err != nil {
+		ds = nil
+		err = errorInRows(table, "scanning", err)
+	}
+	return
+}
+
+// query retrieves the saved searches selected by the query with the given arguments.
+func (sr SavedSearchRepository) query(ctx context.Context, query string, args ...interface{}) (ss []savedsearch.SavedSearch, err error) {
+	table := "saved_search"
+	// Execute the query and retrieve rows from the database.
+	rows, err := sr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		err = errorInRow(table, "get", err)
+		return
+	}
+	// Release the rows when done, so the connection can be reused.
+	defer rows.Close()
+
+	// Initialize a slice to store the retrieved saved searches.
+	ss = make([]savedsearch.SavedSearch, 0)
+	for rows.Next() {
+		var s savedsearch.SavedSearch
+		s, err = scanSavedSearch(rows)
+		if err != nil {
+			err = errorInRow(table, "scan", err)
+			ss = nil
+			return
+		}
+		ss = append(ss, s)
+	}
+	// Check for any error that occurred during iteration.
+	err = rows.Err()
+	if err != nil {
+		ss = nil
+		err = errorInRows(table, "scanning", err)
+	}
+	return
+}
+
+// scanSavedSearch scans a saved search from a row, decoding its criteria.
+func scanSavedSearch(row interface{ Scan(dest ...any) error }) (s savedsearch.SavedSearch, err error) {
+	var criteria []byte
+	err = row.Scan(&s.ID, &s.ClientID, &s.ScopeID, &s.Name, &criteria, &s.Schedule, &s.WebhookURL, &s.NextRunAt, &s.LastRunAt,
+		&s.Runs, &s.LastProductID, &s.CreatedAt)
+	if err == nil {
+		err = json.Unmarshal(criteria, &s.Criteria)
+	}
+	if err != nil {
+		s = savedsearch.SavedSearch{}
+	}
+	return
+}
+
+// checkAffected checks that an update or deletion of a single row succeeded and affected the row,
+// reporting it as not found otherwise.
+func checkAffected(table, action string, res sql.Result, err error) error {
+	if err != nil {
+		return errorInRow(table, action, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errorInRow(table, action, err)
+	}
+	if n == 0 {
+		return errorInRow(table, action, sql.ErrNoRows)
+	}
+	return nil
+}
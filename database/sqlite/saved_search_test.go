@@ -0,0 +1,11 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/database/databasetest"
+)
+
+func TestSavedSearchRepository(t *testing.T) {
+	databasetest.TestSavedSearchRepository(t, newTestDatabase)
+}
@@ -0,0 +1,159 @@
+CREATE TABLE IF NOT EXISTS client (
+    id integer primary key autoincrement,
+    name varchar,
+    surname varchar,
+    username varchar not null unique,
+    created_at timestamp,
+    password varchar,
+    role varchar not null default 'client'
+);
+
+CREATE TABLE IF NOT EXISTS product (
+    id integer primary key autoincrement,
+    client_id integer not null,
+    guide_number varchar not null unique,
+    type varchar not null,
+    joined_at timestamp not null,
+    delivered_at timestamp not null,
+    shipping_price real not null,
+    vehicle_plate varchar not null,
+    port integer,
+    vault integer,
+    quantity integer not null,
+    status varchar not null default 'REGISTERED',
+    quote_id integer
+);
+
+CREATE INDEX IF NOT EXISTS product_client_id_idx ON product (client_id);
+CREATE INDEX IF NOT EXISTS product_status_idx ON product (status);
+
+CREATE TABLE IF NOT EXISTS refresh_token (
+    id integer primary key autoincrement,
+    client_id integer not null,
+    family_id varchar not null,
+    token_hash varchar not null unique,
+    expires_at timestamp not null,
+    revoked_at timestamp,
+    created_at timestamp not null
+);
+
+CREATE INDEX IF NOT EXISTS refresh_token_family_id_idx ON refresh_token (family_id);
+
+CREATE TABLE IF NOT EXISTS revoked_token (
+    jti varchar not null primary key,
+    expires_at timestamp,
+    revoked_at timestamp not null
+);
+
+CREATE TABLE IF NOT EXISTS product_event (
+    id integer primary key autoincrement,
+    product_id integer not null,
+    recorded_at timestamp not null,
+    port integer,
+    vault integer,
+    vehicle_plate varchar,
+    note varchar not null default '',
+    recorded_by integer not null
+);
+
+CREATE INDEX IF NOT EXISTS product_event_product_id_idx ON product_event (product_id, recorded_at);
+
+-- The array columns hold JSON arrays.
+CREATE TABLE IF NOT EXISTS pricing_rule (
+    id integer primary key autoincrement,
+    name varchar not null unique,
+    kind varchar not null,
+    priority integer not null default 0,
+    percentage real not null default 0,
+    amount real not null default 0,
+    min_quantity integer,
+    max_quantity integer,
+    location varchar not null default '',
+    ports text,
+    vaults text,
+    types text,
+    client_ids text,
+    valid_from timestamp,
+    valid_until timestamp,
+    stop boolean not null default false,
+    active boolean not null default true
+);
+
+INSERT INTO pricing_rule (name, kind, priority, percentage, min_quantity, location, stop) VALUES
+    ('bulk_vault', 'discount', 10, 5, 10, 'vault', true),
+    ('bulk_port', 'discount', 20, 3, 10, 'port', true)
+ON CONFLICT (name) DO NOTHING;
+
+-- The product and lines columns hold JSON documents.
+CREATE TABLE IF NOT EXISTS quote (
+    id integer primary key autoincrement,
+    client_id integer not null,
+    product text not null,
+    shipping_price real not null,
+    discount real not null,
+    surcharge real not null,
+    taxes real not null,
+    total real not null,
+    lines text not null,
+    expires_at timestamp not null,
+    used_at timestamp,
+    created_at timestamp not null
+);
+
+-- The criteria column holds a JSON document.
+CREATE TABLE IF NOT EXISTS saved_search (
+    id integer primary key autoincrement,
+    client_id integer not null,
+    scope_id integer not null default 0,
+    name varchar not null,
+    criteria text not null,
+    schedule varchar not null default '',
+    webhook_url varchar not null default '',
+    next_run_at timestamp,
+    last_run_at timestamp,
+    runs integer not null default 0,
+    last_product_id integer not null default 0,
+    created_at timestamp not null
+);
+
+CREATE INDEX IF NOT EXISTS saved_search_client_id_idx ON saved_search (client_id);
+CREATE INDEX IF NOT EXISTS saved_search_next_run_at_idx ON saved_search (next_run_at) WHERE next_run_at IS NOT NULL;
+
+-- The product_ids column holds a JSON array.
+CREATE TABLE IF NOT EXISTS saved_search_digest (
+    id integer primary key autoincrement,
+    saved_search_id integer not null,
+    client_id integer not null,
+    run integer not null,
+    product_ids text not null,
+    total integer not null,
+    ran_at timestamp not null,
+
+    unique (saved_search_id, run)
+);
+
+-- The params column holds a JSON document, and the input and result columns hold files.
+CREATE TABLE IF NOT EXISTS job (
+    id integer primary key autoincrement,
+    client_id integer not null,
+    kind varchar not null,
+    status varchar not null default 'QUEUED',
+    params text not null,
+    input blob,
+    progress integer not null default 0,
+    attempts integer not null default 0,
+    max_attempts integer not null,
+    error varchar not null default '',
+    result_name varchar not null default '',
+    result_type varchar not null default '',
+    result blob,
+    run_at timestamp not null,
+    locked_until timestamp,
+    created_at timestamp not null,
+    started_at timestamp,
+    finished_at timestamp
+);
+
+CREATE INDEX IF NOT EXISTS job_client_id_idx ON job (client_id);
+CREATE INDEX IF NOT EXISTS job_queued_run_at_idx ON job (run_at) WHERE status = 'QUEUED';
+CREATE INDEX IF NOT EXISTS job_running_locked_until_idx ON job (locked_until) WHERE status = 'RUNNING';
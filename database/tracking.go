@@ -0,0 +1,21 @@
+package database
+
+import (
+	"context"
+
+	"github.com/coffemanfp/docucentertest/tracking"
+)
+
+// Constant TRACKING_REPOSITORY is used to uniquely identify the tracking repository.
+const TRACKING_REPOSITORY RepositoryID = "TRACKING_REPOSITORY"
+
+// TrackingRepository defines the methods for working with the tracking events of the products.
+// Every operation checks the ownership of the product, and every clientID parameter can be ANY_CLIENT
+// to not restrict the operation to a single client.
+type TrackingRepository interface {
+	// Get retrieves the tracking events of a product, ordered by the time they were recorded.
+	Get(ctx context.Context, productID, clientID int) (events []*tracking.Event, err error)
+
+	// Create inserts a new tracking event for a product and returns its ID.
+	Create(ctx context.Context, event tracking.Event, clientID int) (id int, err error)
+}
@@ -0,0 +1,191 @@
+package jobs
+
+import (
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"time"
+)
+
+// MAX_ATTEMPTS is the number of times a job is run before it is failed for good.
+const MAX_ATTEMPTS = 5
+
+// BASE_BACKOFF is the delay before the first retry of a failed job, doubled by every further retry.
+const BASE_BACKOFF = 30 * time.Second
+
+// MAX_BACKOFF is the maximum delay before a retry of a failed job.
+const MAX_BACKOFF = time.Hour
+
+// Kind is the operation run by a job.
+type Kind string
+
+// Kinds of the jobs.
+const (
+	IMPORT_PRODUCTS_KIND Kind = "IMPORT_PRODUCTS" // Creates the products of a spreadsheet, see handlers.ImportProducts.
+	EXPORT_PRODUCTS_KIND Kind = "EXPORT_PRODUCTS" // Writes the products matching a search to a file, see handlers.ExportProducts.
+)
+
+// Status is the state of a job in the queue.
+type Status string
+
+// Statuses of the jobs.
+const (
+	QUEUED_STATUS    Status = "QUEUED"    // The job waits for a worker, either for the first time or to be retried.
+	RUNNING_STATUS   Status = "RUNNING"   // A worker is running the job.
+	SUCCEEDED_STATUS Status = "SUCCEEDED" // The job ran successfully, its result can be downloaded if it has any.
+	FAILED_STATUS    Status = "FAILED"    // The job failed for good, either with a permanent error or after MAX_ATTEMPTS.
+)
+
+// Job represents a long-running operation of a client, run in the background by the workers of a Pool.
+type Job struct {
+	ID          int             `json:"id,omitempty"`          // Unique identifier for the job.
+	ClientID    int             `json:"client_id,omitempty"`   // Identifier of the client the job belongs to.
+	Kind        Kind            `json:"kind"`                  // Operation run by the job.
+	Status      Status          `json:"status"`                // State of the job in the queue.
+	Params      json.RawMessage `json:"-"`                     // Parameters of the operation as a JSON document, read by its Runner.
+	Input       []byte          `json:"-"`                     // File read by the operation, such as an imported spreadsheet, if any.
+	Progress    int             `json:"progress"`              // Percentage of the operation done by the running attempt.
+	Attempts    int             `json:"attempts"`              // Number of times the job was run, including the running one.
+	MaxAttempts int             `json:"max_attempts"`          // Number of times the job can be run before it is failed for good.
+	Error       string          `json:"error,omitempty"`       // Error of the last failed attempt, if any.
+	ResultName  string          `json:"result_name,omitempty"` // File name of the result, empty if the job has none.
+	ResultType  string          `json:"result_type,omitempty"` // MIME type of the result, empty if the job has none.
+	RunAt       time.Time       `json:"run_at"`                // Timestamp after which the job can be run, delayed by the retries.
+	LockedUntil *time.Time      `json:"-"`                     // Timestamp the lease of the running attempt expires at, nil if not running.
+	CreatedAt   time.Time       `json:"created_at"`            // Timestamp when the job was queued.
+	StartedAt   *time.Time      `json:"started_at,omitempty"`  // Timestamp when the last attempt started, nil if it never ran.
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"` // Timestamp when the job succeeded or failed for good, nil until then.
+}
+
+// Result represents the file produced by a job, such as an exported spreadsheet or an import report.
+type Result struct {
+	Name        string // File name the result is downloaded with.
+	ContentType string // MIME type of the result.
+	Data        []byte // Content of the result.
+}
+
+// New creates a new job of a client at the given time, queued to run the operation kind with the given parameters,
+// which are encoded as JSON, and the optional input file.
+func New(clientID int, kind Kind, params interface{}, input []byte, now time.Time) (j Job, err error) {
+	if kind == "" {
+		err = fmt.Errorf("invalid kind: kind cannot be empty")
+		return
+	}
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		err = fmt.Errorf("invalid params: %s", err)
+		return
+	}
+
+	j = Job{
+		ClientID:    clientID,
+		Kind:        kind,
+		Status:      QUEUED_STATUS,
+		Params:      encoded,
+		Input:       input,
+		MaxAttempts: MAX_ATTEMPTS,
+		RunAt:       now,
+		CreatedAt:   now,
+	}
+	return
+}
+
+// ReadParams decodes the parameters of the job into v.
+func (j Job) ReadParams(v interface{}) (err error) {
+	err = json.Unmarshal(j.Params, v)
+	if err != nil {
+		err = Permanent(fmt.Errorf("invalid params: %s", err))
+	}
+	return
+}
+
+// Claimed returns the job as claimed by a worker at the given time, locked for the duration of the lease.
+// A new attempt starts from no progress and without the error of the previous one.
+func (j Job) Claimed(now time.Time, lease time.Duration) (claimed Job) {
+	claimed = j
+	lockedUntil := now.Add(lease)
+	claimed.Status = RUNNING_STATUS
+	claimed.Attempts++
+	claimed.Progress = 0
+	claimed.Error = ""
+	claimed.StartedAt = &now
+	claimed.LockedUntil = &lockedUntil
+	return
+}
+
+// Abandoned reports whether the job was claimed again after the lease of its last attempt expired,
+// so every attempt was already used and it must be failed instead of run.
+func (j Job) Abandoned() bool {
+	return j.Attempts > j.MaxAttempts
+}
+
+// Succeeded returns the job as succeeded at the given time, with the name and type of its result, if any.
+func (j Job) Succeeded(result *Result, now time.Time) (done Job) {
+	done = j
+	done.Status = SUCCEEDED_STATUS
+	done.Progress = 100
+	done.LockedUntil = nil
+	done.FinishedAt = &now
+	if result != nil {
+		done.ResultName = result.Name
+		done.ResultType = result.ContentType
+	}
+	return
+}
+
+// Failed returns the job as failed at the given time with err.
+// It is queued again after Backoff, unless err is permanent or the job already used every attempt.
+func (j Job) Failed(err error, now time.Time) (failed Job) {
+	failed = j
+	failed.Error = err.Error()
+	failed.LockedUntil = nil
+	if IsPermanent(err) || j.Attempts >= j.MaxAttempts {
+		failed.Status = FAILED_STATUS
+		failed.FinishedAt = &now
+		return
+	}
+	failed.Status = QUEUED_STATUS
+	failed.Progress = 0
+	failed.RunAt = now.Add(Backoff(j.Attempts))
+	return
+}
+
+// Backoff returns the delay before the retry of a job failed after the given attempts.
+// It starts at BASE_BACKOFF and doubles with every attempt, up to MAX_BACKOFF.
+func Backoff(attempts int) (d time.Duration) {
+	d = BASE_BACKOFF
+	for i := 1; i < attempts && d < MAX_BACKOFF; i++ {
+		d *= 2
+	}
+	if d > MAX_BACKOFF {
+		d = MAX_BACKOFF
+	}
+	return
+}
+
+// permanentError is an error that retrying the job would not fix, such as invalid parameters.
+type permanentError struct {
+	err error
+}
+
+func (p permanentError) Error() string {
+	return p.err.Error()
+}
+
+func (p permanentError) Unwrap() error {
+	return p.err
+}
+
+// Permanent marks err as an error that retrying the job would not fix, so it is failed for good.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err, or any error it wraps, was marked with Permanent.
+func IsPermanent(err error) bool {
+	var p permanentError
+	return stdErrors.As(err, &p)
+}
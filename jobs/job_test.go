@@ -0,0 +1,132 @@
+package jobs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2023, time.March, 1, 7, 30, 0, 0, time.UTC)
+
+func TestNew(t *testing.T) {
+	j, err := New(1, EXPORT_PRODUCTS_KIND, map[string]string{"format": "csv"}, []byte("input"), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, j.ClientID)
+	assert.Equal(t, QUEUED_STATUS, j.Status)
+	assert.JSONEq(t, `{"format": "csv"}`, string(j.Params))
+	assert.Equal(t, []byte("input"), j.Input)
+	assert.Equal(t, MAX_ATTEMPTS, j.MaxAttempts)
+	assert.Equal(t, now, j.RunAt)
+	assert.Equal(t, now, j.CreatedAt)
+
+	var params struct {
+		Format string `json:"format"`
+	}
+	require.NoError(t, j.ReadParams(&params))
+	assert.Equal(t, "csv", params.Format)
+
+	// Parameters that can not be decoded are a permanent error.
+	var invalid []int
+	assert.True(t, IsPermanent(j.ReadParams(&invalid)))
+
+	_, err = New(1, "", nil, nil, now)
+	assert.Error(t, err)
+	_, err = New(1, EXPORT_PRODUCTS_KIND, func() {}, nil, now)
+	assert.Error(t, err)
+}
+
+func TestJob_Claimed(t *testing.T) {
+	j, err := New(1, EXPORT_PRODUCTS_KIND, nil, nil, now)
+	require.NoError(t, err)
+	j.Progress, j.Error = 40, "connection reset"
+
+	claimed := j.Claimed(now.Add(time.Minute), LEASE)
+	assert.Equal(t, RUNNING_STATUS, claimed.Status)
+	assert.Equal(t, 1, claimed.Attempts)
+	assert.Zero(t, claimed.Progress)
+	assert.Empty(t, claimed.Error)
+	require.NotNil(t, claimed.StartedAt)
+	assert.Equal(t, now.Add(time.Minute), *claimed.StartedAt)
+	require.NotNil(t, claimed.LockedUntil)
+	assert.Equal(t, now.Add(time.Minute+LEASE), *claimed.LockedUntil)
+	assert.False(t, claimed.Abandoned())
+
+	// The original job is not modified.
+	assert.Equal(t, QUEUED_STATUS, j.Status)
+
+	claimed.Attempts = MAX_ATTEMPTS + 1
+	assert.True(t, claimed.Abandoned())
+}
+
+func TestJob_Succeeded(t *testing.T) {
+	j, err := New(1, EXPORT_PRODUCTS_KIND, nil, nil, now)
+	require.NoError(t, err)
+	j = j.Claimed(now, LEASE)
+
+	done := j.Succeeded(&Result{Name: "products.csv", ContentType: "text/csv"}, now.Add(time.Minute))
+	assert.Equal(t, SUCCEEDED_STATUS, done.Status)
+	assert.Equal(t, 100, done.Progress)
+	assert.Equal(t, "products.csv", done.ResultName)
+	assert.Equal(t, "text/csv", done.ResultType)
+	assert.Nil(t, done.LockedUntil)
+	require.NotNil(t, done.FinishedAt)
+	assert.Equal(t, now.Add(time.Minute), *done.FinishedAt)
+
+	done = j.Succeeded(nil, now)
+	assert.Empty(t, done.ResultName)
+}
+
+func TestJob_Failed(t *testing.T) {
+	j, err := New(1, EXPORT_PRODUCTS_KIND, nil, nil, now)
+	require.NoError(t, err)
+
+	t.Run("Retried", func(t *testing.T) {
+		claimed := j.Claimed(now, LEASE)
+		claimed.Progress = 40
+		failed := claimed.Failed(fmt.Errorf("connection reset"), now)
+		assert.Equal(t, QUEUED_STATUS, failed.Status)
+		assert.Equal(t, "connection reset", failed.Error)
+		assert.Zero(t, failed.Progress)
+		assert.Equal(t, now.Add(BASE_BACKOFF), failed.RunAt)
+		assert.Nil(t, failed.LockedUntil)
+		assert.Nil(t, failed.FinishedAt)
+	})
+
+	t.Run("Permanent", func(t *testing.T) {
+		failed := j.Claimed(now, LEASE).Failed(Permanent(fmt.Errorf("invalid format")), now)
+		assert.Equal(t, FAILED_STATUS, failed.Status)
+		assert.Equal(t, "invalid format", failed.Error)
+		require.NotNil(t, failed.FinishedAt)
+		assert.Equal(t, now, *failed.FinishedAt)
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		claimed := j
+		for i := 0; i < MAX_ATTEMPTS; i++ {
+			claimed = claimed.Claimed(now, LEASE)
+		}
+		failed := claimed.Failed(fmt.Errorf("connection reset"), now)
+		assert.Equal(t, FAILED_STATUS, failed.Status)
+		assert.NotNil(t, failed.FinishedAt)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, BASE_BACKOFF, Backoff(1))
+	assert.Equal(t, 2*BASE_BACKOFF, Backoff(2))
+	assert.Equal(t, 4*BASE_BACKOFF, Backoff(3))
+	assert.Equal(t, MAX_BACKOFF, Backoff(8))
+	assert.Equal(t, MAX_BACKOFF, Backoff(100))
+}
+
+func TestPermanent(t *testing.T) {
+	assert.Nil(t, Permanent(nil))
+	assert.False(t, IsPermanent(fmt.Errorf("connection reset")))
+
+	err := fmt.Errorf("failed to run: %w", Permanent(fmt.Errorf("invalid format")))
+	assert.True(t, IsPermanent(err))
+	assert.EqualError(t, err, "failed to run: invalid format")
+}
@@ -0,0 +1,236 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database/errors"
+)
+
+// LEASE is the duration a claimed job is locked for, renewed by every heartbeat of its worker.
+// A job whose lease expires, because its worker stopped, is claimed again by another one.
+const LEASE = time.Minute
+
+// HEARTBEAT_INTERVAL is the duration between the heartbeats of a running job, which store its progress and renew its lease.
+const HEARTBEAT_INTERVAL = 5 * time.Second
+
+// errAbandoned fails the jobs whose last attempt was abandoned by its worker.
+var errAbandoned = fmt.Errorf("abandoned job: the worker of the last attempt stopped before finishing it")
+
+// Queue is the storage of the jobs run by a Pool, see database.JobRepository.
+type Queue interface {
+	// Claim locks the next job due at the given time for the duration of the lease, see Job.Claimed.
+	// It returns a NOT_FOUND error if no job is due.
+	Claim(ctx context.Context, now time.Time, lease time.Duration) (job Job, err error)
+
+	// Heartbeat stores the progress and the lease of the running attempt of a job.
+	// It returns a CONFLICT error if the attempt is no longer running.
+	Heartbeat(ctx context.Context, job Job) (err error)
+
+	// Finish stores the outcome of the running attempt of a job, and its result if any.
+	// It returns a CONFLICT error if the attempt is no longer running.
+	Finish(ctx context.Context, job Job, result *Result) (err error)
+}
+
+// Runner runs the operation of a kind of jobs.
+type Runner interface {
+	// Run runs the operation of a job, reporting the percentage done with progress, and returns its result, if any.
+	// It must stop when ctx is done, and errors that retrying would not fix should be marked with Permanent.
+	Run(ctx context.Context, job Job, progress func(percent int)) (result *Result, err error)
+}
+
+// Pool runs the queued jobs with a fixed number of workers, retrying the failed ones with exponential backoff.
+// Several pools can share a queue, as every job is claimed by a single worker at a time.
+type Pool struct {
+	queue    Queue            // Queue the jobs are claimed from
+	runners  map[Kind]Runner  // Runner of every kind of jobs
+	workers  int              // Number of jobs run at the same time
+	interval time.Duration    // Duration between the checks for due jobs of an idle worker
+	lease    time.Duration    // Duration a claimed job is locked for
+	now      func() time.Time // Clock of the queue, in UTC
+}
+
+// NewPool creates a new Pool of workers running the jobs of the queue with the runners of their kinds,
+// checking for due jobs every interval while idle.
+func NewPool(queue Queue, runners map[Kind]Runner, workers int, interval time.Duration) (p Pool, err error) {
+	if workers < 1 {
+		err = fmt.Errorf("invalid workers: a pool needs at least 1 worker")
+		return
+	}
+	if interval <= 0 {
+		err = fmt.Errorf("invalid interval: interval must be positive")
+		return
+	}
+
+	p = Pool{
+		queue:    queue,
+		runners:  runners,
+		workers:  workers,
+		interval: interval,
+		lease:    LEASE,
+		now: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+	return
+}
+
+// Start runs the workers of the pool until ctx is done, waiting for them to stop.
+// The errors are logged, so a failed job is retried by the queue.
+func (p Pool) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// work runs every due job, one at a time, and checks for new ones every interval once the queue is empty.
+func (p Pool) work(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			_, ran, err := p.RunNext(ctx)
+			if err != nil {
+				log.Printf("failed to run the next job: %s", err)
+			}
+			if !ran || err != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunNext claims the next due job and runs it, storing its outcome.
+// It returns the job as it was finished, and whether any job was due.
+func (p Pool) RunNext(ctx context.Context) (job Job, ran bool, err error) {
+	job, err = p.queue.Claim(ctx, p.now(), p.lease)
+	if dbErr, ok := err.(errors.Error); ok && dbErr.Type == errors.NOT_FOUND {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+	ran = true
+
+	// Every attempt was already used, the last one by a worker that stopped.
+	if job.Abandoned() {
+		job = job.Failed(errAbandoned, p.now())
+		err = p.finish(ctx, job, nil)
+		return
+	}
+
+	result, lost, runErr := p.run(ctx, job)
+	// Another worker claimed the job after its lease expired, so the outcome is its to store.
+	if lost {
+		log.Printf("lost the lease of job %d, its attempt %d is discarded", job.ID, job.Attempts)
+		return
+	}
+
+	if runErr != nil {
+		log.Printf("failed to run attempt %d of job %d: %s", job.Attempts, job.ID, runErr)
+		job = job.Failed(runErr, p.now())
+		result = nil
+	} else {
+		job = job.Succeeded(result, p.now())
+	}
+	err = p.finish(ctx, job, result)
+	return
+}
+
+// run runs a claimed job with the runner of its kind, renewing its lease every HEARTBEAT_INTERVAL until it returns.
+// It reports whether the lease was lost to another worker, which cancels the run.
+func (p Pool) run(ctx context.Context, job Job) (result *Result, lost bool, err error) {
+	runner, ok := p.runners[job.Kind]
+	if !ok {
+		err = Permanent(fmt.Errorf("invalid kind: unknown kind of %s", job.Kind))
+		return
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The progress is reported by the runner and stored by the heartbeats.
+	var progress atomic.Int64
+	var lostLease atomic.Bool
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(HEARTBEAT_INTERVAL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			beat := job
+			beat.Progress = int(progress.Load())
+			lockedUntil := p.now().Add(p.lease)
+			beat.LockedUntil = &lockedUntil
+			beatErr := p.queue.Heartbeat(ctx, beat)
+			if dbErr, ok := beatErr.(errors.Error); ok && dbErr.Type == errors.CONFLICT {
+				lostLease.Store(true)
+				cancel()
+				return
+			}
+			if beatErr != nil {
+				log.Printf("failed to store the heartbeat of job %d: %s", job.ID, beatErr)
+			}
+		}
+	}()
+
+	result, err = p.runSafely(runCtx, runner, job, func(percent int) {
+		// The job is only done once its outcome is stored.
+		if percent < 0 {
+			percent = 0
+		}
+		if percent > 99 {
+			percent = 99
+		}
+		progress.Store(int64(percent))
+	})
+	close(stop)
+	<-stopped
+	lost = lostLease.Load()
+	return
+}
+
+// runSafely runs a job with its runner, turning a panic of the runner into an error of the attempt.
+func (p Pool) runSafely(ctx context.Context, runner Runner, job Job, progress func(percent int)) (result *Result, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = nil
+			err = fmt.Errorf("runner panicked: %v", r)
+		}
+	}()
+	return runner.Run(ctx, job, progress)
+}
+
+// finish stores the outcome of a job, which is discarded if another worker claimed it since.
+func (p Pool) finish(ctx context.Context, job Job, result *Result) (err error) {
+	err = p.queue.Finish(ctx, job, result)
+	if dbErr, ok := err.(errors.Error); ok && dbErr.Type == errors.CONFLICT {
+		log.Printf("lost the lease of job %d, its attempt %d is discarded", job.ID, job.Attempts)
+		err = nil
+	}
+	return
+}
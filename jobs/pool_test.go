@@ -0,0 +1,272 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testQueue is a Queue of the jobs held in memory, as the repositories of the database can not be imported by the tests.
+type testQueue struct {
+	mu      sync.Mutex
+	jobs    []Job
+	results map[int]*Result
+}
+
+func (q *testQueue) Claim(ctx context.Context, now time.Time, lease time.Duration) (j Job, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, stored := range q.jobs {
+		due := (stored.Status == QUEUED_STATUS && !stored.RunAt.After(now)) ||
+			(stored.Status == RUNNING_STATUS && stored.LockedUntil.Before(now))
+		if due {
+			q.jobs[i] = stored.Claimed(now, lease)
+			j = q.jobs[i]
+			return
+		}
+	}
+	err = errors.NewError(errors.NOT_FOUND, "not found", "no job is due")
+	return
+}
+
+func (q *testQueue) Heartbeat(ctx context.Context, j Job) (err error) {
+	return q.update(j, nil)
+}
+
+func (q *testQueue) Finish(ctx context.Context, j Job, result *Result) (err error) {
+	return q.update(j, result)
+}
+
+// update replaces the stored job of a running attempt, reporting a CONFLICT error if it is no longer running.
+func (q *testQueue) update(j Job, result *Result) (err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stored := q.jobs[j.ID-1]
+	if stored.Status != RUNNING_STATUS || stored.Attempts != j.Attempts {
+		err = errors.NewError(errors.CONFLICT, "conflict", "the attempt is no longer running")
+		return
+	}
+	q.jobs[j.ID-1] = j
+	if result != nil {
+		q.results[j.ID] = result
+	}
+	return
+}
+
+// add queues a job of kind, due at the given time, and returns its ID.
+func (q *testQueue) add(t *testing.T, kind Kind, runAt time.Time) int {
+	t.Helper()
+	j, err := New(1, kind, nil, nil, runAt)
+	require.NoError(t, err)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j.ID = len(q.jobs) + 1
+	q.jobs = append(q.jobs, j)
+	return j.ID
+}
+
+// get returns the stored job with the given ID.
+func (q *testQueue) get(id int) Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.jobs[id-1]
+}
+
+// runnerFunc is a Runner calling a function.
+type runnerFunc func(ctx context.Context, job Job, progress func(percent int)) (*Result, error)
+
+func (f runnerFunc) Run(ctx context.Context, job Job, progress func(percent int)) (*Result, error) {
+	return f(ctx, job, progress)
+}
+
+// newTestPool creates a pool of the queue whose clock is read from clock.
+func newTestPool(t *testing.T, queue Queue, runners map[Kind]Runner, clock *time.Time) Pool {
+	t.Helper()
+	p, err := NewPool(queue, runners, 1, time.Millisecond)
+	require.NoError(t, err)
+	p.now = func() time.Time {
+		return *clock
+	}
+	return p
+}
+
+func TestNewPool(t *testing.T) {
+	_, err := NewPool(&testQueue{}, nil, 0, time.Second)
+	assert.Error(t, err)
+	_, err = NewPool(&testQueue{}, nil, 1, 0)
+	assert.Error(t, err)
+}
+
+func TestPool_RunNext(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Succeeded", func(t *testing.T) {
+		q := &testQueue{results: make(map[int]*Result)}
+		clock := now
+		p := newTestPool(t, q, map[Kind]Runner{
+			EXPORT_PRODUCTS_KIND: runnerFunc(func(ctx context.Context, job Job, progress func(percent int)) (*Result, error) {
+				progress(150)
+				return &Result{Name: "products.csv", ContentType: "text/csv", Data: []byte("id\n")}, nil
+			}),
+		}, &clock)
+
+		// Nothing runs before the job is due.
+		id := q.add(t, EXPORT_PRODUCTS_KIND, now.Add(time.Minute))
+		_, ran, err := p.RunNext(ctx)
+		require.NoError(t, err)
+		assert.False(t, ran)
+
+		clock = now.Add(time.Minute)
+		j, ran, err := p.RunNext(ctx)
+		require.NoError(t, err)
+		assert.True(t, ran)
+		assert.Equal(t, id, j.ID)
+		assert.Equal(t, SUCCEEDED_STATUS, q.get(id).Status)
+		assert.Equal(t, 100, q.get(id).Progress)
+		assert.Equal(t, "products.csv", q.get(id).ResultName)
+		assert.Equal(t, []byte("id\n"), q.results[id].Data)
+	})
+
+	t.Run("Retried", func(t *testing.T) {
+		q := &testQueue{results: make(map[int]*Result)}
+		clock := now
+		runs := 0
+		p := newTestPool(t, q, map[Kind]Runner{
+			EXPORT_PRODUCTS_KIND: runnerFunc(func(ctx context.Context, job Job, progress func(percent int)) (*Result, error) {
+				runs++
+				if runs == 1 {
+					return nil, fmt.Errorf("connection reset")
+				}
+				if runs == 2 {
+					panic("nil map")
+				}
+				return nil, nil
+			}),
+		}, &clock)
+		id := q.add(t, EXPORT_PRODUCTS_KIND, now)
+
+		// The failed attempts are retried after their backoff.
+		_, _, err := p.RunNext(ctx)
+		require.NoError(t, err)
+		j := q.get(id)
+		assert.Equal(t, QUEUED_STATUS, j.Status)
+		assert.Equal(t, "connection reset", j.Error)
+		assert.Equal(t, now.Add(Backoff(1)), j.RunAt)
+
+		_, ran, err := p.RunNext(ctx)
+		require.NoError(t, err)
+		assert.False(t, ran)
+
+		// A panic of the runner fails the attempt.
+		clock = j.RunAt
+		_, _, err = p.RunNext(ctx)
+		require.NoError(t, err)
+		j = q.get(id)
+		assert.Equal(t, QUEUED_STATUS, j.Status)
+		assert.Equal(t, "runner panicked: nil map", j.Error)
+		assert.Equal(t, clock.Add(Backoff(2)), j.RunAt)
+
+		clock = j.RunAt
+		_, _, err = p.RunNext(ctx)
+		require.NoError(t, err)
+		j = q.get(id)
+		assert.Equal(t, SUCCEEDED_STATUS, j.Status)
+		assert.Equal(t, 3, j.Attempts)
+		assert.Empty(t, j.Error)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		q := &testQueue{results: make(map[int]*Result)}
+		clock := now
+		p := newTestPool(t, q, map[Kind]Runner{
+			EXPORT_PRODUCTS_KIND: runnerFunc(func(ctx context.Context, job Job, progress func(percent int)) (*Result, error) {
+				return nil, Permanent(fmt.Errorf("invalid format"))
+			}),
+		}, &clock)
+
+		// The permanent errors and the unknown kinds are not retried.
+		permanent := q.add(t, EXPORT_PRODUCTS_KIND, now)
+		unknown := q.add(t, IMPORT_PRODUCTS_KIND, now)
+		for i := 0; i < 2; i++ {
+			_, ran, err := p.RunNext(ctx)
+			require.NoError(t, err)
+			assert.True(t, ran)
+		}
+		assert.Equal(t, FAILED_STATUS, q.get(permanent).Status)
+		assert.Equal(t, "invalid format", q.get(permanent).Error)
+		assert.Equal(t, FAILED_STATUS, q.get(unknown).Status)
+		assert.Equal(t, 1, q.get(unknown).Attempts)
+	})
+
+	t.Run("Abandoned", func(t *testing.T) {
+		q := &testQueue{results: make(map[int]*Result)}
+		clock := now
+		p := newTestPool(t, q, map[Kind]Runner{
+			EXPORT_PRODUCTS_KIND: runnerFunc(func(ctx context.Context, job Job, progress func(percent int)) (*Result, error) {
+				t.Error("the abandoned job must not run")
+				return nil, nil
+			}),
+		}, &clock)
+
+		// The last attempt was claimed by a worker that stopped before finishing it.
+		id := q.add(t, EXPORT_PRODUCTS_KIND, now)
+		q.jobs[0].Attempts = MAX_ATTEMPTS - 1
+		_, err := q.Claim(ctx, now, LEASE)
+		require.NoError(t, err)
+
+		clock = now.Add(LEASE + time.Second)
+		_, ran, err := p.RunNext(ctx)
+		require.NoError(t, err)
+		assert.True(t, ran)
+		j := q.get(id)
+		assert.Equal(t, FAILED_STATUS, j.Status)
+		assert.Equal(t, errAbandoned.Error(), j.Error)
+	})
+}
+
+func TestPool_Start(t *testing.T) {
+	q := &testQueue{results: make(map[int]*Result)}
+	done := make(chan int, 3)
+	p, err := NewPool(q, map[Kind]Runner{
+		EXPORT_PRODUCTS_KIND: runnerFunc(func(ctx context.Context, job Job, progress func(percent int)) (*Result, error) {
+			done <- job.ID
+			return nil, nil
+		}),
+	}, 2, time.Millisecond)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		q.add(t, EXPORT_PRODUCTS_KIND, time.Now().UTC())
+	}
+
+	// Every queued job is run once, and the workers stop with the context.
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		p.Start(ctx)
+		close(stopped)
+	}()
+
+	ran := make(map[int]bool)
+	for len(ran) < 3 {
+		select {
+		case id := <-done:
+			assert.False(t, ran[id], "job %d ran twice", id)
+			ran[id] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("the jobs did not run")
+		}
+	}
+	cancel()
+	<-stopped
+
+	for id := 1; id <= 3; id++ {
+		assert.Equal(t, SUCCEEDED_STATUS, q.get(id).Status)
+	}
+}
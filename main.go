@@ -1,13 +1,24 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/coffemanfp/docucentertest/config"
 	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/memory"
 	"github.com/coffemanfp/docucentertest/database/psql"
+	"github.com/coffemanfp/docucentertest/database/sqlite"
+	"github.com/coffemanfp/docucentertest/jobs"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/scheduler"
+	"github.com/coffemanfp/docucentertest/search"
 	"github.com/coffemanfp/docucentertest/server/gin"
+	"github.com/coffemanfp/docucentertest/server/gin/handlers"
 )
 
 func main() {
@@ -17,29 +28,85 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Run the migrate subcommand instead of the server if requested.
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = runMigrate(conf, os.Args[2:], os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Read the search dates without a time zone in the configured one.
+	loc, err := time.LoadLocation(conf.Server.TimeZone)
+	if err != nil {
+		log.Fatal(fmt.Errorf("invalid SRV_TIME_ZONE env var: %s", err))
+	}
+	search.SetLocation(loc)
+
 	// Set up the database connection.
 	db, err := setUpDatabase(conf)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	// Refuse to start if the schema must be current and it has pending migrations.
+	if conf.DatabaseDriver == config.POSTGRESQL_DRIVER && conf.PostgreSQLProperties.StrictSchema {
+		err = checkSchema(db.Conn.(*psql.PostgreSQLConnector))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Make the configured client an admin, registering it if needed, so the roles can be managed.
+	err = bootstrapAdmin(context.Background(), conf, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Run the scheduled saved searches in the background, unless disabled.
+	if conf.Server.DigestInterval > 0 {
+		sched, err := scheduler.New(db, time.Duration(conf.Server.DigestInterval)*time.Second)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go sched.Start(context.Background())
+	}
+
 	// Create a new server engine using the loaded configuration and database.
-	serverEngine := gin.New(conf, db)
+	serverEngine, err := gin.New(conf, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Run the queued background jobs, unless disabled. The runners use the handlers initialized by the server engine.
+	if conf.Server.JobWorkers > 0 {
+		pool, err := newJobPool(conf, db)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go pool.Start(context.Background())
+	}
 
 	// Start the server on the specified port.
 	serverEngine.Run(fmt.Sprintf(":%d", conf.Server.Port))
 }
 
 func setUpDatabase(conf config.ConfigInfo) (db database.Database, err error) {
+	// Use the configured database driver.
+	switch conf.DatabaseDriver {
+	case config.MEMORY_DRIVER:
+		return setUpMemoryDatabase(conf)
+	case config.SQLITE_DRIVER:
+		return setUpSQLiteDatabase(conf)
+	default:
+		return setUpPostgreSQLDatabase(conf)
+	}
+}
+
+func setUpPostgreSQLDatabase(conf config.ConfigInfo) (db database.Database, err error) {
 	// Create a new PostgreSQL database connector.
-	db.Conn = psql.NewPostgreSQLConnector(
-		conf.PostgreSQLProperties.URL,
-		conf.PostgreSQLProperties.User,
-		conf.PostgreSQLProperties.Password,
-		conf.PostgreSQLProperties.Name,
-		conf.PostgreSQLProperties.Host,
-		conf.PostgreSQLProperties.Port,
-	)
+	db.Conn = newPostgreSQLConnector(conf)
 
 	// Connect to the database.
 	err = db.Conn.Connect()
@@ -65,11 +132,206 @@ func setUpDatabase(conf config.ConfigInfo) (db database.Database, err error) {
 		return
 	}
 
+	// Create a new tracking repository using the PostgreSQL connector.
+	trackingRepo, err := psql.NewTrackingRepository(db.Conn.(*psql.PostgreSQLConnector))
+	if err != nil {
+		return
+	}
+
+	// Create a new quote repository using the PostgreSQL connector.
+	quoteRepo, err := psql.NewQuoteRepository(db.Conn.(*psql.PostgreSQLConnector))
+	if err != nil {
+		return
+	}
+
+	// Create a new saved search repository using the PostgreSQL connector.
+	savedSearchRepo, err := psql.NewSavedSearchRepository(db.Conn.(*psql.PostgreSQLConnector))
+	if err != nil {
+		return
+	}
+
+	// Create a new job repository using the PostgreSQL connector.
+	jobRepo, err := psql.NewJobRepository(db.Conn.(*psql.PostgreSQLConnector))
+	if err != nil {
+		return
+	}
+
+	// Create a new pricing repository, reading the rules from a YAML file if configured.
+	pricingRepo, err := setUpPricingRepository(conf, db.Conn.(*psql.PostgreSQLConnector))
+	if err != nil {
+		return
+	}
+
 	// Initialize the database repositories.
 	db.Repositories = map[database.RepositoryID]interface{}{
-		database.AUTH_REPOSITORY:    authRepo,
-		database.CLIENT_REPOSITORY:  clientRepo,
-		database.PRODUCT_REPOSITORY: productRepo,
+		database.AUTH_REPOSITORY:         authRepo,
+		database.CLIENT_REPOSITORY:       clientRepo,
+		database.PRODUCT_REPOSITORY:      productRepo,
+		database.TRACKING_REPOSITORY:     trackingRepo,
+		database.PRICING_REPOSITORY:      pricingRepo,
+		database.QUOTE_REPOSITORY:        quoteRepo,
+		database.SAVED_SEARCH_REPOSITORY: savedSearchRepo,
+		database.JOB_REPOSITORY:          jobRepo,
 	}
 	return
 }
+
+func setUpMemoryDatabase(conf config.ConfigInfo) (db database.Database, err error) {
+	// Create a new in-memory database connector, every repository shares its rows.
+	conn := memory.NewConnector()
+	db.Conn = conn
+
+	authRepo, err := memory.NewAuthRepository(conn)
+	if err != nil {
+		return
+	}
+	clientRepo, err := memory.NewClientRepository(conn)
+	if err != nil {
+		return
+	}
+	productRepo, err := memory.NewProductRepository(conn)
+	if err != nil {
+		return
+	}
+	trackingRepo, err := memory.NewTrackingRepository(conn)
+	if err != nil {
+		return
+	}
+	quoteRepo, err := memory.NewQuoteRepository(conn)
+	if err != nil {
+		return
+	}
+	savedSearchRepo, err := memory.NewSavedSearchRepository(conn)
+	if err != nil {
+		return
+	}
+	jobRepo, err := memory.NewJobRepository(conn)
+	if err != nil {
+		return
+	}
+
+	// Create a new pricing repository, reading the rules from a YAML file if configured.
+	var pricingRepo database.PricingRepository
+	if conf.Server.PricingRulesFile == "" {
+		pricingRepo, err = memory.NewPricingRepository(conn)
+	} else {
+		pricingRepo, err = product.NewFileRuleSource(conf.Server.PricingRulesFile)
+	}
+	if err != nil {
+		return
+	}
+
+	// Initialize the database repositories.
+	db.Repositories = map[database.RepositoryID]interface{}{
+		database.AUTH_REPOSITORY:         authRepo,
+		database.CLIENT_REPOSITORY:       clientRepo,
+		database.PRODUCT_REPOSITORY:      productRepo,
+		database.TRACKING_REPOSITORY:     trackingRepo,
+		database.PRICING_REPOSITORY:      pricingRepo,
+		database.QUOTE_REPOSITORY:        quoteRepo,
+		database.SAVED_SEARCH_REPOSITORY: savedSearchRepo,
+		database.JOB_REPOSITORY:          jobRepo,
+	}
+	return
+}
+
+func setUpSQLiteDatabase(conf config.ConfigInfo) (db database.Database, err error) {
+	// Create a new SQLite database connector, the tables are created when connecting.
+	conn := sqlite.NewSQLiteConnector(
+		conf.SQLiteProperties.Path,
+		time.Duration(conf.SQLiteProperties.QueryTimeout)*time.Second,
+	)
+	db.Conn = conn
+
+	// Connect to the database.
+	err = db.Conn.Connect()
+	if err != nil {
+		return
+	}
+
+	authRepo, err := sqlite.NewAuthRepository(conn)
+	if err != nil {
+		return
+	}
+	clientRepo, err := sqlite.NewClientRepository(conn)
+	if err != nil {
+		return
+	}
+	productRepo, err := sqlite.NewProductRepository(conn)
+	if err != nil {
+		return
+	}
+	trackingRepo, err := sqlite.NewTrackingRepository(conn)
+	if err != nil {
+		return
+	}
+	quoteRepo, err := sqlite.NewQuoteRepository(conn)
+	if err != nil {
+		return
+	}
+	savedSearchRepo, err := sqlite.NewSavedSearchRepository(conn)
+	if err != nil {
+		return
+	}
+	jobRepo, err := sqlite.NewJobRepository(conn)
+	if err != nil {
+		return
+	}
+
+	// Create a new pricing repository, reading the rules from a YAML file if configured.
+	var pricingRepo database.PricingRepository
+	if conf.Server.PricingRulesFile == "" {
+		pricingRepo, err = sqlite.NewPricingRepository(conn)
+	} else {
+		pricingRepo, err = product.NewFileRuleSource(conf.Server.PricingRulesFile)
+	}
+	if err != nil {
+		return
+	}
+
+	// Initialize the database repositories.
+	db.Repositories = map[database.RepositoryID]interface{}{
+		database.AUTH_REPOSITORY:         authRepo,
+		database.CLIENT_REPOSITORY:       clientRepo,
+		database.PRODUCT_REPOSITORY:      productRepo,
+		database.TRACKING_REPOSITORY:     trackingRepo,
+		database.PRICING_REPOSITORY:      pricingRepo,
+		database.QUOTE_REPOSITORY:        quoteRepo,
+		database.SAVED_SEARCH_REPOSITORY: savedSearchRepo,
+		database.JOB_REPOSITORY:          jobRepo,
+	}
+	return
+}
+
+func newJobPool(conf config.ConfigInfo, db database.Database) (pool jobs.Pool, err error) {
+	// Claim the jobs from the job repository of the configured database.
+	queue, err := database.GetRepository[database.JobRepository](db.Repositories, database.JOB_REPOSITORY)
+	if err != nil {
+		return
+	}
+
+	// Run every kind of jobs with the runners of the handlers.
+	return jobs.NewPool(queue, handlers.JobRunners(), conf.Server.JobWorkers, time.Duration(conf.Server.JobInterval)*time.Second)
+}
+
+func setUpPricingRepository(conf config.ConfigInfo, conn *psql.PostgreSQLConnector) (repo database.PricingRepository, err error) {
+	// Use the PostgreSQL pricing rules if no rules file is configured.
+	if conf.Server.PricingRulesFile == "" {
+		return psql.NewPricingRepository(conn)
+	}
+
+	// Load the pricing rules from the configured YAML file.
+	return product.NewFileRuleSource(conf.Server.PricingRulesFile)
+}
+
+func newPostgreSQLConnector(conf config.ConfigInfo) *psql.PostgreSQLConnector {
+	return psql.NewPostgreSQLConnector(
+		conf.PostgreSQLProperties.URL,
+		conf.PostgreSQLProperties.User,
+		conf.PostgreSQLProperties.Password,
+		conf.PostgreSQLProperties.Name,
+		conf.PostgreSQLProperties.Host,
+		conf.PostgreSQLProperties.Port,
+		time.Duration(conf.PostgreSQLProperties.QueryTimeout)*time.Second,
+	)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database/psql"
+	"github.com/coffemanfp/docucentertest/migrations"
+)
+
+// migrateUsage describes the arguments of the migrate subcommand.
+const migrateUsage = "usage: migrate up|down|status|redo"
+
+// runMigrate runs the migrate subcommand with the given arguments, writing its report to w.
+func runMigrate(conf config.ConfigInfo, args []string, w io.Writer) (err error) {
+	if len(args) != 1 {
+		return errors.New(migrateUsage)
+	}
+	// Only the PostgreSQL schema is versioned, the other drivers create their tables when connecting.
+	if conf.DatabaseDriver != config.POSTGRESQL_DRIVER {
+		return fmt.Errorf("unsupported migrations: the %s database driver has no migrations", conf.DatabaseDriver)
+	}
+
+	// Connect to the database and load the embedded migrations.
+	conn := newPostgreSQLConnector(conf)
+	err = conn.Connect()
+	if err != nil {
+		return
+	}
+	m, err := psql.NewMigrator(conn)
+	if err != nil {
+		return
+	}
+
+	switch args[0] {
+	case "up":
+		var applied []migrations.Migration
+		applied, err = m.Up()
+		// Report the applied migrations even if a later one failed.
+		for _, mg := range applied {
+			fmt.Fprintf(w, "applied %04d %s\n", mg.Version, mg.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(w, "schema is up to date")
+		}
+
+	case "down":
+		var reverted *migrations.Migration
+		reverted, err = m.Down()
+		if err == nil {
+			printMigration(w, "reverted", reverted)
+		}
+
+	case "redo":
+		var redone *migrations.Migration
+		redone, err = m.Redo()
+		if err == nil {
+			printMigration(w, "redone", redone)
+		}
+
+	case "status":
+		var statuses []migrations.Status
+		statuses, err = m.Status()
+		if err == nil {
+			printStatus(w, statuses)
+		}
+
+	default:
+		err = errors.New(migrateUsage)
+	}
+	return
+}
+
+// checkSchema returns an error if the database schema has pending migrations.
+func checkSchema(conn *psql.PostgreSQLConnector) (err error) {
+	m, err := psql.NewMigrator(conn)
+	if err != nil {
+		return
+	}
+	return m.CheckCurrent()
+}
+
+// printMigration writes the action done with a migration, or that there was nothing to do.
+func printMigration(w io.Writer, action string, mg *migrations.Migration) {
+	if mg == nil {
+		fmt.Fprintln(w, "no migration applied")
+		return
+	}
+	fmt.Fprintf(w, "%s %04d %s\n", action, mg.Version, mg.Name)
+}
+
+// printStatus writes a table with the status of every migration.
+func printStatus(w io.Writer, statuses []migrations.Status) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,109 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// FS holds the SQL migrations embedded into the binary.
+//
+//go:embed sql/*.sql
+var FS embed.FS
+
+// migrationsDir is the directory of FS holding the SQL migrations.
+const migrationsDir = "sql"
+
+// fileNameRegexp matches the migration file names, like 0001_create_client.up.sql.
+var fileNameRegexp = regexp.MustCompile(`^([0-9]+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration represents a versioned schema change with the SQL to apply and revert it.
+type Migration struct {
+	Version int    // Version of the migration, migrations are applied in ascending order.
+	Name    string // Descriptive name of the migration.
+	Up      string // SQL applying the migration.
+	Down    string // SQL reverting the migration.
+}
+
+// Load reads the migrations of the embedded FS, sorted by version.
+func Load() (ms []Migration, err error) {
+	sub, err := fs.Sub(FS, migrationsDir)
+	if err != nil {
+		return
+	}
+	return Read(sub)
+}
+
+// Read reads the migrations in the root of the given file system, sorted by version.
+// Every migration needs both an up and a down file, and the versions must be sequential starting at one.
+func Read(fsys fs.FS) (ms []Migration, err error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		err = fmt.Errorf("failed to read migrations: %s", err)
+		return
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+
+		parts := fileNameRegexp.FindStringSubmatch(e.Name())
+		if parts == nil {
+			err = fmt.Errorf("invalid migration file name: %s", e.Name())
+			return
+		}
+		version, _ := strconv.Atoi(parts[1])
+
+		var content []byte
+		content, err = fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			err = fmt.Errorf("failed to read migration %s: %s", e.Name(), err)
+			return
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = m
+		} else if m.Name != parts[2] {
+			err = fmt.Errorf("invalid migration %d: name %s does not match %s", version, parts[2], m.Name)
+			return
+		}
+		if parts[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	ms = make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		ms = append(ms, *m)
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].Version < ms[j].Version
+	})
+
+	// Check every migration is complete and no version is missing.
+	for i, m := range ms {
+		switch {
+		case m.Version != i+1:
+			err = fmt.Errorf("invalid migration %d: expected version %d", m.Version, i+1)
+		case m.Up == "":
+			err = fmt.Errorf("invalid migration %d: missing up file", m.Version)
+		case m.Down == "":
+			err = fmt.Errorf("invalid migration %d: missing down file", m.Version)
+		}
+		if err != nil {
+			ms = nil
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	ms, err := Load()
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, ms) {
+		assert.Equal(t, 1, ms[0].Version)
+		assert.Equal(t, "create_client_and_product", ms[0].Name)
+		assert.Contains(t, ms[0].Up, "CREATE TABLE IF NOT EXISTS client")
+		assert.Contains(t, ms[0].Down, "DROP TABLE IF EXISTS client")
+	}
+}
+
+func TestRead(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		ms, err := Read(fstest.MapFS{
+			"0002_second.up.sql":   {Data: []byte("up 2")},
+			"0002_second.down.sql": {Data: []byte("down 2")},
+			"0001_first.up.sql":    {Data: []byte("up 1")},
+			"0001_first.down.sql":  {Data: []byte("down 1")},
+			"README.md":            {Data: []byte("ignored")},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []Migration{
+			{Version: 1, Name: "first", Up: "up 1", Down: "down 1"},
+			{Version: 2, Name: "second", Up: "up 2", Down: "down 2"},
+		}, ms)
+	})
+
+	t.Run("MissingDown", func(t *testing.T) {
+		_, err := Read(fstest.MapFS{
+			"0001_first.up.sql": {Data: []byte("up 1")},
+		})
+		assert.EqualError(t, err, "invalid migration 1: missing down file")
+	})
+
+	t.Run("MissingVersion", func(t *testing.T) {
+		_, err := Read(fstest.MapFS{
+			"0001_first.up.sql":   {Data: []byte("up 1")},
+			"0001_first.down.sql": {Data: []byte("down 1")},
+			"0003_third.up.sql":   {Data: []byte("up 3")},
+			"0003_third.down.sql": {Data: []byte("down 3")},
+		})
+		assert.EqualError(t, err, "invalid migration 3: expected version 2")
+	})
+
+	t.Run("InvalidFileName", func(t *testing.T) {
+		_, err := Read(fstest.MapFS{
+			"first.sql": {Data: []byte("up 1")},
+		})
+		assert.EqualError(t, err, "invalid migration file name: first.sql")
+	})
+
+	t.Run("NameMismatch", func(t *testing.T) {
+		_, err := Read(fstest.MapFS{
+			"0001_first.up.sql":   {Data: []byte("up 1")},
+			"0001_other.down.sql": {Data: []byte("down 1")},
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match")
+	})
+}
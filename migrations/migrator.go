@@ -0,0 +1,312 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// schemaMigrationsTable is the table keeping track of the applied migrations.
+const schemaMigrationsTable = "schema_migrations"
+
+// lockKey is the key of the advisory lock held while the migrations are applied or reverted.
+const lockKey = 7253194106
+
+// Status represents whether a migration was applied to the database.
+type Status struct {
+	Migration            // The migration.
+	AppliedAt *time.Time // Timestamp when the migration was applied, nil if it is pending.
+}
+
+// Migrator applies and reverts migrations on a PostgreSQL database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a new Migrator of the given migrations, sorted by version.
+func NewMigrator(db *sql.DB, migrations []Migration) Migrator {
+	return Migrator{
+		db:         db,
+		migrations: migrations,
+	}
+}
+
+// Up applies every pending migration, in ascending order, and returns the applied ones.
+// Each migration is applied in its own transaction, while the migrator holds the advisory lock.
+func (m Migrator) Up() (applied []Migration, err error) {
+	err = m.withLock(func() (err error) {
+		applied, err = m.up()
+		return
+	})
+	return
+}
+
+// up applies every pending migration, in ascending order, and returns the applied ones.
+func (m Migrator) up() (applied []Migration, err error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return
+	}
+
+	for _, s := range statuses {
+		if s.AppliedAt != nil {
+			continue
+		}
+
+		err = m.apply(s.Migration)
+		if err != nil {
+			return
+		}
+		applied = append(applied, s.Migration)
+	}
+	return
+}
+
+// Down reverts the last applied migration and returns it, while the migrator holds the advisory lock.
+// It returns a nil migration if no migration was applied.
+func (m Migrator) Down() (reverted *Migration, err error) {
+	err = m.withLock(func() (err error) {
+		reverted, err = m.down()
+		return
+	})
+	return
+}
+
+// down reverts the last applied migration and returns it.
+func (m Migrator) down() (reverted *Migration, err error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return
+	}
+
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if statuses[i].AppliedAt == nil {
+			continue
+		}
+
+		err = m.revert(statuses[i].Migration)
+		if err != nil {
+			return
+		}
+		reverted = &statuses[i].Migration
+		return
+	}
+	return
+}
+
+// Redo reverts and applies again the last applied migration and returns it, while the migrator holds the advisory lock.
+// It returns a nil migration if no migration was applied.
+func (m Migrator) Redo() (redone *Migration, err error) {
+	err = m.withLock(func() (err error) {
+		redone, err = m.down()
+		if err != nil || redone == nil {
+			return
+		}
+
+		err = m.apply(*redone)
+		return
+	})
+	return
+}
+
+// Status returns every known migration with the time it was applied, sorted by version.
+func (m Migrator) Status() (statuses []Status, err error) {
+	err = m.createTable()
+	if err != nil {
+		return
+	}
+
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return
+	}
+
+	statuses = make([]Status, len(m.migrations))
+	for i, mg := range m.migrations {
+		statuses[i] = Status{Migration: mg}
+		if at, ok := applied[mg.Version]; ok {
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return
+}
+
+// Pending returns the migrations not applied yet.
+func (m Migrator) Pending() (pending []Migration, err error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return
+	}
+
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			pending = append(pending, s.Migration)
+		}
+	}
+	return
+}
+
+// CheckCurrent returns an error if the database schema is behind the known migrations.
+func (m Migrator) CheckCurrent() (err error) {
+	pending, err := m.Pending()
+	if err != nil {
+		return
+	}
+	if len(pending) > 0 {
+		err = fmt.Errorf("database schema is behind: %d pending migrations, starting at version %d", len(pending), pending[0].Version)
+	}
+	return
+}
+
+// apply runs the up SQL of a migration and records it, in a single transaction.
+func (m Migrator) apply(mg Migration) (err error) {
+	return m.inTx(func(tx *sql.Tx) (err error) {
+		_, err = tx.Exec(mg.Up)
+		if err != nil {
+			err = fmt.Errorf("failed to apply migration %d %s: %s", mg.Version, mg.Name, err)
+			return
+		}
+
+		query := fmt.Sprintf(`
+			insert into
+				%s(version, name, applied_at)
+			values
+				($1, $2, $3)
+		`, schemaMigrationsTable)
+		_, err = tx.Exec(query, mg.Version, mg.Name, time.Now())
+		if err != nil {
+			err = fmt.Errorf("failed to record migration %d %s: %s", mg.Version, mg.Name, err)
+		}
+		return
+	})
+}
+
+// revert runs the down SQL of a migration and removes its record, in a single transaction.
+func (m Migrator) revert(mg Migration) (err error) {
+	return m.inTx(func(tx *sql.Tx) (err error) {
+		_, err = tx.Exec(mg.Down)
+		if err != nil {
+			err = fmt.Errorf("failed to revert migration %d %s: %s", mg.Version, mg.Name, err)
+			return
+		}
+
+		query := fmt.Sprintf(`
+			delete from
+				%s
+			where
+				version = $1
+		`, schemaMigrationsTable)
+		_, err = tx.Exec(query, mg.Version)
+		if err != nil {
+			err = fmt.Errorf("failed to remove migration record %d %s: %s", mg.Version, mg.Name, err)
+		}
+		return
+	})
+}
+
+// inTx runs fn in a transaction, committing it if fn succeeds and rolling it back otherwise.
+func (m Migrator) inTx(fn func(tx *sql.Tx) error) (err error) {
+	tx, err := m.db.Begin()
+	if err != nil {
+		err = fmt.Errorf("failed to begin migration transaction: %s", err)
+		return
+	}
+
+	err = fn(tx)
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		err = fmt.Errorf("failed to commit migration transaction: %s", err)
+	}
+	return
+}
+
+// withLock runs fn while holding the session advisory lock of the migrations, so the migrators of
+// other instances wait for it to finish instead of applying the same versions.
+// The lock is taken and released on a dedicated connection, which is kept until fn returns.
+func (m Migrator) withLock(fn func() error) (err error) {
+	ctx := context.Background()
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to get migration lock connection: %s", err)
+		return
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "select pg_advisory_lock($1)", lockKey)
+	if err != nil {
+		err = fmt.Errorf("failed to take migration lock: %s", err)
+		return
+	}
+
+	err = fn()
+
+	_, unlockErr := conn.ExecContext(ctx, "select pg_advisory_unlock($1)", lockKey)
+	if unlockErr != nil {
+		// Discard the connection, so its session ends and the lock is released with it.
+		conn.Raw(func(driverConn any) error {
+			return driver.ErrBadConn
+		})
+		if err == nil {
+			err = fmt.Errorf("failed to release migration lock: %s", unlockErr)
+		}
+	}
+	return
+}
+
+// createTable creates the table keeping track of the applied migrations, if it does not exist.
+func (m Migrator) createTable() (err error) {
+	query := fmt.Sprintf(`
+		create table if not exists %s (
+			version integer not null,
+			name varchar not null,
+			applied_at timestamp not null,
+
+			primary key (version)
+		)
+	`, schemaMigrationsTable)
+
+	_, err = m.db.Exec(query)
+	if err != nil {
+		err = fmt.Errorf("failed to create %s table: %s", schemaMigrationsTable, err)
+	}
+	return
+}
+
+// appliedVersions returns the time each applied migration was applied, by version.
+func (m Migrator) appliedVersions() (applied map[int]time.Time, err error) {
+	query := fmt.Sprintf(`
+		select
+			version, applied_at
+		from
+			%s
+	`, schemaMigrationsTable)
+
+	rows, err := m.db.Query(query)
+	if err != nil {
+		err = fmt.Errorf("failed to get applied migrations: %s", err)
+		return
+	}
+	defer rows.Close()
+
+	applied = make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at time.Time
+		err = rows.Scan(&version, &at)
+		if err != nil {
+			err = fmt.Errorf("failed to scan applied migrations: %s", err)
+			return
+		}
+		applied[version] = at
+	}
+	err = rows.Err()
+	return
+}
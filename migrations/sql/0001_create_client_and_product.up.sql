@@ -21,6 +21,6 @@ CREATE TABLE IF NOT EXISTS product (
     port integer,
     vault integer,
     quantity integer not null,
- 
+
     primary key (id)
-);
\ No newline at end of file
+);
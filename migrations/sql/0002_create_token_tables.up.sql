@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS refresh_token (
+    id serial not null unique,
+    client_id integer not null,
+    family_id varchar not null,
+    token_hash varchar not null unique,
+    expires_at timestamp not null,
+    revoked_at timestamp,
+    created_at timestamp not null,
+
+    primary key (id)
+);
+
+CREATE INDEX IF NOT EXISTS refresh_token_family_id_idx ON refresh_token (family_id);
+
+CREATE TABLE IF NOT EXISTS revoked_token (
+    jti varchar not null unique,
+    expires_at timestamp,
+    revoked_at timestamp not null,
+
+    primary key (jti)
+);
@@ -0,0 +1 @@
+ALTER TABLE client ADD COLUMN IF NOT EXISTS role varchar not null default 'client';
@@ -0,0 +1,3 @@
+ALTER TABLE product ADD COLUMN IF NOT EXISTS status varchar not null default 'REGISTERED';
+
+CREATE INDEX IF NOT EXISTS product_status_idx ON product (status);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS product_event (
+    id serial not null unique,
+    product_id integer not null,
+    recorded_at timestamp not null,
+    port integer,
+    vault integer,
+    vehicle_plate varchar,
+    note varchar not null default '',
+    recorded_by integer not null,
+
+    primary key (id)
+);
+
+CREATE INDEX IF NOT EXISTS product_event_product_id_idx ON product_event (product_id, recorded_at);
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS pricing_rule (
+    id serial not null unique,
+    name varchar not null unique,
+    kind varchar not null,
+    priority integer not null default 0,
+    percentage numeric(9, 5) not null default 0,
+    amount numeric(19, 5) not null default 0,
+    min_quantity integer,
+    max_quantity integer,
+    location varchar not null default '',
+    ports integer[],
+    vaults integer[],
+    types varchar[],
+    client_ids integer[],
+    valid_from timestamp,
+    valid_until timestamp,
+    stop boolean not null default false,
+    active boolean not null default true,
+
+    primary key (id)
+);
+
+INSERT INTO pricing_rule (name, kind, priority, percentage, min_quantity, location, stop) VALUES
+    ('bulk_vault', 'discount', 10, 5, 10, 'vault', true),
+    ('bulk_port', 'discount', 20, 3, 10, 'port', true)
+ON CONFLICT (name) DO NOTHING;
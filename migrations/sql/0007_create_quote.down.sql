@@ -0,0 +1,3 @@
+ALTER TABLE product DROP COLUMN IF EXISTS quote_id;
+
+DROP TABLE IF EXISTS quote;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS quote (
+    id serial not null unique,
+    client_id integer not null,
+    product jsonb not null,
+    shipping_price numeric(19, 5) not null,
+    discount numeric(19, 5) not null,
+    surcharge numeric(19, 5) not null,
+    taxes numeric(19, 5) not null,
+    total numeric(19, 5) not null,
+    lines jsonb not null,
+    expires_at timestamp not null,
+    used_at timestamp,
+    created_at timestamp not null,
+
+    primary key (id)
+);
+
+ALTER TABLE product ADD COLUMN IF NOT EXISTS quote_id integer;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS product_vehicle_plate_trgm_idx;
+
+DROP INDEX IF EXISTS product_guide_number_trgm_idx;
+
+DROP INDEX IF EXISTS product_search_vector_idx;
+
+ALTER TABLE product DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,11 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE product ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', coalesce(type, '') || ' ' || coalesce(guide_number, '') || ' ' || coalesce(vehicle_plate, ''))
+) STORED;
+
+CREATE INDEX IF NOT EXISTS product_search_vector_idx ON product USING gin (search_vector);
+
+CREATE INDEX IF NOT EXISTS product_guide_number_trgm_idx ON product USING gin (guide_number gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS product_vehicle_plate_trgm_idx ON product USING gin (vehicle_plate gin_trgm_ops);
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS saved_search (
+    id serial not null unique,
+    client_id integer not null,
+    scope_id integer not null default 0,
+    name varchar not null,
+    criteria jsonb not null,
+    schedule varchar not null default '',
+    webhook_url varchar not null default '',
+    next_run_at timestamp,
+    last_run_at timestamp,
+    runs integer not null default 0,
+    last_product_id integer not null default 0,
+    created_at timestamp not null,
+
+    primary key (id)
+);
+
+CREATE INDEX IF NOT EXISTS saved_search_client_id_idx ON saved_search (client_id);
+CREATE INDEX IF NOT EXISTS saved_search_next_run_at_idx ON saved_search (next_run_at) WHERE next_run_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS saved_search_digest (
+    id serial not null unique,
+    saved_search_id integer not null,
+    client_id integer not null,
+    run integer not null,
+    product_ids integer[] not null,
+    total integer not null,
+    ran_at timestamp not null,
+
+    primary key (id),
+    unique (saved_search_id, run)
+);
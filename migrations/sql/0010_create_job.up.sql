@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS job (
+    id serial not null unique,
+    client_id integer not null,
+    kind varchar not null,
+    status varchar not null default 'QUEUED',
+    params jsonb not null,
+    input bytea,
+    progress integer not null default 0,
+    attempts integer not null default 0,
+    max_attempts integer not null,
+    error varchar not null default '',
+    result_name varchar not null default '',
+    result_type varchar not null default '',
+    result bytea,
+    run_at timestamp not null,
+    locked_until timestamp,
+    created_at timestamp not null,
+    started_at timestamp,
+    finished_at timestamp,
+
+    primary key (id)
+);
+
+CREATE INDEX IF NOT EXISTS job_client_id_idx ON job (client_id);
+CREATE INDEX IF NOT EXISTS job_queued_run_at_idx ON job (run_at) WHERE status = 'QUEUED';
+CREATE INDEX IF NOT EXISTS job_running_locked_until_idx ON job (locked_until) WHERE status = 'RUNNING';
@@ -18,7 +18,10 @@ type Product struct {
 	VehiclePlate  *string    `json:"vehicle_plate,omitempty"`  // Vehicle plate associated with the product, can be nil.
 	Port          *int       `json:"port,omitempty"`           // Port associated with the product, can be nil.
 	Vault         *int       `json:"vault,omitempty"`          // Vault associated with the product, can be nil.
+	Status        Status     `json:"status,omitempty"`         // Stage of the shipment lifecycle the product is in.
 	Discount      float64    `json:"discount,omitempty"`       // Discount applied to the product.
+	Pricing       *Pricing   `json:"pricing,omitempty"`        // Itemised price of the product, can be nil.
+	QuoteID       *int       `json:"quote_id,omitempty"`       // Identifier of the quote locking the price, can be nil.
 }
 
 // New creates a new Product instance while validating certain fields.
@@ -51,12 +54,19 @@ func New(productR Product) (product Product, err error) {
 		}
 	}
 
+	if productR.VehiclePlate == nil {
+		err = fmt.Errorf("invalid vehicle plate: vehicle plate cannot be empty")
+		return
+	}
 	err = ValidateVehiclePlate(productR.VehiclePlate)
 	if err != nil {
 		return
 	}
 
 	product = productR // Assign the validated product to the result.
+
+	// Every shipment starts its lifecycle as registered.
+	product.Status = REGISTERED_STATUS
 	return
 }
 
@@ -79,45 +89,62 @@ func Update(productR Product) (product Product, err error) {
 	}
 
 	product = productR // If validations are successful, assign the updated product.
+	// The status can only be changed through a transition.
+	product.Status = ""
 	return
 }
 
 // DiscountGenerator is an interface for generating discounts.
 type DiscountGenerator interface {
 	Generate() (discount float64) // Generate calculates and returns the discount.
+	Pricing() (pricing Pricing)   // Pricing calculates and returns the itemised price.
 }
 
-// DiscountGeneratorImpl is an implementation of the DiscountGenerator interface.
-type DiscountGeneratorImpl struct {
-	quantity      int     // The quantity of products.
-	vault         int     // The number of vaults.
-	port          int     // The number of ports.
-	shippingPrice float64 // The shipping price.
+// RulesDiscountGenerator is an implementation of the DiscountGenerator interface backed by a RulesEngine.
+type RulesDiscountGenerator struct {
+	engine  RulesEngine // The engine evaluating the pricing rules.
+	product Product     // The product to price.
+	now     time.Time   // The time the rules are evaluated at.
 }
 
-// Generate calculates the discount based on certain conditions.
-func (dgi DiscountGeneratorImpl) Generate() (discount float64) {
-	// Check if the quantity is less than 10.
-	if dgi.quantity < 10 {
-		return // Return without applying any discount.
-	}
+// Generate calculates the discount of the product with the pricing rules.
+func (rdg RulesDiscountGenerator) Generate() (discount float64) {
+	return rdg.Pricing().Discount
+}
 
-	// Check if there are vaults or ports for potential discounts.
-	if dgi.vault > 0 {
-		discount = dgi.shippingPrice * 0.05 // Apply a discount of 5%.
-	} else if dgi.port > 0 {
-		discount = dgi.shippingPrice * 0.03 // Apply a discount of 3%.
-	}
+// Pricing calculates the itemised price of the product with the pricing rules.
+func (rdg RulesDiscountGenerator) Pricing() (pricing Pricing) {
+	return rdg.engine.Price(rdg.product, rdg.now)
+}
 
-	return // Return the calculated discount.
+// LockedDiscountGenerator is an implementation of the DiscountGenerator interface returning a fixed price,
+// used for the products whose price was locked by a quote.
+type LockedDiscountGenerator struct {
+	pricing Pricing // The locked itemised price.
+}
+
+// Generate returns the locked discount.
+func (ldg LockedDiscountGenerator) Generate() (discount float64) {
+	return ldg.pricing.Discount
+}
+
+// Pricing returns the locked itemised price.
+func (ldg LockedDiscountGenerator) Pricing() (pricing Pricing) {
+	return ldg.pricing
+}
+
+// NewLockedDiscountGenerator creates a new DiscountGenerator instance returning the given price.
+func NewLockedDiscountGenerator(pricing Pricing) DiscountGenerator {
+	return LockedDiscountGenerator{
+		pricing: pricing,
+	}
 }
 
 // NewDiscountGenerator creates a new DiscountGenerator instance.
-func NewDiscountGenerator(vault, port, quantity int, shippingPrice float64) DiscountGenerator {
-	return DiscountGeneratorImpl{
-		vault:         vault,
-		quantity:      quantity,
-		port:          port,
-		shippingPrice: shippingPrice,
+func NewDiscountGenerator(engine RulesEngine, product Product, now time.Time) DiscountGenerator {
+	return RulesDiscountGenerator{
+		engine:  engine,
+		product: product,
+		now:     now,
 	}
 }
@@ -60,7 +60,9 @@ func TestNewProduct(t *testing.T) {
 	t.Run("ValidProduct", func(t *testing.T) {
 		product, err := New(validProduct)
 		assert.NoError(t, err)
-		assert.Equal(t, validProduct, product)
+		expected := validProduct
+		expected.Status = REGISTERED_STATUS
+		assert.Equal(t, expected, product)
 	})
 
 	t.Run("InvalidClientID", func(t *testing.T) {
@@ -84,6 +86,12 @@ func TestNewProduct(t *testing.T) {
 		assert.Empty(t, product)
 	})
 
+	t.Run("MissingVehiclePlate", func(t *testing.T) {
+		product, err := New(Product{ClientID: 1, GuideNumber: newString("ABC1234567")})
+		assert.EqualError(t, err, "invalid vehicle plate: vehicle plate cannot be empty")
+		assert.Empty(t, product)
+	})
+
 	t.Run("InvalidPort", func(t *testing.T) {
 		product, err := New(invalidPort)
 		assert.Error(t, err)
@@ -0,0 +1,277 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RuleKind represents whether a pricing rule lowers or raises the shipping price.
+type RuleKind string
+
+// Pricing rule kinds.
+const (
+	DISCOUNT_RULE  RuleKind = "discount"  // The rule lowers the shipping price.
+	SURCHARGE_RULE RuleKind = "surcharge" // The rule raises the shipping price.
+)
+
+// Locations a pricing rule can be restricted to.
+const (
+	ANY_LOCATION   = ""      // The rule applies wherever the product is stored.
+	PORT_LOCATION  = "port"  // The rule only applies to products assigned to a port.
+	VAULT_LOCATION = "vault" // The rule only applies to products assigned to a vault.
+)
+
+// PricingRule represents a discount or surcharge applied to the products matching its conditions.
+// Every empty condition matches any product.
+type PricingRule struct {
+	ID          int        `json:"id,omitempty" yaml:"-"`                      // Unique identifier for the rule, only set for stored rules.
+	Name        string     `json:"name" yaml:"name"`                           // Name of the rule, shown in the price breakdown.
+	Kind        RuleKind   `json:"kind" yaml:"kind"`                           // Whether the rule is a discount or a surcharge.
+	Percentage  float64    `json:"percentage,omitempty" yaml:"percentage"`     // Percentage of the shipping price to apply.
+	Amount      float64    `json:"amount,omitempty" yaml:"amount"`             // Fixed amount to apply.
+	MinQuantity *int       `json:"min_quantity,omitempty" yaml:"min_quantity"` // Minimum quantity (inclusive), can be nil.
+	MaxQuantity *int       `json:"max_quantity,omitempty" yaml:"max_quantity"` // Maximum quantity (inclusive), can be nil.
+	Location    string     `json:"location,omitempty" yaml:"location"`         // Kind of location the product must be assigned to.
+	Ports       []int      `json:"ports,omitempty" yaml:"ports"`               // Ports the product must be assigned to.
+	Vaults      []int      `json:"vaults,omitempty" yaml:"vaults"`             // Vaults the product must be assigned to.
+	Types       []string   `json:"types,omitempty" yaml:"types"`               // Types the product must be of.
+	ClientIDs   []int      `json:"client_ids,omitempty" yaml:"client_ids"`     // Clients the product must belong to.
+	ValidFrom   *time.Time `json:"valid_from,omitempty" yaml:"valid_from"`     // Start of the window of shipment dates (inclusive), can be nil.
+	ValidUntil  *time.Time `json:"valid_until,omitempty" yaml:"valid_until"`   // End of the window of shipment dates (exclusive), can be nil.
+	Stop        bool       `json:"stop,omitempty" yaml:"stop"`                 // Whether no further rules are evaluated after this one matches.
+}
+
+// PriceLine represents a single applied rule in a price breakdown.
+type PriceLine struct {
+	Rule   string   `json:"rule"`   // Name of the applied rule.
+	Kind   RuleKind `json:"kind"`   // Whether the line is a discount or a surcharge.
+	Amount float64  `json:"amount"` // Amount discounted or surcharged.
+}
+
+// Pricing represents the itemised price of a product.
+type Pricing struct {
+	ShippingPrice float64     `json:"shipping_price"` // Shipping price before any rule.
+	Lines         []PriceLine `json:"lines"`          // Applied rules, in evaluation order.
+	Discount      float64     `json:"discount"`       // Sum of every applied discount.
+	Surcharge     float64     `json:"surcharge"`      // Sum of every applied surcharge.
+	Total         float64     `json:"total"`          // Shipping price after every rule, never negative.
+}
+
+// RulesEngine evaluates an ordered list of pricing rules.
+type RulesEngine struct {
+	rules []PricingRule
+}
+
+// NewRulesEngine creates a new RulesEngine evaluating the given rules in order.
+func NewRulesEngine(rules []PricingRule) (engine RulesEngine, err error) {
+	for _, r := range rules {
+		err = ValidatePricingRule(r)
+		if err != nil {
+			return
+		}
+	}
+	engine = RulesEngine{
+		rules: rules,
+	}
+	return
+}
+
+// Price evaluates the rules against a product and returns its itemised price.
+// The validity windows of the rules are checked against the date the product joined,
+// or the given time if it has none yet, so the price of a shipment does not change once registered.
+func (re RulesEngine) Price(p Product, now time.Time) (pricing Pricing) {
+	if p.ShippingPrice != nil {
+		pricing.ShippingPrice = *p.ShippingPrice
+	}
+	pricing.Lines = make([]PriceLine, 0)
+
+	for _, r := range re.rules {
+		if !r.matches(p, now) {
+			continue
+		}
+
+		amount := pricing.ShippingPrice*r.Percentage/100 + r.Amount
+		pricing.Lines = append(pricing.Lines, PriceLine{
+			Rule:   r.Name,
+			Kind:   r.Kind,
+			Amount: amount,
+		})
+		if r.Kind == DISCOUNT_RULE {
+			pricing.Discount += amount
+		} else {
+			pricing.Surcharge += amount
+		}
+
+		// Stop evaluating the next rules if the rule is final.
+		if r.Stop {
+			break
+		}
+	}
+
+	pricing.Total = math.Max(0, pricing.ShippingPrice-pricing.Discount+pricing.Surcharge)
+	return
+}
+
+// matches reports whether every condition of the rule holds for the product.
+// The validity window is checked against the date the product joined, or the given time if it has none.
+func (r PricingRule) matches(p Product, now time.Time) bool {
+	var quantity, port, vault int
+	var productType string
+	shippedAt := now
+	if p.JoinedAt != nil {
+		shippedAt = *p.JoinedAt
+	}
+	if p.Quantity != nil {
+		quantity = *p.Quantity
+	}
+	if p.Port != nil {
+		port = *p.Port
+	}
+	if p.Vault != nil {
+		vault = *p.Vault
+	}
+	if p.Type != nil {
+		productType = *p.Type
+	}
+
+	switch {
+	case r.MinQuantity != nil && quantity < *r.MinQuantity:
+		return false
+	case r.MaxQuantity != nil && quantity > *r.MaxQuantity:
+		return false
+	case r.Location == PORT_LOCATION && port <= 0:
+		return false
+	case r.Location == VAULT_LOCATION && vault <= 0:
+		return false
+	case len(r.Ports) > 0 && !contains(r.Ports, port):
+		return false
+	case len(r.Vaults) > 0 && !contains(r.Vaults, vault):
+		return false
+	case len(r.Types) > 0 && !contains(r.Types, productType):
+		return false
+	case len(r.ClientIDs) > 0 && !contains(r.ClientIDs, p.ClientID):
+		return false
+	case r.ValidFrom != nil && shippedAt.Before(*r.ValidFrom):
+		return false
+	case r.ValidUntil != nil && !shippedAt.Before(*r.ValidUntil):
+		return false
+	}
+	return true
+}
+
+// ValidatePricingRule validates the fields of a pricing rule.
+func ValidatePricingRule(r PricingRule) (err error) {
+	switch {
+	case r.Name == "":
+		err = fmt.Errorf("invalid pricing rule: name cannot be empty")
+	case r.Kind != DISCOUNT_RULE && r.Kind != SURCHARGE_RULE:
+		err = fmt.Errorf("invalid pricing rule %s: unknown kind %s", r.Name, r.Kind)
+	case r.Percentage < 0 || r.Amount < 0:
+		err = fmt.Errorf("invalid pricing rule %s: percentage and amount must be positive numbers", r.Name)
+	case r.Percentage == 0 && r.Amount == 0:
+		err = fmt.Errorf("invalid pricing rule %s: percentage or amount must be provided", r.Name)
+	case r.MinQuantity != nil && r.MaxQuantity != nil && *r.MinQuantity > *r.MaxQuantity:
+		err = fmt.Errorf("invalid pricing rule %s: min quantity is greater than max quantity", r.Name)
+	case r.Location != ANY_LOCATION && r.Location != PORT_LOCATION && r.Location != VAULT_LOCATION:
+		err = fmt.Errorf("invalid pricing rule %s: unknown location %s", r.Name, r.Location)
+	case r.ValidFrom != nil && r.ValidUntil != nil && !r.ValidFrom.Before(*r.ValidUntil):
+		err = fmt.Errorf("invalid pricing rule %s: valid from must be before valid until", r.Name)
+	}
+	return
+}
+
+// DefaultPricingRules returns the rules applied before the pricing rules were configurable:
+// 5% off for 10 or more products in a vault, otherwise 3% off for 10 or more products in a port.
+func DefaultPricingRules() []PricingRule {
+	minQuantity := 10
+	return []PricingRule{
+		{
+			Name:        "bulk_vault",
+			Kind:        DISCOUNT_RULE,
+			Percentage:  5,
+			MinQuantity: &minQuantity,
+			Location:    VAULT_LOCATION,
+			Stop:        true,
+		},
+		{
+			Name:        "bulk_port",
+			Kind:        DISCOUNT_RULE,
+			Percentage:  3,
+			MinQuantity: &minQuantity,
+			Location:    PORT_LOCATION,
+			Stop:        true,
+		},
+	}
+}
+
+// rulesFile represents the content of a pricing rules YAML file.
+type rulesFile struct {
+	Rules []PricingRule `yaml:"rules"` // Pricing rules, in evaluation order.
+}
+
+// ReadPricingRules reads and validates the pricing rules of a YAML document.
+func ReadPricingRules(r io.Reader) (rules []PricingRule, err error) {
+	var f rulesFile
+	err = yaml.NewDecoder(r).Decode(&f)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("failed to read pricing rules: %s", err)
+		return
+	}
+	err = nil
+
+	for _, r := range f.Rules {
+		err = ValidatePricingRule(r)
+		if err != nil {
+			return
+		}
+	}
+	rules = f.Rules
+	return
+}
+
+// FileRuleSource provides the pricing rules loaded from a YAML file.
+type FileRuleSource struct {
+	rules []PricingRule
+}
+
+// NewFileRuleSource loads the pricing rules of the YAML file at the given path.
+func NewFileRuleSource(path string) (source FileRuleSource, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		err = fmt.Errorf("failed to open pricing rules file: %s", err)
+		return
+	}
+	defer f.Close()
+
+	rules, err := ReadPricingRules(f)
+	if err != nil {
+		return
+	}
+	source = FileRuleSource{
+		rules: rules,
+	}
+	return
+}
+
+// GetRules returns the loaded pricing rules, in evaluation order.
+func (frs FileRuleSource) GetRules(ctx context.Context) (rules []PricingRule, err error) {
+	rules = make([]PricingRule, len(frs.rules))
+	copy(rules, frs.rules)
+	return
+}
+
+// contains reports whether v is one of the values of s.
+func contains[T comparable](s []T, v T) bool {
+	for _, sv := range s {
+		if sv == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package product
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRulesEngine_Price(t *testing.T) {
+	price := 100.0
+	quantity := 12
+	fewQuantity := 5
+	port := 2
+	vault := 4
+	productType := "Electronics"
+
+	t.Run("DefaultRulesVault", func(t *testing.T) {
+		engine, err := NewRulesEngine(DefaultPricingRules())
+		assert.NoError(t, err)
+
+		pricing := engine.Price(Product{Quantity: &quantity, Vault: &vault, Port: &port, ShippingPrice: &price}, time.Now())
+		assert.Equal(t, 5.0, pricing.Discount)
+		assert.Equal(t, 95.0, pricing.Total)
+		assert.Equal(t, []PriceLine{{Rule: "bulk_vault", Kind: DISCOUNT_RULE, Amount: 5}}, pricing.Lines)
+	})
+
+	t.Run("DefaultRulesPort", func(t *testing.T) {
+		engine, err := NewRulesEngine(DefaultPricingRules())
+		assert.NoError(t, err)
+
+		pricing := engine.Price(Product{Quantity: &quantity, Port: &port, ShippingPrice: &price}, time.Now())
+		assert.Equal(t, 3.0, pricing.Discount)
+		assert.Equal(t, "bulk_port", pricing.Lines[0].Rule)
+	})
+
+	t.Run("DefaultRulesFewProducts", func(t *testing.T) {
+		engine, err := NewRulesEngine(DefaultPricingRules())
+		assert.NoError(t, err)
+
+		pricing := engine.Price(Product{Quantity: &fewQuantity, Vault: &vault, ShippingPrice: &price}, time.Now())
+		assert.Zero(t, pricing.Discount)
+		assert.Empty(t, pricing.Lines)
+		assert.Equal(t, price, pricing.Total)
+	})
+
+	t.Run("SurchargeAndConditions", func(t *testing.T) {
+		now := time.Now()
+		from := now.Add(-time.Hour)
+		until := now.Add(time.Hour)
+		engine, err := NewRulesEngine([]PricingRule{
+			{Name: "fragile", Kind: SURCHARGE_RULE, Amount: 10, Types: []string{productType}},
+			{Name: "other_client", Kind: DISCOUNT_RULE, Percentage: 50, ClientIDs: []int{99}},
+			{Name: "campaign", Kind: DISCOUNT_RULE, Percentage: 20, ValidFrom: &from, ValidUntil: &until},
+			{Name: "port_two", Kind: DISCOUNT_RULE, Amount: 1, Ports: []int{port}, Stop: true},
+			{Name: "never", Kind: DISCOUNT_RULE, Amount: 1},
+		})
+		assert.NoError(t, err)
+
+		p := Product{ClientID: 1, Type: &productType, Quantity: &fewQuantity, Port: &port, ShippingPrice: &price}
+		pricing := engine.Price(p, now)
+		assert.Equal(t, []PriceLine{
+			{Rule: "fragile", Kind: SURCHARGE_RULE, Amount: 10},
+			{Rule: "campaign", Kind: DISCOUNT_RULE, Amount: 20},
+			{Rule: "port_two", Kind: DISCOUNT_RULE, Amount: 1},
+		}, pricing.Lines)
+		assert.Equal(t, 21.0, pricing.Discount)
+		assert.Equal(t, 10.0, pricing.Surcharge)
+		assert.Equal(t, 89.0, pricing.Total)
+
+		// Out of the campaign window
+		pricing = engine.Price(p, until)
+		assert.Equal(t, 1.0, pricing.Discount)
+
+		// The window is checked against the date the product joined, if any, instead of the given time.
+		joinedAt := from.Add(-time.Minute)
+		p.JoinedAt = &joinedAt
+		pricing = engine.Price(p, now)
+		assert.Equal(t, 1.0, pricing.Discount)
+		joinedAt = now
+		pricing = engine.Price(p, until.Add(time.Hour))
+		assert.Equal(t, 21.0, pricing.Discount)
+	})
+
+	t.Run("TotalNeverNegative", func(t *testing.T) {
+		engine, err := NewRulesEngine([]PricingRule{{Name: "free", Kind: DISCOUNT_RULE, Amount: 500}})
+		assert.NoError(t, err)
+
+		pricing := engine.Price(Product{ShippingPrice: &price}, time.Now())
+		assert.Zero(t, pricing.Total)
+	})
+}
+
+func TestValidatePricingRule(t *testing.T) {
+	min := 10
+	max := 5
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		rule PricingRule
+		err  string
+	}{
+		{"Valid", PricingRule{Name: "valid", Kind: DISCOUNT_RULE, Percentage: 5}, ""},
+		{"EmptyName", PricingRule{Kind: DISCOUNT_RULE, Percentage: 5}, "name cannot be empty"},
+		{"UnknownKind", PricingRule{Name: "r", Kind: "gift", Percentage: 5}, "unknown kind"},
+		{"NegativeAmount", PricingRule{Name: "r", Kind: DISCOUNT_RULE, Amount: -1}, "must be positive"},
+		{"NoAmount", PricingRule{Name: "r", Kind: DISCOUNT_RULE}, "must be provided"},
+		{"QuantityRange", PricingRule{Name: "r", Kind: DISCOUNT_RULE, Amount: 1, MinQuantity: &min, MaxQuantity: &max}, "min quantity"},
+		{"UnknownLocation", PricingRule{Name: "r", Kind: DISCOUNT_RULE, Amount: 1, Location: "airport"}, "unknown location"},
+		{"DateWindow", PricingRule{Name: "r", Kind: DISCOUNT_RULE, Amount: 1, ValidFrom: &now, ValidUntil: &now}, "valid from"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePricingRule(tt.rule)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.err)
+			}
+		})
+	}
+}
+
+func TestReadPricingRules(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		rules, err := ReadPricingRules(strings.NewReader(`
+rules:
+  - name: bulk_vault
+    kind: discount
+    percentage: 5
+    min_quantity: 10
+    location: vault
+    stop: true
+  - name: express
+    kind: surcharge
+    amount: 15
+    types: [Express]
+    valid_from: 2024-01-01T00:00:00Z
+`))
+		assert.NoError(t, err)
+		if assert.Len(t, rules, 2) {
+			assert.Equal(t, "bulk_vault", rules[0].Name)
+			assert.Equal(t, 10, *rules[0].MinQuantity)
+			assert.True(t, rules[0].Stop)
+			assert.Equal(t, SURCHARGE_RULE, rules[1].Kind)
+			assert.Equal(t, []string{"Express"}, rules[1].Types)
+			assert.Equal(t, 2024, rules[1].ValidFrom.Year())
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		rules, err := ReadPricingRules(strings.NewReader(""))
+		assert.NoError(t, err)
+		assert.Empty(t, rules)
+	})
+
+	t.Run("InvalidRule", func(t *testing.T) {
+		_, err := ReadPricingRules(strings.NewReader("rules:\n  - name: broken\n    kind: gift\n    amount: 1\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("FileSource", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rules.yaml")
+		err := os.WriteFile(path, []byte("rules:\n  - name: flat\n    kind: discount\n    amount: 2\n"), 0600)
+		assert.NoError(t, err)
+
+		source, err := NewFileRuleSource(path)
+		assert.NoError(t, err)
+		rules, err := source.GetRules(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []PricingRule{{Name: "flat", Kind: DISCOUNT_RULE, Amount: 2}}, rules)
+	})
+}
@@ -0,0 +1,55 @@
+package product
+
+import "fmt"
+
+// Status represents the stage of the shipment lifecycle a product is in.
+type Status string
+
+// Constants representing the shipment lifecycle stages.
+const (
+	REGISTERED_STATUS       Status = "REGISTERED"       // The shipment was registered but not dispatched yet.
+	IN_TRANSIT_STATUS       Status = "IN_TRANSIT"       // The shipment is on its way.
+	AT_PORT_STATUS          Status = "AT_PORT"          // The shipment is held at a port, e.g. at customs.
+	AT_VAULT_STATUS         Status = "AT_VAULT"         // The shipment is stored in a vault.
+	OUT_FOR_DELIVERY_STATUS Status = "OUT_FOR_DELIVERY" // The shipment left for its final destination.
+	DELIVERED_STATUS        Status = "DELIVERED"        // The shipment was delivered.
+	CANCELLED_STATUS        Status = "CANCELLED"        // The shipment was cancelled before being dispatched.
+	RETURNED_STATUS         Status = "RETURNED"         // The shipment was returned to the sender.
+)
+
+// transitions holds the statuses a product can move to from each status.
+// Statuses without entries are final.
+var transitions = map[Status][]Status{
+	REGISTERED_STATUS:       {IN_TRANSIT_STATUS, CANCELLED_STATUS},
+	IN_TRANSIT_STATUS:       {AT_PORT_STATUS, AT_VAULT_STATUS, RETURNED_STATUS},
+	AT_PORT_STATUS:          {AT_VAULT_STATUS, OUT_FOR_DELIVERY_STATUS, RETURNED_STATUS},
+	AT_VAULT_STATUS:         {OUT_FOR_DELIVERY_STATUS, RETURNED_STATUS},
+	OUT_FOR_DELIVERY_STATUS: {DELIVERED_STATUS, RETURNED_STATUS},
+	DELIVERED_STATUS:        {},
+	CANCELLED_STATUS:        {},
+	RETURNED_STATUS:         {},
+}
+
+// ValidateStatus checks if the provided status is one of the lifecycle stages.
+func ValidateStatus(s Status) (err error) {
+	if _, ok := transitions[s]; !ok {
+		err = fmt.Errorf("invalid status: unknown status %s", s)
+	}
+	return
+}
+
+// ValidateTransition checks if a product can move from one status to another.
+func ValidateTransition(from, to Status) (err error) {
+	err = ValidateStatus(to)
+	if err != nil {
+		return
+	}
+
+	for _, s := range transitions[from] {
+		if s == to {
+			return
+		}
+	}
+	err = fmt.Errorf("invalid transition: cannot move from %s to %s", from, to)
+	return
+}
@@ -0,0 +1,49 @@
+package product
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateStatus(t *testing.T) {
+	t.Run("ValidStatus", func(t *testing.T) {
+		err := ValidateStatus(AT_PORT_STATUS)
+		assert.NoError(t, err)
+	})
+
+	t.Run("InvalidStatus", func(t *testing.T) {
+		err := ValidateStatus("LOST")
+		assert.Error(t, err)
+		assert.EqualError(t, err, "invalid status: unknown status LOST")
+	})
+}
+
+func TestValidateTransition(t *testing.T) {
+	t.Run("ValidTransitions", func(t *testing.T) {
+		path := []Status{REGISTERED_STATUS, IN_TRANSIT_STATUS, AT_PORT_STATUS, AT_VAULT_STATUS, OUT_FOR_DELIVERY_STATUS, DELIVERED_STATUS}
+		for i := 1; i < len(path); i++ {
+			assert.NoError(t, ValidateTransition(path[i-1], path[i]))
+		}
+		assert.NoError(t, ValidateTransition(REGISTERED_STATUS, CANCELLED_STATUS))
+		assert.NoError(t, ValidateTransition(OUT_FOR_DELIVERY_STATUS, RETURNED_STATUS))
+	})
+
+	t.Run("IllegalTransition", func(t *testing.T) {
+		err := ValidateTransition(REGISTERED_STATUS, DELIVERED_STATUS)
+		assert.Error(t, err)
+		assert.EqualError(t, err, "invalid transition: cannot move from REGISTERED to DELIVERED")
+	})
+
+	t.Run("FinalStatus", func(t *testing.T) {
+		err := ValidateTransition(DELIVERED_STATUS, IN_TRANSIT_STATUS)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid transition")
+	})
+
+	t.Run("UnknownStatus", func(t *testing.T) {
+		err := ValidateTransition(REGISTERED_STATUS, "LOST")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid status")
+	})
+}
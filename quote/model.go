@@ -0,0 +1,124 @@
+package quote
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/product"
+)
+
+// Quote represents the price of a shipment issued before it is registered.
+type Quote struct {
+	ID            int                 `json:"id,omitempty"`        // Unique identifier for the quote.
+	ClientID      int                 `json:"client_id,omitempty"` // Identifier of the client the quote was issued for.
+	Product       product.Product     `json:"product"`             // Quoted shipment.
+	ShippingPrice float64             `json:"shipping_price"`      // Shipping price before any pricing rule.
+	Discount      float64             `json:"discount"`            // Sum of every applied discount.
+	Surcharge     float64             `json:"surcharge"`           // Sum of every applied surcharge.
+	Taxes         float64             `json:"taxes"`               // Taxes over the shipping price after the pricing rules.
+	Total         float64             `json:"total"`               // Final price, taxes included.
+	Rules         []string            `json:"rules"`               // Names of the applied pricing rules, in evaluation order.
+	Lines         []product.PriceLine `json:"lines"`               // Itemised applied pricing rules.
+	ExpiresAt     time.Time           `json:"expires_at"`          // Timestamp after which the price is no longer locked.
+	UsedAt        *time.Time          `json:"used_at,omitempty"`   // Timestamp when a product was created with the quote, can be nil.
+	CreatedAt     time.Time           `json:"created_at"`          // Timestamp when the quote was issued.
+}
+
+// New creates a new Quote for a validated product with its itemised price.
+// The taxRate is a percentage over the price after the pricing rules, and the lifespan is in hours.
+func New(p product.Product, pricing product.Pricing, taxRate float64, lifespan int, now time.Time) (quote Quote, err error) {
+	if taxRate < 0 {
+		err = fmt.Errorf("invalid tax rate: tax rate must be a positive number %f", taxRate)
+		return
+	}
+	if lifespan <= 0 {
+		err = fmt.Errorf("invalid quote lifespan: lifespan must be greater than zero %d", lifespan)
+		return
+	}
+
+	quote = Quote{
+		ClientID:      p.ClientID,
+		Product:       p,
+		ShippingPrice: pricing.ShippingPrice,
+		Discount:      pricing.Discount,
+		Surcharge:     pricing.Surcharge,
+		Taxes:         pricing.Total * taxRate / 100,
+		Rules:         make([]string, 0, len(pricing.Lines)),
+		Lines:         pricing.Lines,
+		ExpiresAt:     now.Add(time.Hour * time.Duration(lifespan)),
+		CreatedAt:     now,
+	}
+	quote.Total = pricing.Total + quote.Taxes
+	for _, l := range pricing.Lines {
+		quote.Rules = append(quote.Rules, l.Rule)
+	}
+
+	// The quoted product is not registered yet, so it has no lifecycle nor computed prices.
+	quote.Product.Status = ""
+	quote.Product.Discount = 0
+	quote.Product.Pricing = nil
+	quote.Product.QuoteID = nil
+	return
+}
+
+// Pricing returns the itemised price locked by the quote, without taxes.
+func (q Quote) Pricing() product.Pricing {
+	return product.Pricing{
+		ShippingPrice: q.ShippingPrice,
+		Lines:         q.Lines,
+		Discount:      q.Discount,
+		Surcharge:     q.Surcharge,
+		Total:         math.Max(0, q.ShippingPrice-q.Discount+q.Surcharge),
+	}
+}
+
+// Apply checks that a product can be created with the quote at the given time
+// and returns it with the quoted shipping price locked in.
+func (q Quote) Apply(pr product.Product, now time.Time) (p product.Product, err error) {
+	if q.UsedAt != nil {
+		err = fmt.Errorf("invalid quote: quote %d was already used", q.ID)
+		return
+	}
+	if !now.Before(q.ExpiresAt) {
+		err = fmt.Errorf("invalid quote: quote %d expired at %s", q.ID, q.ExpiresAt.Format(time.RFC3339))
+		return
+	}
+
+	err = q.checkProduct(pr)
+	if err != nil {
+		return
+	}
+
+	p = pr
+	p.ShippingPrice = &q.ShippingPrice
+	return
+}
+
+// checkProduct checks that the fields the price depends on did not change since the product was quoted.
+func (q Quote) checkProduct(p product.Product) (err error) {
+	qp := q.Product
+	switch {
+	case p.ClientID != qp.ClientID:
+		err = fmt.Errorf("invalid quote: client does not match the quoted one")
+	case !equal(p.Type, qp.Type):
+		err = fmt.Errorf("invalid quote: type does not match the quoted one")
+	case !equal(p.Quantity, qp.Quantity):
+		err = fmt.Errorf("invalid quote: quantity does not match the quoted one")
+	case !equal(p.Port, qp.Port):
+		err = fmt.Errorf("invalid quote: port does not match the quoted one")
+	case !equal(p.Vault, qp.Vault):
+		err = fmt.Errorf("invalid quote: vault does not match the quoted one")
+	case p.ShippingPrice != nil && *p.ShippingPrice != q.ShippingPrice:
+		err = fmt.Errorf("invalid quote: shipping price does not match the quoted one")
+	}
+	return
+}
+
+// equal reports whether two optional values are both nil or point to the same value.
+func equal[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,105 @@
+package quote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewQuote(t *testing.T) {
+	price := 200.0
+	quantity := 12
+	vault := 1
+	p := product.Product{
+		ClientID:      1,
+		GuideNumber:   newString("ABC1234567"),
+		VehiclePlate:  newString("ABC-123"),
+		Quantity:      &quantity,
+		Vault:         &vault,
+		ShippingPrice: &price,
+		Status:        product.REGISTERED_STATUS,
+	}
+	engine, _ := product.NewRulesEngine(product.DefaultPricingRules())
+	now := time.Now()
+	pricing := engine.Price(p, now)
+
+	t.Run("ValidQuote", func(t *testing.T) {
+		q, err := New(p, pricing, 10, 24, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, q.ClientID)
+		assert.Equal(t, 200.0, q.ShippingPrice)
+		assert.Equal(t, 10.0, q.Discount)
+		assert.Equal(t, 19.0, q.Taxes)
+		assert.Equal(t, 209.0, q.Total)
+		assert.Equal(t, []string{"bulk_vault"}, q.Rules)
+		assert.Equal(t, now.Add(24*time.Hour), q.ExpiresAt)
+		assert.Empty(t, q.Product.Status)
+		assert.Equal(t, pricing, q.Pricing())
+	})
+
+	t.Run("InvalidTaxRate", func(t *testing.T) {
+		_, err := New(p, pricing, -1, 24, now)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid tax rate")
+	})
+
+	t.Run("InvalidLifespan", func(t *testing.T) {
+		_, err := New(p, pricing, 0, 0, now)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid quote lifespan")
+	})
+}
+
+func TestQuote_Apply(t *testing.T) {
+	quantity := 12
+	otherQuantity := 3
+	otherPrice := 1.0
+	now := time.Now()
+	q := Quote{
+		ID:            1,
+		ClientID:      1,
+		Product:       product.Product{ClientID: 1, Quantity: &quantity},
+		ShippingPrice: 200,
+		ExpiresAt:     now.Add(time.Hour),
+	}
+
+	t.Run("LocksPrice", func(t *testing.T) {
+		p, err := q.Apply(product.Product{ClientID: 1, Quantity: &quantity}, now)
+		assert.NoError(t, err)
+		if assert.NotNil(t, p.ShippingPrice) {
+			assert.Equal(t, 200.0, *p.ShippingPrice)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		_, err := q.Apply(product.Product{ClientID: 1, Quantity: &quantity}, now.Add(time.Hour))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "expired")
+	})
+
+	t.Run("Used", func(t *testing.T) {
+		used := q
+		used.UsedAt = &now
+		_, err := used.Apply(product.Product{ClientID: 1, Quantity: &quantity}, now)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "already used")
+	})
+
+	t.Run("DifferentQuantity", func(t *testing.T) {
+		_, err := q.Apply(product.Product{ClientID: 1, Quantity: &otherQuantity}, now)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "quantity does not match")
+	})
+
+	t.Run("DifferentPrice", func(t *testing.T) {
+		_, err := q.Apply(product.Product{ClientID: 1, Quantity: &quantity, ShippingPrice: &otherPrice}, now)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "shipping price does not match")
+	})
+}
+
+func newString(s string) *string {
+	return &s
+}
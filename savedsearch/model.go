@@ -0,0 +1,162 @@
+package savedsearch
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/robfig/cron/v3"
+)
+
+// MAX_NAME_LENGTH is the maximum number of characters of the name of a saved search.
+const MAX_NAME_LENGTH = 100
+
+// MAX_DIGEST_PRODUCTS is the maximum number of product IDs listed by a digest, the rest are only counted.
+const MAX_DIGEST_PRODUCTS = 100
+
+// SavedSearch represents the named search criteria of a client, optionally run on a schedule.
+type SavedSearch struct {
+	ID            int             `json:"id,omitempty"`          // Unique identifier for the saved search.
+	ClientID      int             `json:"client_id,omitempty"`   // Identifier of the client the saved search belongs to.
+	ScopeID       int             `json:"scope_id,omitempty"`    // Identifier of the client whose products are searched, every client if zero.
+	Name          string          `json:"name"`                  // Name of the saved search.
+	Criteria      search.Criteria `json:"criteria"`              // Criteria of the search, validated again on every run.
+	Schedule      string          `json:"schedule,omitempty"`    // Cron expression of the runs, never run if empty.
+	WebhookURL    string          `json:"webhook_url,omitempty"` // URL every digest is posted to, if any.
+	NextRunAt     *time.Time      `json:"next_run_at,omitempty"` // Timestamp of the next scheduled run, nil if it is not scheduled.
+	LastRunAt     *time.Time      `json:"last_run_at,omitempty"` // Timestamp of the last run, nil if it never ran.
+	Runs          int             `json:"runs"`                  // Number of runs, the number of the last digest.
+	LastProductID int             `json:"-"`                     // Greatest ID of the products reported by the runs, or existing when it was saved.
+	CreatedAt     time.Time       `json:"created_at"`            // Timestamp when the search was saved.
+}
+
+// Digest represents the new products matching a saved search in one of its runs.
+// A product is new if its ID is greater than the ones of every product reported by the previous runs,
+// and of every product existing when the search was saved.
+type Digest struct {
+	ID            int       `json:"id,omitempty"`        // Unique identifier for the digest.
+	SavedSearchID int       `json:"saved_search_id"`     // Identifier of the saved search that was run.
+	ClientID      int       `json:"client_id,omitempty"` // Identifier of the client the saved search belongs to.
+	Run           int       `json:"run"`                 // Number of the run, starting at one.
+	ProductIDs    []int     `json:"product_ids"`         // IDs of the first MAX_DIGEST_PRODUCTS new products, in the order of the search.
+	Total         int       `json:"total"`               // Number of new products.
+	RanAt         time.Time `json:"ran_at"`              // Timestamp of the run.
+}
+
+// New creates a new saved search of a client at the given time, validating its name, criteria, schedule and webhook URL.
+// The products of the client scopeID are searched, or the ones of every client if it is zero.
+// A scheduled search is first run at the next time of the schedule after now.
+func New(clientID, scopeID int, name string, criteria search.Criteria, schedule, webhookURL string, now time.Time) (s SavedSearch, err error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > MAX_NAME_LENGTH {
+		err = fmt.Errorf("invalid name: name must have between 1 and %d characters", MAX_NAME_LENGTH)
+		return
+	}
+
+	// The criteria are run as they are stored, so they must be valid.
+	_, err = criteria.Search(scopeID)
+	if err != nil {
+		return
+	}
+
+	nextRunAt, err := nextRun(schedule, now)
+	if err != nil {
+		return
+	}
+
+	err = validateWebhookURL(webhookURL)
+	if err != nil {
+		return
+	}
+
+	s = SavedSearch{
+		ClientID:   clientID,
+		ScopeID:    scopeID,
+		Name:       name,
+		Criteria:   criteria,
+		Schedule:   schedule,
+		WebhookURL: webhookURL,
+		NextRunAt:  nextRunAt,
+		CreatedAt:  now,
+	}
+	return
+}
+
+// Results creates the search of every product matching the saved criteria.
+func (s SavedSearch) Results() (srch search.Search, err error) {
+	return s.Criteria.Search(s.ScopeID)
+}
+
+// Search creates the search of the products matching the saved criteria that are new for the next run.
+func (s SavedSearch) Search() (srch search.Search, err error) {
+	srch, err = s.Results()
+	if err != nil {
+		return
+	}
+	srch.AfterID = s.LastProductID
+	return
+}
+
+// Ran records a run of the saved search at the given time, which found total new products.
+// The ids are the ones of the first new products in the order of the search, and lastID is the greatest ID of every new product.
+// It returns the saved search with its next run scheduled, and the digest of the run.
+func (s SavedSearch) Ran(ids []int, total, lastID int, now time.Time) (ran SavedSearch, d Digest) {
+	if len(ids) > MAX_DIGEST_PRODUCTS {
+		ids = ids[:MAX_DIGEST_PRODUCTS]
+	}
+
+	ran = s
+	ran.Runs++
+	ran.LastRunAt = &now
+	if lastID > ran.LastProductID {
+		ran.LastProductID = lastID
+	}
+	// The schedule was validated when the search was saved, so it can not fail.
+	ran.NextRunAt, _ = nextRun(s.Schedule, now)
+
+	d = Digest{
+		SavedSearchID: s.ID,
+		ClientID:      s.ClientID,
+		Run:           ran.Runs,
+		ProductIDs:    append(make([]int, 0, len(ids)), ids...),
+		Total:         total,
+		RanAt:         now,
+	}
+	return
+}
+
+// nextRun returns the next time of the cron schedule after the given time, in UTC, or nil if there is no schedule.
+// The schedule has the five standard fields, or a descriptor like "@daily", optionally prefixed by a "CRON_TZ=" time zone.
+func nextRun(schedule string, after time.Time) (next *time.Time, err error) {
+	if schedule == "" {
+		return
+	}
+
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		err = fmt.Errorf("invalid schedule: %s", err)
+		return
+	}
+	t := sched.Next(after.UTC())
+	if t.IsZero() {
+		err = fmt.Errorf("invalid schedule: %s never runs", schedule)
+		return
+	}
+	next = &t
+	return
+}
+
+// validateWebhookURL checks that the webhook URL, if any, is an absolute HTTP or HTTPS URL.
+func validateWebhookURL(v string) (err error) {
+	if v == "" {
+		return
+	}
+
+	u, err := url.Parse(v)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		err = fmt.Errorf("invalid webhook url: %s is not an absolute http or https url", v)
+	}
+	return
+}
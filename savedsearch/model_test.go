@@ -0,0 +1,111 @@
+package savedsearch
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2023, time.March, 1, 7, 30, 0, 0, time.UTC)
+
+func TestNew(t *testing.T) {
+	t.Run("Scheduled", func(t *testing.T) {
+		s, err := New(1, 2, "  Boxes ", search.Criteria{Type: "box"}, "0 8 * * 1-5", "https://example.com/hooks", now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, s.ClientID)
+		assert.Equal(t, 2, s.ScopeID)
+		assert.Equal(t, "Boxes", s.Name)
+		assert.Equal(t, now, s.CreatedAt)
+		// The first of March of 2023 is a Wednesday.
+		require.NotNil(t, s.NextRunAt)
+		assert.Equal(t, time.Date(2023, time.March, 1, 8, 0, 0, 0, time.UTC), *s.NextRunAt)
+	})
+
+	t.Run("Unscheduled", func(t *testing.T) {
+		s, err := New(1, 1, "Boxes", search.Criteria{}, "", "", now)
+		require.NoError(t, err)
+		assert.Nil(t, s.NextRunAt)
+	})
+
+	t.Run("TimeZone", func(t *testing.T) {
+		s, err := New(1, 1, "Boxes", search.Criteria{}, "CRON_TZ=America/Caracas 0 8 * * *", "", now)
+		require.NoError(t, err)
+		require.NotNil(t, s.NextRunAt)
+		assert.Equal(t, time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC), s.NextRunAt.UTC())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		cases := map[string]struct {
+			name       string
+			criteria   search.Criteria
+			schedule   string
+			webhookURL string
+		}{
+			"invalid name":         {name: " "},
+			"invalid name: name":   {name: strings.Repeat("a", MAX_NAME_LENGTH+1)},
+			"invalid port":         {name: "Boxes", criteria: search.Criteria{Port: "nope"}},
+			"invalid schedule":     {name: "Boxes", schedule: "every day"},
+			"never runs":           {name: "Boxes", schedule: "0 0 30 2 *"},
+			"invalid webhook url":  {name: "Boxes", webhookURL: "example.com/hooks"},
+			"invalid webhook url:": {name: "Boxes", webhookURL: "ftp://example.com/hooks"},
+		}
+		for expected, c := range cases {
+			_, err := New(1, 1, c.name, c.criteria, c.schedule, c.webhookURL, now)
+			if assert.Error(t, err, expected) {
+				assert.Contains(t, err.Error(), strings.TrimSuffix(expected, ":"))
+			}
+		}
+	})
+}
+
+func TestSavedSearch_Search(t *testing.T) {
+	s, err := New(1, 2, "Boxes", search.Criteria{Type: "box"}, "", "", now)
+	require.NoError(t, err)
+	s.LastProductID = 7
+
+	// Only the products after the reported ones are new.
+	srch, err := s.Search()
+	require.NoError(t, err)
+	assert.Equal(t, 2, srch.ClientID)
+	assert.Equal(t, []string{"box"}, srch.Type.Values)
+	assert.Equal(t, 7, srch.AfterID)
+
+	srch, err = s.Results()
+	require.NoError(t, err)
+	assert.Zero(t, srch.AfterID)
+}
+
+func TestSavedSearch_Ran(t *testing.T) {
+	s, err := New(1, 1, "Boxes", search.Criteria{}, "@hourly", "", now)
+	require.NoError(t, err)
+	s.ID = 4
+	s.LastProductID = 9
+
+	ids := make([]int, MAX_DIGEST_PRODUCTS+5)
+	for i := range ids {
+		ids[i] = i + 10
+	}
+	ranAt := now.Add(time.Hour)
+	ran, d := s.Ran(ids, len(ids), ids[len(ids)-1], ranAt)
+
+	assert.Equal(t, 1, ran.Runs)
+	assert.Equal(t, ranAt, *ran.LastRunAt)
+	assert.Equal(t, time.Date(2023, time.March, 1, 9, 0, 0, 0, time.UTC), *ran.NextRunAt)
+	assert.Equal(t, ids[len(ids)-1], ran.LastProductID)
+
+	assert.Equal(t, 4, d.SavedSearchID)
+	assert.Equal(t, 1, d.Run)
+	assert.Equal(t, ids[:MAX_DIGEST_PRODUCTS], d.ProductIDs)
+	assert.Equal(t, len(ids), d.Total)
+	assert.Equal(t, ranAt, d.RanAt)
+
+	// A run without new products keeps the greatest reported ID.
+	ran, d = ran.Ran(nil, 0, 0, ranAt.Add(time.Hour))
+	assert.Equal(t, 2, d.Run)
+	assert.Equal(t, []int{}, d.ProductIDs)
+	assert.Equal(t, ids[len(ids)-1], ran.LastProductID)
+}
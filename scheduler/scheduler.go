@@ -0,0 +1,232 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/coffemanfp/docucentertest/search"
+)
+
+// PAGE_SIZE is the number of new products retrieved at once while running a saved search.
+const PAGE_SIZE = 500
+
+// BATCH_SIZE is the maximum number of due saved searches run on every tick, the rest wait for the next ones.
+const BATCH_SIZE = 100
+
+// WEBHOOK_TIMEOUT is the maximum duration of the delivery of a digest to a webhook.
+const WEBHOOK_TIMEOUT = 10 * time.Second
+
+// Scheduler runs the scheduled saved searches when they are due, storing their digests and pushing them to their webhooks.
+// Several schedulers can share a database, as every run of a saved search can only be saved once.
+// On every tick, it also purges the expired refresh tokens and revoked access tokens.
+type Scheduler struct {
+	savedSearches database.SavedSearchRepository // Repository of the saved searches and their digests
+	products      database.ProductRepository     // Repository of the products the saved searches are run on
+	auth          database.AuthRepository        // Repository of the tokens purged once expired
+	client        *http.Client                   // Client the digests are pushed to the webhooks with
+	interval      time.Duration                  // Duration between the checks for due saved searches
+}
+
+// New creates a new Scheduler checking the saved searches of the database every interval.
+func New(db database.Database, interval time.Duration) (s Scheduler, err error) {
+	savedSearches, err := database.GetRepository[database.SavedSearchRepository](db.Repositories, database.SAVED_SEARCH_REPOSITORY)
+	if err != nil {
+		return
+	}
+	products, err := database.GetRepository[database.ProductRepository](db.Repositories, database.PRODUCT_REPOSITORY)
+	if err != nil {
+		return
+	}
+	auth, err := database.GetRepository[database.AuthRepository](db.Repositories, database.AUTH_REPOSITORY)
+	if err != nil {
+		return
+	}
+
+	s = Scheduler{
+		savedSearches: savedSearches,
+		products:      products,
+		auth:          auth,
+		client:        newWebhookClient(dialPublicOnly),
+		interval:      interval,
+	}
+	return
+}
+
+// Start runs the due saved searches and purges the expired tokens every interval until ctx is done.
+// The errors are logged, so a failed run is retried on the next tick.
+func (s Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now().UTC()
+			_, err := s.RunDue(ctx, now)
+			if err != nil {
+				log.Printf("failed to run the due saved searches: %s", err)
+			}
+			err = s.auth.PurgeExpiredTokens(ctx, now)
+			if err != nil {
+				log.Printf("failed to purge the expired tokens: %s", err)
+			}
+		}
+	}
+}
+
+// RunDue runs up to BATCH_SIZE saved searches due at the given time and returns the digests of their runs.
+// A saved search failing to run is logged and skipped, so it does not prevent the others from running.
+func (s Scheduler) RunDue(ctx context.Context, now time.Time) (digests []savedsearch.Digest, err error) {
+	due, err := s.savedSearches.GetDue(ctx, now, BATCH_SIZE)
+	if err != nil {
+		return
+	}
+
+	digests = make([]savedsearch.Digest, 0, len(due))
+	for _, ss := range due {
+		d, ran, err := s.run(ctx, ss, now)
+		if err != nil {
+			log.Printf("failed to run saved search %d: %s", ss.ID, err)
+			continue
+		}
+		// Another scheduler already ran it, so the digest was not saved.
+		if !ran {
+			continue
+		}
+		digests = append(digests, d)
+
+		if ss.WebhookURL != "" {
+			err = s.push(ctx, ss.WebhookURL, d)
+			if err != nil {
+				log.Printf("failed to push digest %d of saved search %d: %s", d.ID, ss.ID, err)
+			}
+		}
+	}
+	return
+}
+
+// run runs a saved search, saving its digest and its next run.
+// It reports whether the run was saved, which is not the case if another scheduler saved it first.
+func (s Scheduler) run(ctx context.Context, ss savedsearch.SavedSearch, now time.Time) (d savedsearch.Digest, ran bool, err error) {
+	ids, total, lastID, err := s.newProducts(ctx, ss)
+	if err != nil {
+		return
+	}
+
+	ss, d = ss.Ran(ids, total, lastID, now)
+	d.ID, err = s.savedSearches.SaveRun(ctx, ss, d)
+	if dbErr, ok := err.(errors.Error); ok && dbErr.Type == errors.CONFLICT {
+		err = nil
+		return
+	}
+	ran = err == nil
+	return
+}
+
+// newProducts retrieves every product matching a saved search that is new since its last run, page by page.
+// It returns the IDs of the first MAX_DIGEST_PRODUCTS products in the order of the search,
+// the number of new products and the greatest of their IDs.
+func (s Scheduler) newProducts(ctx context.Context, ss savedsearch.SavedSearch) (ids []int, total, lastID int, err error) {
+	srch, err := ss.Search()
+	if err != nil {
+		return
+	}
+
+	ids = make([]int, 0)
+	// Ranked searches ignore the cursors, so the pages are retrieved by offset.
+	srch.Pagination = search.Pagination{Limit: PAGE_SIZE}
+	for {
+		ps, n, err := s.products.Search(ctx, srch)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		total = n
+
+		for _, p := range ps {
+			if len(ids) < savedsearch.MAX_DIGEST_PRODUCTS {
+				ids = append(ids, p.ID)
+			}
+			if p.ID > lastID {
+				lastID = p.ID
+			}
+		}
+
+		srch.Pagination.Offset += len(ps)
+		if len(ps) < PAGE_SIZE || srch.Pagination.Offset >= total {
+			return ids, total, lastID, nil
+		}
+	}
+}
+
+// newWebhookClient creates the client the digests are pushed with, which checks every address it connects to with control.
+// The redirects are not followed, and no proxy is used, so every webhook is reached at an address checked by control.
+func newWebhookClient(control func(network, address string, c syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: WEBHOOK_TIMEOUT,
+		Control: control,
+	}
+	return &http.Client{
+		Timeout: WEBHOOK_TIMEOUT,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: WEBHOOK_TIMEOUT,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// dialPublicOnly refuses the connections to loopback, private, link-local and other non-public addresses,
+// so the webhooks of the clients can not reach the server itself nor its internal network.
+// It is checked once the host of a webhook is resolved, so a public host name can not resolve to a private address.
+func dialPublicOnly(network, address string, c syscall.RawConn) (err error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		err = fmt.Errorf("invalid webhook address: %s is not a public address", host)
+	}
+	return
+}
+
+// push posts a digest as JSON to a webhook, which must answer with a 2xx status.
+// A redirect is not followed, so it fails the delivery like any other status.
+func (s Scheduler) push(ctx context.Context, url string, d savedsearch.Digest) (err error) {
+	body, err := json.Marshal(d)
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		err = fmt.Errorf("webhook answered with status %d", res.StatusCode)
+	}
+	return
+}
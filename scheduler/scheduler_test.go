@@ -0,0 +1,189 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/database/memory"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// baseTime is the time the saved searches of the tests are created at.
+var baseTime = time.Date(2023, time.March, 1, 7, 30, 0, 0, time.UTC)
+
+func TestScheduler_RunDue(t *testing.T) {
+	ctx := context.Background()
+	db, products, savedSearches := newTestDatabase(t)
+
+	// Every digest is pushed to the webhook.
+	pushed := make(chan savedsearch.Digest, 10)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var d savedsearch.Digest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&d))
+		pushed <- d
+	}))
+	defer hook.Close()
+
+	// A box of client 1 existing before the search is saved.
+	createProduct(t, products, 1, 1, "box")
+
+	s, err := savedsearch.New(1, 1, "Boxes", search.Criteria{Type: "box"}, "0 8 * * *", hook.URL, baseTime)
+	require.NoError(t, err)
+	s.ID, err = savedSearches.Create(ctx, s)
+	require.NoError(t, err)
+
+	// Three boxes and a pallet of client 1, and a box of client 2, created after the search was saved.
+	for n, productType := range []string{"box", "box", "pallet", "box"} {
+		createProduct(t, products, 1, n+2, productType)
+	}
+	createProduct(t, products, 2, 6, "box")
+
+	sched, err := New(db, time.Minute)
+	require.NoError(t, err)
+	// The test webhook listens on the loopback, which the scheduler refuses to connect to.
+	sched.client = newWebhookClient(nil)
+
+	// Nothing runs before the schedule.
+	digests, err := sched.RunDue(ctx, baseTime.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, digests)
+
+	// The first run reports every matching product of the client created since the search was saved.
+	digests, err = sched.RunDue(ctx, baseTime.Add(time.Hour))
+	require.NoError(t, err)
+	if assert.Len(t, digests, 1) {
+		assert.Equal(t, 1, digests[0].Run)
+		assert.Equal(t, []int{2, 3, 5}, digests[0].ProductIDs)
+		assert.Equal(t, 3, digests[0].Total)
+		assert.Equal(t, digests[0], <-pushed)
+	}
+
+	// It is not due again until the next day.
+	digests, err = sched.RunDue(ctx, baseTime.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, digests)
+
+	// The next run only reports the products created since.
+	createProduct(t, products, 1, 7, "box")
+	digests, err = sched.RunDue(ctx, baseTime.Add(25*time.Hour))
+	require.NoError(t, err)
+	if assert.Len(t, digests, 1) {
+		assert.Equal(t, 2, digests[0].Run)
+		assert.Equal(t, []int{7}, digests[0].ProductIDs)
+		assert.Equal(t, 1, digests[0].Total)
+		assert.Equal(t, digests[0], <-pushed)
+	}
+
+	ds, total, err := savedSearches.GetDigests(ctx, s.ID, search.Pagination{}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, []int{7}, ds[0].ProductIDs)
+}
+
+func TestScheduler_RunDueFailures(t *testing.T) {
+	ctx := context.Background()
+	db, products, savedSearches := newTestDatabase(t)
+	createProduct(t, products, 1, 1, "box")
+
+	// The webhook fails, but the run is still saved.
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer hook.Close()
+
+	s, err := savedsearch.New(1, 1, "Boxes", search.Criteria{Type: "box"}, "@hourly", hook.URL, baseTime)
+	require.NoError(t, err)
+	s.ID, err = savedSearches.Create(ctx, s)
+	require.NoError(t, err)
+
+	sched, err := New(db, time.Minute)
+	require.NoError(t, err)
+	// The test webhook listens on the loopback, which the scheduler refuses to connect to.
+	sched.client = newWebhookClient(nil)
+
+	digests, err := sched.RunDue(ctx, baseTime.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, digests, 1)
+
+	// A run saved by another scheduler is skipped.
+	_, ran, err := sched.run(ctx, s, baseTime.Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, ran)
+
+	err = sched.push(ctx, hook.URL, digests[0])
+	assert.EqualError(t, err, "webhook answered with status 500")
+}
+
+func TestScheduler_PushRefusedAddresses(t *testing.T) {
+	ctx := context.Background()
+	db, _, _ := newTestDatabase(t)
+
+	called := false
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer hook.Close()
+
+	// The redirects are not followed, even to an allowed address.
+	redirect := httptest.NewServer(http.RedirectHandler(hook.URL, http.StatusFound))
+	defer redirect.Close()
+
+	sched, err := New(db, time.Minute)
+	require.NoError(t, err)
+
+	// The loopback, private and link-local addresses are refused before connecting.
+	for _, url := range []string{hook.URL, "http://10.0.0.1/hooks", "http://169.254.169.254/latest", "http://[::1]:80/hooks"} {
+		err = sched.push(ctx, url, savedsearch.Digest{})
+		if assert.Error(t, err, url) {
+			assert.Contains(t, err.Error(), "is not a public address")
+		}
+	}
+
+	sched.client = newWebhookClient(nil)
+	err = sched.push(ctx, redirect.URL, savedsearch.Digest{})
+	assert.EqualError(t, err, "webhook answered with status 302")
+	assert.False(t, called)
+}
+
+// newTestDatabase creates an in-memory database with its auth, product and saved search repositories.
+func newTestDatabase(t *testing.T) (db database.Database, products database.ProductRepository, savedSearches database.SavedSearchRepository) {
+	conn := memory.NewConnector()
+	products, err := memory.NewProductRepository(conn)
+	require.NoError(t, err)
+	savedSearches, err = memory.NewSavedSearchRepository(conn)
+	require.NoError(t, err)
+	auth, err := memory.NewAuthRepository(conn)
+	require.NoError(t, err)
+
+	db = database.Database{
+		Conn: conn,
+		Repositories: database.Repositories{
+			database.AUTH_REPOSITORY:         auth,
+			database.PRODUCT_REPOSITORY:      products,
+			database.SAVED_SEARCH_REPOSITORY: savedSearches,
+		},
+	}
+	return
+}
+
+// createProduct creates the product number n of a client, with the given type.
+func createProduct(t *testing.T, repo database.ProductRepository, clientID, n int, productType string) {
+	guideNumber := fmt.Sprintf("GUIDE%05d", n)
+	_, err := repo.Create(context.Background(), product.Product{
+		ClientID:    clientID,
+		GuideNumber: &guideNumber,
+		Type:        &productType,
+		Status:      product.REGISTERED_STATUS,
+	})
+	require.NoError(t, err)
+}
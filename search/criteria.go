@@ -0,0 +1,80 @@
+package search
+
+import "fmt"
+
+// Criteria represents the criteria of a search the way a user provides them, like the query parameters of a search.
+// Unlike a Search, they are not validated nor bound to a client, so they can be stored and turned into a Search
+// every time they are run.
+type Criteria struct {
+	GuideNumber      string  `json:"guideNumber,omitempty"`      // Guide number filter, in the query syntax of New.
+	VehiclePlate     string  `json:"vehiclePlate,omitempty"`     // Vehicle plate filter, in the query syntax of New.
+	Type             string  `json:"type,omitempty"`             // Product type filter, in the query syntax of New.
+	Port             string  `json:"port,omitempty"`             // Port filter, in the query syntax of New.
+	Vault            string  `json:"vault,omitempty"`            // Vault filter, in the query syntax of New.
+	Status           string  `json:"status,omitempty"`           // Status filter, in the query syntax of New.
+	StartPrice       float64 `json:"startPrice,omitempty"`       // Start of the price range, open if zero.
+	EndPrice         float64 `json:"endPrice,omitempty"`         // End of the price range, open if zero.
+	StartQuantity    int     `json:"startQuantity,omitempty"`    // Start of the quantity range, open if zero.
+	EndQuantity      int     `json:"endQuantity,omitempty"`      // End of the quantity range, open if zero.
+	StartJoinedAt    string  `json:"startJoinedAt,omitempty"`    // Start of the joined at range, open if empty.
+	EndJoinedAt      string  `json:"endJoinedAt,omitempty"`      // End of the joined at range, open if empty.
+	StartDeliveredAt string  `json:"startDeliveredAt,omitempty"` // Start of the delivered at range, open if empty.
+	EndDeliveredAt   string  `json:"endDeliveredAt,omitempty"`   // End of the delivered at range, open if empty.
+	Joined           string  `json:"joined,omitempty"`           // Period of the joined at range, instead of its start and end.
+	Delivered        string  `json:"delivered,omitempty"`        // Period of the delivered at range, instead of its start and end.
+	Filter           *Filter `json:"filter,omitempty"`           // Filter tree the products must also meet, if any.
+	Query            string  `json:"q,omitempty"`                // Full-text query the products must also match, if any.
+}
+
+// Search validates the criteria and creates the search of the matching products of a client,
+// or of every client if clientID is zero. See New, NewFilter and NewQuery.
+// The dates are read every time, so the relative ones, such as last_7d, match the current period.
+func (c Criteria) Search(clientID int) (s Search, err error) {
+	// A period is both the start and the end of its range.
+	startJoinedAt, endJoinedAt, err := periodBounds(c.Joined, c.StartJoinedAt, c.EndJoinedAt, "joined at")
+	if err != nil {
+		return
+	}
+	startDeliveredAt, endDeliveredAt, err := periodBounds(c.Delivered, c.StartDeliveredAt, c.EndDeliveredAt, "delivered at")
+	if err != nil {
+		return
+	}
+
+	s, err = New(clientID, c.Port, c.Vault, c.GuideNumber, c.Type, c.VehiclePlate, c.Status, c.StartPrice, c.EndPrice,
+		c.StartQuantity, c.EndQuantity, startJoinedAt, endJoinedAt, startDeliveredAt, endDeliveredAt)
+	if err != nil {
+		return
+	}
+
+	// Validate the filter tree, whose values are still the ones decoded from JSON.
+	if c.Filter != nil {
+		var filter Filter
+		filter, err = NewFilter(*c.Filter)
+		if err != nil {
+			s = Search{}
+			return
+		}
+		s.Filter = &filter
+	}
+
+	s.Query, err = NewQuery(c.Query)
+	if err != nil {
+		s = Search{}
+	}
+	return
+}
+
+// periodBounds returns the start and end bounds of a time range given either as a period or as its bounds.
+// It returns an error if both are given.
+func periodBounds(period, start, end, name string) (startBound, endBound string, err error) {
+	if period == "" {
+		startBound, endBound = start, end
+		return
+	}
+	if start != "" || end != "" {
+		err = fmt.Errorf("invalid %s: a %s period can not be combined with the start and end of its range", name, name)
+		return
+	}
+	startBound, endBound = period, period
+	return
+}
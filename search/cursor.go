@@ -0,0 +1,98 @@
+package search
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/coffemanfp/docucentertest/product"
+)
+
+// Cursor represents the position after the last product of a page, to retrieve the products following it.
+// Unlike an offset, it keeps its position when products are inserted or deleted before it.
+// It is keyed on the values of the sort fields of the last product, and its ID to break ties.
+type Cursor struct {
+	Sort   string        `json:"sort,omitempty"`   // Sort of the page, in the format read by NewProductSort.
+	Values []interface{} `json:"values,omitempty"` // Values of the sort fields of the last product of the page.
+	ID     int           `json:"id"`               // ID of the last product of the page.
+}
+
+// NewCursor creates a new Cursor instance after the product p of a page sorted by s.
+func NewCursor(s Sort, p product.Product) Cursor {
+	return Cursor{
+		Sort:   s.String(),
+		Values: s.ProductValues(p),
+		ID:     p.ID,
+	}
+}
+
+// EncodeCursor returns the opaque representation of the cursor, signed with key so it can not be forged.
+func EncodeCursor(c Cursor, key []byte) string {
+	// The sort values of a cursor are strings, numbers and times, which are always marshalled.
+	payload, _ := json.Marshal(c)
+	return encodeSegment(payload) + "." + encodeSegment(sign(payload, key))
+}
+
+// DecodeCursor reads a cursor created by EncodeCursor, checking it was signed with key.
+func DecodeCursor(s string, key []byte) (c Cursor, err error) {
+	payload, signature, ok := strings.Cut(s, ".")
+	if !ok {
+		err = fmt.Errorf("invalid cursor: malformed cursor")
+		return
+	}
+	p, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		err = fmt.Errorf("invalid cursor: malformed cursor")
+		return
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		err = fmt.Errorf("invalid cursor: malformed cursor")
+		return
+	}
+
+	// Reject any cursor not signed with the key, before trusting its content.
+	if !hmac.Equal(sig, sign(p, key)) {
+		err = fmt.Errorf("invalid cursor: invalid cursor signature")
+		return
+	}
+
+	err = json.Unmarshal(p, &c)
+	if err != nil || c.ID <= 0 {
+		c = Cursor{}
+		err = fmt.Errorf("invalid cursor: malformed cursor")
+		return
+	}
+
+	// Restore the types of the values of the sort fields, lost in JSON.
+	sort, err := NewProductSort(c.Sort)
+	if err != nil || len(sort) != len(c.Values) {
+		c = Cursor{}
+		err = fmt.Errorf("invalid cursor: malformed cursor")
+		return
+	}
+	for i, f := range sort {
+		c.Values[i], err = parseValue(c.Values[i], productSortFields[f.Name])
+		if err != nil {
+			c = Cursor{}
+			err = fmt.Errorf("invalid cursor: malformed cursor")
+			return
+		}
+	}
+	return
+}
+
+// sign returns the HMAC-SHA256 signature of the payload with key.
+func sign(payload, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// encodeSegment encodes b as an URL-safe segment of a cursor.
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
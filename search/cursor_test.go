@@ -0,0 +1,77 @@
+package search
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/product"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	key := []byte("secret")
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		s := EncodeCursor(Cursor{ID: 42}, key)
+		c, err := DecodeCursor(s, key)
+		assert.NoError(t, err)
+		assert.Equal(t, Cursor{ID: 42}, c)
+	})
+
+	t.Run("SortValues", func(t *testing.T) {
+		s, err := NewProductSort("-delivered_at,port,shipping_price,type")
+		assert.NoError(t, err)
+		deliveredAt := time.Date(2024, 3, 1, 10, 30, 0, 500, time.UTC)
+		price := 12.5
+		productType := "box"
+		c := NewCursor(s, product.Product{ID: 7, DeliveredAt: &deliveredAt, ShippingPrice: &price, Type: &productType})
+
+		// The values keep their types, and missing values are kept
+		decoded, err := DecodeCursor(EncodeCursor(c, key), key)
+		assert.NoError(t, err)
+		assert.Equal(t, "-delivered_at,port,shipping_price,type", decoded.Sort)
+		assert.Equal(t, []interface{}{deliveredAt, nil, 12.5, "box"}, decoded.Values)
+		assert.Equal(t, 7, decoded.ID)
+	})
+
+	t.Run("InvalidSortValues", func(t *testing.T) {
+		for _, c := range []Cursor{
+			{Sort: "port", Values: []interface{}{"one"}, ID: 1},
+			{Sort: "port", Values: []interface{}{1.5}, ID: 1},
+			{Sort: "port", ID: 1},
+			{Sort: "id", Values: []interface{}{1}, ID: 1},
+			{Sort: "joined_at", Values: []interface{}{"yesterday"}, ID: 1},
+		} {
+			_, err := DecodeCursor(EncodeCursor(c, key), key)
+			assert.Error(t, err, c.Sort)
+			assert.Contains(t, err.Error(), "malformed cursor", c.Sort)
+		}
+	})
+
+	t.Run("WrongKey", func(t *testing.T) {
+		s := EncodeCursor(Cursor{ID: 42}, key)
+		_, err := DecodeCursor(s, []byte("other"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid cursor signature")
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		s := EncodeCursor(Cursor{ID: 42}, key)
+		forged := EncodeCursor(Cursor{ID: 1}, key)
+		// The payload of a cursor with the signature of another one
+		s = strings.Split(forged, ".")[0] + "." + strings.Split(s, ".")[1]
+		_, err := DecodeCursor(s, key)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid cursor signature")
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		for _, s := range []string{"", "abc", "a.b.c", "!!.!!"} {
+			_, err := DecodeCursor(s, key)
+			assert.Error(t, err, s)
+			assert.Contains(t, err.Error(), "invalid cursor", s)
+		}
+	})
+}
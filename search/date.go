@@ -0,0 +1,124 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Calendar periods relative to the current one, accepted by the time filters.
+const (
+	TODAY_PERIOD      = "today"      // Current day
+	YESTERDAY_PERIOD  = "yesterday"  // Day before the current one
+	THIS_WEEK_PERIOD  = "this_week"  // Current week, from Monday
+	LAST_WEEK_PERIOD  = "last_week"  // Week before the current one
+	THIS_MONTH_PERIOD = "this_month" // Current month
+	LAST_MONTH_PERIOD = "last_month" // Month before the current one
+	THIS_YEAR_PERIOD  = "this_year"  // Current year
+	LAST_YEAR_PERIOD  = "last_year"  // Year before the current one
+)
+
+// location is the time zone the dates without one are read in. See SetLocation.
+var location = time.UTC
+
+// clock returns the current time the relative periods are read from.
+var clock = time.Now
+
+// lastPeriod matches the periods of the last hours, days, weeks or months up to now, such as last_7d.
+var lastPeriod = regexp.MustCompile(`^last_([1-9][0-9]{0,3})([hdwm])$`)
+
+// calendarLayouts are the layouts of the calendar years, months and days, with the period each one spans.
+var calendarLayouts = []struct {
+	layout              string
+	years, months, days int
+}{
+	{"2006", 1, 0, 0},
+	{"2006-01", 0, 1, 0},
+	{"2006-01-02", 0, 0, 1},
+}
+
+// SetLocation sets the time zone the calendar dates and periods are read in, such as 2024-03-05 or this_month.
+// They are read in UTC by default.
+func SetLocation(loc *time.Location) {
+	location = loc
+}
+
+// ParsePeriod parses a date expression to the range of time it spans:
+// an RFC 3339 timestamp, which only spans its own instant;
+// a calendar year, month or day, such as 2024, 2024-03 or 2024-03-05, read in the time zone set by SetLocation;
+// one of the calendar periods relative to the current one, such as today, this_week or last_month;
+// or the last hours, days, weeks or months up to now, such as last_12h, last_7d, last_2w or last_3m.
+// Calendar periods are half-open, ending at the start of the next one, which is excluded,
+// so no timestamp falls between two consecutive periods whatever the precision of its backend. The rest include both ends.
+func ParsePeriod(v string) (r RangeTime, err error) {
+	// Timestamps are instants.
+	if t, parseErr := time.Parse(time.RFC3339, v); parseErr == nil {
+		r.Start, r.End = t, t
+		return
+	}
+
+	// Calendar years, months and days.
+	for _, l := range calendarLayouts {
+		start, parseErr := time.ParseInLocation(l.layout, v, location)
+		if parseErr == nil {
+			r = calendarPeriod(start, l.years, l.months, l.days)
+			return
+		}
+	}
+
+	// Periods of the last hours, days, weeks or months up to now.
+	now := clock().In(location)
+	if m := lastPeriod.FindStringSubmatch(v); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "h":
+			r.Start = now.Add(-time.Duration(n) * time.Hour)
+		case "d":
+			r.Start = now.AddDate(0, 0, -n)
+		case "w":
+			r.Start = now.AddDate(0, 0, -7*n)
+		case "m":
+			r.Start = now.AddDate(0, -n, 0)
+		}
+		r.End = now
+		return
+	}
+
+	// Calendar periods relative to the current one.
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, location)
+	// Weeks start on Monday.
+	monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	switch v {
+	case TODAY_PERIOD:
+		r = calendarPeriod(today, 0, 0, 1)
+	case YESTERDAY_PERIOD:
+		r = calendarPeriod(today.AddDate(0, 0, -1), 0, 0, 1)
+	case THIS_WEEK_PERIOD:
+		r = calendarPeriod(monday, 0, 0, 7)
+	case LAST_WEEK_PERIOD:
+		r = calendarPeriod(monday.AddDate(0, 0, -7), 0, 0, 7)
+	case THIS_MONTH_PERIOD:
+		r = calendarPeriod(time.Date(year, month, 1, 0, 0, 0, 0, location), 0, 1, 0)
+	case LAST_MONTH_PERIOD:
+		r = calendarPeriod(time.Date(year, month-1, 1, 0, 0, 0, 0, location), 0, 1, 0)
+	case THIS_YEAR_PERIOD:
+		r = calendarPeriod(time.Date(year, time.January, 1, 0, 0, 0, 0, location), 1, 0, 0)
+	case LAST_YEAR_PERIOD:
+		r = calendarPeriod(time.Date(year-1, time.January, 1, 0, 0, 0, 0, location), 1, 0, 0)
+	default:
+		err = fmt.Errorf("invalid date of %s", v)
+	}
+	return
+}
+
+// calendarPeriod returns the calendar period starting at start and spanning the given years, months and days,
+// which ends at the excluded start of the next one.
+func calendarPeriod(start time.Time, years, months, days int) RangeTime {
+	return RangeTime{
+		Start:       start,
+		End:         start.AddDate(years, months, days),
+		EndExcluded: true,
+	}
+}
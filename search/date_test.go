@@ -0,0 +1,97 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useClock makes the relative periods be read from now, in the loc time zone, until the test ends.
+func useClock(t *testing.T, now time.Time, loc *time.Location) {
+	t.Helper()
+	clock = func() time.Time { return now }
+	SetLocation(loc)
+	t.Cleanup(func() {
+		clock = time.Now
+		SetLocation(time.UTC)
+	})
+}
+
+func TestParsePeriod(t *testing.T) {
+	caracas, err := time.LoadLocation("America/Caracas")
+	require.NoError(t, err)
+	// Thursday, the 14th of March of 2024, at 01:30 in Caracas.
+	now := time.Date(2024, time.March, 14, 5, 30, 0, 0, time.UTC)
+	useClock(t, now, caracas)
+
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, caracas)
+	}
+	// period returns the calendar period from start to the excluded start of the next one.
+	period := func(start, next time.Time) RangeTime {
+		return RangeTime{Start: start, End: next, EndExcluded: true}
+	}
+
+	cases := map[string]RangeTime{
+		"2024-03-05T10:00:00Z": {Start: time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC), End: time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC)},
+		"2024":                 period(day(2024, time.January, 1), day(2025, time.January, 1)),
+		"2024-02":              period(day(2024, time.February, 1), day(2024, time.March, 1)),
+		"2024-03-05":           period(day(2024, time.March, 5), day(2024, time.March, 6)),
+		TODAY_PERIOD:           period(day(2024, time.March, 14), day(2024, time.March, 15)),
+		YESTERDAY_PERIOD:       period(day(2024, time.March, 13), day(2024, time.March, 14)),
+		THIS_WEEK_PERIOD:       period(day(2024, time.March, 11), day(2024, time.March, 18)),
+		LAST_WEEK_PERIOD:       period(day(2024, time.March, 4), day(2024, time.March, 11)),
+		THIS_MONTH_PERIOD:      period(day(2024, time.March, 1), day(2024, time.April, 1)),
+		LAST_MONTH_PERIOD:      period(day(2024, time.February, 1), day(2024, time.March, 1)),
+		THIS_YEAR_PERIOD:       period(day(2024, time.January, 1), day(2025, time.January, 1)),
+		LAST_YEAR_PERIOD:       period(day(2023, time.January, 1), day(2024, time.January, 1)),
+		"last_12h":             {Start: now.Add(-12 * time.Hour), End: now},
+		"last_7d":              {Start: now.AddDate(0, 0, -7), End: now},
+		"last_2w":              {Start: now.AddDate(0, 0, -14), End: now},
+		"last_3m":              {Start: now.AddDate(0, -3, 0), End: now},
+	}
+	for v, expected := range cases {
+		r, err := ParsePeriod(v)
+		if assert.NoError(t, err, v) {
+			assert.True(t, expected.Start.Equal(r.Start), "%s starts at %s, expected %s", v, r.Start, expected.Start)
+			assert.True(t, expected.End.Equal(r.End), "%s ends at %s, expected %s", v, r.End, expected.End)
+			assert.Equal(t, expected.EndExcluded, r.EndExcluded, v)
+		}
+	}
+
+	for _, v := range []string{"", "tomorrow", "last_0d", "last_7y", "2024-3", "2024-02-30", "2024-03-05T10:00:00"} {
+		_, err := ParsePeriod(v)
+		assert.EqualError(t, err, "invalid date of "+v)
+	}
+}
+
+func TestCriteria_SearchPeriods(t *testing.T) {
+	useClock(t, time.Date(2024, time.March, 14, 5, 30, 0, 0, time.UTC), time.UTC)
+
+	// A period is both bounds of its range.
+	s, err := Criteria{Joined: "last_month", Delivered: THIS_WEEK_PERIOD}.Search(1)
+	require.NoError(t, err)
+	assert.Equal(t, RangeTime{
+		Start:       time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		EndExcluded: true,
+	}, s.JoinedAtRange)
+	assert.Equal(t, RangeTime{
+		Start:       time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2024, time.March, 18, 0, 0, 0, 0, time.UTC),
+		EndExcluded: true,
+	}, s.DeliveredAtRange)
+
+	// Bounds can be periods too, leaving the range open on the other side.
+	s, err = Criteria{EndDeliveredAt: "yesterday"}.Search(1)
+	require.NoError(t, err)
+	assert.Equal(t, RangeTime{End: time.Date(2024, time.March, 14, 0, 0, 0, 0, time.UTC), EndExcluded: true}, s.DeliveredAtRange)
+
+	_, err = Criteria{Joined: "today", StartJoinedAt: "2024-03-01"}.Search(1)
+	assert.EqualError(t, err, "invalid joined at: a joined at period can not be combined with the start and end of its range")
+
+	_, err = Criteria{Delivered: "soon"}.Search(1)
+	assert.EqualError(t, err, "invalid start delivered at: invalid start delivered at time format of soon")
+}
@@ -0,0 +1,175 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/coffemanfp/docucentertest/product"
+)
+
+// MAX_FACET_BUCKETS is the maximum number of buckets of a facet, the products of the rest are only counted as others.
+const MAX_FACET_BUCKETS = 50
+
+// Widths of the buckets of the histogram facets when a request does not provide them.
+const (
+	DEFAULT_PRICE_INTERVAL    = 100.0
+	DEFAULT_QUANTITY_INTERVAL = 10.0
+)
+
+// facetIntervals maps the product columns the products can be aggregated by to the default width of their buckets,
+// zero for the columns whose products are counted per value.
+var facetIntervals = map[string]float64{
+	"type":           0,
+	"port":           0,
+	"vault":          0,
+	"shipping_price": DEFAULT_PRICE_INTERVAL,
+	"quantity":       DEFAULT_QUANTITY_INTERVAL,
+}
+
+// Facet represents an aggregation of the products matching a search by one of their columns.
+type Facet struct {
+	Field    string  // Name of the column the products are aggregated by.
+	Interval float64 // Width of the buckets of a histogram, zero if the products are counted per value.
+}
+
+// FacetBucket represents a value of a facet and the number of products with it.
+type FacetBucket struct {
+	Value interface{} `json:"value"` // Value of the column, or the start of the range of a histogram bucket.
+	Count int         `json:"count"` // Number of products with the value, or within the range.
+}
+
+// FacetResult represents the buckets of a facet for the products matching a search.
+type FacetResult struct {
+	Buckets  []FacetBucket `json:"buckets"`            // Buckets with products, the most common values first, or the lowest ranges first for histograms.
+	Interval float64       `json:"interval,omitempty"` // Width of the ranges of a histogram.
+	Missing  int           `json:"missing"`            // Number of products without a value for the column.
+	Other    int           `json:"other"`              // Number of products of the buckets after the first MAX_FACET_BUCKETS.
+}
+
+// NewFacets creates the facets from comma-separated product columns, each one optionally followed by ":" and the width
+// of its buckets, like "type,shipping_price:50". Only shipping_price and quantity are histograms, and their buckets
+// default to DEFAULT_PRICE_INTERVAL and DEFAULT_QUANTITY_INTERVAL.
+func NewFacets(v string) (fs []Facet, err error) {
+	if v == "" {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(v, ",") {
+		var f Facet
+		f, err = newFacet(strings.TrimSpace(name))
+		if err != nil {
+			fs = nil
+			return
+		}
+		if seen[f.Field] {
+			fs = nil
+			err = fmt.Errorf("invalid facets: %s is repeated", f.Field)
+			return
+		}
+		seen[f.Field] = true
+		fs = append(fs, f)
+	}
+	return
+}
+
+// newFacet creates a facet from a product column and the optional width of its buckets.
+func newFacet(v string) (f Facet, err error) {
+	field, interval, hasInterval := strings.Cut(v, ":")
+	def, ok := facetIntervals[field]
+	if !ok {
+		err = fmt.Errorf("invalid facets: %q is not a facet field", field)
+		return
+	}
+	f = Facet{Field: field, Interval: def}
+	if !hasInterval {
+		return
+	}
+
+	if def == 0 {
+		err = fmt.Errorf("invalid facets: %s is not a histogram, it can not have an interval", field)
+		return
+	}
+	f.Interval, err = strconv.ParseFloat(interval, 64)
+	switch {
+	case err != nil || f.Interval <= 0 || math.IsInf(f.Interval, 0) || math.IsNaN(f.Interval):
+		err = fmt.Errorf("invalid facets: interval of %s must be a positive number", field)
+	case productSortFields[field] == intValue && f.Interval != math.Trunc(f.Interval):
+		err = fmt.Errorf("invalid facets: interval of %s must be an integer", field)
+	}
+	return
+}
+
+// Histogram reports whether the products are counted per range of values of width Interval, instead of per value.
+func (f Facet) Histogram() bool {
+	return f.Interval > 0
+}
+
+// Value converts a value of the facet read from a database to the type of the values of its column,
+// which is nil if the value is missing. Histogram values must already be the start of their ranges.
+func (f Facet) Value(v interface{}) interface{} {
+	var n float64
+	switch v := v.(type) {
+	case int:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	case float64:
+		n = v
+	case []byte:
+		return string(v)
+	default:
+		return v
+	}
+	if productSortFields[f.Field] == intValue {
+		return int(n)
+	}
+	return n
+}
+
+// ProductValue returns the value of the facet for the product, the start of its range for histograms,
+// or nil if the product is missing the column.
+func (f Facet) ProductValue(p product.Product) interface{} {
+	v := f.Value(productValue(p, f.Field))
+	if v == nil || !f.Histogram() {
+		return v
+	}
+
+	switch v := v.(type) {
+	case int:
+		interval := int(f.Interval)
+		return int(math.Floor(float64(v)/float64(interval))) * interval
+	case float64:
+		return math.Floor(v/f.Interval) * f.Interval
+	}
+	return v
+}
+
+// NewResult creates the result of the facet from the number of products of every value, and of the missing ones.
+// The most common values come first, or the lowest ranges for histograms, and the products of the buckets
+// after the first MAX_FACET_BUCKETS are only counted as others.
+func (f Facet) NewResult(buckets []FacetBucket, missing int) (r FacetResult) {
+	sorted := append(make([]FacetBucket, 0, len(buckets)), buckets...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !f.Histogram() && sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return compareFilterValues(sorted[i].Value, sorted[j].Value) < 0
+	})
+
+	r = FacetResult{
+		Buckets:  sorted,
+		Interval: f.Interval,
+		Missing:  missing,
+	}
+	if len(sorted) > MAX_FACET_BUCKETS {
+		for _, b := range sorted[MAX_FACET_BUCKETS:] {
+			r.Other += b.Count
+		}
+		r.Buckets = sorted[:MAX_FACET_BUCKETS]
+	}
+	return
+}
@@ -0,0 +1,84 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFacets(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		fs, err := NewFacets("type, port,shipping_price:50,quantity")
+		assert.NoError(t, err)
+		assert.Equal(t, []Facet{
+			{Field: "type"},
+			{Field: "port"},
+			{Field: "shipping_price", Interval: 50},
+			{Field: "quantity", Interval: DEFAULT_QUANTITY_INTERVAL},
+		}, fs)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		fs, err := NewFacets("")
+		assert.NoError(t, err)
+		assert.Empty(t, fs)
+	})
+
+	for name, tc := range map[string]struct {
+		facets, reason string
+	}{
+		"UnknownField":       {"type,guide_number", `"guide_number" is not a facet field`},
+		"Repeated":           {"port,vault,port", "port is repeated"},
+		"IntervalOfTerms":    {"vault:5", "vault is not a histogram"},
+		"NegativeInterval":   {"shipping_price:-5", "interval of shipping_price must be a positive number"},
+		"NotANumber":         {"shipping_price:abc", "interval of shipping_price must be a positive number"},
+		"NaNInterval":        {"shipping_price:NaN", "interval of shipping_price must be a positive number"},
+		"InfiniteInterval":   {"shipping_price:Inf", "interval of shipping_price must be a positive number"},
+		"FractionalQuantity": {"quantity:2.5", "interval of quantity must be an integer"},
+		"EmptyField":         {"type,", `"" is not a facet field`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			fs, err := NewFacets(tc.facets)
+			assert.ErrorContains(t, err, tc.reason)
+			assert.Nil(t, fs)
+		})
+	}
+}
+
+func TestFacetProductValue(t *testing.T) {
+	productType, price, quantity, port := "box", 155.5, 19, 4
+	p := product.Product{Type: &productType, ShippingPrice: &price, Quantity: &quantity, Port: &port}
+
+	assert.Equal(t, "box", Facet{Field: "type"}.ProductValue(p))
+	assert.Equal(t, 4, Facet{Field: "port"}.ProductValue(p))
+	assert.Nil(t, Facet{Field: "vault"}.ProductValue(p))
+	assert.Equal(t, 150.0, Facet{Field: "shipping_price", Interval: 50}.ProductValue(p))
+	assert.Equal(t, 10, Facet{Field: "quantity", Interval: 10}.ProductValue(p))
+}
+
+func TestFacetNewResult(t *testing.T) {
+	t.Run("Terms", func(t *testing.T) {
+		r := Facet{Field: "type"}.NewResult([]FacetBucket{{Value: "crate", Count: 1}, {Value: "pallet", Count: 3}, {Value: "box", Count: 1}}, 2)
+		assert.Equal(t, FacetResult{
+			Buckets: []FacetBucket{{Value: "pallet", Count: 3}, {Value: "box", Count: 1}, {Value: "crate", Count: 1}},
+			Missing: 2,
+		}, r)
+	})
+
+	t.Run("Histogram", func(t *testing.T) {
+		r := Facet{Field: "quantity", Interval: 10}.NewResult([]FacetBucket{{Value: 20, Count: 5}, {Value: 0, Count: 1}}, 0)
+		assert.Equal(t, FacetResult{Buckets: []FacetBucket{{Value: 0, Count: 1}, {Value: 20, Count: 5}}, Interval: 10}, r)
+	})
+
+	t.Run("Others", func(t *testing.T) {
+		buckets := make([]FacetBucket, MAX_FACET_BUCKETS+2)
+		for i := range buckets {
+			buckets[i] = FacetBucket{Value: i, Count: 2}
+		}
+		r := Facet{Field: "port"}.NewResult(buckets, 0)
+		assert.Len(t, r.Buckets, MAX_FACET_BUCKETS)
+		assert.Equal(t, MAX_FACET_BUCKETS-1, r.Buckets[MAX_FACET_BUCKETS-1].Value)
+		assert.Equal(t, 4, r.Other)
+	})
+}
@@ -0,0 +1,165 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MISSING_VALUE is the filter value matching the products without a value in the filtered column.
+const MISSING_VALUE = "none"
+
+// StringFilter represents the criteria on a text column of the products.
+// A product matches if its value is one of the values, starts with one of the prefixes, or is missing when Missing is set.
+// An empty filter matches every product.
+type StringFilter struct {
+	Values   []string // Values the column can be equal to.
+	Prefixes []string // Prefixes the column can start with.
+	Missing  bool     // Whether the products without a value match.
+	Not      bool     // Whether the filter is negated, matching only the products the criteria do not.
+}
+
+// IsZero reports whether the filter has no criteria, so it matches every product.
+func (f StringFilter) IsZero() bool {
+	return len(f.Values) == 0 && len(f.Prefixes) == 0 && !f.Missing
+}
+
+// Matches reports whether the value v of a column, nil if missing, meets the filter.
+func (f StringFilter) Matches(v *string) bool {
+	if f.IsZero() {
+		return true
+	}
+
+	matches := v == nil && f.Missing
+	if v != nil {
+		for _, value := range f.Values {
+			matches = matches || *v == value
+		}
+		for _, prefix := range f.Prefixes {
+			matches = matches || strings.HasPrefix(*v, prefix)
+		}
+	}
+	return matches != f.Not
+}
+
+// IntFilter represents the criteria on an integer column of the products.
+// A product matches if its value is one of the values, or is missing when Missing is set.
+// An empty filter matches every product.
+type IntFilter struct {
+	Values  []int // Values the column can be equal to.
+	Missing bool  // Whether the products without a value match.
+	Not     bool  // Whether the filter is negated, matching only the products the criteria do not.
+}
+
+// IsZero reports whether the filter has no criteria, so it matches every product.
+func (f IntFilter) IsZero() bool {
+	return len(f.Values) == 0 && !f.Missing
+}
+
+// Matches reports whether the value v of a column, nil if missing, meets the filter.
+func (f IntFilter) Matches(v *int) bool {
+	if f.IsZero() {
+		return true
+	}
+
+	matches := v == nil && f.Missing
+	if v != nil {
+		for _, value := range f.Values {
+			matches = matches || *v == value
+		}
+	}
+	return matches != f.Not
+}
+
+// parseStringFilter parses the filter of the column name from its query syntax:
+// comma-separated values, any of which may be MISSING_VALUE or end with "*" to match a prefix,
+// the whole list prefixed with "!" to negate it.
+// Every value is checked with validate, and every prefix with validatePrefix. A nil validatePrefix rejects prefixes.
+func parseStringFilter(v, name string, validate, validatePrefix func(string) error) (f StringFilter, err error) {
+	items, not, err := splitFilter(v, name)
+	if err != nil {
+		return
+	}
+
+	for _, item := range items {
+		switch {
+		case item == MISSING_VALUE:
+			f.Missing = true
+		case strings.HasSuffix(item, "*"):
+			if validatePrefix == nil {
+				err = fmt.Errorf("invalid %s: %s can not be matched by prefix", name, name)
+				return
+			}
+			prefix := strings.TrimSuffix(item, "*")
+			err = validatePrefix(prefix)
+			if err != nil {
+				return
+			}
+			f.Prefixes = append(f.Prefixes, prefix)
+		default:
+			if validate != nil {
+				err = validate(item)
+				if err != nil {
+					return
+				}
+			}
+			f.Values = append(f.Values, item)
+		}
+	}
+	f.Not = not
+	return
+}
+
+// parseIntFilter parses the filter of the column name from its query syntax:
+// comma-separated numbers, any of which may be MISSING_VALUE, the whole list prefixed with "!" to negate it.
+// Every number is checked with validate.
+func parseIntFilter(v, name string, validate func(int) error) (f IntFilter, err error) {
+	items, not, err := splitFilter(v, name)
+	if err != nil {
+		return
+	}
+
+	for _, item := range items {
+		if item == MISSING_VALUE {
+			f.Missing = true
+			continue
+		}
+		n, errConv := strconv.Atoi(item)
+		if errConv != nil {
+			err = fmt.Errorf("invalid %s: %s is not a number", name, item)
+			return
+		}
+		err = validate(n)
+		if err != nil {
+			return
+		}
+		f.Values = append(f.Values, n)
+	}
+	f.Not = not
+	return
+}
+
+// splitFilter splits the query syntax of a filter into its values, and whether it is negated.
+// An empty v has no values.
+func splitFilter(v, name string) (items []string, not bool, err error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return
+	}
+	if strings.HasPrefix(v, "!") {
+		not = true
+		v = strings.TrimPrefix(v, "!")
+	}
+
+	items = strings.Split(v, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+		if items[i] == "" {
+			items = nil
+			not = false
+			err = fmt.Errorf("invalid %s: empty value in %s filter", name, name)
+			return
+		}
+	}
+	return
+}
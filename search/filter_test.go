@@ -0,0 +1,53 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringFilterMatches(t *testing.T) {
+	box, crate := "box", "crate"
+
+	t.Run("Empty", func(t *testing.T) {
+		assert.True(t, StringFilter{}.Matches(&box))
+		assert.True(t, StringFilter{}.Matches(nil))
+	})
+
+	t.Run("ValuesAndPrefixes", func(t *testing.T) {
+		f := StringFilter{Values: []string{"pallet"}, Prefixes: []string{"bo"}}
+		assert.True(t, f.Matches(&box))
+		assert.False(t, f.Matches(&crate))
+		assert.False(t, f.Matches(nil))
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		f := StringFilter{Missing: true}
+		assert.True(t, f.Matches(nil))
+		assert.False(t, f.Matches(&box))
+	})
+
+	t.Run("Not", func(t *testing.T) {
+		f := StringFilter{Values: []string{"box"}, Not: true}
+		assert.False(t, f.Matches(&box))
+		assert.True(t, f.Matches(&crate))
+		assert.True(t, f.Matches(nil))
+	})
+}
+
+func TestIntFilterMatches(t *testing.T) {
+	one, two := 1, 2
+
+	t.Run("Values", func(t *testing.T) {
+		f := IntFilter{Values: []int{1, 3}}
+		assert.True(t, f.Matches(&one))
+		assert.False(t, f.Matches(&two))
+		assert.False(t, f.Matches(nil))
+	})
+
+	t.Run("NotMissing", func(t *testing.T) {
+		f := IntFilter{Missing: true, Not: true}
+		assert.True(t, f.Matches(&one))
+		assert.False(t, f.Matches(nil))
+	})
+}
@@ -10,15 +10,20 @@ import (
 // Search represents the search criteria for filtering products.
 type Search struct {
 	ClientID         int          // Client ID to filter products by.
-	GuideNumber      string       // Guide number to filter products by.
-	Type             string       // Product type to filter products by.
-	Port             int          // Port number to filter products by.
-	Vault            int          // Vault number to filter products by.
-	VehiclePlate     string       // Vehicle plate to filter products by.
+	GuideNumber      StringFilter // Guide numbers or prefixes to filter products by.
+	Type             StringFilter // Product types to filter products by.
+	Port             IntFilter    // Port numbers to filter products by.
+	Vault            IntFilter    // Vault numbers to filter products by.
+	VehiclePlate     StringFilter // Vehicle plates or prefixes to filter products by.
+	Status           StringFilter // Lifecycle statuses to filter products by.
 	PriceRange       RangeFloat64 // Price range to filter products by.
 	QuantityRange    RangeInt     // Quantity range to filter products by.
 	JoinedAtRange    RangeTime    // JoinedAt (timestamp) range to filter products by.
 	DeliveredAtRange RangeTime    // DeliveredAt (timestamp) range to filter products by.
+	Filter           *Filter      // Filter tree the products must also meet, if any.
+	Query            string       // Full-text query normalized by NewQuery the products must also match, if any.
+	AfterID          int          // ID the products must be greater than, if not zero.
+	Pagination       Pagination   // Window of the matching products to retrieve.
 }
 
 // RangeFloat64 represents a range of floating-point numbers.
@@ -35,41 +40,55 @@ type RangeInt struct {
 
 // RangeTime represents a range of time values.
 type RangeTime struct {
-	Start time.Time // Start of the range.
-	End   time.Time // End of the range.
+	Start       time.Time // Start of the range, included.
+	End         time.Time // End of the range, included unless EndExcluded is set.
+	EndExcluded bool      // Whether the range ends right before End, like the calendar periods.
 }
 
 // New creates a new Search instance with the provided search criteria.
-func New(clientID, port, vault int, guideNumber, productType, vehiclePlate string,
+// The guide number, type, vehicle plate, port, vault and status filters are read from their query syntax:
+// comma-separated values to match any of them, MISSING_VALUE to match the products without a value,
+// a trailing "*" to match a prefix of a guide number or vehicle plate, and a leading "!" to negate the filter.
+// The joined at and delivered at bounds are date expressions of ParsePeriod: a start bound is read as the start
+// of its period and an end bound as its end, so both can be the same period. Either bound can be left empty.
+func New(clientID int, port, vault, guideNumber, productType, vehiclePlate, status string,
 	startPrice, endPrice float64, startQuantity, endQuantity int, startJoinedAt, endJoinedAt,
 	startDeliveredAt, endDeliveredAt string) (s Search, err error) {
 
-	// Validate and set port.
-	err = product.ValidatePort(port)
+	// Parse and validate the port filter.
+	portFilter, err := parseIntFilter(port, "port", product.ValidatePort)
 	if err != nil {
 		return
 	}
 
-	// Validate and set vault.
-	err = product.ValidateVault(vault)
+	// Parse and validate the vault filter.
+	vaultFilter, err := parseIntFilter(vault, "vault", product.ValidateVault)
 	if err != nil {
 		return
 	}
 
-	if guideNumber != "" {
-		// Validate and set guide number.
-		err = product.ValidateGuideNumber(&guideNumber)
-		if err != nil {
-			return
-		}
+	// Parse and validate the guide number filter, which can match prefixes.
+	guideNumberFilter, err := parseStringFilter(guideNumber, "guide number", validateGuideNumber, validateGuideNumberPrefix)
+	if err != nil {
+		return
+	}
+
+	// Parse the type filter, whose values are free text.
+	typeFilter, err := parseStringFilter(productType, "type", nil, nil)
+	if err != nil {
+		return
+	}
+
+	// Parse and validate the vehicle plate filter, which can match prefixes.
+	vehiclePlateFilter, err := parseStringFilter(vehiclePlate, "vehicle plate", validateVehiclePlate, validateVehiclePlatePrefix)
+	if err != nil {
+		return
 	}
 
-	if vehiclePlate != "" {
-		// Validate and set vehicle plate.
-		err = product.ValidateVehiclePlate(&vehiclePlate)
-		if err != nil {
-			return
-		}
+	// Parse and validate the status filter.
+	statusFilter, err := parseStringFilter(status, "status", validateStatus, nil)
+	if err != nil {
+		return
 	}
 
 	// Validate and set price range.
@@ -85,65 +104,71 @@ func New(clientID, port, vault int, guideNumber, productType, vehiclePlate strin
 	}
 
 	// Parse and set the start and end joined at values.
-	startJoinedAtTime, err := parseTimeValue(startJoinedAt, "start joined at")
+	var joinedAtRange RangeTime
+	joinedAtRange.Start, _, err = parseTimeValue(startJoinedAt, "start joined at", false)
 	if err != nil {
 		return
 	}
-	endJoinedAtTime, err := parseTimeValue(endJoinedAt, "end joined at")
+	joinedAtRange.End, joinedAtRange.EndExcluded, err = parseTimeValue(endJoinedAt, "end joined at", true)
 	if err != nil {
 		return
 	}
 	// Validate the joined at range.
-	err = validateJoinedAtRange(startJoinedAtTime, endJoinedAtTime)
+	err = validateJoinedAtRange(joinedAtRange)
 	if err != nil {
 		return
 	}
 
 	// Parse and set the start and end delivered at values.
-	startDeliveredAtTime, err := parseTimeValue(startDeliveredAt, "start delivered at")
+	var deliveredAtRange RangeTime
+	deliveredAtRange.Start, _, err = parseTimeValue(startDeliveredAt, "start delivered at", false)
 	if err != nil {
 		return
 	}
-	endDeliveredAtTime, err := parseTimeValue(endDeliveredAt, "end delivered at")
+	deliveredAtRange.End, deliveredAtRange.EndExcluded, err = parseTimeValue(endDeliveredAt, "end delivered at", true)
 	if err != nil {
 		return
 	}
 	// Validate the delivered at range.
-	err = validateDeliveredAtRange(startDeliveredAtTime, endDeliveredAtTime)
+	err = validateDeliveredAtRange(deliveredAtRange)
 	if err != nil {
 		return
 	}
 
 	s.ClientID = clientID
-	s.Port = port
-	s.Type = productType
-	s.Vault = vault
-	s.GuideNumber = guideNumber
-	s.VehiclePlate = vehiclePlate
+	s.Port = portFilter
+	s.Type = typeFilter
+	s.Vault = vaultFilter
+	s.GuideNumber = guideNumberFilter
+	s.VehiclePlate = vehiclePlateFilter
+	s.Status = statusFilter
 	s.PriceRange.Start = startPrice
 	s.PriceRange.End = endPrice
 	s.QuantityRange.Start = startQuantity
 	s.QuantityRange.End = endQuantity
-	s.JoinedAtRange.Start = startJoinedAtTime
-	s.JoinedAtRange.End = endJoinedAtTime
-	s.DeliveredAtRange.Start = startDeliveredAtTime
-	s.DeliveredAtRange.End = endDeliveredAtTime
+	s.JoinedAtRange = joinedAtRange
+	s.DeliveredAtRange = deliveredAtRange
 	return
 }
 
-// parseTimeValue parses a string value to a time.Time instance.
-// If the input value is empty, it returns a zero time value.
-// Otherwise, it attempts to parse the input value using RFC3339 format.
-// If parsing fails, it returns an error.
-func parseTimeValue(v string, name string) (t time.Time, err error) {
+// parseTimeValue parses a string value to a bound of a time range.
+// If the input value is empty, it returns a zero time value, which leaves the range open.
+// Otherwise, it parses the input value as a date expression of ParsePeriod, returning the end
+// of its period and whether it is excluded if end is set, or its start otherwise. If parsing fails, it returns an error.
+func parseTimeValue(v string, name string, end bool) (t time.Time, excluded bool, err error) {
 	// If the input value is empty, return zero time value.
 	if v == "" {
 		return
 	}
-	// Attempt to parse the input value using RFC3339 format.
-	t, err = time.Parse(time.RFC3339, v)
+	// Parse the period of the input value, keeping the bound of the range it is for.
+	period, err := ParsePeriod(v)
 	if err != nil {
 		err = fmt.Errorf("invalid %s: invalid %s time format of %s", name, name, v)
+		return
+	}
+	t = period.Start
+	if end {
+		t, excluded = period.End, period.EndExcluded
 	}
 	return
 }
@@ -7,260 +7,155 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestNewSearch(t *testing.T) {
-	validSearch := Search{
-		ClientID:      1,
-		Port:          80,
-		Vault:         2,
-		GuideNumber:   "ABC1234567",
-		VehiclePlate:  "ABC-123",
-		PriceRange:    RangeFloat64{Start: 100.0, End: 200.0},
-		QuantityRange: RangeInt{Start: 5, End: 10},
-		JoinedAtRange: RangeTime{
-			Start: parseTimeIgnoringError(time.Now().Add(-24 * time.Hour).Format(time.RFC3339)),
-			End:   parseTimeIgnoringError(time.Now().Format(time.RFC3339)),
-		},
-		DeliveredAtRange: RangeTime{
-			Start: parseTimeIgnoringError(time.Now().Add(-48 * time.Hour).Format(time.RFC3339)),
-			End:   parseTimeIgnoringError(time.Now().Add(-24 * time.Hour).Format(time.RFC3339)),
-		},
-	}
-
-	invalidPort := Search{
-		ClientID:      1,
-		Port:          -1,
-		Vault:         2,
-		GuideNumber:   "ABC1234567",
-		VehiclePlate:  "ABC-123",
-		PriceRange:    RangeFloat64{Start: 100.0, End: 200.0},
-		QuantityRange: RangeInt{Start: 5, End: 10},
-		JoinedAtRange: RangeTime{
-			Start: parseTimeIgnoringError(time.Now().Add(-24 * time.Hour).Format(time.RFC3339)),
-			End:   parseTimeIgnoringError(time.Now().Format(time.RFC3339)),
-		},
-		DeliveredAtRange: RangeTime{
-			Start: parseTimeIgnoringError(time.Now().Add(-48 * time.Hour).Format(time.RFC3339)),
-			End:   parseTimeIgnoringError(time.Now().Add(-24 * time.Hour).Format(time.RFC3339)),
-		},
-	}
-
-	invalidVault := Search{
-		ClientID:      1,
-		Port:          80,
-		Vault:         -2,
-		GuideNumber:   "ABC1234567",
-		VehiclePlate:  "ABC-123",
-		PriceRange:    RangeFloat64{Start: 100.0, End: 200.0},
-		QuantityRange: RangeInt{Start: 5, End: 10},
-		JoinedAtRange: RangeTime{
-			Start: parseTimeIgnoringError(time.Now().Add(-24 * time.Hour).Format(time.RFC3339)),
-			End:   parseTimeIgnoringError(time.Now().Format(time.RFC3339)),
-		},
-		DeliveredAtRange: RangeTime{
-			Start: parseTimeIgnoringError(time.Now().Add(-48 * time.Hour).Format(time.RFC3339)),
-			End:   parseTimeIgnoringError(time.Now().Add(-24 * time.Hour).Format(time.RFC3339)),
-		},
-	}
-
-	invalidGuideNumber := Search{
-		ClientID:      1,
-		Port:          80,
-		Vault:         2,
-		GuideNumber:   "ABC-123",
-		VehiclePlate:  "ABC-123",
-		PriceRange:    RangeFloat64{Start: 100.0, End: 200.0},
-		QuantityRange: RangeInt{Start: 5, End: 10},
-		JoinedAtRange: RangeTime{
-			Start: parseTimeIgnoringError(time.Now().Add(-24 * time.Hour).Format(time.RFC3339)),
-			End:   parseTimeIgnoringError(time.Now().Format(time.RFC3339)),
-		},
-		DeliveredAtRange: RangeTime{
-			Start: parseTimeIgnoringError(time.Now().Add(-48 * time.Hour).Format(time.RFC3339)),
-			End:   parseTimeIgnoringError(time.Now().Add(-24 * time.Hour).Format(time.RFC3339)),
-		},
-	}
-
-	invalidVehiclePlate := Search{
-		ClientID:      1,
-		Port:          80,
-		Vault:         2,
-		GuideNumber:   "ABC1234567",
-		VehiclePlate:  "123-ABC",
-		PriceRange:    RangeFloat64{Start: 100.0, End: 200.0},
-		QuantityRange: RangeInt{Start: 5, End: 10},
-		JoinedAtRange: RangeTime{
-			Start: parseTimeIgnoringError(time.Now().Add(-24 * time.Hour).Format(time.RFC3339)),
-			End:   parseTimeIgnoringError(time.Now().Format(time.RFC3339)),
-		},
-		DeliveredAtRange: RangeTime{
-			Start: parseTimeIgnoringError(time.Now().Add(-48 * time.Hour).Format(time.RFC3339)),
-			End:   parseTimeIgnoringError(time.Now().Add(-24 * time.Hour).Format(time.RFC3339)),
-		},
-	}
-
-	invalidPriceRange := Search{
-		ClientID:      1,
-		Port:          80,
-		Vault:         2,
-		GuideNumber:   "ABC1234567",
-		VehiclePlate:  "ABC-123",
-		PriceRange:    RangeFloat64{Start: 200.0, End: 100.0}, // Invalid range
-		QuantityRange: RangeInt{Start: 5, End: 10},
-		JoinedAtRange: RangeTime{
-			Start: parseTimeIgnoringError(time.Now().Add(-24 * time.Hour).Format(time.RFC3339)),
-			End:   parseTimeIgnoringError(time.Now().Format(time.RFC3339)),
-		},
-		DeliveredAtRange: RangeTime{
-			Start: parseTimeIgnoringError(time.Now().Add(-48 * time.Hour).Format(time.RFC3339)),
-			End:   parseTimeIgnoringError(time.Now().Add(-24 * time.Hour).Format(time.RFC3339)),
-		},
-	}
-
-	invalidQuantityRange := Search{
-		ClientID:      1,
-		Port:          80,
-		Vault:         2,
-		GuideNumber:   "ABC1234567",
-		VehiclePlate:  "ABC-123",
-		PriceRange:    RangeFloat64{Start: 100.0, End: 200.0},
-		QuantityRange: RangeInt{Start: 10, End: 5}, // Invalid range
-		JoinedAtRange: RangeTime{
-			Start: parseTimeIgnoringError(time.Now().Add(-24 * time.Hour).Format(time.RFC3339)),
-			End:   parseTimeIgnoringError(time.Now().Format(time.RFC3339)),
-		},
-		DeliveredAtRange: RangeTime{
-			Start: parseTimeIgnoringError(time.Now().Add(-48 * time.Hour).Format(time.RFC3339)),
-			End:   parseTimeIgnoringError(time.Now().Add(-24 * time.Hour).Format(time.RFC3339)),
-		},
-	}
+// searchArgs represents the arguments of New.
+type searchArgs struct {
+	clientID                                                     int
+	port, vault, guideNumber, productType, vehiclePlate          string
+	status                                                       string
+	startPrice, endPrice                                         float64
+	startQuantity, endQuantity                                   int
+	startJoinedAt, endJoinedAt, startDeliveredAt, endDeliveredAt string
+}
 
-	invalidJoinedAtRange := Search{
-		ClientID:      1,
-		Port:          80,
-		Vault:         2,
-		GuideNumber:   "ABC1234567",
-		VehiclePlate:  "ABC-123",
-		PriceRange:    RangeFloat64{Start: 100.0, End: 200.0},
-		QuantityRange: RangeInt{Start: 5, End: 10},
-		JoinedAtRange: RangeTime{Start: time.Now(), End: time.Now().Add(-24 * time.Hour)}, // Invalid range
-		DeliveredAtRange: RangeTime{
-			Start: parseTimeIgnoringError(time.Now().Add(-48 * time.Hour).Format(time.RFC3339)),
-			End:   parseTimeIgnoringError(time.Now().Add(-24 * time.Hour).Format(time.RFC3339)),
-		},
-	}
+// new calls New with the arguments.
+func (a searchArgs) new() (Search, error) {
+	return New(a.clientID, a.port, a.vault, a.guideNumber, a.productType, a.vehiclePlate, a.status, a.startPrice, a.endPrice,
+		a.startQuantity, a.endQuantity, a.startJoinedAt, a.endJoinedAt, a.startDeliveredAt, a.endDeliveredAt)
+}
 
-	invalidDeliveredAtRange := Search{
-		ClientID:         1,
-		Port:             80,
-		Vault:            2,
-		GuideNumber:      "ABC1234567",
-		VehiclePlate:     "ABC-123",
-		PriceRange:       RangeFloat64{Start: 100.0, End: 200.0},
-		QuantityRange:    RangeInt{Start: 5, End: 10},
-		JoinedAtRange:    RangeTime{Start: time.Now().Add(-24 * time.Hour), End: time.Now()},
-		DeliveredAtRange: RangeTime{Start: time.Now().Add(-24 * time.Hour), End: time.Now().Add(-48 * time.Hour)}, // Invalid range
+func TestNewSearch(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	validArgs := func() searchArgs {
+		return searchArgs{
+			clientID:         1,
+			port:             "80",
+			vault:            "2",
+			guideNumber:      "ABC1234567",
+			vehiclePlate:     "ABC-123",
+			status:           "IN_TRANSIT",
+			startPrice:       100.0,
+			endPrice:         200.0,
+			startQuantity:    5,
+			endQuantity:      10,
+			startJoinedAt:    now.Add(-24 * time.Hour).Format(time.RFC3339),
+			endJoinedAt:      now.Format(time.RFC3339),
+			startDeliveredAt: now.Add(-48 * time.Hour).Format(time.RFC3339),
+			endDeliveredAt:   now.Add(-24 * time.Hour).Format(time.RFC3339),
+		}
 	}
 
 	t.Run("ValidSearch", func(t *testing.T) {
-		search, err := New(validSearch.ClientID, validSearch.Port, validSearch.Vault, validSearch.GuideNumber,
-			validSearch.Type, validSearch.VehiclePlate, validSearch.PriceRange.Start, validSearch.PriceRange.End,
-			validSearch.QuantityRange.Start, validSearch.QuantityRange.End, validSearch.JoinedAtRange.Start.Format(time.RFC3339),
-			validSearch.JoinedAtRange.End.Format(time.RFC3339), validSearch.DeliveredAtRange.Start.Format(time.RFC3339), validSearch.DeliveredAtRange.End.Format(time.RFC3339))
+		search, err := validArgs().new()
 		assert.NoError(t, err)
-		if assert.WithinDuration(t, validSearch.JoinedAtRange.Start, search.JoinedAtRange.Start, time.Second) {
-			validSearch.JoinedAtRange.Start = search.JoinedAtRange.Start
-		}
-		if assert.WithinDuration(t, validSearch.JoinedAtRange.End, search.JoinedAtRange.End, time.Second) {
-			validSearch.JoinedAtRange.End = search.JoinedAtRange.End
-		}
-		if assert.WithinDuration(t, validSearch.DeliveredAtRange.Start, search.DeliveredAtRange.Start, time.Second) {
-			validSearch.DeliveredAtRange.Start = search.DeliveredAtRange.Start
-		}
-		if assert.WithinDuration(t, validSearch.DeliveredAtRange.End, search.DeliveredAtRange.End, time.Second) {
-			validSearch.DeliveredAtRange.End = search.DeliveredAtRange.End
-		}
-		assert.Equal(t, validSearch, search)
-	})
-
-	t.Run("InvalidPort", func(t *testing.T) {
-		search, err := New(invalidPort.ClientID, invalidPort.Port, invalidPort.Vault, invalidPort.GuideNumber,
-			invalidPort.Type, invalidPort.VehiclePlate, invalidPort.PriceRange.Start, invalidPort.PriceRange.End,
-			invalidPort.QuantityRange.Start, invalidPort.QuantityRange.End, invalidPort.JoinedAtRange.Start.Format(time.RFC3339),
-			invalidPort.JoinedAtRange.End.Format(time.RFC3339), invalidPort.DeliveredAtRange.Start.Format(time.RFC3339), invalidPort.DeliveredAtRange.End.Format(time.RFC3339))
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "invalid port")
-		assert.Empty(t, search)
+		assert.Equal(t, Search{
+			ClientID:         1,
+			Port:             IntFilter{Values: []int{80}},
+			Vault:            IntFilter{Values: []int{2}},
+			GuideNumber:      StringFilter{Values: []string{"ABC1234567"}},
+			VehiclePlate:     StringFilter{Values: []string{"ABC-123"}},
+			Status:           StringFilter{Values: []string{"IN_TRANSIT"}},
+			PriceRange:       RangeFloat64{Start: 100.0, End: 200.0},
+			QuantityRange:    RangeInt{Start: 5, End: 10},
+			JoinedAtRange:    RangeTime{Start: parseTimeIgnoringError(validArgs().startJoinedAt), End: parseTimeIgnoringError(validArgs().endJoinedAt)},
+			DeliveredAtRange: RangeTime{Start: parseTimeIgnoringError(validArgs().startDeliveredAt), End: parseTimeIgnoringError(validArgs().endDeliveredAt)},
+		}, search)
 	})
 
-	t.Run("InvalidVault", func(t *testing.T) {
-		search, err := New(invalidVault.ClientID, invalidVault.Port, invalidVault.Vault, invalidVault.GuideNumber,
-			invalidVault.Type, invalidVault.VehiclePlate, invalidVault.PriceRange.Start, invalidVault.PriceRange.End,
-			invalidVault.QuantityRange.Start, invalidVault.QuantityRange.End, invalidVault.JoinedAtRange.Start.Format(time.RFC3339),
-			invalidVault.JoinedAtRange.End.Format(time.RFC3339), invalidVault.DeliveredAtRange.Start.Format(time.RFC3339), invalidVault.DeliveredAtRange.End.Format(time.RFC3339))
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "invalid vault")
-		assert.Empty(t, search)
-	})
-
-	t.Run("InvalidGuideNumber", func(t *testing.T) {
-		search, err := New(invalidGuideNumber.ClientID, invalidGuideNumber.Port, invalidGuideNumber.Vault, invalidGuideNumber.GuideNumber,
-			invalidGuideNumber.Type, invalidGuideNumber.VehiclePlate, invalidGuideNumber.PriceRange.Start, invalidGuideNumber.PriceRange.End,
-			invalidGuideNumber.QuantityRange.Start, invalidGuideNumber.QuantityRange.End, invalidGuideNumber.JoinedAtRange.Start.Format(time.RFC3339),
-			invalidGuideNumber.JoinedAtRange.End.Format(time.RFC3339), invalidGuideNumber.DeliveredAtRange.Start.Format(time.RFC3339), invalidGuideNumber.DeliveredAtRange.End.Format(time.RFC3339))
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "invalid guide number format")
-		assert.Empty(t, search)
-	})
-
-	t.Run("InvalidVehiclePlate", func(t *testing.T) {
-		search, err := New(invalidVehiclePlate.ClientID, invalidVehiclePlate.Port, invalidVehiclePlate.Vault, invalidVehiclePlate.GuideNumber,
-			invalidVehiclePlate.Type, invalidVehiclePlate.VehiclePlate, invalidVehiclePlate.PriceRange.Start, invalidVehiclePlate.PriceRange.End,
-			invalidVehiclePlate.QuantityRange.Start, invalidVehiclePlate.QuantityRange.End, invalidVehiclePlate.JoinedAtRange.Start.Format(time.RFC3339),
-			invalidVehiclePlate.JoinedAtRange.End.Format(time.RFC3339), invalidVehiclePlate.DeliveredAtRange.Start.Format(time.RFC3339), invalidVehiclePlate.DeliveredAtRange.End.Format(time.RFC3339))
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "invalid vehicle plate format")
-		assert.Empty(t, search)
+	t.Run("EmptySearch", func(t *testing.T) {
+		search, err := searchArgs{}.new()
+		assert.NoError(t, err)
+		assert.Equal(t, Search{}, search)
 	})
 
-	t.Run("InvalidPriceRange", func(t *testing.T) {
-		search, err := New(invalidPriceRange.ClientID, invalidPriceRange.Port, invalidPriceRange.Vault, invalidPriceRange.GuideNumber,
-			invalidPriceRange.Type, invalidPriceRange.VehiclePlate, invalidPriceRange.PriceRange.Start, invalidPriceRange.PriceRange.End,
-			invalidPriceRange.QuantityRange.Start, invalidPriceRange.QuantityRange.End, invalidPriceRange.JoinedAtRange.Start.Format(time.RFC3339),
-			invalidPriceRange.JoinedAtRange.End.Format(time.RFC3339), invalidPriceRange.DeliveredAtRange.Start.Format(time.RFC3339), invalidPriceRange.DeliveredAtRange.End.Format(time.RFC3339))
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "invalid price range")
-		assert.Empty(t, search)
+	t.Run("OpenRanges", func(t *testing.T) {
+		end := validArgs().endDeliveredAt
+		args := searchArgs{startPrice: 100.0, endQuantity: 10, startJoinedAt: "2024-03", endDeliveredAt: end}
+		search, err := args.new()
+		assert.NoError(t, err)
+		assert.Equal(t, RangeFloat64{Start: 100.0}, search.PriceRange)
+		assert.Equal(t, RangeInt{End: 10}, search.QuantityRange)
+		assert.Equal(t, RangeTime{Start: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)}, search.JoinedAtRange)
+		assert.Equal(t, RangeTime{End: parseTimeIgnoringError(end)}, search.DeliveredAtRange)
 	})
 
-	t.Run("InvalidQuantityRange", func(t *testing.T) {
-		search, err := New(invalidQuantityRange.ClientID, invalidQuantityRange.Port, invalidQuantityRange.Vault, invalidQuantityRange.GuideNumber,
-			invalidQuantityRange.Type, invalidQuantityRange.VehiclePlate, invalidQuantityRange.PriceRange.Start, invalidQuantityRange.PriceRange.End,
-			invalidQuantityRange.QuantityRange.Start, invalidQuantityRange.QuantityRange.End, invalidQuantityRange.JoinedAtRange.Start.Format(time.RFC3339),
-			invalidQuantityRange.JoinedAtRange.End.Format(time.RFC3339), invalidQuantityRange.DeliveredAtRange.Start.Format(time.RFC3339), invalidQuantityRange.DeliveredAtRange.End.Format(time.RFC3339))
+	t.Run("CalendarRanges", func(t *testing.T) {
+		// A start bound is the start of its period, and an end bound its end, the excluded start of the next one.
+		args := searchArgs{startJoinedAt: "2024", endJoinedAt: "2024-03", startDeliveredAt: "2024-03-05", endDeliveredAt: "2024-03-05"}
+		search, err := args.new()
+		assert.NoError(t, err)
+		assert.Equal(t, RangeTime{
+			Start:       time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+			End:         time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC),
+			EndExcluded: true,
+		}, search.JoinedAtRange)
+		assert.Equal(t, RangeTime{
+			Start:       time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC),
+			End:         time.Date(2024, time.March, 6, 0, 0, 0, 0, time.UTC),
+			EndExcluded: true,
+		}, search.DeliveredAtRange)
+
+		// A period ending where the start period begins is before it.
+		args = searchArgs{startJoinedAt: "2024-04", endJoinedAt: "2024-03"}
+		_, err = args.new()
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "invalid quantity range")
-		assert.Empty(t, search)
 	})
 
-	t.Run("InvalidJoinedAtRange", func(t *testing.T) {
-		search, err := New(invalidJoinedAtRange.ClientID, invalidJoinedAtRange.Port, invalidJoinedAtRange.Vault, invalidJoinedAtRange.GuideNumber,
-			invalidJoinedAtRange.Type, invalidJoinedAtRange.VehiclePlate, invalidJoinedAtRange.PriceRange.Start, invalidJoinedAtRange.PriceRange.End,
-			invalidJoinedAtRange.QuantityRange.Start, invalidJoinedAtRange.QuantityRange.End, invalidJoinedAtRange.JoinedAtRange.Start.Format(time.RFC3339),
-			invalidJoinedAtRange.JoinedAtRange.End.Format(time.RFC3339), invalidJoinedAtRange.DeliveredAtRange.Start.Format(time.RFC3339), invalidJoinedAtRange.DeliveredAtRange.End.Format(time.RFC3339))
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "invalid joined at range")
-		assert.Empty(t, search)
+	t.Run("RichFilters", func(t *testing.T) {
+		args := searchArgs{
+			port:         "1, 2,3",
+			vault:        "!none",
+			guideNumber:  "ABC*,DEF1234567",
+			productType:  "!box,pallet",
+			vehiclePlate: "ABC-1*,none",
+			status:       "REGISTERED,IN_TRANSIT",
+		}
+		search, err := args.new()
+		assert.NoError(t, err)
+		assert.Equal(t, Search{
+			Port:         IntFilter{Values: []int{1, 2, 3}},
+			Vault:        IntFilter{Missing: true, Not: true},
+			GuideNumber:  StringFilter{Values: []string{"DEF1234567"}, Prefixes: []string{"ABC"}},
+			Type:         StringFilter{Values: []string{"box", "pallet"}, Not: true},
+			VehiclePlate: StringFilter{Prefixes: []string{"ABC-1"}, Missing: true},
+			Status:       StringFilter{Values: []string{"REGISTERED", "IN_TRANSIT"}},
+		}, search)
 	})
 
-	t.Run("InvalidDeliveredAtRange", func(t *testing.T) {
-		search, err := New(invalidDeliveredAtRange.ClientID, invalidDeliveredAtRange.Port, invalidDeliveredAtRange.Vault, invalidDeliveredAtRange.GuideNumber,
-			invalidDeliveredAtRange.Type, invalidDeliveredAtRange.VehiclePlate, invalidDeliveredAtRange.PriceRange.Start, invalidDeliveredAtRange.PriceRange.End,
-			invalidDeliveredAtRange.QuantityRange.Start, invalidDeliveredAtRange.QuantityRange.End, invalidDeliveredAtRange.JoinedAtRange.Start.Format(time.RFC3339),
-			invalidDeliveredAtRange.JoinedAtRange.End.Format(time.RFC3339), invalidDeliveredAtRange.DeliveredAtRange.Start.Format(time.RFC3339), invalidDeliveredAtRange.DeliveredAtRange.End.Format(time.RFC3339))
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "invalid delivered at range")
-		assert.Empty(t, search)
-	})
+	invalidCases := []struct {
+		name     string
+		modify   func(a *searchArgs)
+		expected string
+	}{
+		{name: "InvalidPort", modify: func(a *searchArgs) { a.port = "-1" }, expected: "invalid port"},
+		{name: "PortNotANumber", modify: func(a *searchArgs) { a.port = "1,a" }, expected: "invalid port: a is not a number"},
+		{name: "EmptyPortValue", modify: func(a *searchArgs) { a.port = "1,,2" }, expected: "invalid port: empty value"},
+		{name: "InvalidVault", modify: func(a *searchArgs) { a.vault = "!3,-2" }, expected: "invalid vault"},
+		{name: "InvalidGuideNumber", modify: func(a *searchArgs) { a.guideNumber = "ABC-123" }, expected: "invalid guide number format"},
+		{name: "InvalidGuideNumberPrefix", modify: func(a *searchArgs) { a.guideNumber = "ABC-*" }, expected: "invalid guide number prefix"},
+		{name: "InvalidVehiclePlate", modify: func(a *searchArgs) { a.vehiclePlate = "123-ABC" }, expected: "invalid vehicle plate format"},
+		{name: "InvalidVehiclePlatePrefix", modify: func(a *searchArgs) { a.vehiclePlate = "AB-*" }, expected: "invalid vehicle plate prefix"},
+		{name: "InvalidStatus", modify: func(a *searchArgs) { a.status = "IN_TRANSIT,LOST" }, expected: "invalid status"},
+		{name: "StatusPrefix", modify: func(a *searchArgs) { a.status = "IN_*" }, expected: "invalid status: status can not be matched by prefix"},
+		{name: "TypePrefix", modify: func(a *searchArgs) { a.productType = "bo*" }, expected: "invalid type: type can not be matched by prefix"},
+		{name: "EmptyNegation", modify: func(a *searchArgs) { a.productType = "!" }, expected: "invalid type: empty value"},
+		{name: "InvalidPriceRange", modify: func(a *searchArgs) { a.startPrice, a.endPrice = 200.0, 100.0 }, expected: "invalid price range"},
+		{name: "InvalidQuantityRange", modify: func(a *searchArgs) { a.startQuantity, a.endQuantity = 10, 5 }, expected: "invalid quantity range"},
+		{name: "InvalidJoinedAtRange", modify: func(a *searchArgs) { a.startJoinedAt, a.endJoinedAt = a.endJoinedAt, a.startJoinedAt }, expected: "invalid joined at range"},
+		{name: "InvalidDeliveredAtRange", modify: func(a *searchArgs) { a.startDeliveredAt, a.endDeliveredAt = a.endDeliveredAt, a.startDeliveredAt }, expected: "invalid delivered at range"},
+		{name: "InvalidJoinedAtFormat", modify: func(a *searchArgs) { a.startJoinedAt = "the other day" }, expected: "invalid start joined at"},
+		{name: "InvalidDeliveredAtFormat", modify: func(a *searchArgs) { a.endDeliveredAt = "last_7y" }, expected: "invalid end delivered at"},
+		{name: "InvalidCalendarRange", modify: func(a *searchArgs) { a.startJoinedAt, a.endJoinedAt = "2024-03", "2024-02" }, expected: "invalid joined at range"},
+	}
+	for _, tc := range invalidCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args := validArgs()
+			tc.modify(&args)
+			search, err := args.new()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+			assert.Empty(t, search)
+		})
+	}
 }
 
 func parseTimeIgnoringError(s string) time.Time {
@@ -0,0 +1,139 @@
+package search
+
+import "fmt"
+
+// DEFAULT_PAGE_SIZE is the number of results of a page when no page size is requested.
+const DEFAULT_PAGE_SIZE = 20
+
+// Pagination represents the window of results to retrieve.
+type Pagination struct {
+	Limit  int     // Maximum number of results to retrieve, every result if zero.
+	Offset int     // Number of results to skip.
+	After  *Cursor // Position the results are retrieved after, from the start if nil.
+	Sort   Sort    // Fields the results are sorted by, before their IDs.
+}
+
+// NewPagination creates a new Pagination instance for a 1-based page of pageSize results.
+// A zero page or pageSize selects the first page or the default page size.
+// A zero maxPageSize leaves the page size unlimited.
+func NewPagination(page, pageSize, maxPageSize int) (p Pagination, err error) {
+	if page == 0 {
+		page = 1
+	}
+	if page < 0 {
+		err = fmt.Errorf("invalid page: page must be greater than zero")
+		return
+	}
+
+	pageSize, err = validatePageSize(pageSize, maxPageSize, "page size")
+	if err != nil {
+		return
+	}
+
+	p.Limit = pageSize
+	p.Offset = (page - 1) * pageSize
+	return
+}
+
+// NewOffsetPagination creates a new Pagination instance skipping offset results and retrieving up to limit results.
+// A zero limit selects the default page size. A zero maxPageSize leaves the limit unlimited.
+func NewOffsetPagination(limit, offset, maxPageSize int) (p Pagination, err error) {
+	if offset < 0 {
+		err = fmt.Errorf("invalid offset: offset must not be negative")
+		return
+	}
+
+	limit, err = validatePageSize(limit, maxPageSize, "limit")
+	if err != nil {
+		return
+	}
+
+	p.Limit = limit
+	p.Offset = offset
+	return
+}
+
+// NewCursorPagination creates a new Pagination instance retrieving up to limit results after the cursor.
+// A nil cursor starts from the first result. A zero limit selects the default page size.
+// A zero maxPageSize leaves the limit unlimited.
+func NewCursorPagination(after *Cursor, limit, maxPageSize int) (p Pagination, err error) {
+	limit, err = validatePageSize(limit, maxPageSize, "limit")
+	if err != nil {
+		return
+	}
+
+	p.Limit = limit
+	p.After = after
+	return
+}
+
+// Sorted returns a copy of the pagination with its results sorted by s.
+// A pagination with a cursor can only be sorted the way the cursor was created.
+func (p Pagination) Sorted(s Sort) (sorted Pagination, err error) {
+	if p.After != nil && p.After.Sort != s.String() {
+		err = fmt.Errorf("invalid cursor: cursor was created for another sort")
+		return
+	}
+	sorted = p
+	sorted.Sort = s
+	return
+}
+
+// Page returns the 1-based number of the page the pagination starts in.
+func (p Pagination) Page() int {
+	if p.Limit <= 0 {
+		return 1
+	}
+	return p.Offset/p.Limit + 1
+}
+
+// HasNext reports whether there are results after the pagination, out of total results.
+func (p Pagination) HasNext(total int) bool {
+	return p.Limit > 0 && p.Offset+p.Limit < total
+}
+
+// HasPrev reports whether there are results before the pagination.
+func (p Pagination) HasPrev() bool {
+	return p.Offset > 0
+}
+
+// Next returns the pagination of the results following p.
+func (p Pagination) Next() Pagination {
+	return Pagination{
+		Limit:  p.Limit,
+		Offset: p.Offset + p.Limit,
+	}
+}
+
+// Prev returns the pagination of the results preceding p, never before the first result.
+func (p Pagination) Prev() Pagination {
+	offset := p.Offset - p.Limit
+	if offset < 0 {
+		offset = 0
+	}
+	return Pagination{
+		Limit:  p.Limit,
+		Offset: offset,
+	}
+}
+
+// validatePageSize checks if size is positive and not greater than max, unless max is zero.
+// A zero size is replaced by the default page size, bounded by max.
+func validatePageSize(size, max int, name string) (validated int, err error) {
+	if size < 0 {
+		err = fmt.Errorf("invalid %s: %s must be greater than zero", name, name)
+		return
+	}
+	if max > 0 && size > max {
+		err = fmt.Errorf("invalid %s: %s must not be greater than %d", name, name, max)
+		return
+	}
+	validated = size
+	if validated == 0 {
+		validated = DEFAULT_PAGE_SIZE
+		if max > 0 && max < validated {
+			validated = max
+		}
+	}
+	return
+}
@@ -0,0 +1,136 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPagination(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		p, err := NewPagination(0, 0, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, Pagination{Limit: DEFAULT_PAGE_SIZE, Offset: 0}, p)
+	})
+
+	t.Run("DefaultBoundedByMax", func(t *testing.T) {
+		p, err := NewPagination(0, 0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, Pagination{Limit: 10, Offset: 0}, p)
+	})
+
+	t.Run("Page", func(t *testing.T) {
+		p, err := NewPagination(3, 25, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, Pagination{Limit: 25, Offset: 50}, p)
+		assert.Equal(t, 3, p.Page())
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		p, err := NewPagination(1, 5000, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, Pagination{Limit: 5000, Offset: 0}, p)
+	})
+
+	t.Run("InvalidPage", func(t *testing.T) {
+		_, err := NewPagination(-1, 20, 100)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid page")
+	})
+
+	t.Run("InvalidPageSize", func(t *testing.T) {
+		_, err := NewPagination(1, -20, 100)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid page size")
+	})
+
+	t.Run("PageSizeOverMax", func(t *testing.T) {
+		_, err := NewPagination(1, 101, 100)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "page size must not be greater than 100")
+	})
+}
+
+func TestNewOffsetPagination(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		p, err := NewOffsetPagination(10, 35, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, Pagination{Limit: 10, Offset: 35}, p)
+		assert.Equal(t, 4, p.Page())
+	})
+
+	t.Run("DefaultLimit", func(t *testing.T) {
+		p, err := NewOffsetPagination(0, 5, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, Pagination{Limit: DEFAULT_PAGE_SIZE, Offset: 5}, p)
+	})
+
+	t.Run("InvalidOffset", func(t *testing.T) {
+		_, err := NewOffsetPagination(10, -1, 100)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid offset")
+	})
+
+	t.Run("LimitOverMax", func(t *testing.T) {
+		_, err := NewOffsetPagination(200, 0, 100)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "limit must not be greater than 100")
+	})
+}
+
+func TestPaginationLinks(t *testing.T) {
+	p := Pagination{Limit: 20, Offset: 10}
+
+	assert.True(t, p.HasPrev())
+	assert.Equal(t, Pagination{Limit: 20, Offset: 0}, p.Prev())
+	assert.True(t, p.HasNext(31))
+	assert.False(t, p.HasNext(30))
+	assert.Equal(t, Pagination{Limit: 20, Offset: 30}, p.Next())
+
+	first := Pagination{Limit: 20}
+	assert.False(t, first.HasPrev())
+}
+
+func TestNewCursorPagination(t *testing.T) {
+	t.Run("Start", func(t *testing.T) {
+		p, err := NewCursorPagination(nil, 0, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, Pagination{Limit: DEFAULT_PAGE_SIZE}, p)
+	})
+
+	t.Run("After", func(t *testing.T) {
+		p, err := NewCursorPagination(&Cursor{ID: 42}, 10, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, 10, p.Limit)
+		assert.Equal(t, &Cursor{ID: 42}, p.After)
+	})
+
+	t.Run("LimitOverMax", func(t *testing.T) {
+		_, err := NewCursorPagination(nil, 101, 100)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "limit must not be greater than 100")
+	})
+}
+
+func TestPaginationSorted(t *testing.T) {
+	s, err := NewProductSort("-delivered_at,port")
+	assert.NoError(t, err)
+
+	t.Run("WithoutCursor", func(t *testing.T) {
+		p, err := Pagination{Limit: 20}.Sorted(s)
+		assert.NoError(t, err)
+		assert.Equal(t, s, p.Sort)
+	})
+
+	t.Run("CursorWithSameSort", func(t *testing.T) {
+		p, err := Pagination{Limit: 20, After: &Cursor{Sort: "-delivered_at,port", Values: []interface{}{nil, 1}, ID: 3}}.Sorted(s)
+		assert.NoError(t, err)
+		assert.Equal(t, s, p.Sort)
+	})
+
+	t.Run("CursorWithAnotherSort", func(t *testing.T) {
+		_, err := Pagination{Limit: 20, After: &Cursor{ID: 3}}.Sorted(s)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cursor was created for another sort")
+	})
+}
@@ -0,0 +1,131 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/coffemanfp/docucentertest/product"
+)
+
+// MAX_QUERY_TERMS is the maximum number of terms of a full-text query.
+const MAX_QUERY_TERMS = 10
+
+// MAX_QUERY_TERM_LENGTH is the maximum number of characters of a term of a full-text query.
+const MAX_QUERY_TERM_LENGTH = 50
+
+// NewQuery normalizes a full-text query typed by a user into its lowercase terms, separated by single spaces.
+// The terms are the runs of letters, digits and hyphens of q, so any other character separates them.
+// A product matches a query if it matches every term of it, see MatchesQuery.
+func NewQuery(q string) (query string, err error) {
+	terms := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+	if len(terms) > MAX_QUERY_TERMS {
+		err = fmt.Errorf("invalid query: query must not have more than %d terms", MAX_QUERY_TERMS)
+		return
+	}
+	for _, term := range terms {
+		if len([]rune(term)) > MAX_QUERY_TERM_LENGTH {
+			err = fmt.Errorf("invalid query: %s is longer than %d characters", term, MAX_QUERY_TERM_LENGTH)
+			return
+		}
+	}
+	query = strings.Join(terms, " ")
+	return
+}
+
+// Terms returns the terms of the full-text query of the search, none if it has no query.
+func (s Search) Terms() []string {
+	return strings.Fields(s.Query)
+}
+
+// Ranked reports whether the products are sorted by relevance, when there is a full-text query and no sort.
+func (s Search) Ranked() bool {
+	return s.Query != "" && len(s.Pagination.Sort) == 0
+}
+
+// TermWords returns the words of a term of a full-text query, its runs of letters and digits.
+func TermWords(term string) []string {
+	return strings.FieldsFunc(term, func(r rune) bool {
+		return r == '-'
+	})
+}
+
+// MatchesQuery reports whether the product matches every term of the full-text query of the search.
+// A term matches if every word of it is the start of a word of the type, guide number or vehicle plate of the product,
+// or if the term is part of the guide number or vehicle plate, ignoring the case.
+func (s Search) MatchesQuery(p product.Product) bool {
+	words := documentWords(p)
+	for _, term := range s.Terms() {
+		if !containsTerm(p, term) && !prefixesWords(TermWords(term), words) {
+			return false
+		}
+	}
+	return true
+}
+
+// Rank returns the relevance of the product for the full-text query of the search, greater the more relevant.
+// A term equal to the guide number or vehicle plate of the product adds one, and so does every word of a term
+// equal to a word of its type, guide number or vehicle plate.
+func (s Search) Rank(p product.Product) (rank int) {
+	words := documentWords(p)
+	for _, term := range s.Terms() {
+		if equalsFold(p.GuideNumber, term) || equalsFold(p.VehiclePlate, term) {
+			rank++
+		}
+		for _, w := range TermWords(term) {
+			for _, word := range words {
+				if word == w {
+					rank++
+					break
+				}
+			}
+		}
+	}
+	return
+}
+
+// documentWords returns the lowercase words of the type, guide number and vehicle plate of the product.
+func documentWords(p product.Product) []string {
+	document := strings.Join([]string{stringOf(p.Type), stringOf(p.GuideNumber), stringOf(p.VehiclePlate)}, " ")
+	return strings.FieldsFunc(strings.ToLower(document), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// containsTerm reports whether the term is part of the guide number or vehicle plate of the product, ignoring the case.
+func containsTerm(p product.Product, term string) bool {
+	return strings.Contains(strings.ToLower(stringOf(p.GuideNumber)), term) ||
+		strings.Contains(strings.ToLower(stringOf(p.VehiclePlate)), term)
+}
+
+// prefixesWords reports whether every word of ws is the start of one of words.
+func prefixesWords(ws, words []string) bool {
+	if len(ws) == 0 {
+		return false
+	}
+	for _, w := range ws {
+		found := false
+		for _, word := range words {
+			found = found || strings.HasPrefix(word, w)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// equalsFold reports whether v is not nil and equal to s, ignoring the case.
+func equalsFold(v *string, s string) bool {
+	return v != nil && strings.EqualFold(*v, s)
+}
+
+// stringOf returns the string v points to, or an empty string if it is nil.
+func stringOf(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
@@ -0,0 +1,71 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewQuery(t *testing.T) {
+	t.Run("Normalized", func(t *testing.T) {
+		query, err := NewQuery("  ABC-123, Pallet  'GUIDE*' ")
+		assert.NoError(t, err)
+		assert.Equal(t, "abc-123 pallet guide", query)
+		assert.Equal(t, []string{"abc-123", "pallet", "guide"}, Search{Query: query}.Terms())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		query, err := NewQuery(" %_ ")
+		assert.NoError(t, err)
+		assert.Empty(t, query)
+		assert.Empty(t, Search{Query: query}.Terms())
+	})
+
+	t.Run("TooManyTerms", func(t *testing.T) {
+		_, err := NewQuery(strings.Repeat("a ", MAX_QUERY_TERMS+1))
+		assert.ErrorContains(t, err, "invalid query")
+	})
+
+	t.Run("TermTooLong", func(t *testing.T) {
+		_, err := NewQuery(strings.Repeat("a", MAX_QUERY_TERM_LENGTH+1))
+		assert.ErrorContains(t, err, "invalid query")
+	})
+}
+
+func TestSearchRanked(t *testing.T) {
+	assert.False(t, Search{}.Ranked())
+	assert.True(t, Search{Query: "box"}.Ranked())
+	assert.False(t, Search{Query: "box", Pagination: Pagination{Sort: Sort{{Name: "port"}}}}.Ranked())
+}
+
+func TestSearchMatchesQuery(t *testing.T) {
+	productType, guideNumber, plate := "pallet", "GUIDE00042", "ABC-123"
+	p := product.Product{Type: &productType, GuideNumber: &guideNumber, VehiclePlate: &plate}
+
+	for query, expected := range map[string]bool{
+		"":             true,
+		"pal":          true,
+		"let":          false,
+		"0004":         true,
+		"abc-12":       true,
+		"bc-1":         true,
+		"abc 123":      true,
+		"pallet 0042":  true,
+		"pallet crate": false,
+	} {
+		assert.Equal(t, expected, Search{Query: query}.MatchesQuery(p), query)
+	}
+}
+
+func TestSearchRank(t *testing.T) {
+	productType, guideNumber, plate := "aaa-003 crate", "GUIDE00009", "AAA-009"
+	crate := product.Product{Type: &productType, GuideNumber: &guideNumber, VehiclePlate: &plate}
+	boxType, boxGuideNumber, boxPlate := "box", "GUIDE00003", "AAA-003"
+	box := product.Product{Type: &boxType, GuideNumber: &boxGuideNumber, VehiclePlate: &boxPlate}
+
+	srch := Search{Query: "aaa-003"}
+	assert.Equal(t, 3, srch.Rank(box))
+	assert.Equal(t, 2, srch.Rank(crate))
+}
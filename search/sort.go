@@ -0,0 +1,203 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/client"
+	"github.com/coffemanfp/docucentertest/product"
+)
+
+// valueKind represents the type of the values of a sortable field.
+type valueKind int
+
+// Types of the values of the sortable fields.
+const (
+	stringValue valueKind = iota // Text values
+	intValue                     // Integer values
+	floatValue                   // Floating-point values
+	timeValue                    // Timestamp values
+)
+
+// productSortFields maps the product columns the products can be sorted by to the type of their values.
+var productSortFields = map[string]valueKind{
+	"guide_number":   stringValue,
+	"type":           stringValue,
+	"quantity":       intValue,
+	"joined_at":      timeValue,
+	"delivered_at":   timeValue,
+	"shipping_price": floatValue,
+	"vehicle_plate":  stringValue,
+	"port":           intValue,
+	"vault":          intValue,
+	"status":         stringValue,
+}
+
+// clientSortFields maps the client columns the clients can be sorted by to the type of their values.
+var clientSortFields = map[string]valueKind{
+	"name":       stringValue,
+	"surname":    stringValue,
+	"created_at": timeValue,
+}
+
+// SortField represents a field the results are sorted by.
+type SortField struct {
+	Name string // Name of the column of the field.
+	Desc bool   // Whether the results are sorted in descending order.
+	Time bool   // Whether the values of the field are timestamps.
+}
+
+// Sort represents the fields the results are sorted by, in order of precedence.
+// The results are always sorted by ascending ID last, and missing values are sorted after every other value.
+type Sort []SortField
+
+// NewProductSort creates a new Sort instance for products from comma-separated product columns.
+// Every column is sorted in ascending order, unless it is prefixed with "-".
+func NewProductSort(v string) (s Sort, err error) {
+	return newSort(v, productSortFields)
+}
+
+// NewClientSort creates a new Sort instance for clients from comma-separated client columns.
+// Every column is sorted in ascending order, unless it is prefixed with "-".
+func NewClientSort(v string) (s Sort, err error) {
+	return newSort(v, clientSortFields)
+}
+
+// newSort creates a new Sort instance from comma-separated columns, which must be keys of fields.
+func newSort(v string, fields map[string]valueKind) (s Sort, err error) {
+	if v == "" {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(v, ",") {
+		f := SortField{Name: strings.TrimSpace(name)}
+		if strings.HasPrefix(f.Name, "-") {
+			f.Name = strings.TrimPrefix(f.Name, "-")
+			f.Desc = true
+		}
+
+		// Only the whitelisted columns can be sorted by, so they are safe to be written in a query.
+		kind, ok := fields[f.Name]
+		if !ok {
+			s = nil
+			err = fmt.Errorf("invalid sort: %s is not a sortable field", f.Name)
+			return
+		}
+		if seen[f.Name] {
+			s = nil
+			err = fmt.Errorf("invalid sort: %s is sorted by more than once", f.Name)
+			return
+		}
+		seen[f.Name] = true
+
+		f.Time = kind == timeValue
+		s = append(s, f)
+	}
+	return
+}
+
+// String returns the sort in the format read by NewProductSort and NewClientSort.
+func (s Sort) String() string {
+	names := make([]string, len(s))
+	for i, f := range s {
+		names[i] = f.Name
+		if f.Desc {
+			names[i] = "-" + f.Name
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// ProductValues returns the values of the sort fields of the product, nil for the missing ones.
+func (s Sort) ProductValues(p product.Product) (values []interface{}) {
+	values = make([]interface{}, len(s))
+	for i, f := range s {
+		values[i] = productValue(p, f.Name)
+	}
+	return
+}
+
+// productValue returns the value of the product column name, nil if it is missing.
+func productValue(p product.Product, name string) interface{} {
+	switch name {
+	case "guide_number":
+		return valueOf(p.GuideNumber)
+	case "type":
+		return valueOf(p.Type)
+	case "quantity":
+		return valueOf(p.Quantity)
+	case "joined_at":
+		return valueOf(p.JoinedAt)
+	case "delivered_at":
+		return valueOf(p.DeliveredAt)
+	case "shipping_price":
+		return valueOf(p.ShippingPrice)
+	case "vehicle_plate":
+		return valueOf(p.VehiclePlate)
+	case "port":
+		return valueOf(p.Port)
+	case "vault":
+		return valueOf(p.Vault)
+	case "status":
+		return string(p.Status)
+	}
+	return nil
+}
+
+// ClientValues returns the values of the sort fields of the client.
+func (s Sort) ClientValues(c client.Client) (values []interface{}) {
+	values = make([]interface{}, len(s))
+	for i, f := range s {
+		switch f.Name {
+		case "name":
+			values[i] = c.Name
+		case "surname":
+			values[i] = c.Surname
+		case "created_at":
+			values[i] = c.CreatedAt
+		}
+	}
+	return
+}
+
+// valueOf returns the value v points to, or nil if it is nil.
+func valueOf[T any](v *T) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// parseValue converts a value decoded from JSON to the type of the values of kind.
+func parseValue(v interface{}, kind valueKind) (parsed interface{}, err error) {
+	if v == nil {
+		return
+	}
+
+	switch kind {
+	case stringValue:
+		if s, ok := v.(string); ok {
+			parsed = s
+			return
+		}
+	case intValue:
+		if f, ok := v.(float64); ok && f == float64(int(f)) {
+			parsed = int(f)
+			return
+		}
+	case floatValue:
+		if f, ok := v.(float64); ok {
+			parsed = f
+			return
+		}
+	case timeValue:
+		if s, ok := v.(string); ok {
+			parsed, err = time.Parse(time.RFC3339Nano, s)
+			return
+		}
+	}
+	err = fmt.Errorf("unexpected value %v", v)
+	return
+}
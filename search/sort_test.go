@@ -0,0 +1,68 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/client"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProductSort(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		s, err := NewProductSort("")
+		assert.NoError(t, err)
+		assert.Empty(t, s)
+		assert.Equal(t, "", s.String())
+	})
+
+	t.Run("MultipleFields", func(t *testing.T) {
+		s, err := NewProductSort("-delivered_at, shipping_price")
+		assert.NoError(t, err)
+		assert.Equal(t, Sort{
+			{Name: "delivered_at", Desc: true, Time: true},
+			{Name: "shipping_price"},
+		}, s)
+		assert.Equal(t, "-delivered_at,shipping_price", s.String())
+	})
+
+	t.Run("UnknownField", func(t *testing.T) {
+		for _, v := range []string{"password", "id; drop table product", "client_id", "-", "port,", "name"} {
+			s, err := NewProductSort(v)
+			assert.Error(t, err, v)
+			assert.Contains(t, err.Error(), "is not a sortable field", v)
+			assert.Nil(t, s, v)
+		}
+	})
+
+	t.Run("RepeatedField", func(t *testing.T) {
+		_, err := NewProductSort("port,-port")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "port is sorted by more than once")
+	})
+}
+
+func TestNewClientSort(t *testing.T) {
+	s, err := NewClientSort("surname,-created_at,name")
+	assert.NoError(t, err)
+	assert.Equal(t, "surname,-created_at,name", s.String())
+
+	_, err = NewClientSort("username")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "username is not a sortable field")
+}
+
+func TestSortValues(t *testing.T) {
+	port := 3
+	p := product.Product{Port: &port, Status: product.REGISTERED_STATUS}
+	s, err := NewProductSort("port,vault,status")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{3, nil, "REGISTERED"}, s.ProductValues(p))
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := client.Client{Name: "Ana", Surname: "Diaz", CreatedAt: createdAt}
+	s, err = NewClientSort("created_at,surname")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{createdAt, "Diaz"}, s.ClientValues(c))
+}
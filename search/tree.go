@@ -0,0 +1,334 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/product"
+)
+
+// Operator represents the comparison of a leaf of a filter tree between a product field and its value.
+type Operator string
+
+// Operators of the leaves of a filter tree.
+const (
+	EQ_OPERATOR      Operator = "eq"      // The field is equal to the value.
+	NE_OPERATOR      Operator = "ne"      // The field is not equal to the value, or missing.
+	IN_OPERATOR      Operator = "in"      // The field is equal to one of the values of a list.
+	NIN_OPERATOR     Operator = "nin"     // The field is equal to none of the values of a list, or missing.
+	LT_OPERATOR      Operator = "lt"      // The field is less than the value.
+	LTE_OPERATOR     Operator = "lte"     // The field is less than or equal to the value.
+	GT_OPERATOR      Operator = "gt"      // The field is greater than the value.
+	GTE_OPERATOR     Operator = "gte"     // The field is greater than or equal to the value.
+	PREFIX_OPERATOR  Operator = "prefix"  // The field starts with the value.
+	MISSING_OPERATOR Operator = "missing" // The field is missing if the value is true, or present if false.
+)
+
+// MAX_FILTER_DEPTH is the maximum number of levels of a filter tree.
+const MAX_FILTER_DEPTH = 10
+
+// MAX_FILTER_NODES is the maximum number of nodes of a filter tree.
+const MAX_FILTER_NODES = 100
+
+// MAX_FILTER_VALUES is the maximum number of values of a list of a leaf.
+const MAX_FILTER_VALUES = 100
+
+// Filter represents a node of a filter tree over the product fields.
+// A node is either a conjunction (And), a disjunction (Or) or a negation (Not) of other nodes,
+// or a leaf comparing a Field with its Value through an Op.
+// A leaf on a missing field is false, so the negation of any leaf matches the products missing its field.
+type Filter struct {
+	And   []Filter    `json:"and,omitempty"`   // Nodes every matching product meets.
+	Or    []Filter    `json:"or,omitempty"`    // Nodes every matching product meets at least one of.
+	Not   *Filter     `json:"not,omitempty"`   // Node no matching product meets.
+	Field string      `json:"field,omitempty"` // Product column compared by the leaf.
+	Op    Operator    `json:"op,omitempty"`    // Comparison of the leaf.
+	Value interface{} `json:"value,omitempty"` // Value, or list of values, the field is compared to.
+}
+
+// FilterError represents an invalid node of a filter tree.
+type FilterError struct {
+	Path string // Path of the node from the root of the tree, such as "filter.and[1].not".
+	Err  error  // Reason the node is invalid.
+}
+
+// Error returns the reason the node is invalid, prefixed by its path.
+func (e FilterError) Error() string {
+	return fmt.Sprintf("invalid filter at %s: %s", e.Path, e.Err)
+}
+
+// Unwrap returns the reason the node is invalid.
+func (e FilterError) Unwrap() error {
+	return e.Err
+}
+
+// stringValidators checks the values of the product fields with a format.
+var stringValidators = map[string]func(string) error{
+	"guide_number":  validateGuideNumber,
+	"vehicle_plate": validateVehiclePlate,
+	"status":        validateStatus,
+}
+
+// prefixValidators checks the prefixes of the product fields that can be matched by prefix.
+var prefixValidators = map[string]func(string) error{
+	"guide_number":  validateGuideNumberPrefix,
+	"vehicle_plate": validateVehiclePlatePrefix,
+}
+
+// intValidators checks the values of the numeric product fields with a valid range.
+var intValidators = map[string]func(int) error{
+	"port":  product.ValidatePort,
+	"vault": product.ValidateVault,
+}
+
+// NewFilter creates a new Filter instance from a filter tree decoded from JSON.
+// Every node is validated, and the values of the leaves are converted to the type of their fields.
+// The returned error is a FilterError pointing to the first invalid node.
+func NewFilter(f Filter) (validated Filter, err error) {
+	nodes := 0
+	validated, err = newFilterNode(f, "filter", 1, &nodes)
+	if err != nil {
+		validated = Filter{}
+	}
+	return
+}
+
+// newFilterNode validates the node at path, at the given depth of the tree, counting it in nodes.
+func newFilterNode(f Filter, path string, depth int, nodes *int) (validated Filter, err error) {
+	*nodes++
+	if depth > MAX_FILTER_DEPTH {
+		err = FilterError{Path: path, Err: fmt.Errorf("filter must not be deeper than %d levels", MAX_FILTER_DEPTH)}
+		return
+	}
+	if *nodes > MAX_FILTER_NODES {
+		err = FilterError{Path: path, Err: fmt.Errorf("filter must not have more than %d nodes", MAX_FILTER_NODES)}
+		return
+	}
+
+	// A node must be exactly one of a conjunction, a disjunction, a negation or a leaf.
+	kinds := 0
+	for _, set := range []bool{f.And != nil, f.Or != nil, f.Not != nil, f.Field != "" || f.Op != "" || f.Value != nil} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		err = FilterError{Path: path, Err: errors.New("node must have exactly one of and, or, not, or field, op and value")}
+		return
+	}
+
+	switch {
+	case f.And != nil:
+		validated.And, err = newFilterNodes(f.And, path+".and", depth, nodes)
+	case f.Or != nil:
+		validated.Or, err = newFilterNodes(f.Or, path+".or", depth, nodes)
+	case f.Not != nil:
+		var not Filter
+		not, err = newFilterNode(*f.Not, path+".not", depth+1, nodes)
+		validated.Not = &not
+	default:
+		validated, err = newFilterLeaf(f)
+		if err != nil {
+			err = FilterError{Path: path, Err: err}
+		}
+	}
+	return
+}
+
+// newFilterNodes validates the children of a conjunction or disjunction at path.
+func newFilterNodes(fs []Filter, path string, depth int, nodes *int) (validated []Filter, err error) {
+	if len(fs) == 0 {
+		err = FilterError{Path: path, Err: errors.New("list of filters must not be empty")}
+		return
+	}
+
+	validated = make([]Filter, len(fs))
+	for i, f := range fs {
+		validated[i], err = newFilterNode(f, fmt.Sprintf("%s[%d]", path, i), depth+1, nodes)
+		if err != nil {
+			validated = nil
+			return
+		}
+	}
+	return
+}
+
+// newFilterLeaf validates the field, operator and value of a leaf.
+func newFilterLeaf(f Filter) (validated Filter, err error) {
+	// Only the whitelisted columns can be filtered by, so they are safe to be written in a query.
+	kind, ok := productSortFields[f.Field]
+	if !ok {
+		err = fmt.Errorf("%q is not a filterable field", f.Field)
+		return
+	}
+	validated = Filter{Field: f.Field, Op: f.Op}
+
+	switch f.Op {
+	case EQ_OPERATOR, NE_OPERATOR:
+		validated.Value, err = newFilterValue(f.Field, kind, f.Value)
+	case IN_OPERATOR, NIN_OPERATOR:
+		list, isList := f.Value.([]interface{})
+		if !isList || len(list) == 0 || len(list) > MAX_FILTER_VALUES {
+			err = fmt.Errorf("value of %s must be a list of 1 to %d values", f.Op, MAX_FILTER_VALUES)
+			return
+		}
+		values := make([]interface{}, len(list))
+		for i, v := range list {
+			values[i], err = newFilterValue(f.Field, kind, v)
+			if err != nil {
+				return
+			}
+		}
+		validated.Value = values
+	case LT_OPERATOR, LTE_OPERATOR, GT_OPERATOR, GTE_OPERATOR:
+		if kind == stringValue {
+			err = fmt.Errorf("%s can not be compared with %s", f.Field, f.Op)
+			return
+		}
+		validated.Value, err = newFilterValue(f.Field, kind, f.Value)
+	case PREFIX_OPERATOR:
+		validate, ok := prefixValidators[f.Field]
+		if !ok {
+			err = fmt.Errorf("%s can not be matched by prefix", f.Field)
+			return
+		}
+		prefix, isString := f.Value.(string)
+		if !isString {
+			err = fmt.Errorf("value of %s must be a string", f.Op)
+			return
+		}
+		err = validate(prefix)
+		validated.Value = prefix
+	case MISSING_OPERATOR:
+		missing, isBool := f.Value.(bool)
+		if !isBool {
+			err = fmt.Errorf("value of %s must be a boolean", f.Op)
+			return
+		}
+		validated.Value = missing
+	default:
+		err = fmt.Errorf("%q is not an operator", f.Op)
+	}
+	if err != nil {
+		validated = Filter{}
+	}
+	return
+}
+
+// newFilterValue converts a value of the field decoded from JSON to the type of the values of kind, and validates it.
+func newFilterValue(field string, kind valueKind, v interface{}) (value interface{}, err error) {
+	if v != nil {
+		value, err = parseValue(v, kind)
+	}
+	if v == nil || err != nil {
+		err = fmt.Errorf("value of %s must be %s", field, kindNames[kind])
+		return
+	}
+
+	switch value := value.(type) {
+	case string:
+		if validate, ok := stringValidators[field]; ok {
+			err = validate(value)
+		}
+	case int:
+		if validate, ok := intValidators[field]; ok {
+			err = validate(value)
+		}
+	}
+	return
+}
+
+// kindNames describes the types of the values of the fields in errors.
+var kindNames = map[valueKind]string{
+	stringValue: "a string",
+	intValue:    "an integer",
+	floatValue:  "a number",
+	timeValue:   "an RFC 3339 timestamp",
+}
+
+// Matches reports whether the product meets the filter tree.
+func (f Filter) Matches(p product.Product) bool {
+	switch {
+	case f.And != nil:
+		for _, child := range f.And {
+			if !child.Matches(p) {
+				return false
+			}
+		}
+		return true
+	case f.Or != nil:
+		for _, child := range f.Or {
+			if child.Matches(p) {
+				return true
+			}
+		}
+		return false
+	case f.Not != nil:
+		return !f.Not.Matches(p)
+	}
+
+	v := productValue(p, f.Field)
+	switch f.Op {
+	case MISSING_OPERATOR:
+		return (v == nil) == f.Value.(bool)
+	case NE_OPERATOR:
+		return !Filter{Field: f.Field, Op: EQ_OPERATOR, Value: f.Value}.Matches(p)
+	case NIN_OPERATOR:
+		return !Filter{Field: f.Field, Op: IN_OPERATOR, Value: f.Value}.Matches(p)
+	}
+
+	// Every other comparison of a missing field is false.
+	if v == nil {
+		return false
+	}
+	switch f.Op {
+	case EQ_OPERATOR:
+		return compareFilterValues(v, f.Value) == 0
+	case IN_OPERATOR:
+		for _, value := range f.Value.([]interface{}) {
+			if compareFilterValues(v, value) == 0 {
+				return true
+			}
+		}
+		return false
+	case LT_OPERATOR:
+		return compareFilterValues(v, f.Value) < 0
+	case LTE_OPERATOR:
+		return compareFilterValues(v, f.Value) <= 0
+	case GT_OPERATOR:
+		return compareFilterValues(v, f.Value) > 0
+	case GTE_OPERATOR:
+		return compareFilterValues(v, f.Value) >= 0
+	case PREFIX_OPERATOR:
+		return strings.HasPrefix(v.(string), f.Value.(string))
+	}
+	return false
+}
+
+// compareFilterValues compares a value of a field with a value of the same type of a leaf.
+func compareFilterValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case int:
+		return compareNumbers(a, b.(int))
+	case float64:
+		return compareNumbers(a, b.(float64))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	}
+	return 0
+}
+
+// compareNumbers compares two numbers.
+func compareNumbers[T int | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeFilter decodes the JSON filter tree v.
+func decodeFilter(t *testing.T, v string) Filter {
+	var f Filter
+	require.NoError(t, json.Unmarshal([]byte(v), &f))
+	return f
+}
+
+func TestNewFilter(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		f, err := NewFilter(decodeFilter(t, `{"and": [
+			{"field": "port", "op": "in", "value": [1, 2]},
+			{"not": {"field": "joined_at", "op": "lt", "value": "2024-03-01T00:00:00Z"}},
+			{"or": [{"field": "vehicle_plate", "op": "prefix", "value": "ABC-"}, {"field": "vault", "op": "missing", "value": true}]}
+		]}`))
+		assert.NoError(t, err)
+		assert.Equal(t, Filter{And: []Filter{
+			{Field: "port", Op: IN_OPERATOR, Value: []interface{}{1, 2}},
+			{Not: &Filter{Field: "joined_at", Op: LT_OPERATOR, Value: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)}},
+			{Or: []Filter{
+				{Field: "vehicle_plate", Op: PREFIX_OPERATOR, Value: "ABC-"},
+				{Field: "vault", Op: MISSING_OPERATOR, Value: true},
+			}},
+		}}, f)
+	})
+
+	for name, tc := range map[string]struct {
+		filter, path, reason string
+	}{
+		"UnknownField":        {`{"field": "password", "op": "eq", "value": "x"}`, "filter", `"password" is not a filterable field`},
+		"UnknownOperator":     {`{"field": "port", "op": "like", "value": 1}`, "filter", `"like" is not an operator`},
+		"MixedNode":           {`{"and": [{"field": "port", "op": "eq", "value": 1}], "field": "port"}`, "filter", "exactly one of"},
+		"EmptyList":           {`{"or": []}`, "filter.or", "must not be empty"},
+		"InvalidGuideNumber":  {`{"and": [{"field": "port", "op": "eq", "value": 1}, {"not": {"field": "guide_number", "op": "eq", "value": "ABC"}}]}`, "filter.and[1].not", "invalid guide number format"},
+		"InvalidPort":         {`{"or": [{"field": "port", "op": "in", "value": [1, -2]}]}`, "filter.or[0]", "invalid port"},
+		"NotAnInteger":        {`{"field": "quantity", "op": "eq", "value": 1.5}`, "filter", "value of quantity must be an integer"},
+		"NotATimestamp":       {`{"field": "delivered_at", "op": "gte", "value": "yesterday"}`, "filter", "must be an RFC 3339 timestamp"},
+		"OrderedString":       {`{"field": "type", "op": "lt", "value": "box"}`, "filter", "type can not be compared with lt"},
+		"PrefixOfType":        {`{"field": "type", "op": "prefix", "value": "bo"}`, "filter", "type can not be matched by prefix"},
+		"InvalidPlatePrefix":  {`{"field": "vehicle_plate", "op": "prefix", "value": "A1"}`, "filter", "invalid vehicle plate prefix"},
+		"MissingNotABoolean":  {`{"field": "vault", "op": "missing", "value": "yes"}`, "filter", "must be a boolean"},
+		"InNotAList":          {`{"field": "vault", "op": "in", "value": 1}`, "filter", "must be a list"},
+		"MissingValue":        {`{"field": "vault", "op": "eq"}`, "filter", "value of vault must be an integer"},
+		"InvalidStatusInList": {`{"field": "status", "op": "nin", "value": ["IN_TRANSIT", "LOST"]}`, "filter", "invalid status"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			f, err := NewFilter(decodeFilter(t, tc.filter))
+			var filterErr FilterError
+			require.True(t, errors.As(err, &filterErr), err)
+			assert.Equal(t, tc.path, filterErr.Path)
+			assert.Contains(t, filterErr.Err.Error(), tc.reason)
+			assert.Contains(t, err.Error(), "invalid filter at "+tc.path)
+			assert.Empty(t, f)
+		})
+	}
+
+	t.Run("TooDeep", func(t *testing.T) {
+		f := Filter{Field: "port", Op: EQ_OPERATOR, Value: 1.0}
+		for i := 0; i < MAX_FILTER_DEPTH; i++ {
+			f = Filter{Not: &f}
+		}
+		_, err := NewFilter(f)
+		assert.ErrorContains(t, err, "filter must not be deeper than")
+	})
+}
+
+func TestFilterMatches(t *testing.T) {
+	port, plate := 3, "ABC-123"
+	p := product.Product{Port: &port, VehiclePlate: &plate, Status: product.IN_TRANSIT_STATUS}
+
+	for filter, expected := range map[string]bool{
+		`{"field": "port", "op": "eq", "value": 3}`:                                                          true,
+		`{"field": "port", "op": "gt", "value": 3}`:                                                          false,
+		`{"field": "vault", "op": "eq", "value": 3}`:                                                         false,
+		`{"field": "vault", "op": "ne", "value": 3}`:                                                         true,
+		`{"field": "vault", "op": "nin", "value": [3]}`:                                                      true,
+		`{"field": "vault", "op": "missing", "value": true}`:                                                 true,
+		`{"field": "vehicle_plate", "op": "prefix", "value": "ABC"}`:                                         true,
+		`{"field": "status", "op": "in", "value": ["REGISTERED", "IN_TRANSIT"]}`:                             true,
+		`{"not": {"field": "vault", "op": "lte", "value": 3}}`:                                               true,
+		`{"and": [{"field": "port", "op": "lte", "value": 3}, {"field": "vault", "op": "gte", "value": 0}]}`: false,
+		`{"or": [{"field": "port", "op": "lte", "value": 3}, {"field": "vault", "op": "gte", "value": 0}]}`:  true,
+		`{"field": "joined_at", "op": "lt", "value": "2024-03-01T00:00:00Z"}`:                                false,
+		`{"not": {"field": "joined_at", "op": "lt", "value": "2024-03-01T00:00:00Z"}}`:                       true,
+	} {
+		f, err := NewFilter(decodeFilter(t, filter))
+		require.NoError(t, err, filter)
+		assert.Equal(t, expected, f.Matches(p), filter)
+	}
+}
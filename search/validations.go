@@ -2,41 +2,89 @@ package search
 
 import (
 	"fmt"
-	"time"
+	"regexp"
+
+	"github.com/coffemanfp/docucentertest/product"
 )
 
-// validatePriceRange checks if the start price is less than or equal to the end price.
+// guideNumberPrefix matches the start of a guide number, as validated by product.ValidateGuideNumber.
+var guideNumberPrefix = regexp.MustCompile(`^[A-Za-z0-9]{1,10}$`)
+
+// vehiclePlatePrefix matches the start of a vehicle plate, as validated by product.ValidateVehiclePlate.
+var vehiclePlatePrefix = regexp.MustCompile(`^([A-Za-z]{1,3}|[A-Za-z]{3}-[0-9]{0,3})$`)
+
+// validatePriceRange checks if the start price is less than or equal to the end price, unless the range is open.
 // If not, it returns an error indicating an invalid price range.
 func validatePriceRange(startPrice, endPrice float64) (err error) {
-	if startPrice > endPrice {
+	if endPrice != 0 && startPrice > endPrice {
 		err = fmt.Errorf("invalid price range: start price must be less than end price")
 	}
 	return
 }
 
-// validateQuantityRange checks if the start quantity is less than or equal to the end quantity.
+// validateQuantityRange checks if the start quantity is less than or equal to the end quantity, unless the range is open.
 // If not, it returns an error indicating an invalid quantity range.
 func validateQuantityRange(startQuantity, endQuantity int) (err error) {
-	if startQuantity > endQuantity {
+	if endQuantity != 0 && startQuantity > endQuantity {
 		err = fmt.Errorf("invalid quantity range: start quantity must be less than end quantity")
 	}
 	return
 }
 
-// validateDeliveredAtRange checks if the end delivered datetime is after the start delivered datetime.
+// validateDeliveredAtRange checks if the end delivered datetime is after the start delivered datetime, unless the range is open.
 // If not, it returns an error indicating an invalid delivered at range.
-func validateDeliveredAtRange(startDeliveredAt, endDeliveredAt time.Time) (err error) {
-	if endDeliveredAt.Before(startDeliveredAt) {
+func validateDeliveredAtRange(r RangeTime) (err error) {
+	if isEmptyTimeRange(r) {
 		err = fmt.Errorf("invalid delivered at range: start delivered datetime must be earlier than end delivered datetime")
 	}
 	return
 }
 
-// validateJoinedAtRange checks if the end joined datetime is after the start joined datetime.
+// validateJoinedAtRange checks if the end joined datetime is after the start joined datetime, unless the range is open.
 // If not, it returns an error indicating an invalid joined at range.
-func validateJoinedAtRange(startJoinedAt, endJoinedAt time.Time) (err error) {
-	if endJoinedAt.Before(startJoinedAt) {
+func validateJoinedAtRange(r RangeTime) (err error) {
+	if isEmptyTimeRange(r) {
 		err = fmt.Errorf("invalid joined at range: start joined datetime must be earlier than end joined datetime")
 	}
 	return
 }
+
+// isEmptyTimeRange reports whether a time range closed at both ends can not contain any time.
+// An excluded end can not be the start of the range.
+func isEmptyTimeRange(r RangeTime) bool {
+	if r.End.IsZero() {
+		return false
+	}
+	return r.End.Before(r.Start) || (r.EndExcluded && r.End.Equal(r.Start))
+}
+
+// validateGuideNumber checks if gn is a valid guide number.
+func validateGuideNumber(gn string) (err error) {
+	return product.ValidateGuideNumber(&gn)
+}
+
+// validateGuideNumberPrefix checks if prefix can be the start of a valid guide number.
+func validateGuideNumberPrefix(prefix string) (err error) {
+	if !guideNumberPrefix.MatchString(prefix) {
+		err = fmt.Errorf("invalid guide number: invalid guide number prefix of %s", prefix)
+	}
+	return
+}
+
+// validateVehiclePlate checks if vp is a valid vehicle plate.
+func validateVehiclePlate(vp string) (err error) {
+	return product.ValidateVehiclePlate(&vp)
+}
+
+// validateVehiclePlatePrefix checks if prefix can be the start of a valid vehicle plate.
+func validateVehiclePlatePrefix(prefix string) (err error) {
+	if !vehiclePlatePrefix.MatchString(prefix) {
+		err = fmt.Errorf("invalid vehicle plate: invalid vehicle plate prefix of %s", prefix)
+	}
+	return
+}
+
+// validateStatus checks if status is one of the product lifecycle stages.
+func validateStatus(status string) (err error) {
+	return product.ValidateStatus(product.Status(status))
+}
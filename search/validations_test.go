@@ -13,6 +13,11 @@ func TestValidatePriceRange(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("OpenRange", func(t *testing.T) {
+		err := validatePriceRange(10.0, 0)
+		assert.NoError(t, err)
+	})
+
 	t.Run("InvalidRange", func(t *testing.T) {
 		err := validatePriceRange(30.0, 20.0)
 		assert.Error(t, err)
@@ -26,6 +31,11 @@ func TestValidateQuantityRange(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("OpenRange", func(t *testing.T) {
+		err := validateQuantityRange(5, 0)
+		assert.NoError(t, err)
+	})
+
 	t.Run("InvalidRange", func(t *testing.T) {
 		err := validateQuantityRange(15, 10)
 		assert.Error(t, err)
@@ -38,15 +48,27 @@ func TestValidateDeliveredAtRange(t *testing.T) {
 	endTime := startTime.Add(24 * time.Hour)
 
 	t.Run("ValidRange", func(t *testing.T) {
-		err := validateDeliveredAtRange(startTime, endTime)
+		err := validateDeliveredAtRange(RangeTime{Start: startTime, End: endTime})
+		assert.NoError(t, err)
+	})
+
+	t.Run("OpenRange", func(t *testing.T) {
+		err := validateDeliveredAtRange(RangeTime{Start: endTime})
 		assert.NoError(t, err)
 	})
 
 	t.Run("InvalidRange", func(t *testing.T) {
-		err := validateDeliveredAtRange(endTime, startTime)
+		err := validateDeliveredAtRange(RangeTime{Start: endTime, End: startTime})
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "start delivered datetime must be earlier than end delivered datetime")
 	})
+
+	t.Run("ExcludedEndAtStart", func(t *testing.T) {
+		err := validateDeliveredAtRange(RangeTime{Start: startTime, End: startTime})
+		assert.NoError(t, err)
+		err = validateDeliveredAtRange(RangeTime{Start: startTime, End: startTime, EndExcluded: true})
+		assert.Error(t, err)
+	})
 }
 
 func TestValidateJoinedAtRange(t *testing.T) {
@@ -54,13 +76,25 @@ func TestValidateJoinedAtRange(t *testing.T) {
 	endTime := startTime.Add(24 * time.Hour)
 
 	t.Run("ValidRange", func(t *testing.T) {
-		err := validateJoinedAtRange(startTime, endTime)
+		err := validateJoinedAtRange(RangeTime{Start: startTime, End: endTime})
+		assert.NoError(t, err)
+	})
+
+	t.Run("OpenRange", func(t *testing.T) {
+		err := validateJoinedAtRange(RangeTime{Start: endTime})
 		assert.NoError(t, err)
 	})
 
 	t.Run("InvalidRange", func(t *testing.T) {
-		err := validateJoinedAtRange(endTime, startTime)
+		err := validateJoinedAtRange(RangeTime{Start: endTime, End: startTime})
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "start joined datetime must be earlier than end joined datetime")
 	})
+
+	t.Run("ExcludedEndAtStart", func(t *testing.T) {
+		err := validateJoinedAtRange(RangeTime{Start: startTime, End: startTime})
+		assert.NoError(t, err)
+		err = validateJoinedAtRange(RangeTime{Start: startTime, End: startTime, EndExcluded: true})
+		assert.Error(t, err)
+	})
 }
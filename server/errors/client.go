@@ -3,6 +3,15 @@ package errors
 // UNAUTHORIZED_ERROR_MESSAGE is a constant representing the error message for unauthorized access.
 const UNAUTHORIZED_ERROR_MESSAGE = "Wrong credentials, impostor!"
 
+// FORBIDDEN_ERROR_MESSAGE is a constant representing the error message for an authenticated client without enough privileges.
+const FORBIDDEN_ERROR_MESSAGE = "You shall not pass!"
+
+// CONFLICT_ERROR_MESSAGE is a constant representing the error message for a resource changed by another request.
+const CONFLICT_ERROR_MESSAGE = "Someone got there first! Try again."
+
+// TOO_MANY_REQUESTS_ERROR_MESSAGE is a constant representing the error message for a client exceeding the rate limit.
+const TOO_MANY_REQUESTS_ERROR_MESSAGE = "Easy there! Wait a moment before trying again."
+
 // NOT_FOUND_ERROR_MESSAGE is a constant representing the error message for a resource not being found.
 const NOT_FOUND_ERROR_MESSAGE = "Maybe it's on your imagination..."
 
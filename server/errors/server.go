@@ -2,3 +2,6 @@ package errors
 
 // INTERNAL_SERVER_ERROR_MESSAGE is a common message used when a internal server error is perfomed.
 const INTERNAL_SERVER_ERROR_MESSAGE = "That wasn't supposed to happen..."
+
+// TIMEOUT_ERROR_MESSAGE is a constant representing the error message for an operation that took too long.
+const TIMEOUT_ERROR_MESSAGE = "This is taking forever... Try again later."
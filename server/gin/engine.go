@@ -1,6 +1,9 @@
 package gin
 
 import (
+	"time"
+
+	"github.com/coffemanfp/docucentertest/auth"
 	"github.com/coffemanfp/docucentertest/config"
 	"github.com/coffemanfp/docucentertest/database"
 	"github.com/coffemanfp/docucentertest/server"
@@ -16,7 +19,8 @@ type GinEngine struct {
 }
 
 // New creates a new instance of the GinEngine.
-func New(conf config.ConfigInfo, db database.Database) server.Engine {
+// It returns an error if any of the configured trusted proxies is not a valid IP or CIDR.
+func New(conf config.ConfigInfo, db database.Database) (e server.Engine, err error) {
 	// Initialize a new GinEngine instance with the provided configuration and database
 	ge := GinEngine{
 		conf: conf,
@@ -24,8 +28,15 @@ func New(conf config.ConfigInfo, db database.Database) server.Engine {
 		r:    gin.New(),
 	}
 
-	// Initialize the handlers with the database repositories and configuration
-	handlers.Init(ge.db.Repositories, ge.conf)
+	// Only trust the client IPs forwarded by the configured proxies, so the rate limits can not be bypassed
+	// by sending a different X-Forwarded-For header with every request
+	err = ge.r.SetTrustedProxies(ge.conf.Server.TrustedProxies)
+	if err != nil {
+		return
+	}
+
+	// Initialize the handlers with the database and configuration
+	handlers.Init(ge.db, ge.conf)
 
 	// Use CORS middleware to handle cross-origin requests
 	ge.r.Use(newCors(ge.conf))
@@ -43,20 +54,32 @@ func New(conf config.ConfigInfo, db database.Database) server.Engine {
 	ge.setProductHandlers(v1)
 	// Set up search-related handlers
 	ge.setSearchHandlers(v1)
+	// Set up saved search handlers
+	ge.setSavedSearchHandlers(v1)
+	// Set up background job handlers
+	ge.setJobHandlers(v1)
 	// Set up client-related handlers
 	ge.setClientHandlers(v1)
+	// Set up quote-related handlers
+	ge.setQuoteHandlers(v1)
+	// Set up public tracking handlers
+	ge.setTrackHandlers(v1)
 
 	// Return the configured Gin engine
-	return ge.r
+	e = ge.r
+	return
 }
 
 // setAuthHandlers configures authentication-related routes and handlers.
 func (ge GinEngine) setAuthHandlers(r *gin.RouterGroup) {
 	// Create a sub-group for authentication routes
 	auth := r.Group("/auth")
-	// Configure the login and register endpoints with their respective handlers
+	// Configure the login, register and refresh endpoints with their respective handlers
 	auth.POST("/login", handlers.Login{}.Do)
 	auth.POST("/register", handlers.Register{}.Do)
+	auth.POST("/refresh", handlers.Refresh{}.Do)
+	// Configure the logout endpoint, protected by the authorization middleware
+	auth.POST("/logout", authorize(ge.conf.Server.SecretKey, ge.db.Repositories), handlers.Logout{}.Do)
 }
 
 // setProductHandlers configures product-related routes and handlers.
@@ -64,13 +87,24 @@ func (ge GinEngine) setProductHandlers(r *gin.RouterGroup) {
 	// Create a sub-group for product routes
 	product := r.Group("/products")
 	// Use authorization middleware to protect these routes
-	product.Use(authorize(ge.conf.Server.SecretKey))
+	product.Use(authorize(ge.conf.Server.SecretKey, ge.db.Repositories))
+	// Every role can manage products, clients are limited to their own ones by the handlers
+	product.Use(requireRoles(auth.ADMIN_ROLE, auth.OPERATOR_ROLE, auth.CLIENT_ROLE))
 	// Configure endpoints for getting, creating, updating, and deleting products
 	product.GET("/:id", handlers.GetProduct{}.Do)
 	product.GET("", handlers.GetSomeProducts{}.Do)
 	product.POST("", handlers.CreateProduct{}.Do)
 	product.PUT("/:id", handlers.UpdateProduct{}.Do)
 	product.DELETE("/:id", handlers.DeleteProduct{}.Do)
+	// Configure endpoint for moving a product through its lifecycle
+	product.POST("/:id/transitions", handlers.TransitionProduct{}.Do)
+	// Configure endpoints for getting and recording the tracking events of a product
+	product.GET("/:id/events", handlers.GetProductEvents{}.Do)
+	product.POST("/:id/events", handlers.CreateProductEvent{}.Do)
+	// Configure endpoint for creating the products of a CSV or XLSX spreadsheet, right away or by a background job
+	product.POST("/import", handlers.ImportProducts{}.Do)
+	// Configure endpoint for exporting every product as a file, right away or by a background job
+	product.GET("/export", handlers.ExportProducts{}.Do)
 }
 
 // setSearchHandlers configures search-related routes and handlers.
@@ -78,9 +112,48 @@ func (ge GinEngine) setSearchHandlers(r *gin.RouterGroup) {
 	// Create a sub-group for search routes
 	product := r.Group("/search")
 	// Use authorization middleware to protect this route
-	product.Use(authorize(ge.conf.Server.SecretKey))
+	product.Use(authorize(ge.conf.Server.SecretKey, ge.db.Repositories))
+	// Every role can search products, clients are limited to their own ones by the handler
+	product.Use(requireRoles(auth.ADMIN_ROLE, auth.OPERATOR_ROLE, auth.CLIENT_ROLE))
 	// Configure endpoint for searching products
 	product.GET("", handlers.Search{}.Do)
+	// Configure endpoint for searching products with a JSON filter tree
+	product.POST("", handlers.FilterSearch{}.Do)
+	// Configure endpoints for exporting every matching product as a file
+	product.GET("/export", handlers.ExportSearch{}.Do)
+	product.POST("/export", handlers.ExportFilterSearch{}.Do)
+}
+
+// setSavedSearchHandlers configures the saved search routes and handlers.
+func (ge GinEngine) setSavedSearchHandlers(r *gin.RouterGroup) {
+	// Create a sub-group for saved search routes
+	searches := r.Group("/searches")
+	// Use authorization middleware to protect these routes
+	searches.Use(authorize(ge.conf.Server.SecretKey, ge.db.Repositories))
+	// Every role can save searches, clients are limited to their own ones by the handlers
+	searches.Use(requireRoles(auth.ADMIN_ROLE, auth.OPERATOR_ROLE, auth.CLIENT_ROLE))
+	// Configure endpoints for getting, creating, replacing and deleting saved searches
+	searches.GET("", handlers.GetSomeSavedSearches{}.Do)
+	searches.GET("/:id", handlers.GetSavedSearch{}.Do)
+	searches.POST("", handlers.CreateSavedSearch{}.Do)
+	searches.PUT("/:id", handlers.UpdateSavedSearch{}.Do)
+	searches.DELETE("/:id", handlers.DeleteSavedSearch{}.Do)
+	// Configure endpoints for running a saved search and getting the digests of its scheduled runs
+	searches.GET("/:id/results", handlers.GetSavedSearchResults{}.Do)
+	searches.GET("/:id/digests", handlers.GetSavedSearchDigests{}.Do)
+}
+
+// setJobHandlers configures the background job routes and handlers.
+func (ge GinEngine) setJobHandlers(r *gin.RouterGroup) {
+	// Create a sub-group for job routes
+	jobs := r.Group("/jobs")
+	// Use authorization middleware to protect these routes
+	jobs.Use(authorize(ge.conf.Server.SecretKey, ge.db.Repositories))
+	// Every role can queue jobs, clients are limited to their own ones by the handlers
+	jobs.Use(requireRoles(auth.ADMIN_ROLE, auth.OPERATOR_ROLE, auth.CLIENT_ROLE))
+	// Configure endpoints for polling a job and downloading its result
+	jobs.GET("/:id", handlers.GetJob{}.Do)
+	jobs.GET("/:id/result", handlers.GetJobResult{}.Do)
 }
 
 // setClientHandlers configures client-related routes and handlers.
@@ -88,10 +161,36 @@ func (ge GinEngine) setClientHandlers(r *gin.RouterGroup) {
 	// Create a sub-group for client routes
 	client := r.Group("/clients")
 	// Use authorization middleware to protect these routes
-	client.Use(authorize(ge.conf.Server.SecretKey))
-	// Configure endpoints for getting clients and getting a specific client
-	client.GET("", handlers.GetSomeClients{}.Do)
-	client.GET("/:id", handlers.GetClient{}.Do)
+	client.Use(authorize(ge.conf.Server.SecretKey, ge.db.Repositories))
+	// Configure endpoints for getting clients, only available for the privileged roles
+	client.GET("", requireRoles(auth.ADMIN_ROLE, auth.OPERATOR_ROLE), handlers.GetSomeClients{}.Do)
+	// Configure endpoint for getting a specific client, clients are limited to themselves by the handler
+	client.GET("/:id", requireRoles(auth.ADMIN_ROLE, auth.OPERATOR_ROLE, auth.CLIENT_ROLE), handlers.GetClient{}.Do)
+	// Configure endpoint for changing the role of a client, only available for admins
+	client.PUT("/:id/role", requireRoles(auth.ADMIN_ROLE), handlers.UpdateClientRole{}.Do)
+}
+
+// setQuoteHandlers configures quote-related routes and handlers.
+func (ge GinEngine) setQuoteHandlers(r *gin.RouterGroup) {
+	// Create a sub-group for quote routes
+	quote := r.Group("/quotes")
+	// Use authorization middleware to protect these routes
+	quote.Use(authorize(ge.conf.Server.SecretKey, ge.db.Repositories))
+	// Every role can request quotes, clients are limited to their own ones by the handlers
+	quote.Use(requireRoles(auth.ADMIN_ROLE, auth.OPERATOR_ROLE, auth.CLIENT_ROLE))
+	// Configure endpoints for issuing and getting quotes
+	quote.POST("", handlers.CreateQuote{}.Do)
+	quote.GET("/:id", handlers.GetQuote{}.Do)
+}
+
+// setTrackHandlers configures the public tracking routes and handlers.
+func (ge GinEngine) setTrackHandlers(r *gin.RouterGroup) {
+	// Create a sub-group for tracking routes
+	track := r.Group("/track")
+	// These routes are unauthenticated, so they are rate limited by client IP instead
+	track.Use(rateLimit(ge.conf.Server.TrackRateLimit, time.Minute))
+	// Configure endpoint for tracking a shipment by its guide number
+	track.GET("/:guideNumber", handlers.TrackProduct{}.Do)
 }
 
 // setCommonMiddlewares configures common middlewares for all routes.
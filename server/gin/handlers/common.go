@@ -1,11 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/coffemanfp/docucentertest/auth"
 	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/search"
 	"github.com/coffemanfp/docucentertest/server/errors"
 	"github.com/gin-gonic/gin"
 )
@@ -70,6 +77,137 @@ func getProductRepository(c *gin.Context) (repo database.ProductRepository, ok b
 	return
 }
 
+// getTrackingRepository tries to retrieve an instance of the TrackingRepository from the repository map.
+// If successful, it returns the retrieved repository and ok as true. If there's an error, it handles the error and returns ok as false.
+func getTrackingRepository(c *gin.Context) (repo database.TrackingRepository, ok bool) {
+	repo, err := database.GetRepository[database.TrackingRepository](db, database.TRACKING_REPOSITORY)
+	if err != nil {
+		// If there's an error while retrieving the repository, handle the error using the handleError function.
+		handleError(c, err)
+		return
+	}
+	// Indicate that the repository retrieval was successful.
+	ok = true
+	return
+}
+
+// getQuoteRepository tries to retrieve an instance of the QuoteRepository from the repository map.
+// If successful, it returns the retrieved repository and ok as true. If there's an error, it handles the error and returns ok as false.
+func getQuoteRepository(c *gin.Context) (repo database.QuoteRepository, ok bool) {
+	repo, err := database.GetRepository[database.QuoteRepository](db, database.QUOTE_REPOSITORY)
+	if err != nil {
+		// If there's an error while retrieving the repository, handle the error using the handleError function.
+		handleError(c, err)
+		return
+	}
+	// Indicate that the repository retrieval was successful.
+	ok = true
+	return
+}
+
+// getSavedSearchRepository tries to retrieve an instance of the SavedSearchRepository from the repository map.
+// If successful, it returns the retrieved repository and ok as true. If there's an error, it handles the error and returns ok as false.
+func getSavedSearchRepository(c *gin.Context) (repo database.SavedSearchRepository, ok bool) {
+	repo, err := database.GetRepository[database.SavedSearchRepository](db, database.SAVED_SEARCH_REPOSITORY)
+	if err != nil {
+		// If there's an error while retrieving the repository, handle the error using the handleError function.
+		handleError(c, err)
+		return
+	}
+	// Indicate that the repository retrieval was successful.
+	ok = true
+	return
+}
+
+// getJobRepository tries to retrieve an instance of the JobRepository from the repository map.
+// If successful, it returns the retrieved repository and ok as true. If there's an error, it handles the error and returns ok as false.
+func getJobRepository(c *gin.Context) (repo database.JobRepository, ok bool) {
+	repo, err := database.GetRepository[database.JobRepository](db, database.JOB_REPOSITORY)
+	if err != nil {
+		// If there's an error while retrieving the repository, handle the error using the handleError function.
+		handleError(c, err)
+		return
+	}
+	// Indicate that the repository retrieval was successful.
+	ok = true
+	return
+}
+
+// getLockedPricings retrieves the prices locked by the quotes of the given products, by quote ID.
+// If successful, it returns the prices and ok as true. If there's an error, it handles the error and returns ok as false.
+func getLockedPricings(c *gin.Context, ps []*product.Product) (pricings map[int]product.Pricing, ok bool) {
+	pricings, err := lockedPricings(c.Request.Context(), ps)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
+// lockedPricings retrieves the prices locked by the quotes of the given products, by quote ID.
+// The QuoteRepository is only used if any of the products was created with a quote.
+func lockedPricings(ctx context.Context, ps []*product.Product) (pricings map[int]product.Pricing, err error) {
+	// Collect the IDs of the quotes used by the products.
+	ids := make([]int, 0)
+	for _, p := range ps {
+		if p.QuoteID != nil {
+			ids = append(ids, *p.QuoteID)
+		}
+	}
+	if len(ids) == 0 {
+		pricings = make(map[int]product.Pricing)
+		return
+	}
+
+	repo, err := database.GetRepository[database.QuoteRepository](db, database.QUOTE_REPOSITORY)
+	if err != nil {
+		return
+	}
+	return repo.GetPricings(ctx, ids)
+}
+
+// newDiscountGenerator creates the discount generator of a product.
+// Products created with a quote keep the price locked by it, the rest are priced by the rules engine.
+func newDiscountGenerator(engine product.RulesEngine, locked map[int]product.Pricing, p product.Product, now time.Time) product.DiscountGenerator {
+	if p.QuoteID != nil {
+		if pricing, ok := locked[*p.QuoteID]; ok {
+			return product.NewLockedDiscountGenerator(pricing)
+		}
+	}
+	return product.NewDiscountGenerator(engine, p, now)
+}
+
+// getPricingEngine tries to build a pricing rules engine with the rules of the PricingRepository.
+// If successful, it returns the engine and ok as true. If there's an error, it handles the error and returns ok as false.
+func getPricingEngine(c *gin.Context) (engine product.RulesEngine, ok bool) {
+	engine, err := pricingEngine(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
+// pricingEngine builds a pricing rules engine with the rules of the PricingRepository.
+func pricingEngine(ctx context.Context) (engine product.RulesEngine, err error) {
+	// Attempt to get the PricingRepository from the repository map using the appropriate key.
+	repo, err := database.GetRepository[database.PricingRepository](db, database.PRICING_REPOSITORY)
+	if err != nil {
+		return
+	}
+
+	// Retrieve the pricing rules, in evaluation order.
+	rules, err := repo.GetRules(ctx)
+	if err != nil {
+		return
+	}
+
+	// Build the engine, invalid stored rules are considered an internal error.
+	return product.NewRulesEngine(rules)
+}
+
 // readIntFromURL reads an integer value from the URL parameter or query parameter based on isQueryParam.
 // It returns the parsed integer value and ok as true if successful. If the parameter is empty, it returns ok as true without value.
 // If parsing fails or the parameter is invalid, it creates an HTTP error and handles it using the handleError function, returning ok as false.
@@ -132,10 +270,295 @@ func readFloatFromURL(c *gin.Context, param string, isQueryParam bool) (v float6
 	return
 }
 
-// readPagination reads the "page" parameter from the URL using readIntFromURL and returns it.
-// It delegates to readIntFromURL to handle parsing and potential errors.
-func readPagination(c *gin.Context) (page int, ok bool) {
-	page, ok = readIntFromURL(c, "page", false)
+// readBoolFromURL reads a boolean value from the URL parameter or query parameter based on isQueryParam.
+// It returns the parsed boolean value and ok as true if successful. If the parameter is empty, it returns ok as true without value.
+// If parsing fails or the parameter is invalid, it creates an HTTP error and handles it using the handleError function, returning ok as false.
+func readBoolFromURL(c *gin.Context, param string, isQueryParam bool) (v bool, ok bool) {
+	// Get the parameter value from the URL based on whether it's a query parameter or not.
+	var p string
+	if isQueryParam {
+		p = c.Query(param)
+	} else {
+		p = c.Param(param)
+	}
+	// If the parameter is empty, return without an error.
+	if p == "" {
+		ok = true
+		return
+	}
+	// Parse the parameter value as a boolean.
+	v, err := strconv.ParseBool(p)
+	if err != nil {
+		// If parsing fails, create an HTTP error and handle it using the handleError function.
+		err = fmt.Errorf("invalid %s param: %s", param, p)
+		err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		handleError(c, err)
+		return
+	}
+
+	// Indicate that the parameter parsing was successful.
+	ok = true
+	return
+}
+
+// readPagination reads the pagination from the query parameters of the URL.
+// It is either a 1-based "page" of "page_size" results or a "limit" of results after an "offset", which can not be mixed.
+// If allowCursor is true, it can also be a "limit" of results after a "cursor", which starts from the first result if empty.
+// The page size or limit can not be greater than the configured maximum.
+// The results are sorted by the fields of the "sort" parameter, read with newSort.
+func readPagination(c *gin.Context, newSort func(v string) (search.Sort, error), allowCursor bool) (pagination search.Pagination, ok bool) {
+	// Read the page-based parameters.
+	page, ok := readIntFromURL(c, "page", true)
+	if !ok {
+		return
+	}
+	pageSize, ok := readIntFromURL(c, "page_size", true)
+	if !ok {
+		return
+	}
+
+	// Read the offset-based parameters.
+	limit, ok := readIntFromURL(c, "limit", true)
+	if !ok {
+		return
+	}
+	offset, ok := readIntFromURL(c, "offset", true)
+	if !ok {
+		return
+	}
+	ok = false
+
+	var err error
+	switch {
+	case allowCursor && isCursorPagination(c):
+		if c.Query("page") != "" || c.Query("page_size") != "" || c.Query("offset") != "" {
+			err = errors.NewHTTPError(http.StatusBadRequest, "page, page_size and offset params can not be mixed with the cursor param")
+			handleError(c, err)
+			return
+		}
+		// Read the position the results are retrieved after.
+		var after *search.Cursor
+		after, err = readCursor(c)
+		if err == nil {
+			pagination, err = search.NewCursorPagination(after, limit, conf.Server.MaxPageSize)
+		}
+	case isOffsetPagination(c):
+		if c.Query("page") != "" || c.Query("page_size") != "" {
+			err = errors.NewHTTPError(http.StatusBadRequest, "page and page_size params can not be mixed with limit and offset params")
+			handleError(c, err)
+			return
+		}
+		pagination, err = search.NewOffsetPagination(limit, offset, conf.Server.MaxPageSize)
+	default:
+		pagination, err = search.NewPagination(page, pageSize, conf.Server.MaxPageSize)
+	}
+	if err != nil {
+		// If the pagination is invalid, create an HTTP error and handle it using the handleError function.
+		err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		handleError(c, err)
+		return
+	}
+
+	// Sort the results by the whitelisted fields, the same way as the cursor if any.
+	sort, err := newSort(c.Query("sort"))
+	if err == nil {
+		pagination, err = pagination.Sorted(sort)
+	}
+	if err != nil {
+		err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		handleError(c, err)
+		return
+	}
+
+	ok = true
+	return
+}
+
+// noSort creates the sort of the listings that can not be sorted, rejecting any sort parameter.
+func noSort(v string) (s search.Sort, err error) {
+	if v != "" {
+		err = fmt.Errorf("invalid sort: these results can not be sorted")
+	}
+	return
+}
+
+// readCursor reads the signed "cursor" parameter from the URL, returning nil if it is empty.
+func readCursor(c *gin.Context) (cursor *search.Cursor, err error) {
+	if c.Query("cursor") == "" {
+		return
+	}
+	after, err := search.DecodeCursor(c.Query("cursor"), []byte(conf.Server.SecretKey))
+	if err != nil {
+		return
+	}
+	cursor = &after
+	return
+}
+
+// isCursorPagination reports whether the pagination of the request is requested with the "cursor" parameter, even if empty.
+func isCursorPagination(c *gin.Context) bool {
+	_, ok := c.GetQuery("cursor")
+	return ok
+}
+
+// isOffsetPagination reports whether the pagination of the request is requested with the "limit" and "offset" parameters.
+func isOffsetPagination(c *gin.Context) bool {
+	return c.Query("limit") != "" || c.Query("offset") != ""
+}
+
+// writePagination sets the pagination headers of a response with the given pagination out of total results.
+// X-Total-Count, X-Page and X-Page-Size describe the page, and Link has the URLs of the first, previous, next and last pages,
+// using the same pagination parameters as the request.
+func writePagination(c *gin.Context, pagination search.Pagination, total int) {
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	c.Header("X-Page", strconv.Itoa(pagination.Page()))
+	c.Header("X-Page-Size", strconv.Itoa(pagination.Limit))
+
+	// The last page starts at the last multiple of the page size, or at the start if there are no results.
+	last := pagination
+	last.Offset = 0
+	if total > 0 && pagination.Limit > 0 {
+		last.Offset = (total - 1) / pagination.Limit * pagination.Limit
+	}
+	first := pagination
+	first.Offset = 0
+
+	links := []string{paginationLink(c, first, "first")}
+	if pagination.HasPrev() {
+		links = append(links, paginationLink(c, pagination.Prev(), "prev"))
+	}
+	if pagination.HasNext(total) {
+		links = append(links, paginationLink(c, pagination.Next(), "next"))
+	}
+	links = append(links, paginationLink(c, last, "last"))
+	c.Header("Link", strings.Join(links, ", "))
+}
+
+// paginationLink returns a Link header value for the request URL with the given pagination and relation type.
+func paginationLink(c *gin.Context, pagination search.Pagination, rel string) string {
+	q := c.Request.URL.Query()
+	if isOffsetPagination(c) {
+		q.Set("limit", strconv.Itoa(pagination.Limit))
+		q.Set("offset", strconv.Itoa(pagination.Offset))
+	} else {
+		q.Set("page", strconv.Itoa(pagination.Page()))
+		q.Set("page_size", strconv.Itoa(pagination.Limit))
+	}
+	return requestLink(c, q, rel)
+}
+
+// requestLink returns a Link header value for the request path with the query q and the given relation type.
+func requestLink(c *gin.Context, q url.Values, rel string) string {
+	u := url.URL{
+		Path:     c.Request.URL.Path,
+		RawQuery: q.Encode(),
+	}
+	return fmt.Sprintf("<%s>; rel=\"%s\"", u.String(), rel)
+}
+
+// productPage represents a page of search results wrapped with the details of the page.
+type productPage struct {
+	Products   []*product.Product            `json:"products"`              // Products of the page
+	NextCursor string                        `json:"next_cursor,omitempty"` // Cursor of the following page, empty after the last one or without a cursor
+	Facets     map[string]search.FacetResult `json:"facets,omitempty"`      // Aggregations of every product of the search, if requested
+}
+
+// writeProducts responds with a page of products out of total products as a JSON array, whatever its pagination.
+// The page is described by the headers of writeProductsPagination.
+func writeProducts(c *gin.Context, pagination search.Pagination, total int, ps []*product.Product) {
+	writeProductsPagination(c, pagination, total, ps)
+	c.JSON(http.StatusOK, ps)
+}
+
+// writeProductPage responds with a page of search results out of total products, always wrapped in a productPage
+// with the facets of every result, if any, and the cursor of the following page, if requested with a cursor.
+// The page is also described by the headers of writeProductsPagination.
+func writeProductPage(c *gin.Context, pagination search.Pagination, total int, ps []*product.Product, facets map[string]search.FacetResult) {
+	next := writeProductsPagination(c, pagination, total, ps)
+	c.JSON(http.StatusOK, productPage{Products: ps, NextCursor: next, Facets: facets})
+}
+
+// writeProductsPagination sets the headers describing a page of products out of total products.
+// Pages requested with a cursor return the cursor of the following page, also sent in the X-Next-Cursor and Link headers,
+// and any other page is described by writePagination.
+func writeProductsPagination(c *gin.Context, pagination search.Pagination, total int, ps []*product.Product) (next string) {
+	if !isCursorPagination(c) {
+		writePagination(c, pagination, total)
+		return
+	}
+
+	// A full page may be followed by more products, which are retrieved after its last product.
+	if pagination.Limit > 0 && len(ps) == pagination.Limit {
+		next = search.EncodeCursor(search.NewCursor(pagination.Sort, *ps[len(ps)-1]), []byte(conf.Server.SecretKey))
+
+		q := c.Request.URL.Query()
+		q.Set("cursor", next)
+		c.Header("Link", requestLink(c, q, "next"))
+		c.Header("X-Next-Cursor", next)
+	}
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	c.Header("X-Page-Size", strconv.Itoa(pagination.Limit))
+	return
+}
+
+// getRole returns the role saved in the Gin context by the authorization middleware.
+// If no role was saved, it returns the least privileged role.
+func getRole(c *gin.Context) (role auth.Role) {
+	role, ok := c.Value("role").(auth.Role)
+	if !ok {
+		role = auth.CLIENT_ROLE
+	}
+	return
+}
+
+// readClientScope returns the client ID the request must be restricted to.
+// Clients are always restricted to their own ID. Privileged roles are not restricted,
+// unless they provide the optional "client_id" query parameter.
+// If the parameter is invalid, it handles the error and returns ok as false.
+func readClientScope(c *gin.Context) (clientID int, ok bool) {
+	if !getRole(c).IsPrivileged() {
+		clientID = c.GetInt("id")
+		ok = true
+		return
+	}
+	clientID, ok = readIntFromURL(c, "client_id", true)
+	if ok && clientID == 0 {
+		clientID = database.ANY_CLIENT
+	}
+	return
+}
+
+// generateTokens generates a new JWT access token and a refresh token for the given client ID.
+// The refresh token is stored through the authentication repository as part of the given family;
+// an empty familyID starts a new family.
+// If successful, it returns both tokens and ok as true. If there's an error, it handles the error and returns ok as false.
+func generateTokens(c *gin.Context, repo database.AuthRepository, id int, familyID string) (token, refreshToken string, ok bool) {
+	// Get the current role of the client, so role changes apply to the next issued token.
+	role, err := repo.GetRole(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	// Generate a JWT access token using the client's ID, role and configuration settings.
+	token, err = auth.GenerateToken(id, role, conf.Server.JWTLifespan, conf.Server.SecretKey)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	// Generate a new refresh token and store its hash in the database.
+	refreshToken, rt, err := auth.NewRefreshToken(id, familyID, conf.Server.RefreshTokenLifespan)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	err = repo.SaveRefreshToken(c.Request.Context(), rt)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
 	return
 }
 
@@ -2,16 +2,22 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/coffemanfp/docucentertest/auth"
 	"github.com/coffemanfp/docucentertest/client"
 	"github.com/coffemanfp/docucentertest/config"
 	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/jobs"
 	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/quote"
+	"github.com/coffemanfp/docucentertest/savedsearch"
 	"github.com/coffemanfp/docucentertest/search"
+	"github.com/coffemanfp/docucentertest/tracking"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -72,16 +78,56 @@ type MockAuthRepository struct {
 	mock.Mock
 }
 
-func (m *MockAuthRepository) GetIdAndHashedPassword(auth auth.Auth) (int, string, error) {
+func (m *MockAuthRepository) GetIdAndHashedPassword(ctx context.Context, auth auth.Auth) (int, string, error) {
 	args := m.Called(auth)
 	return args.Int(0), args.String(1), args.Error(2)
 }
 
-func (m *MockAuthRepository) Register(client client.Client) (int, error) {
+func (m *MockAuthRepository) Register(ctx context.Context, client client.Client) (int, error) {
 	args := m.Called(client)
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockAuthRepository) GetRole(ctx context.Context, id int) (auth.Role, error) {
+	args := m.Called(id)
+	return args.Get(0).(auth.Role), args.Error(1)
+}
+
+func (m *MockAuthRepository) SaveRefreshToken(ctx context.Context, rt auth.RefreshToken) error {
+	args := m.Called(rt)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) GetRefreshToken(ctx context.Context, hash string) (auth.RefreshToken, error) {
+	args := m.Called(hash)
+	return args.Get(0).(auth.RefreshToken), args.Error(1)
+}
+
+func (m *MockAuthRepository) RevokeRefreshToken(ctx context.Context, id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	args := m.Called(familyID)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) RevokeToken(ctx context.Context, jti string, expiresAt *time.Time) error {
+	args := m.Called(jti, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	args := m.Called(jti)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) PurgeExpiredTokens(ctx context.Context, now time.Time) error {
+	args := m.Called(now)
+	return args.Error(0)
+}
+
 func TestGetAuthRepository(t *testing.T) {
 	t.Run("SuccessfulRepositoryRetrieval", func(t *testing.T) {
 		mockRepo := new(MockAuthRepository)
@@ -97,7 +143,7 @@ func TestGetAuthRepository(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 
 		repo, ok := getAuthRepository(c)
 
@@ -115,7 +161,7 @@ func TestGetAuthRepository(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		req, _ := http.NewRequest("GET", "/", nil)
 		rec := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(rec)
@@ -133,14 +179,19 @@ type MockClientRepository struct {
 	mock.Mock
 }
 
-func (m *MockClientRepository) GetOne(id int) (client.Client, error) {
+func (m *MockClientRepository) GetOne(ctx context.Context, id int) (client.Client, error) {
 	args := m.Called(id)
 	return args.Get(0).(client.Client), args.Error(1)
 }
 
-func (m *MockClientRepository) Get(page int) ([]*client.Client, error) {
-	args := m.Called(page)
-	return args.Get(0).([]*client.Client), args.Error(1)
+func (m *MockClientRepository) Get(ctx context.Context, pagination search.Pagination) ([]*client.Client, int, error) {
+	args := m.Called(pagination)
+	return args.Get(0).([]*client.Client), args.Int(1), args.Error(2)
+}
+
+func (m *MockClientRepository) UpdateRole(ctx context.Context, id int, role auth.Role) error {
+	args := m.Called(id, role)
+	return args.Error(0)
 }
 
 func TestGetClientRepository(t *testing.T) {
@@ -153,7 +204,7 @@ func TestGetClientRepository(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 
 		req, _ := http.NewRequest("GET", "/", nil)
 		rec := httptest.NewRecorder()
@@ -176,7 +227,7 @@ func TestGetClientRepository(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 
 		req, _ := http.NewRequest("GET", "/", nil)
 		rec := httptest.NewRecorder()
@@ -195,36 +246,220 @@ type MockProductRepository struct {
 	mock.Mock
 }
 
-func (m *MockProductRepository) Get(page, clientID int) ([]*product.Product, error) {
-	args := m.Called(page, clientID)
-	return args.Get(0).([]*product.Product), args.Error(1)
+func (m *MockProductRepository) Get(ctx context.Context, pagination search.Pagination, clientID int) ([]*product.Product, int, error) {
+	args := m.Called(pagination, clientID)
+	return args.Get(0).([]*product.Product), args.Int(1), args.Error(2)
 }
 
-func (m *MockProductRepository) GetOne(id, clientID int) (product.Product, error) {
+func (m *MockProductRepository) GetOne(ctx context.Context, id, clientID int) (product.Product, error) {
 	args := m.Called(id, clientID)
 	return args.Get(0).(product.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) Create(product product.Product) (int, error) {
+func (m *MockProductRepository) GetByGuideNumber(ctx context.Context, guideNumber string) (product.Product, error) {
+	args := m.Called(guideNumber)
+	return args.Get(0).(product.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) Create(ctx context.Context, product product.Product) (int, error) {
 	args := m.Called(product)
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockProductRepository) Search(search search.Search) ([]*product.Product, error) {
+func (m *MockProductRepository) CreateBatch(ctx context.Context, products []product.Product) ([]int, error) {
+	args := m.Called(products)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockProductRepository) Search(ctx context.Context, search search.Search) ([]*product.Product, int, error) {
 	args := m.Called(search)
-	return args.Get(0).([]*product.Product), args.Error(1)
+	return args.Get(0).([]*product.Product), args.Int(1), args.Error(2)
 }
 
-func (m *MockProductRepository) Update(product product.Product) error {
+func (m *MockProductRepository) Export(ctx context.Context, srch search.Search, size int, fn func(ps []*product.Product) error) error {
+	args := m.Called(srch, size)
+	// Export the batches of products to return, if any, stopping at the first error of fn.
+	if batches, ok := args.Get(0).([][]*product.Product); ok {
+		for _, ps := range batches {
+			if err := fn(ps); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockProductRepository) Facets(ctx context.Context, srch search.Search, facets []search.Facet) (map[string]search.FacetResult, error) {
+	args := m.Called(srch, facets)
+	results, _ := args.Get(0).(map[string]search.FacetResult)
+	return results, args.Error(1)
+}
+
+func (m *MockProductRepository) Update(ctx context.Context, product product.Product) error {
 	args := m.Called(product)
 	return args.Error(0)
 }
 
-func (m *MockProductRepository) Delete(id, clientID int) error {
+func (m *MockProductRepository) UpdateStatus(ctx context.Context, id, clientID int, from, to product.Status) error {
+	args := m.Called(id, clientID, from, to)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) Delete(ctx context.Context, id, clientID int) error {
+	args := m.Called(id, clientID)
+	return args.Error(0)
+}
+
+// MockTxConnector runs the transactions directly on the given repositories.
+type MockTxConnector struct {
+	repos  database.Repositories
+	result *error // Error the last transaction finished with, rolling it back, recorded if not nil
+}
+
+func (m MockTxConnector) Connect() error {
+	return nil
+}
+
+func (m MockTxConnector) WithTx(ctx context.Context, fn func(tx database.Repositories) error) error {
+	err := fn(m.repos)
+	if m.result != nil {
+		*m.result = err
+	}
+	return err
+}
+
+type MockPricingRepository struct {
+	mock.Mock
+}
+
+func (m *MockPricingRepository) GetRules(ctx context.Context) ([]product.PricingRule, error) {
+	args := m.Called()
+	return args.Get(0).([]product.PricingRule), args.Error(1)
+}
+
+// newMockPricingRepository creates a MockPricingRepository returning the default pricing rules.
+func newMockPricingRepository() *MockPricingRepository {
+	m := new(MockPricingRepository)
+	m.On("GetRules").Return(product.DefaultPricingRules(), nil)
+	return m
+}
+
+type MockQuoteRepository struct {
+	mock.Mock
+}
+
+func (m *MockQuoteRepository) Create(ctx context.Context, quote quote.Quote) (int, error) {
+	args := m.Called(quote)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockQuoteRepository) GetOne(ctx context.Context, id, clientID int) (quote.Quote, error) {
+	args := m.Called(id, clientID)
+	return args.Get(0).(quote.Quote), args.Error(1)
+}
+
+func (m *MockQuoteRepository) GetPricings(ctx context.Context, ids []int) (map[int]product.Pricing, error) {
+	args := m.Called(ids)
+	return args.Get(0).(map[int]product.Pricing), args.Error(1)
+}
+
+func (m *MockQuoteRepository) Use(ctx context.Context, id, clientID int) error {
+	args := m.Called(id, clientID)
+	return args.Error(0)
+}
+
+type MockSavedSearchRepository struct {
+	mock.Mock
+}
+
+func (m *MockSavedSearchRepository) Create(ctx context.Context, s savedsearch.SavedSearch) (int, error) {
+	args := m.Called(s)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockSavedSearchRepository) GetOne(ctx context.Context, id, clientID int) (savedsearch.SavedSearch, error) {
+	args := m.Called(id, clientID)
+	return args.Get(0).(savedsearch.SavedSearch), args.Error(1)
+}
+
+func (m *MockSavedSearchRepository) Get(ctx context.Context, pagination search.Pagination, clientID int) ([]savedsearch.SavedSearch, int, error) {
+	args := m.Called(pagination, clientID)
+	return args.Get(0).([]savedsearch.SavedSearch), args.Int(1), args.Error(2)
+}
+
+func (m *MockSavedSearchRepository) Update(ctx context.Context, s savedsearch.SavedSearch) error {
+	args := m.Called(s)
+	return args.Error(0)
+}
+
+func (m *MockSavedSearchRepository) Delete(ctx context.Context, id, clientID int) error {
 	args := m.Called(id, clientID)
 	return args.Error(0)
 }
 
+func (m *MockSavedSearchRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]savedsearch.SavedSearch, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]savedsearch.SavedSearch), args.Error(1)
+}
+
+func (m *MockSavedSearchRepository) SaveRun(ctx context.Context, s savedsearch.SavedSearch, d savedsearch.Digest) (int, error) {
+	args := m.Called(s, d)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockSavedSearchRepository) GetDigests(ctx context.Context, savedSearchID int, pagination search.Pagination, clientID int) ([]savedsearch.Digest, int, error) {
+	args := m.Called(savedSearchID, pagination, clientID)
+	return args.Get(0).([]savedsearch.Digest), args.Int(1), args.Error(2)
+}
+
+type MockJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobRepository) Create(ctx context.Context, job jobs.Job) (int, error) {
+	args := m.Called(job)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockJobRepository) GetOne(ctx context.Context, id, clientID int) (jobs.Job, error) {
+	args := m.Called(id, clientID)
+	return args.Get(0).(jobs.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) GetResult(ctx context.Context, id, clientID int) (jobs.Result, error) {
+	args := m.Called(id, clientID)
+	return args.Get(0).(jobs.Result), args.Error(1)
+}
+
+func (m *MockJobRepository) Claim(ctx context.Context, now time.Time, lease time.Duration) (jobs.Job, error) {
+	args := m.Called(now, lease)
+	return args.Get(0).(jobs.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) Heartbeat(ctx context.Context, job jobs.Job) error {
+	args := m.Called(job)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) Finish(ctx context.Context, job jobs.Job, result *jobs.Result) error {
+	args := m.Called(job, result)
+	return args.Error(0)
+}
+
+type MockTrackingRepository struct {
+	mock.Mock
+}
+
+func (m *MockTrackingRepository) Get(ctx context.Context, productID, clientID int) ([]*tracking.Event, error) {
+	args := m.Called(productID, clientID)
+	return args.Get(0).([]*tracking.Event), args.Error(1)
+}
+
+func (m *MockTrackingRepository) Create(ctx context.Context, event tracking.Event, clientID int) (int, error) {
+	args := m.Called(event, clientID)
+	return args.Int(0), args.Error(1)
+}
+
 func TestGetProductRepository(t *testing.T) {
 	t.Run("SuccessfulRepositoryRetrieval", func(t *testing.T) {
 		mockRepo := new(MockProductRepository)
@@ -235,7 +470,7 @@ func TestGetProductRepository(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		req, _ := http.NewRequest("GET", "/", nil)
 		rec := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(rec)
@@ -257,7 +492,7 @@ func TestGetProductRepository(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 
 		req, _ := http.NewRequest("GET", "/", nil)
 		rec := httptest.NewRecorder()
@@ -434,6 +669,15 @@ func TestReadFloat64FromURL(t *testing.T) {
 	})
 }
 
+// setClient returns a middleware saving the client ID and role in the Gin context,
+// as the authorization middleware does.
+func setClient(id int, role auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("id", id)
+		c.Set("role", role)
+	}
+}
+
 func newString(s string) *string {
 	n := &s
 	return n
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/coffemanfp/docucentertest/database"
 	"github.com/coffemanfp/docucentertest/product"
@@ -28,14 +30,19 @@ func (ct CreateProduct) Do(c *gin.Context) {
 		return
 	}
 
-	// Get the product repository.
-	repo, ok := getProductRepository(c)
-	if !ok {
-		return
-	}
-
 	// Save the product in the database and handle any errors.
-	id, ok := ct.saveProductInDB(c, repo, p)
+	var id int
+	if p.QuoteID != nil {
+		// Lock in the price of the quote the product references, in the same transaction.
+		p, id, ok = ct.saveQuotedProductInDB(c, p)
+	} else {
+		var repo database.ProductRepository
+		repo, ok = getProductRepository(c)
+		if !ok {
+			return
+		}
+		id, ok = ct.saveProductInDB(c, repo, p)
+	}
 	if !ok {
 		return
 	}
@@ -57,11 +64,12 @@ func (ct CreateProduct) readProduct(c *gin.Context) (p product.Product, ok bool)
 }
 
 // createProduct is a method of the CreateProduct struct that creates a new product based on the provided data.
-// It sets the client ID from the context if not provided in the request data and validates the product data.
+// Clients always create products for themselves. Privileged roles can create products for any client,
+// using their own client ID if not provided in the request data. Then it validates the product data.
 // If successful, it returns the created product instance and a boolean indicating success.
 func (ct CreateProduct) createProduct(c *gin.Context, pr product.Product) (p product.Product, ok bool) {
-	// If the client ID is not provided in the request data, use the client ID from the context.
-	if pr.ClientID == 0 {
+	// If the client ID is not provided in the request data or the role is not privileged, use the client ID from the context.
+	if pr.ClientID == 0 || !getRole(c).IsPrivileged() {
 		pr.ClientID = c.GetInt("id")
 	}
 
@@ -76,12 +84,66 @@ func (ct CreateProduct) createProduct(c *gin.Context, pr product.Product) (p pro
 	return
 }
 
+// saveQuotedProductInDB is a method of the CreateProduct struct that saves a product locking in the price of its quote.
+// The quote must belong to the product client, be unexpired and unused, and match the product data it priced.
+// The quote is marked as used and the product is saved in a single transaction, so a quote can only lock one product.
+func (ct CreateProduct) saveQuotedProductInDB(c *gin.Context, pr product.Product) (p product.Product, id int, ok bool) {
+	ctx := c.Request.Context()
+	err := dbManager.WithTx(ctx, func(tx database.Repositories) (err error) {
+		p, err = ct.applyQuote(ctx, tx, pr)
+		if err != nil {
+			return
+		}
+
+		// Use the ProductRepository of the transaction to save the product.
+		repo, err := database.GetRepository[database.ProductRepository](tx, database.PRODUCT_REPOSITORY)
+		if err != nil {
+			return
+		}
+		id, err = repo.Create(ctx, p)
+		return
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
+// applyQuote is a method of the CreateProduct struct that locks in the price of the quote referenced by the product
+// and marks the quote as used, using the given repositories.
+func (ct CreateProduct) applyQuote(ctx context.Context, repos database.Repositories, pr product.Product) (p product.Product, err error) {
+	// Get the quote repository.
+	repo, err := database.GetRepository[database.QuoteRepository](repos, database.QUOTE_REPOSITORY)
+	if err != nil {
+		return
+	}
+
+	// Retrieve the quote, restricted to the client of the product.
+	q, err := repo.GetOne(ctx, *pr.QuoteID, pr.ClientID)
+	if err != nil {
+		return
+	}
+
+	// Check the quote can be used by the product and lock in its price.
+	p, err = q.Apply(pr, time.Now().UTC())
+	if err != nil {
+		err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	// Mark the quote as used.
+	err = repo.Use(ctx, q.ID, pr.ClientID)
+	return
+}
+
 // saveProductInDB is a method of the CreateProduct struct that saves the created product in the database.
 // It uses the provided ProductRepository to call the Create method and saves the product.
 // If successful, it returns the generated ID and a boolean indicating success.
 func (ct CreateProduct) saveProductInDB(c *gin.Context, repo database.ProductRepository, p product.Product) (id int, ok bool) {
 	// Use the ProductRepository to create and save the product in the database.
-	id, err := repo.Create(p)
+	id, err := repo.Create(c.Request.Context(), p)
 	if err != nil {
 		handleError(c, err)
 		return
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/coffemanfp/docucentertest/tracking"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateProductEvent represents the action of recording a tracking event for a product.
+type CreateProductEvent struct{}
+
+// Do is a method of the CreateProductEvent struct that records a new tracking event.
+// It reads the event data from the request, validates it, saves it in the database,
+// and sends the created event back as a JSON response.
+func (cpe CreateProductEvent) Do(c *gin.Context) {
+	// Read the product ID from the request.
+	id, ok := cpe.readProductID(c)
+	if !ok {
+		return
+	}
+
+	// Read the event data from the request.
+	e, ok := cpe.readEvent(c)
+	if !ok {
+		return
+	}
+
+	// Create the event and handle any errors.
+	e, ok = cpe.createEvent(c, id, e)
+	if !ok {
+		return
+	}
+
+	// Read the client ID the request is restricted to.
+	clientID, ok := readClientScope(c)
+	if !ok {
+		return
+	}
+
+	// Get the tracking repository.
+	repo, ok := getTrackingRepository(c)
+	if !ok {
+		return
+	}
+
+	// Save the event in the database and handle any errors.
+	e.ID, ok = cpe.saveEventInDB(c, repo, e, clientID)
+	if !ok {
+		return
+	}
+
+	// Send the created event as a JSON response with a 201 Created status.
+	c.JSON(http.StatusCreated, e)
+}
+
+// readProductID is a method of the CreateProductEvent struct that reads the product ID from the URL parameter.
+func (cpe CreateProductEvent) readProductID(c *gin.Context) (id int, ok bool) {
+	return readIntFromURL(c, "id", false)
+}
+
+// readEvent is a method of the CreateProductEvent struct that reads the event data from the request.
+func (cpe CreateProductEvent) readEvent(c *gin.Context) (e tracking.Event, ok bool) {
+	ok = readRequestData(c, &e)
+	return
+}
+
+// createEvent is a method of the CreateProductEvent struct that creates a new event based on the provided data.
+// The product ID is taken from the URL and the recorder from the authenticated client.
+func (cpe CreateProductEvent) createEvent(c *gin.Context, id int, eR tracking.Event) (e tracking.Event, ok bool) {
+	eR.ProductID = id
+	eR.RecordedBy = c.GetInt("id")
+
+	e, err := tracking.New(eR)
+	if err != nil {
+		err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
+// saveEventInDB is a method of the CreateProductEvent struct that saves the created event in the database.
+func (cpe CreateProductEvent) saveEventInDB(c *gin.Context, repo database.TrackingRepository, e tracking.Event, clientID int) (id int, ok bool) {
+	id, err := repo.Create(c.Request.Context(), e, clientID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
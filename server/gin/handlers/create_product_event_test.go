@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/tracking"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateProductEvent_Do(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockTrackingRepository)
+		mockRepo.On("Create", mock.MatchedBy(func(e tracking.Event) bool {
+			return e.ProductID == 3 && e.RecordedBy == 2
+		}), database.ANY_CLIENT).Return(7, nil)
+
+		eventJSON, _ := json.Marshal(tracking.Event{
+			Port:         newInt(1),
+			VehiclePlate: newString("ABC-123"),
+			Note:         "Arrived",
+		})
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.TRACKING_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		r := gin.New()
+		r.POST("/path/:id/events", setClient(2, auth.OPERATOR_ROLE), CreateProductEvent{}.Do)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/path/3/events", bytes.NewBuffer(eventJSON))
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var responseEvent tracking.Event
+		err := json.Unmarshal(rec.Body.Bytes(), &responseEvent)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, responseEvent.ID)
+		assert.Equal(t, 3, responseEvent.ProductID)
+		assert.Equal(t, "Arrived", responseEvent.Note)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidData", func(t *testing.T) {
+		mockRepo := new(MockTrackingRepository)
+
+		eventJSON, _ := json.Marshal(tracking.Event{Note: "Nowhere"})
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.TRACKING_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		r := gin.New()
+		r.POST("/path/:id/events", setClient(2, auth.CLIENT_ROLE), CreateProductEvent{}.Do)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/path/3/events", bytes.NewBuffer(eventJSON))
+		r.ServeHTTP(rec, req)
+
+		assert.Empty(t, rec.Body)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
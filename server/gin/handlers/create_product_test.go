@@ -6,10 +6,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/coffemanfp/docucentertest/auth"
 	"github.com/coffemanfp/docucentertest/config"
 	"github.com/coffemanfp/docucentertest/database"
+	dbErrors "github.com/coffemanfp/docucentertest/database/errors"
 	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/quote"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -35,9 +39,9 @@ func TestCreateProduct_Do(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		r := gin.New()
-		r.POST("/path", ct.Do)
+		r.POST("/path", setClient(1, auth.CLIENT_ROLE), ct.Do)
 
 		rec := httptest.NewRecorder()
 		req, _ := http.NewRequest("POST", "/path", bytes.NewBuffer(prJSON))
@@ -49,9 +53,74 @@ func TestCreateProduct_Do(t *testing.T) {
 		err := json.Unmarshal(rec.Body.Bytes(), &responseProduct)
 		assert.NoError(t, err)
 		pr.ID = newID
+		pr.Status = product.REGISTERED_STATUS
 		assert.Equal(t, pr, responseProduct)
 	})
 
+	t.Run("ClientForAnotherClient", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Create", mock.MatchedBy(func(p product.Product) bool {
+			return p.ClientID == 1
+		})).Return(1, nil)
+
+		pr := product.Product{
+			ClientID:     2,
+			GuideNumber:  newString("ABC1234567"),
+			VehiclePlate: newString("ABC-123"),
+		}
+		prJSON, _ := json.Marshal(pr)
+		ct := CreateProduct{}
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		r := gin.New()
+		r.POST("/path", setClient(1, auth.CLIENT_ROLE), ct.Do)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/path", bytes.NewBuffer(prJSON))
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("OperatorForAnotherClient", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Create", mock.MatchedBy(func(p product.Product) bool {
+			return p.ClientID == 2
+		})).Return(1, nil)
+
+		pr := product.Product{
+			ClientID:     2,
+			GuideNumber:  newString("ABC1234567"),
+			VehiclePlate: newString("ABC-123"),
+		}
+		prJSON, _ := json.Marshal(pr)
+		ct := CreateProduct{}
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		r := gin.New()
+		r.POST("/path", setClient(1, auth.OPERATOR_ROLE), ct.Do)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/path", bytes.NewBuffer(prJSON))
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("InvalidData", func(t *testing.T) {
 		mockRepo := new(MockProductRepository)
 		mockRepo.On("Create", mock.Anything).Return(0, nil)
@@ -68,7 +137,7 @@ func TestCreateProduct_Do(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		r := gin.New()
 		r.POST("/path", ct.Do)
 
@@ -78,4 +147,129 @@ func TestCreateProduct_Do(t *testing.T) {
 
 		assert.Empty(t, rec.Body)
 	})
+
+	t.Run("WithQuote", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Create", mock.MatchedBy(func(p product.Product) bool {
+			return p.ShippingPrice != nil && *p.ShippingPrice == 250 && *p.QuoteID == 7
+		})).Return(1, nil)
+		mockQuoteRepo := new(MockQuoteRepository)
+		mockQuoteRepo.On("GetOne", 7, 1).Return(quote.Quote{
+			ID:            7,
+			ClientID:      1,
+			Product:       product.Product{ClientID: 1},
+			ShippingPrice: 250,
+			ExpiresAt:     time.Now().Add(time.Hour),
+		}, nil)
+		mockQuoteRepo.On("Use", 7, 1).Return(nil)
+
+		pr := product.Product{
+			GuideNumber:  newString("ABC1234567"),
+			VehiclePlate: newString("ABC-123"),
+			QuoteID:      newInt(7),
+		}
+		prJSON, _ := json.Marshal(pr)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+				database.QUOTE_REPOSITORY:   mockQuoteRepo,
+			},
+		}
+		db.Conn = MockTxConnector{repos: db.Repositories}
+
+		Init(db, config.ConfigInfo{})
+		r := gin.New()
+		r.POST("/path", setClient(1, auth.CLIENT_ROLE), CreateProduct{}.Do)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/path", bytes.NewBuffer(prJSON))
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockRepo.AssertExpectations(t)
+		mockQuoteRepo.AssertExpectations(t)
+	})
+
+	t.Run("WithExpiredQuote", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockQuoteRepo := new(MockQuoteRepository)
+		mockQuoteRepo.On("GetOne", 7, 1).Return(quote.Quote{
+			ID:            7,
+			ClientID:      1,
+			Product:       product.Product{ClientID: 1},
+			ShippingPrice: 250,
+			ExpiresAt:     time.Now().Add(-time.Hour),
+		}, nil)
+
+		pr := product.Product{
+			GuideNumber:  newString("ABC1234567"),
+			VehiclePlate: newString("ABC-123"),
+			QuoteID:      newInt(7),
+		}
+		prJSON, _ := json.Marshal(pr)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+				database.QUOTE_REPOSITORY:   mockQuoteRepo,
+			},
+		}
+		db.Conn = MockTxConnector{repos: db.Repositories}
+
+		Init(db, config.ConfigInfo{})
+		r := gin.New()
+		r.POST("/path", setClient(1, auth.CLIENT_ROLE), CreateProduct{}.Do)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/path", bytes.NewBuffer(prJSON))
+		r.ServeHTTP(rec, req)
+
+		assert.Empty(t, rec.Body)
+		mockQuoteRepo.AssertNotCalled(t, "Use", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("WithQuoteFailedInsert", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Create", mock.Anything).Return(0, dbErrors.NewError(dbErrors.ALREADY_EXISTS, "failed to insert a row in product table", "already exists"))
+		mockQuoteRepo := new(MockQuoteRepository)
+		mockQuoteRepo.On("GetOne", 7, 1).Return(quote.Quote{
+			ID:            7,
+			ClientID:      1,
+			Product:       product.Product{ClientID: 1},
+			ShippingPrice: 250,
+			ExpiresAt:     time.Now().Add(time.Hour),
+		}, nil)
+		mockQuoteRepo.On("Use", 7, 1).Return(nil)
+
+		pr := product.Product{
+			GuideNumber:  newString("ABC1234567"),
+			VehiclePlate: newString("ABC-123"),
+			QuoteID:      newInt(7),
+		}
+		prJSON, _ := json.Marshal(pr)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+				database.QUOTE_REPOSITORY:   mockQuoteRepo,
+			},
+		}
+		var txErr error
+		db.Conn = MockTxConnector{repos: db.Repositories, result: &txErr}
+
+		Init(db, config.ConfigInfo{})
+		r := gin.New()
+		r.POST("/path", setClient(1, auth.CLIENT_ROLE), CreateProduct{}.Do)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/path", bytes.NewBuffer(prJSON))
+		r.ServeHTTP(rec, req)
+
+		// The quote is used in the transaction of the failed insert, so it is rolled back with it.
+		assert.Empty(t, rec.Body)
+		mockQuoteRepo.AssertExpectations(t)
+		assert.Error(t, txErr)
+	})
 }
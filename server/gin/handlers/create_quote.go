@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/quote"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateQuote is a struct that represents the action of pricing a shipment without registering it.
+type CreateQuote struct{}
+
+// Do is a method of the CreateQuote struct that handles the creation of a new quote.
+// It reads the same product data as CreateProduct, validates and prices it with the pricing rules,
+// saves the quote with an expiry and sends it back as a JSON response. The product is not saved.
+func (cq CreateQuote) Do(c *gin.Context) {
+	// Read the product data from the request.
+	p, ok := cq.readProduct(c)
+	if !ok {
+		return
+	}
+
+	// Validate the product data the same way it is validated on creation.
+	p, ok = CreateProduct{}.createProduct(c, p)
+	if !ok {
+		return
+	}
+
+	// Build the pricing rules engine.
+	engine, ok := getPricingEngine(c)
+	if !ok {
+		return
+	}
+
+	// Price the product and issue the quote.
+	q, ok := cq.createQuote(c, engine, p)
+	if !ok {
+		return
+	}
+
+	// Get the quote repository.
+	repo, ok := getQuoteRepository(c)
+	if !ok {
+		return
+	}
+
+	// Save the quote in the database and handle any errors.
+	id, ok := cq.saveQuoteInDB(c, repo, q)
+	if !ok {
+		return
+	}
+
+	// Set the generated ID in the quote.
+	q.ID = id
+
+	// Send the created quote as a JSON response with a 201 Created status.
+	c.JSON(http.StatusCreated, q)
+}
+
+// readProduct is a method of the CreateQuote struct that reads the product data from the request.
+func (cq CreateQuote) readProduct(c *gin.Context) (p product.Product, ok bool) {
+	ok = readRequestData(c, &p)
+	return
+}
+
+// createQuote is a method of the CreateQuote struct that prices a product and issues a quote for it,
+// with the tax rate and lifespan of the configuration.
+func (cq CreateQuote) createQuote(c *gin.Context, engine product.RulesEngine, p product.Product) (q quote.Quote, ok bool) {
+	now := time.Now().UTC()
+	// Calculate the itemised price of the product with the pricing rules.
+	pricing := product.NewDiscountGenerator(engine, p, now).Pricing()
+
+	q, err := quote.New(p, pricing, conf.Server.TaxRate, conf.Server.QuoteLifespan, now)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
+// saveQuoteInDB is a method of the CreateQuote struct that saves the issued quote in the database.
+func (cq CreateQuote) saveQuoteInDB(c *gin.Context, repo database.QuoteRepository, q quote.Quote) (id int, ok bool) {
+	id, err := repo.Create(c.Request.Context(), q)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/quote"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateQuote_Do(t *testing.T) {
+	conf := config.ConfigInfo{}
+	conf.Server.TaxRate = 10
+	conf.Server.QuoteLifespan = 24
+
+	t.Run("Success", func(t *testing.T) {
+		mockQuoteRepo := new(MockQuoteRepository)
+		mockQuoteRepo.On("Create", mock.MatchedBy(func(q quote.Quote) bool {
+			return q.ClientID == 1 && q.Discount == 5 && q.ExpiresAt.Location() == time.UTC
+		})).Return(4, nil)
+		mockProductRepo := new(MockProductRepository)
+
+		pr := product.Product{
+			GuideNumber:   newString("ABC1234567"),
+			VehiclePlate:  newString("ABC-123"),
+			Quantity:      newInt(10),
+			Vault:         newInt(1),
+			ShippingPrice: newFloat64(100),
+		}
+		prJSON, _ := json.Marshal(pr)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockProductRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
+				database.QUOTE_REPOSITORY:   mockQuoteRepo,
+			},
+		}
+
+		Init(db, conf)
+		r := gin.New()
+		r.POST("/path", setClient(1, auth.CLIENT_ROLE), CreateQuote{}.Do)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/path", bytes.NewBuffer(prJSON))
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var responseQuote quote.Quote
+		err := json.Unmarshal(rec.Body.Bytes(), &responseQuote)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, responseQuote.ID)
+		assert.Equal(t, 100.0, responseQuote.ShippingPrice)
+		assert.Equal(t, 5.0, responseQuote.Discount)
+		assert.Equal(t, 9.5, responseQuote.Taxes)
+		assert.Equal(t, 104.5, responseQuote.Total)
+		assert.Equal(t, []string{"bulk_vault"}, responseQuote.Rules)
+		mockProductRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("InvalidData", func(t *testing.T) {
+		mockQuoteRepo := new(MockQuoteRepository)
+
+		pr := product.Product{
+			GuideNumber:  newString("short"),
+			VehiclePlate: newString("ABC-123"),
+		}
+		prJSON, _ := json.Marshal(pr)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
+				database.QUOTE_REPOSITORY:   mockQuoteRepo,
+			},
+		}
+
+		Init(db, conf)
+		r := gin.New()
+		r.POST("/path", setClient(1, auth.CLIENT_ROLE), CreateQuote{}.Do)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/path", bytes.NewBuffer(prJSON))
+		r.ServeHTTP(rec, req)
+
+		assert.Empty(t, rec.Body)
+		mockQuoteRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateSavedSearch is a struct that represents the action of saving the criteria of a search.
+type CreateSavedSearch struct{}
+
+// savedSearchRequest represents the body of a request creating or replacing a saved search.
+type savedSearchRequest struct {
+	Name       string          `json:"name"`        // Name of the saved search.
+	ScopeID    int             `json:"scope_id"`    // Client whose products are searched, every client if zero. Only privileged roles can set it.
+	Criteria   search.Criteria `json:"criteria"`    // Criteria of the search, with the same names as the search query parameters.
+	Schedule   string          `json:"schedule"`    // Cron expression of the runs, never run if empty.
+	WebhookURL string          `json:"webhook_url"` // URL every digest is posted to, if any.
+}
+
+// Do is a method of the CreateSavedSearch struct that handles the creation of a new saved search.
+// It reads the saved search from the request, validates it, saves it in the database
+// and sends the created saved search back as a JSON response.
+func (cs CreateSavedSearch) Do(c *gin.Context) {
+	// Read the saved search data from the request.
+	var req savedSearchRequest
+	ok := readRequestData(c, &req)
+	if !ok {
+		return
+	}
+
+	// Validate the saved search, owned by the authenticated client.
+	s, ok := newSavedSearch(c, c.GetInt("id"), req)
+	if !ok {
+		return
+	}
+
+	// Get the saved search repository.
+	repo, ok := getSavedSearchRepository(c)
+	if !ok {
+		return
+	}
+
+	// Save the saved search in the database and handle any errors.
+	id, ok := cs.saveSavedSearchInDB(c, repo, s)
+	if !ok {
+		return
+	}
+
+	// Set the generated ID in the saved search.
+	s.ID = id
+
+	// Send the created saved search as a JSON response with a 201 Created status.
+	c.JSON(http.StatusCreated, s)
+}
+
+// saveSavedSearchInDB is a method of the CreateSavedSearch struct that saves a new saved search in the database.
+func (cs CreateSavedSearch) saveSavedSearchInDB(c *gin.Context, repo database.SavedSearchRepository, s savedsearch.SavedSearch) (id int, ok bool) {
+	id, err := repo.Create(c.Request.Context(), s)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
+// newSavedSearch validates the saved search of a request, belonging to clientID.
+// Clients always search their own products, privileged roles search the ones of the requested scope.
+// If it is invalid, it handles the error and returns ok as false.
+func newSavedSearch(c *gin.Context, clientID int, req savedSearchRequest) (s savedsearch.SavedSearch, ok bool) {
+	if !getRole(c).IsPrivileged() {
+		req.ScopeID = c.GetInt("id")
+	}
+
+	s, err := savedsearch.New(clientID, req.ScopeID, req.Name, req.Criteria, req.Schedule, req.WebhookURL, time.Now().UTC())
+	if err != nil {
+		err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	sErrors "github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateSavedSearch_Do(t *testing.T) {
+	newRouter := func(repo *MockSavedSearchRepository, role auth.Role) *gin.Engine {
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.SAVED_SEARCH_REPOSITORY: repo,
+			},
+		}
+		Init(db, config.ConfigInfo{})
+		r := gin.New()
+		r.POST("/path", setClient(1, role), CreateSavedSearch{}.Do)
+		return r
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockSavedSearchRepository)
+		mockRepo.On("Create", mock.MatchedBy(func(s savedsearch.SavedSearch) bool {
+			return s.ClientID == 1 && s.ScopeID == 1 && s.Name == "Boxes" && s.Criteria.Type == "box" && s.NextRunAt != nil
+		})).Return(3, nil)
+
+		// Clients can not search the products of other clients, so the scope is ignored
+		body := `{"name": " Boxes ", "scope_id": 2, "criteria": {"type": "box"}, "schedule": "0 8 * * *"}`
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/path", bytes.NewBufferString(body))
+		newRouter(mockRepo, auth.CLIENT_ROLE).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var s savedsearch.SavedSearch
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &s))
+		assert.Equal(t, 3, s.ID)
+		assert.Equal(t, "Boxes", s.Name)
+		assert.Equal(t, 8, s.NextRunAt.Hour())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("PrivilegedScope", func(t *testing.T) {
+		mockRepo := new(MockSavedSearchRepository)
+		mockRepo.On("Create", mock.MatchedBy(func(s savedsearch.SavedSearch) bool {
+			return s.ClientID == 1 && s.ScopeID == database.ANY_CLIENT && s.NextRunAt == nil
+		})).Return(3, nil)
+
+		body := `{"name": "Every box", "criteria": {"type": "box"}}`
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/path", bytes.NewBufferString(body))
+		newRouter(mockRepo, auth.OPERATOR_ROLE).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidData", func(t *testing.T) {
+		for _, body := range []string{
+			`{"name": "", "criteria": {}}`,
+			`{"name": "Boxes", "criteria": {"port": "nope"}}`,
+			`{"name": "Boxes", "criteria": {}, "schedule": "every day"}`,
+			`{"name": "Boxes", "criteria": {}, "webhook_url": "ftp://example.com"}`,
+		} {
+			mockRepo := new(MockSavedSearchRepository)
+			Init(database.Database{
+				Repositories: map[database.RepositoryID]interface{}{
+					database.SAVED_SEARCH_REPOSITORY: mockRepo,
+				},
+			}, config.ConfigInfo{})
+
+			req, _ := http.NewRequest("POST", "/path", bytes.NewBufferString(body))
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = req
+			c.Set("id", 1)
+			CreateSavedSearch{}.Do(c)
+
+			if assert.NotEmpty(t, c.Errors, body) {
+				httpErr, ok := c.Errors[0].Err.(sErrors.HTTPError)
+				assert.True(t, ok)
+				assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code, body)
+			}
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+		}
+	})
+}
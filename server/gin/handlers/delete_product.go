@@ -21,6 +21,12 @@ func (dp DeleteProduct) Do(c *gin.Context) {
 		return
 	}
 
+	// Read the client ID the request is restricted to.
+	clientID, ok := readClientScope(c)
+	if !ok {
+		return
+	}
+
 	// Get the product repository using the getProductRepository function.
 	repo, ok := getProductRepository(c)
 	if !ok {
@@ -28,7 +34,7 @@ func (dp DeleteProduct) Do(c *gin.Context) {
 	}
 
 	// Delete the product in the database using the deleteProductInDB function.
-	ok = dp.deleteProductInDB(c, repo, id)
+	ok = dp.deleteProductInDB(c, repo, id, clientID)
 	if !ok {
 		return
 	}
@@ -44,11 +50,11 @@ func (dp DeleteProduct) readProductID(c *gin.Context) (id int, ok bool) {
 }
 
 // deleteProductInDB is a method of the DeleteProduct struct that deletes a product from the database.
-// It takes the product ID and product repository as parameters and uses the Delete method of the repository.
+// It takes the product ID, the client ID and product repository as parameters and uses the Delete method of the repository.
 // If successful, it returns true, otherwise, it handles the error and returns false.
-func (dp DeleteProduct) deleteProductInDB(c *gin.Context, repo database.ProductRepository, id int) (ok bool) {
+func (dp DeleteProduct) deleteProductInDB(c *gin.Context, repo database.ProductRepository, id, clientID int) (ok bool) {
 	// Delete the product in the database using the Delete method of the repository.
-	err := repo.Delete(id, c.GetInt("id"))
+	err := repo.Delete(c.Request.Context(), id, clientID)
 	if err != nil {
 		// Handle the error using the handleError function.
 		handleError(c, err)
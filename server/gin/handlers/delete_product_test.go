@@ -26,7 +26,7 @@ func TestDeleteProduct_Do(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		r := gin.New()
 		r.DELETE("/path/:id", ct.Do)
 
@@ -55,7 +55,7 @@ func TestDeleteProduct_Do(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		// Set up the handler and execute the action
 		gc := DeleteProduct{}
 		gc.Do(c)
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteSavedSearch represents the action of deleting a saved search and its digests.
+type DeleteSavedSearch struct{}
+
+// Do is a method of the DeleteSavedSearch struct that performs the deletion of a saved search.
+// If successful, it responds with a 200 OK status.
+func (ds DeleteSavedSearch) Do(c *gin.Context) {
+	// Read the saved search ID from the request, and the client ID the request is restricted to.
+	id, clientID, ok := readSavedSearchID(c)
+	if !ok {
+		return
+	}
+
+	// Get the saved search repository.
+	repo, ok := getSavedSearchRepository(c)
+	if !ok {
+		return
+	}
+
+	// Delete the saved search in the database.
+	ok = ds.deleteSavedSearchInDB(c, repo, id, clientID)
+	if !ok {
+		return
+	}
+
+	// Respond with a 200 OK status.
+	c.Status(http.StatusOK)
+}
+
+// deleteSavedSearchInDB is a method of the DeleteSavedSearch struct that deletes a saved search of the client from the database.
+func (ds DeleteSavedSearch) deleteSavedSearchInDB(c *gin.Context, repo database.SavedSearchRepository, id, clientID int) (ok bool) {
+	err := repo.Delete(c.Request.Context(), id, clientID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
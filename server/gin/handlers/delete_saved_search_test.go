@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteSavedSearch_Do(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockSavedSearchRepository)
+		mockRepo.On("Delete", 3, 1).Return(nil)
+
+		c, rec := newSavedSearchContext("DELETE", "/path/3", "", mockRepo, auth.CLIENT_ROLE)
+		DeleteSavedSearch{}.Do(c)
+
+		assert.Empty(t, c.Errors)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("OtherClient", func(t *testing.T) {
+		mockRepo := new(MockSavedSearchRepository)
+		mockRepo.On("Delete", 3, 1).Return(errSavedSearchNotFound)
+
+		c, _ := newSavedSearchContext("DELETE", "/path/3?client_id=2", "", mockRepo, auth.CLIENT_ROLE)
+		DeleteSavedSearch{}.Do(c)
+
+		assertNotFound(t, c)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AdminScope", func(t *testing.T) {
+		mockRepo := new(MockSavedSearchRepository)
+		mockRepo.On("Delete", 3, database.ANY_CLIENT).Return(nil)
+
+		c, rec := newSavedSearchContext("DELETE", "/path/3", "", mockRepo, auth.ADMIN_ROLE)
+		DeleteSavedSearch{}.Do(c)
+
+		assert.Empty(t, c.Errors)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
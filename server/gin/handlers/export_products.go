@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/jobs"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/coffemanfp/docucentertest/spreadsheet"
+	"github.com/gin-gonic/gin"
+)
+
+// exportBatchSize is the number of products retrieved from the database at a time by the exports.
+const exportBatchSize = 500
+
+// exportFormats are the formats the products can be exported in, the first one being the default.
+var exportFormats = []spreadsheet.Format{spreadsheet.CSV_FORMAT, spreadsheet.XLSX_FORMAT, spreadsheet.NDJSON_FORMAT}
+
+// ExportProducts is a struct that represents an export products operation.
+type ExportProducts struct{}
+
+// exportParams are the parameters of an export job, see ExportProducts.Run.
+type exportParams struct {
+	Criteria search.Criteria    `json:"criteria"`       // Criteria of the exported products, validated again when the job runs
+	ScopeID  int                `json:"scope_id"`       // Identifier of the client whose products are exported, every client if zero
+	Sort     string             `json:"sort,omitempty"` // Order of the products, in the syntax of the "sort" query parameter
+	Format   spreadsheet.Format `json:"format"`         // Format the products are exported in
+}
+
+// Do is a method of the ExportProducts struct that exports every product of the client as a file.
+// The products are sorted by the "sort" query parameter, and the format is negotiated by exportProducts.
+func (ep ExportProducts) Do(c *gin.Context) {
+	// Read the client ID the export is restricted to.
+	clientID, ok := readClientScope(c)
+	if !ok {
+		return
+	}
+
+	// Read the order of the products.
+	sort, err := search.NewProductSort(c.Query("sort"))
+	if err != nil {
+		err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		handleError(c, err)
+		return
+	}
+
+	// Export every product of the client.
+	exportProducts(c, search.Search{
+		ClientID:   clientID,
+		Pagination: search.Pagination{Sort: sort},
+	}, exportParams{ScopeID: clientID, Sort: c.Query("sort")})
+}
+
+// Run is a method of the ExportProducts struct that runs an export job, see jobs.Runner.
+// The products matching the criteria of the job are exported like exportProducts does, and the file is its result.
+// The progress is the percentage of the products matching the criteria when the job started that were exported.
+func (ep ExportProducts) Run(ctx context.Context, job jobs.Job, progress func(percent int)) (result *jobs.Result, err error) {
+	var params exportParams
+	err = job.ReadParams(&params)
+	if err != nil {
+		return
+	}
+
+	// Create the search of the criteria, whose relative dates match the current period.
+	srch, err := params.Criteria.Search(params.ScopeID)
+	if err != nil {
+		err = jobs.Permanent(err)
+		return
+	}
+	sort, err := search.NewProductSort(params.Sort)
+	if err != nil {
+		err = jobs.Permanent(err)
+		return
+	}
+	srch.Pagination = search.Pagination{Sort: sort}
+
+	repo, err := database.GetRepository[database.ProductRepository](db, database.PRODUCT_REPOSITORY)
+	if err != nil {
+		return
+	}
+	engine, err := pricingEngine(ctx)
+	if err != nil {
+		return
+	}
+
+	// Count the matching products, to report the progress.
+	count := srch
+	count.Pagination = search.Pagination{Limit: 1}
+	_, total, err := repo.Search(ctx, count)
+	if err != nil {
+		return
+	}
+
+	// Write every batch of matching products to the file.
+	var buf bytes.Buffer
+	pe := &productExport{
+		ctx:    ctx,
+		format: params.Format,
+		engine: engine,
+		open: func() io.Writer {
+			return &buf
+		},
+		total:    total,
+		progress: progress,
+	}
+	err = repo.Export(ctx, srch, exportBatchSize, pe.write)
+	if err == nil {
+		err = pe.close()
+	}
+	if err != nil {
+		return
+	}
+
+	result = &jobs.Result{
+		Name:        fmt.Sprintf("products.%s", params.Format),
+		ContentType: params.Format.ContentType(),
+		Data:        buf.Bytes(),
+	}
+	return
+}
+
+// exportProducts streams every product matching srch to the response, with its discount, ignoring the pagination of srch.
+// The format is read from the "format" query parameter, or negotiated with the Accept header, CSV by default.
+// The products are retrieved and written in batches of exportBatchSize, so they are never held in memory all at once.
+// With the "async" query parameter, the products are exported by a background job instead, see enqueueJob,
+// whose parameters are params with the format of the "format" query parameter, as the job is polled as JSON.
+func exportProducts(c *gin.Context, srch search.Search, params exportParams) {
+	// Read whether the products must be exported by a background job.
+	async, ok := readBoolFromURL(c, "async", true)
+	if !ok {
+		return
+	}
+
+	// Read the format to export the products in.
+	format, ok := readExportFormat(c, !async)
+	if !ok {
+		return
+	}
+
+	// Queue the export of the products, the file being the result of the job.
+	if async {
+		params.Format = format
+		enqueueJob(c, jobs.EXPORT_PRODUCTS_KIND, params, nil)
+		return
+	}
+
+	// Get the product repository.
+	repo, ok := getProductRepository(c)
+	if !ok {
+		return
+	}
+
+	// Build the pricing rules engine.
+	engine, ok := getPricingEngine(c)
+	if !ok {
+		return
+	}
+
+	// Write every batch of matching products as it is retrieved, starting the response with the first one.
+	pe := &productExport{
+		ctx:    c.Request.Context(),
+		format: format,
+		engine: engine,
+		open: func() io.Writer {
+			c.Header("Content-Type", format.ContentType())
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=products.%s", format))
+			c.Status(http.StatusOK)
+			return c.Writer
+		},
+	}
+	err := repo.Export(c.Request.Context(), srch, exportBatchSize, pe.write)
+	if err == nil {
+		err = pe.close()
+	}
+	if err != nil {
+		// If no part of the file was sent yet, the error is responded like any other one.
+		// Otherwise the response can not be replaced, and it ends without the rest of the file.
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+		}
+		handleError(c, err)
+	}
+}
+
+// readExportFormat reads the format of an export from the "format" query parameter, or negotiates it with the Accept
+// header if negotiate is true. Otherwise the first of the exportFormats is the default.
+// If it is invalid or unsupported, it handles the error and returns ok as false.
+func readExportFormat(c *gin.Context, negotiate bool) (format spreadsheet.Format, ok bool) {
+	var err error
+	if v := c.Query("format"); v != "" {
+		format, err = spreadsheet.ParseFormat(v)
+		if err != nil {
+			err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+			handleError(c, err)
+			return
+		}
+		ok = true
+		return
+	}
+	if !negotiate {
+		format, ok = exportFormats[0], true
+		return
+	}
+
+	// Negotiate the format with the content types accepted by the client.
+	offered := make([]string, len(exportFormats))
+	for i, f := range exportFormats {
+		offered[i] = f.ContentType()
+	}
+	format, err = spreadsheet.FormatOfContentType(c.NegotiateFormat(offered...))
+	if err != nil {
+		err = errors.NewHTTPError(http.StatusNotAcceptable, "invalid accept header: the products can only be exported as %v", offered)
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
+// productExport writes the products of an export to a file, which is only opened with the first batch of products,
+// so an error retrieving it can still be responded.
+type productExport struct {
+	ctx      context.Context
+	format   spreadsheet.Format
+	engine   product.RulesEngine
+	open     func() io.Writer           // Opens the output of the file, before its header is written
+	total    int                        // Number of products to export, to report the progress, unknown if zero
+	progress func(percent int)          // Reports the percentage of the products exported, if not nil
+	exported int                        // Number of products exported so far
+	w        *spreadsheet.ProductWriter // Writer of the file, nil until it is opened
+}
+
+// start opens the output of the export, writing the header of the file.
+func (pe *productExport) start() (err error) {
+	if pe.w != nil {
+		return
+	}
+	w, err := spreadsheet.NewProductWriter(pe.open(), pe.format)
+	if err != nil {
+		return
+	}
+	pe.w = &w
+	return
+}
+
+// write writes a batch of products to the file, with their discounts.
+func (pe *productExport) write(ps []*product.Product) (err error) {
+	// Retrieve the prices locked by the quotes of the batch.
+	locked, err := lockedPricings(pe.ctx, ps)
+	if err != nil {
+		return
+	}
+
+	// Apply discount calculation to the batch.
+	ps = Search{}.generateDiscount(pe.engine, locked, ps)
+
+	err = pe.start()
+	if err != nil {
+		return
+	}
+	for _, p := range ps {
+		err = pe.w.Write(*p)
+		if err != nil {
+			return
+		}
+	}
+
+	pe.exported += len(ps)
+	if pe.progress != nil && pe.total > 0 {
+		pe.progress(pe.exported * 100 / pe.total)
+	}
+	return
+}
+
+// close writes the rest of the file, opening it if no product was exported.
+func (pe *productExport) close() (err error) {
+	err = pe.start()
+	if err != nil {
+		return
+	}
+	return pe.w.Close()
+}
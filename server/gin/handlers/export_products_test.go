@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/jobs"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/search"
+	sErrors "github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/coffemanfp/docucentertest/spreadsheet"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newExportContext creates the context of an export request of client 1, on the given repositories.
+func newExportContext(method, target, body string, mockRepo *MockProductRepository, mockQuoteRepo *MockQuoteRepository) (c *gin.Context, rec *httptest.ResponseRecorder) {
+	req, _ := http.NewRequest(method, target, strings.NewReader(body))
+	rec = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(rec)
+	c.Request = req
+	c.Set("id", 1)
+
+	db := database.Database{
+		Repositories: map[database.RepositoryID]interface{}{
+			database.PRODUCT_REPOSITORY: mockRepo,
+			database.PRICING_REPOSITORY: newMockPricingRepository(),
+			database.QUOTE_REPOSITORY:   mockQuoteRepo,
+		},
+	}
+	Init(db, config.ConfigInfo{})
+	return
+}
+
+func TestExportProducts_Do(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Two batches, the second one with a product priced by its quote.
+		batches := [][]*product.Product{
+			{{ID: 1, ClientID: 1, GuideNumber: newString("ABC1234567"), Quantity: newInt(1), ShippingPrice: newFloat64(100), VehiclePlate: newString("ABC-123")}},
+			{{ID: 2, ClientID: 1, GuideNumber: newString("DEF1234567"), Quantity: newInt(1), ShippingPrice: newFloat64(100), VehiclePlate: newString("ABC-123"), QuoteID: newInt(7)}},
+		}
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Export", search.Search{
+			ClientID:   1,
+			Pagination: search.Pagination{Sort: search.Sort{{Name: "shipping_price", Desc: true}}},
+		}, exportBatchSize).Return(batches, nil)
+		mockQuoteRepo := new(MockQuoteRepository)
+		mockQuoteRepo.On("GetPricings", []int{7}).Return(map[int]product.Pricing{7: {Discount: 30}}, nil)
+
+		c, rec := newExportContext("GET", "/path?sort=-shipping_price", "", mockRepo, mockQuoteRepo)
+		ExportProducts{}.Do(c)
+
+		assert.Empty(t, c.Errors)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "attachment; filename=products.csv", rec.Header().Get("Content-Disposition"))
+		lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		if assert.Len(t, lines, 3) {
+			assert.Equal(t, "1,1,ABC1234567,,1,,,100,0,ABC-123,,,", lines[1])
+			assert.Equal(t, "2,1,DEF1234567,,1,,,100,30,ABC-123,,,", lines[2])
+		}
+		mockRepo.AssertExpectations(t)
+		mockQuoteRepo.AssertExpectations(t)
+	})
+
+	t.Run("NoProducts", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Export", mock.Anything, exportBatchSize).Return(nil, nil)
+
+		c, rec := newExportContext("GET", "/path?format=ndjson", "", mockRepo, nil)
+		ExportProducts{}.Do(c)
+
+		// Only the header is written, which is empty in NDJSON.
+		assert.Empty(t, c.Errors)
+		assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("FailureBeforeTheFirstBatch", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Export", mock.Anything, exportBatchSize).Return(nil, fmt.Errorf("failed"))
+
+		c, rec := newExportContext("GET", "/path", "", mockRepo, nil)
+		ExportProducts{}.Do(c)
+
+		// The error can still be responded, as nothing was sent.
+		if assert.NotEmpty(t, c.Errors) {
+			assert.EqualError(t, c.Errors[0].Err, "failed")
+		}
+		assert.Empty(t, rec.Header().Get("Content-Disposition"))
+		assert.False(t, c.Writer.Written())
+	})
+
+	t.Run("InvalidFormat", func(t *testing.T) {
+		c, _ := newExportContext("GET", "/path?format=pdf", "", new(MockProductRepository), nil)
+		ExportProducts{}.Do(c)
+
+		if assert.NotEmpty(t, c.Errors) {
+			httpErr, ok := c.Errors[0].Err.(sErrors.HTTPError)
+			assert.True(t, ok)
+			assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+		}
+	})
+
+	t.Run("NotAcceptable", func(t *testing.T) {
+		c, _ := newExportContext("GET", "/path", "", new(MockProductRepository), nil)
+		c.Request.Header.Set("Accept", "application/json")
+		ExportProducts{}.Do(c)
+
+		if assert.NotEmpty(t, c.Errors) {
+			httpErr, ok := c.Errors[0].Err.(sErrors.HTTPError)
+			assert.True(t, ok)
+			assert.Equal(t, http.StatusNotAcceptable, httpErr.Code)
+		}
+	})
+}
+
+func TestExportSearch_Do(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockRepo.On("Export", mock.MatchedBy(func(s search.Search) bool {
+		return s.ClientID == 1 && assert.ObjectsAreEqual([]string{"box"}, s.Type.Values)
+	}), exportBatchSize).Return([][]*product.Product{{{ID: 1, ClientID: 1, Type: newString("box")}}}, nil)
+
+	c, rec := newExportContext("GET", "/path?type=box&page=2", "", mockRepo, nil)
+	c.Request.Header.Set("Accept", "application/x-ndjson, text/csv;q=0.5")
+	ExportSearch{}.Do(c)
+
+	assert.Empty(t, c.Errors)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `{"id":1,"client_id":1,"guide_number":null,"type":"box","quantity":null,"joined_at":null,"delivered_at":null,"shipping_price":null,"discount":0,"vehicle_plate":null,"port":null,"vault":null,"status":""}`+"\n", rec.Body.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportFilterSearch_Do(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockRepo.On("Export", mock.MatchedBy(func(s search.Search) bool {
+		return s.Filter != nil
+	}), exportBatchSize).Return([][]*product.Product{}, nil)
+
+	c, rec := newExportContext("POST", "/path?format=xlsx", `{"filter": {"field": "port", "op": "eq", "value": 1}}`, mockRepo, nil)
+	ExportFilterSearch{}.Do(c)
+
+	assert.Empty(t, c.Errors)
+	assert.Equal(t, "attachment; filename=products.xlsx", rec.Header().Get("Content-Disposition"))
+	assert.NotEmpty(t, rec.Body.Bytes())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportProducts_Async(t *testing.T) {
+	mockJobRepo := new(MockJobRepository)
+	mockJobRepo.On("Create", mock.MatchedBy(func(j jobs.Job) bool {
+		var params exportParams
+		return j.ClientID == 1 && j.Kind == jobs.EXPORT_PRODUCTS_KIND && j.Input == nil && j.ReadParams(&params) == nil &&
+			params.ScopeID == 1 && params.Sort == "-shipping_price" && params.Format == spreadsheet.XLSX_FORMAT
+	})).Return(3, nil)
+
+	c, rec := newExportContext("GET", "/path?async=true&format=xlsx&sort=-shipping_price", "", new(MockProductRepository), nil)
+	db[database.JOB_REPOSITORY] = mockJobRepo
+	ExportProducts{}.Do(c)
+
+	// The products are only exported once the job runs.
+	assert.Empty(t, c.Errors)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "/v1/jobs/3", rec.Header().Get("Location"))
+	assert.Empty(t, rec.Header().Get("Content-Disposition"))
+	mockJobRepo.AssertExpectations(t)
+}
+
+func TestExportProducts_Run(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		batches := [][]*product.Product{
+			{{ID: 1, ClientID: 1, GuideNumber: newString("ABC1234567")}},
+			{{ID: 2, ClientID: 1, GuideNumber: newString("DEF1234567")}},
+		}
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Search", mock.MatchedBy(func(srch search.Search) bool {
+			return srch.ClientID == 1 && srch.Type.Values[0] == "box" && srch.Pagination.Limit == 1
+		})).Return([]*product.Product{}, 2, nil)
+		mockRepo.On("Export", mock.MatchedBy(func(srch search.Search) bool {
+			return srch.ClientID == 1 && srch.Type.Values[0] == "box" && srch.Pagination.Sort[0].Name == "joined_at"
+		}), exportBatchSize).Return(batches, nil)
+		newExportContext("GET", "/path", "", mockRepo, nil)
+
+		params := exportParams{Criteria: search.Criteria{Type: "box"}, ScopeID: 1, Sort: "joined_at", Format: spreadsheet.NDJSON_FORMAT}
+		j, err := jobs.New(1, jobs.EXPORT_PRODUCTS_KIND, params, nil, time.Now())
+		require.NoError(t, err)
+		percents := make([]int, 0)
+		result, err := ExportProducts{}.Run(context.Background(), j, func(percent int) {
+			percents = append(percents, percent)
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{50, 100}, percents)
+		assert.Equal(t, "products.ndjson", result.Name)
+		assert.Equal(t, "application/x-ndjson", result.ContentType)
+		assert.Len(t, strings.Split(strings.TrimSpace(string(result.Data)), "\n"), 2)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidCriteria", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		newExportContext("GET", "/path", "", mockRepo, nil)
+
+		params := exportParams{Criteria: search.Criteria{StartQuantity: 10, EndQuantity: 1}, Format: spreadsheet.CSV_FORMAT}
+		j, err := jobs.New(1, jobs.EXPORT_PRODUCTS_KIND, params, nil, time.Now())
+		require.NoError(t, err)
+		_, err = ExportProducts{}.Run(context.Background(), j, func(percent int) {})
+
+		// Retrying the job would not fix its criteria.
+		assert.True(t, jobs.IsPermanent(err))
+		mockRepo.AssertNotCalled(t, "Export", mock.Anything, mock.Anything)
+	})
+}
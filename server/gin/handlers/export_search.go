@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// ExportSearch represents an export handler of the products matching a search.
+type ExportSearch struct{}
+
+// Do exports every product matching the search parameters, read like the ones of the regular search.
+// The pagination parameters are ignored, and the format is negotiated by exportProducts.
+func (es ExportSearch) Do(c *gin.Context) {
+	// Read the search parameters from the request
+	srch, criteria, ok := Search{}.readSearch(c)
+	if !ok {
+		return
+	}
+
+	// Export every product matching the search
+	exportProducts(c, srch, exportParams{Criteria: criteria, ScopeID: srch.ClientID, Sort: c.Query("sort")})
+}
+
+// ExportFilterSearch represents an export handler of the products matching a JSON filter tree.
+type ExportFilterSearch struct{}
+
+// Do exports every product matching the filter tree of the request body, read like the one of the structured search.
+// The pagination parameters are ignored, and the format is negotiated by exportProducts.
+func (efs ExportFilterSearch) Do(c *gin.Context) {
+	// Read the filtered search from the request
+	srch, criteria, ok := FilterSearch{}.readSearch(c)
+	if !ok {
+		return
+	}
+
+	// Export every product matching the filter tree
+	exportProducts(c, srch, exportParams{Criteria: criteria, ScopeID: srch.ClientID, Sort: c.Query("sort")})
+}
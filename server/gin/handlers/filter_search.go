@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// FilterSearch represents a structured search handler for products, taking a JSON filter tree.
+type FilterSearch struct{}
+
+// filterSearchRequest represents the body of a structured search.
+type filterSearchRequest struct {
+	Filter *search.Filter `json:"filter"` // Filter tree the products must meet, every product if nil.
+	Query  string         `json:"q"`      // Full-text query the products must also match, if any.
+}
+
+// Do performs the product search based on the filter tree of the request body.
+// The page of the results and its facets are read from the query string, like for the regular search.
+func (fs FilterSearch) Do(c *gin.Context) {
+	// Read the filtered search from the request
+	srch, _, ok := fs.readSearch(c)
+	if !ok {
+		return
+	}
+
+	// Read the aggregations requested alongside the products
+	facets, ok := readFacets(c)
+	if !ok {
+		return
+	}
+
+	// Respond with the page of products matching the filter tree
+	Search{}.respond(c, srch, facets)
+}
+
+// readSearch reads the filtered search of the request from its body and query parameters.
+// It returns the search along with the criteria it was created from, which can be stored to run it again.
+func (fs FilterSearch) readSearch(c *gin.Context) (srch search.Search, criteria search.Criteria, ok bool) {
+	// Read the filter tree from the request body
+	var req filterSearchRequest
+	ok = readRequestData(c, &req)
+	if !ok {
+		return
+	}
+
+	// Read the client ID the search is restricted to
+	clientID, ok := readClientScope(c)
+	if !ok {
+		return
+	}
+
+	// Read the page of results to retrieve
+	pagination, ok := readPagination(c, search.NewProductSort, true)
+	if !ok {
+		return
+	}
+
+	// Read the full-text query matched alongside the filter tree
+	query, ok := readQuery(c, req.Query)
+	if !ok {
+		return
+	}
+
+	// The criteria keep the filter tree as it was decoded, as they are validated again when they are run
+	criteria = search.Criteria{Filter: req.Filter, Query: req.Query}
+
+	srch.ClientID = clientID
+	srch.Pagination = pagination
+	srch.Query = query
+	if req.Filter == nil {
+		return
+	}
+
+	// Validate the filter tree, pointing at the failing node if it is invalid
+	filter, err := search.NewFilter(*req.Filter)
+	if err != nil {
+		err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		handleError(c, err)
+		ok = false
+		return
+	}
+	srch.Filter = &filter
+	return
+}
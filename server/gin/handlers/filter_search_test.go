@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/search"
+	sErrors "github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFilterSearch_Do(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockProducts := []*product.Product{{ID: 3, ClientID: 1, Port: newInt(1)}}
+
+		// The filter tree is validated before being passed to the repository, with the page of the query string
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Search", search.Search{
+			ClientID: 1,
+			Filter: &search.Filter{Or: []search.Filter{
+				{Field: "port", Op: search.IN_OPERATOR, Value: []interface{}{1, 2}},
+				{Not: &search.Filter{Field: "type", Op: search.EQ_OPERATOR, Value: "box"}},
+			}},
+			Pagination: search.Pagination{Limit: 10},
+		}).Return(mockProducts, 1, nil)
+
+		body := `{"filter": {"or": [{"field": "port", "op": "in", "value": [1, 2]}, {"not": {"field": "type", "op": "eq", "value": "box"}}]}}`
+		req, _ := http.NewRequest("POST", "/path?page_size=10", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Set("id", 1)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		FilterSearch{}.Do(c)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get("X-Total-Count"))
+
+		var page productPage
+		err := json.Unmarshal(rec.Body.Bytes(), &page)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{3}, []int{page.Products[0].ID})
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidNode", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+
+		body := `{"filter": {"and": [{"field": "port", "op": "eq", "value": 1}, {"field": "vehicle_plate", "op": "eq", "value": "123-ABC"}]}}`
+		req, _ := http.NewRequest("POST", "/path", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Set("id", 1)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		FilterSearch{}.Do(c)
+
+		// The error points at the failing leaf of the tree
+		assert.NotEmpty(t, c.Errors)
+		httpErr, ok := c.Errors[0].Err.(sErrors.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+		assert.Contains(t, httpErr.Message, "invalid filter at filter.and[1]: invalid vehicle plate")
+		mockRepo.AssertNotCalled(t, "Search", mock.Anything)
+	})
+
+	t.Run("MalformedBody", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/path", strings.NewReader(`{"filter": [`))
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		Init(database.Database{}, config.ConfigInfo{})
+		FilterSearch{}.Do(c)
+
+		assert.NotEmpty(t, c.Errors)
+		httpErr, ok := c.Errors[0].Err.(sErrors.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+}
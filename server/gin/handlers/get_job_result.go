@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJobResult is a struct representing the action of downloading the result of a background job.
+type GetJobResult struct{}
+
+// Do is a method of the GetJobResult struct that sends the result of a succeeded job as an attachment.
+// A job that did not succeed, or produced no result, is not found.
+func (gr GetJobResult) Do(c *gin.Context) {
+	// Read the job ID from the request, and the client ID the request is restricted to.
+	id, clientID, ok := readJobID(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the job repository.
+	repo, ok := getJobRepository(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the result of the job from the database.
+	r, err := repo.GetResult(c.Request.Context(), id, clientID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	// Send the result as a file named like the job made it.
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", r.Name))
+	c.Data(http.StatusOK, r.ContentType, r.Data)
+}
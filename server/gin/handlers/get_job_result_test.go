@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/jobs"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetJobResult_Do(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockJobRepository)
+		mockRepo.On("GetResult", 3, 1).Return(jobs.Result{Name: "products.csv", ContentType: "text/csv", Data: []byte("id\n1\n")}, nil)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/path/3/result", nil)
+		newJobRouter(mockRepo, 1, auth.CLIENT_ROLE).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "attachment; filename=products.csv", rec.Header().Get("Content-Disposition"))
+		assert.Equal(t, "id\n1\n", rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockRepo := new(MockJobRepository)
+		mockRepo.On("GetResult", 3, 1).Return(jobs.Result{}, errors.New("not found"))
+		newJobRouter(mockRepo, 1, auth.CLIENT_ROLE)
+
+		req, _ := http.NewRequest("GET", "/path", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Params = gin.Params{{Key: "id", Value: "3"}}
+		c.Set("id", 1)
+		GetJobResult{}.Do(c)
+
+		if assert.NotEmpty(t, c.Errors) {
+			assert.Contains(t, c.Errors[0].Error(), "not found")
+		}
+		assert.Empty(t, rec.Header().Get("Content-Disposition"))
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/coffemanfp/docucentertest/client"
 	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/server/errors"
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,6 +20,12 @@ func (gc GetClient) Do(c *gin.Context) {
 		return
 	}
 
+	// Check that the authenticated client is allowed to see the requested client.
+	ok = gc.checkAccess(c, id)
+	if !ok {
+		return
+	}
+
 	// Get the client repository using the getClientRepository method.
 	repo, ok := getClientRepository(c)
 	if !ok {
@@ -42,11 +49,24 @@ func (gc GetClient) readClientID(c *gin.Context) (id int, ok bool) {
 	return readIntFromURL(c, "id", false)
 }
 
+// checkAccess is a method of the GetClient struct that checks if the authenticated client can see the requested client.
+// Privileged roles can see every client, while clients can only see themselves.
+// It returns a boolean indicating if the access is allowed, handling a forbidden error otherwise.
+func (gc GetClient) checkAccess(c *gin.Context, id int) (ok bool) {
+	if !getRole(c).IsPrivileged() && id != c.GetInt("id") {
+		err := errors.NewHTTPError(http.StatusForbidden, errors.FORBIDDEN_ERROR_MESSAGE)
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
 // getClientFromDB is a method of the GetClient struct that retrieves a client from the database.
 // It returns the retrieved client and a boolean indicating if the operation was successful.
 func (gc GetClient) getClientFromDB(c *gin.Context, repo database.ClientRepository, id int) (cl client.Client, ok bool) {
 	// Retrieve the client using the GetOne method of the client repository.
-	cl, err := repo.GetOne(id)
+	cl, err := repo.GetOne(c.Request.Context(), id)
 	if err != nil {
 		// If an error occurs, handle it and return false.
 		handleError(c, err)
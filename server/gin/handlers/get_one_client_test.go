@@ -40,6 +40,8 @@ func TestGetClient_Do(t *testing.T) {
 		c, _ := gin.CreateTestContext(rec)
 		c.Request = req
 		c.Params = []gin.Param{{Key: "id", Value: strconv.Itoa(mockClient.ID)}}
+		c.Set("id", 2)
+		c.Set("role", auth.OPERATOR_ROLE)
 
 		db := database.Database{
 			Repositories: map[database.RepositoryID]interface{}{
@@ -47,7 +49,7 @@ func TestGetClient_Do(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		// Set up the handler and execute the action
 		gc := GetClient{}
 		gc.Do(c)
@@ -74,6 +76,8 @@ func TestGetClient_Do(t *testing.T) {
 		c, _ := gin.CreateTestContext(rec)
 		c.Request = req
 		c.Params = []gin.Param{{Key: "id", Value: "1"}}
+		c.Set("id", 1)
+		c.Set("role", auth.CLIENT_ROLE)
 
 		db := database.Database{
 			Repositories: map[database.RepositoryID]interface{}{
@@ -81,7 +85,7 @@ func TestGetClient_Do(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		// Set up the handler and execute the action
 		gc := GetClient{}
 		gc.Do(c)
@@ -90,4 +94,31 @@ func TestGetClient_Do(t *testing.T) {
 		assert.NotEmpty(t, c.Errors)
 		assert.Contains(t, c.Errors[0].Error(), "not found")
 	})
+	t.Run("Forbidden", func(t *testing.T) {
+		mockRepo := new(MockClientRepository)
+
+		// Create a mock context requesting another client
+		req, _ := http.NewRequest("GET", "/path/1", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Params = []gin.Param{{Key: "id", Value: "1"}}
+		c.Set("id", 2)
+		c.Set("role", auth.CLIENT_ROLE)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.CLIENT_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		// Set up the handler and execute the action
+		gc := GetClient{}
+		gc.Do(c)
+
+		assert.Empty(t, rec.Body)
+		assert.NotEmpty(t, c.Errors)
+		mockRepo.AssertNotCalled(t, "GetOne", mock.Anything)
+	})
 }
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJob is a struct representing the action of getting a background job.
+type GetJob struct{}
+
+// Do is a method of the GetJob struct that retrieves a job and sends it as a JSON response.
+// It reports the status and progress of the job, and the URL its result can be downloaded from once it succeeded.
+func (gj GetJob) Do(c *gin.Context) {
+	// Read the job ID from the request, and the client ID the request is restricted to.
+	id, clientID, ok := readJobID(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the job repository.
+	repo, ok := getJobRepository(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the job from the database.
+	j, err := repo.GetOne(c.Request.Context(), id, clientID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	// Return the job as JSON response.
+	c.JSON(http.StatusOK, newJobResponse(j))
+}
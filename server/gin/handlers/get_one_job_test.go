@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/jobs"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newJobRouter creates a router of the job routes for a client with the given role, on the given repository.
+func newJobRouter(mockRepo *MockJobRepository, id int, role auth.Role) *gin.Engine {
+	db := database.Database{
+		Repositories: map[database.RepositoryID]interface{}{
+			database.JOB_REPOSITORY: mockRepo,
+		},
+	}
+	Init(db, config.ConfigInfo{})
+	r := gin.New()
+	r.GET("/path/:id", setClient(id, role), GetJob{}.Do)
+	r.GET("/path/:id/result", setClient(id, role), GetJobResult{}.Do)
+	return r
+}
+
+func TestGetJob_Do(t *testing.T) {
+	t.Run("Succeeded", func(t *testing.T) {
+		finishedAt := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+		mockJob := jobs.Job{
+			ID:          3,
+			ClientID:    1,
+			Kind:        jobs.EXPORT_PRODUCTS_KIND,
+			Status:      jobs.SUCCEEDED_STATUS,
+			Progress:    100,
+			Attempts:    1,
+			MaxAttempts: jobs.MAX_ATTEMPTS,
+			ResultName:  "products.csv",
+			ResultType:  "text/csv",
+			FinishedAt:  &finishedAt,
+		}
+		mockRepo := new(MockJobRepository)
+		mockRepo.On("GetOne", 3, 1).Return(mockJob, nil)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/path/3", nil)
+		newJobRouter(mockRepo, 1, auth.CLIENT_ROLE).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var res jobResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Equal(t, "/v1/jobs/3/result", res.ResultURL)
+		assert.Equal(t, jobs.SUCCEEDED_STATUS, res.Status)
+		assert.Equal(t, 100, res.Progress)
+		assert.Equal(t, "products.csv", res.ResultName)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Running", func(t *testing.T) {
+		mockRepo := new(MockJobRepository)
+		mockRepo.On("GetOne", 3, database.ANY_CLIENT).Return(jobs.Job{ID: 3, ClientID: 2, Status: jobs.RUNNING_STATUS, Progress: 40}, nil)
+
+		// Privileged roles see the jobs of every client.
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/path/3", nil)
+		newJobRouter(mockRepo, 1, auth.ADMIN_ROLE).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var res map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Equal(t, float64(40), res["progress"])
+		assert.NotContains(t, res, "result_url")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockRepo := new(MockJobRepository)
+		mockRepo.On("GetOne", 3, 1).Return(jobs.Job{}, errors.New("not found"))
+		newJobRouter(mockRepo, 1, auth.CLIENT_ROLE)
+
+		req, _ := http.NewRequest("GET", "/path", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Params = gin.Params{{Key: "id", Value: "3"}}
+		c.Set("id", 1)
+		GetJob{}.Do(c)
+
+		if assert.NotEmpty(t, c.Errors) {
+			assert.Contains(t, c.Errors[0].Error(), "not found")
+		}
+	})
+}
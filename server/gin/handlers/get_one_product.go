@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/coffemanfp/docucentertest/database"
 	"github.com/coffemanfp/docucentertest/product"
@@ -25,14 +26,32 @@ func (gp GetProduct) Do(c *gin.Context) {
 		return
 	}
 
+	// Read the client ID the request is restricted to.
+	clientID, ok := readClientScope(c)
+	if !ok {
+		return
+	}
+
 	// Retrieve the product from the database using the getProductFromDB method.
-	p, ok := gp.getProductFromDB(c, id, repo)
+	p, ok := gp.getProductFromDB(c, id, clientID, repo)
+	if !ok {
+		return
+	}
+
+	// Build the pricing rules engine.
+	engine, ok := getPricingEngine(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the price locked by the quote of the product.
+	locked, ok := getLockedPricings(c, []*product.Product{&p})
 	if !ok {
 		return
 	}
 
 	// Generate a discount for the product using the generateDiscount method.
-	p = gp.generateDiscount(p)
+	p = gp.generateDiscount(engine, locked, p)
 
 	// Return the product as JSON response.
 	c.JSON(http.StatusOK, p)
@@ -44,9 +63,9 @@ func (gp GetProduct) readProductID(c *gin.Context) (id int, ok bool) {
 }
 
 // getProductFromDB is a method of the GetProduct struct that retrieves a product from the database.
-func (gp GetProduct) getProductFromDB(c *gin.Context, id int, repo database.ProductRepository) (p product.Product, ok bool) {
+func (gp GetProduct) getProductFromDB(c *gin.Context, id, clientID int, repo database.ProductRepository) (p product.Product, ok bool) {
 	// Retrieve the product from the database using the product repository.
-	p, err := repo.GetOne(id, c.GetInt("id"))
+	p, err := repo.GetOne(c.Request.Context(), id, clientID)
 	if err != nil {
 		// Handle any error and abort the request.
 		handleError(c, err)
@@ -57,19 +76,14 @@ func (gp GetProduct) getProductFromDB(c *gin.Context, id int, repo database.Prod
 }
 
 // generateDiscount is a method of the GetProduct struct that calculates and adds a discount to the product.
-func (gp GetProduct) generateDiscount(pr product.Product) (p product.Product) {
+func (gp GetProduct) generateDiscount(engine product.RulesEngine, locked map[int]product.Pricing, pr product.Product) (p product.Product) {
 	// Clone the product to avoid modifying the original object.
 	p = pr
-	var vault, port int
-	if p.Vault != nil {
-		vault = *p.Vault
-	}
-	if p.Port != nil {
-		port = *p.Port
-	}
-	// Create a discount generator based on the product's attributes.
-	discountGenerator := product.NewDiscountGenerator(vault, port, *p.Quantity, *p.ShippingPrice)
-	// Generate the discount using the discount generator.
-	p.Discount = discountGenerator.Generate()
+	// Create a discount generator based on the pricing rules, or the quote, and the product's attributes.
+	discountGenerator := newDiscountGenerator(engine, locked, p, time.Now())
+	// Generate the itemised price and discount using the discount generator.
+	pricing := discountGenerator.Pricing()
+	p.Discount = pricing.Discount
+	p.Pricing = &pricing
 	return
 }
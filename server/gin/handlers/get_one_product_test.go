@@ -45,10 +45,11 @@ func TestGetProduct_Do(t *testing.T) {
 		db := database.Database{
 			Repositories: map[database.RepositoryID]interface{}{
 				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		// Set up the handler and execute the action
 		gc := GetProduct{}
 		gc.Do(c)
@@ -65,6 +66,12 @@ func TestGetProduct_Do(t *testing.T) {
 		if assert.NotEmpty(t, responseProduct.Discount) {
 			mockProduct.Discount = responseProduct.Discount
 		}
+		if assert.NotNil(t, responseProduct.Pricing) {
+			assert.Len(t, responseProduct.Pricing.Lines, 1)
+			assert.Equal(t, "bulk_vault", responseProduct.Pricing.Lines[0].Rule)
+			assert.Equal(t, responseProduct.Discount, responseProduct.Pricing.Discount)
+			mockProduct.Pricing = responseProduct.Pricing
+		}
 		assert.Equal(t, mockProduct, responseProduct)
 	})
 
@@ -82,10 +89,11 @@ func TestGetProduct_Do(t *testing.T) {
 		db := database.Database{
 			Repositories: map[database.RepositoryID]interface{}{
 				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		// Set up the handler and execute the action
 		gc := GetProduct{}
 		gc.Do(c)
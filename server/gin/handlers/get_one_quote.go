@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/quote"
+	"github.com/gin-gonic/gin"
+)
+
+// GetQuote is a struct representing the action of getting an issued quote.
+type GetQuote struct{}
+
+// Do is a method of the GetQuote struct that retrieves a quote and sends it as a JSON response.
+func (gq GetQuote) Do(c *gin.Context) {
+	// Read the quote ID from the request.
+	id, ok := gq.readQuoteID(c)
+	if !ok {
+		return
+	}
+
+	// Read the client ID the request is restricted to.
+	clientID, ok := readClientScope(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the quote repository.
+	repo, ok := getQuoteRepository(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the quote from the database.
+	q, ok := gq.getQuoteFromDB(c, repo, id, clientID)
+	if !ok {
+		return
+	}
+
+	// Return the quote as JSON response.
+	c.JSON(http.StatusOK, q)
+}
+
+// readQuoteID is a method of the GetQuote struct that reads and returns the quote ID from the URL parameter.
+func (gq GetQuote) readQuoteID(c *gin.Context) (id int, ok bool) {
+	return readIntFromURL(c, "id", false)
+}
+
+// getQuoteFromDB is a method of the GetQuote struct that retrieves a quote from the database.
+func (gq GetQuote) getQuoteFromDB(c *gin.Context, repo database.QuoteRepository, id, clientID int) (q quote.Quote, ok bool) {
+	q, err := repo.GetOne(c.Request.Context(), id, clientID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	dbErrors "github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/coffemanfp/docucentertest/quote"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetQuote_Do(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockQuoteRepo := new(MockQuoteRepository)
+		mockQuoteRepo.On("GetOne", 4, 1).Return(quote.Quote{ID: 4, ClientID: 1, ShippingPrice: 100, Total: 104.5}, nil)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.QUOTE_REPOSITORY: mockQuoteRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		r := gin.New()
+		r.GET("/path/:id", setClient(1, auth.CLIENT_ROLE), GetQuote{}.Do)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/path/4", nil)
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var responseQuote quote.Quote
+		err := json.Unmarshal(rec.Body.Bytes(), &responseQuote)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, responseQuote.ID)
+		assert.Equal(t, 104.5, responseQuote.Total)
+		mockQuoteRepo.AssertExpectations(t)
+	})
+
+	t.Run("OtherClient", func(t *testing.T) {
+		// The quotes of other clients are not found for a client.
+		mockQuoteRepo := new(MockQuoteRepository)
+		mockQuoteRepo.On("GetOne", 4, 1).Return(quote.Quote{}, dbErrors.NewError(dbErrors.NOT_FOUND, "failed to get a row of quote table", "not found"))
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.QUOTE_REPOSITORY: mockQuoteRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		req, _ := http.NewRequest("GET", "/path/4", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Params = gin.Params{{Key: "id", Value: "4"}}
+		c.Set("id", 1)
+		c.Set("role", auth.CLIENT_ROLE)
+		GetQuote{}.Do(c)
+
+		assertNotFound(t, c)
+		assert.Empty(t, rec.Body)
+		mockQuoteRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/gin-gonic/gin"
+)
+
+// GetSavedSearch is a struct representing the action of getting a saved search.
+type GetSavedSearch struct{}
+
+// Do is a method of the GetSavedSearch struct that retrieves a saved search and sends it as a JSON response.
+func (gs GetSavedSearch) Do(c *gin.Context) {
+	// Read the saved search ID from the request, and the client ID the request is restricted to.
+	id, clientID, ok := readSavedSearchID(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the saved search repository.
+	repo, ok := getSavedSearchRepository(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the saved search from the database.
+	s, ok := getSavedSearchFromDB(c, repo, id, clientID)
+	if !ok {
+		return
+	}
+
+	// Return the saved search as JSON response.
+	c.JSON(http.StatusOK, s)
+}
+
+// readSavedSearchID reads the saved search ID from the URL parameter, and the client ID the request is restricted to.
+func readSavedSearchID(c *gin.Context) (id, clientID int, ok bool) {
+	id, ok = readIntFromURL(c, "id", false)
+	if !ok {
+		return
+	}
+	clientID, ok = readClientScope(c)
+	return
+}
+
+// getSavedSearchFromDB retrieves a saved search of the client from the database.
+func getSavedSearchFromDB(c *gin.Context, repo database.SavedSearchRepository, id, clientID int) (s savedsearch.SavedSearch, ok bool) {
+	s, err := repo.GetOne(c.Request.Context(), id, clientID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
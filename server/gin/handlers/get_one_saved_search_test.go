@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	dbErrors "github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSavedSearchContext creates the context of a saved search request of client 1 with the given role,
+// on the given repository, with the saved search ID of the URL parameter.
+func newSavedSearchContext(method, target, body string, mockRepo *MockSavedSearchRepository, role auth.Role) (c *gin.Context, rec *httptest.ResponseRecorder) {
+	db := database.Database{
+		Repositories: map[database.RepositoryID]interface{}{
+			database.SAVED_SEARCH_REPOSITORY: mockRepo,
+		},
+	}
+	Init(db, config.ConfigInfo{})
+
+	req, _ := http.NewRequest(method, target, strings.NewReader(body))
+	rec = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(rec)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+	c.Set("id", 1)
+	c.Set("role", role)
+	return
+}
+
+// assertNotFound asserts that the request was aborted with a NOT_FOUND error of the database.
+func assertNotFound(t *testing.T, c *gin.Context) {
+	t.Helper()
+	if assert.NotEmpty(t, c.Errors) {
+		dbErr, ok := c.Errors[0].Err.(dbErrors.Error)
+		if assert.True(t, ok) {
+			assert.Equal(t, dbErrors.NOT_FOUND, dbErr.Type)
+		}
+	}
+}
+
+// errSavedSearchNotFound is the error of the repository for the saved searches of other clients.
+var errSavedSearchNotFound = dbErrors.NewError(dbErrors.NOT_FOUND, "failed to get a row of saved_search table", "not found")
+
+func TestGetSavedSearch_Do(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		saved := savedsearch.SavedSearch{ID: 3, ClientID: 1, ScopeID: 1, Name: "Boxes", Criteria: search.Criteria{Type: "box"}}
+		mockRepo := new(MockSavedSearchRepository)
+		mockRepo.On("GetOne", 3, 1).Return(saved, nil)
+
+		c, rec := newSavedSearchContext("GET", "/path/3", "", mockRepo, auth.CLIENT_ROLE)
+		GetSavedSearch{}.Do(c)
+
+		assert.Empty(t, c.Errors)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var s savedsearch.SavedSearch
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &s))
+		assert.Equal(t, saved, s)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("OtherClient", func(t *testing.T) {
+		// The client ID scope of the request is ignored for clients, so the saved searches of others are not found.
+		mockRepo := new(MockSavedSearchRepository)
+		mockRepo.On("GetOne", 3, 1).Return(savedsearch.SavedSearch{}, errSavedSearchNotFound)
+
+		c, _ := newSavedSearchContext("GET", "/path/3?client_id=2", "", mockRepo, auth.CLIENT_ROLE)
+		GetSavedSearch{}.Do(c)
+
+		assertNotFound(t, c)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AdminScope", func(t *testing.T) {
+		mockRepo := new(MockSavedSearchRepository)
+		mockRepo.On("GetOne", 3, 2).Return(savedsearch.SavedSearch{ID: 3, ClientID: 2}, nil)
+		mockRepo.On("GetOne", 3, database.ANY_CLIENT).Return(savedsearch.SavedSearch{ID: 3, ClientID: 2}, nil)
+
+		// Admins get the saved searches of the client of the scope, or of any client without one.
+		for _, target := range []string{"/path/3?client_id=2", "/path/3"} {
+			c, rec := newSavedSearchContext("GET", target, "", mockRepo, auth.ADMIN_ROLE)
+			GetSavedSearch{}.Do(c)
+
+			assert.Empty(t, c.Errors, target)
+			assert.Equal(t, http.StatusOK, rec.Code, target)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/tracking"
+	"github.com/gin-gonic/gin"
+)
+
+// GetProductEvents represents the action of getting the tracking timeline of a product.
+type GetProductEvents struct{}
+
+// Do is a method of the GetProductEvents struct that retrieves the tracking events of a product
+// and sends them as a JSON response, ordered by the time they were recorded.
+func (gpe GetProductEvents) Do(c *gin.Context) {
+	// Read the product ID from the request.
+	id, ok := gpe.readProductID(c)
+	if !ok {
+		return
+	}
+
+	// Read the client ID the request is restricted to.
+	clientID, ok := readClientScope(c)
+	if !ok {
+		return
+	}
+
+	// Get the tracking repository.
+	repo, ok := getTrackingRepository(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the tracking events from the database.
+	es, ok := gpe.getFromDB(c, repo, id, clientID)
+	if !ok {
+		return
+	}
+
+	// Return the tracking events as JSON response.
+	c.JSON(http.StatusOK, es)
+}
+
+// readProductID is a method of the GetProductEvents struct that reads the product ID from the URL parameter.
+func (gpe GetProductEvents) readProductID(c *gin.Context) (id int, ok bool) {
+	return readIntFromURL(c, "id", false)
+}
+
+// getFromDB is a method of the GetProductEvents struct that retrieves the tracking events of a product from the database.
+func (gpe GetProductEvents) getFromDB(c *gin.Context, repo database.TrackingRepository, id, clientID int) (es []*tracking.Event, ok bool) {
+	es, err := repo.Get(c.Request.Context(), id, clientID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/tracking"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetProductEvents_Do(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Create mock events with sample data
+		mockEvents := []*tracking.Event{
+			{
+				ID:         1,
+				ProductID:  3,
+				RecordedAt: time.Now().Add(-time.Hour).UTC(),
+				Port:       newInt(2),
+				RecordedBy: 1,
+			},
+			{
+				ID:           2,
+				ProductID:    3,
+				RecordedAt:   time.Now().UTC(),
+				Vault:        newInt(4),
+				VehiclePlate: newString("ABC-123"),
+				Note:         "Stored",
+				RecordedBy:   1,
+			},
+		}
+
+		mockRepo := new(MockTrackingRepository)
+		mockRepo.On("Get", 3, 1).Return(mockEvents, nil)
+
+		req, _ := http.NewRequest("GET", "/path/3/events", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Params = []gin.Param{{Key: "id", Value: "3"}}
+		c.Set("id", 1)
+		c.Set("role", auth.CLIENT_ROLE)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.TRACKING_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		GetProductEvents{}.Do(c)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var responseEvents []*tracking.Event
+		err := json.Unmarshal(rec.Body.Bytes(), &responseEvents)
+		assert.NoError(t, err)
+		assert.Equal(t, mockEvents, responseEvents)
+	})
+
+	t.Run("NotOwned", func(t *testing.T) {
+		mockRepo := new(MockTrackingRepository)
+		mockRepo.On("Get", 3, 1).Return([]*tracking.Event(nil), errors.New("not found"))
+
+		req, _ := http.NewRequest("GET", "/path/3/events", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Params = []gin.Param{{Key: "id", Value: "3"}}
+		c.Set("id", 1)
+		c.Set("role", auth.CLIENT_ROLE)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.TRACKING_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		GetProductEvents{}.Do(c)
+
+		assert.Empty(t, rec.Body)
+		assert.NotEmpty(t, c.Errors)
+		assert.Contains(t, c.Errors[0].Error(), "not found")
+	})
+}
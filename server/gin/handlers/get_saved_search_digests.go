@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/gin-gonic/gin"
+)
+
+// GetSavedSearchDigests is a struct representing the action to retrieve the digests of the runs of a saved search.
+type GetSavedSearchDigests struct{}
+
+// Do is the method of the GetSavedSearchDigests struct that performs the action.
+// The digests are listed from the latest run.
+func (gd GetSavedSearchDigests) Do(c *gin.Context) {
+	// Read the saved search ID from the request, and the client ID the request is restricted to.
+	id, clientID, ok := readSavedSearchID(c)
+	if !ok {
+		return
+	}
+
+	// Read the pagination parameters from the URL, the digests are always ordered by run.
+	pagination, ok := readPagination(c, noSort, false)
+	if !ok {
+		return
+	}
+
+	// Get the saved search repository.
+	repo, ok := getSavedSearchRepository(c)
+	if !ok {
+		return
+	}
+
+	// Check the saved search exists and belongs to the client, so a missing one is not an empty list.
+	_, ok = getSavedSearchFromDB(c, repo, id, clientID)
+	if !ok {
+		return
+	}
+
+	// Retrieve the page of digests using the repository, and the total number of digests.
+	ds, total, ok := gd.get(c, repo, id, pagination, clientID)
+	if !ok {
+		return
+	}
+
+	// Return the list of digests as a JSON response, describing its page in the headers.
+	writePagination(c, pagination, total)
+	c.JSON(http.StatusOK, ds)
+}
+
+// get is a method of the GetSavedSearchDigests struct that retrieves a page of digests of a saved search from the database.
+func (gd GetSavedSearchDigests) get(c *gin.Context, repo database.SavedSearchRepository, id int, pagination search.Pagination, clientID int) (ds []savedsearch.Digest, total int, ok bool) {
+	ds, total, err := repo.GetDigests(c.Request.Context(), id, pagination, clientID)
+	if err != nil {
+		// If there's an error, handle it and set ok to false.
+		handleError(c, err)
+		return
+	}
+	// If successful, set ok to true.
+	ok = true
+	return
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetSavedSearchDigests_Do(t *testing.T) {
+	newRouter := func(repo *MockSavedSearchRepository) *gin.Engine {
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.SAVED_SEARCH_REPOSITORY: repo,
+			},
+		}
+		Init(db, config.ConfigInfo{})
+		r := gin.New()
+		r.GET("/path/:id", setClient(1, auth.CLIENT_ROLE), GetSavedSearchDigests{}.Do)
+		return r
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		ranAt := time.Date(2023, time.March, 1, 8, 0, 0, 0, time.UTC)
+		digests := []savedsearch.Digest{
+			{ID: 5, SavedSearchID: 2, ClientID: 1, Run: 2, ProductIDs: []int{9}, Total: 1, RanAt: ranAt.AddDate(0, 0, 1)},
+			{ID: 4, SavedSearchID: 2, ClientID: 1, Run: 1, ProductIDs: []int{3, 7}, Total: 2, RanAt: ranAt},
+		}
+		mockRepo := new(MockSavedSearchRepository)
+		mockRepo.On("GetOne", 2, 1).Return(savedsearch.SavedSearch{ID: 2, ClientID: 1}, nil)
+		mockRepo.On("GetDigests", 2, search.Pagination{Limit: search.DEFAULT_PAGE_SIZE}, 1).Return(digests, 2, nil)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/path/2", nil)
+		newRouter(mockRepo).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var got []savedsearch.Digest
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.Equal(t, digests, got)
+		assert.Equal(t, "2", rec.Header().Get("X-Total-Count"))
+	})
+
+	t.Run("SortNotAllowed", func(t *testing.T) {
+		mockRepo := new(MockSavedSearchRepository)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/path/2?sort=run", nil)
+		newRouter(mockRepo).ServeHTTP(rec, req)
+
+		mockRepo.AssertNotCalled(t, "GetDigests", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
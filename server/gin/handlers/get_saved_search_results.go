@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// GetSavedSearchResults represents the action of running a saved search.
+type GetSavedSearchResults struct{}
+
+// Do runs the criteria of a saved search and responds with every matching product, like the regular search.
+// The page of the results and its facets are read from the query string.
+func (gr GetSavedSearchResults) Do(c *gin.Context) {
+	// Read the saved search ID from the request, and the client ID the request is restricted to
+	id, clientID, ok := readSavedSearchID(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the saved search repository
+	repo, ok := getSavedSearchRepository(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the saved search from the database
+	s, ok := getSavedSearchFromDB(c, repo, id, clientID)
+	if !ok {
+		return
+	}
+
+	// Read the page of results to retrieve
+	pagination, ok := readPagination(c, search.NewProductSort, true)
+	if !ok {
+		return
+	}
+
+	// Read the aggregations requested alongside the products
+	facets, ok := readFacets(c)
+	if !ok {
+		return
+	}
+
+	// Create the search of the saved criteria
+	srch, err := s.Results()
+	if err != nil {
+		err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		handleError(c, err)
+		return
+	}
+	srch.Pagination = pagination
+
+	// Respond with the page of products matching the saved search
+	Search{}.respond(c, srch, facets)
+}
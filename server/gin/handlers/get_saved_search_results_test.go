@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetSavedSearchResults_Do(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		saved := savedsearch.SavedSearch{
+			ID:            2,
+			ClientID:      1,
+			ScopeID:       1,
+			Name:          "Boxes",
+			Criteria:      search.Criteria{Type: "box", StartQuantity: 5, EndQuantity: 10},
+			LastProductID: 7,
+		}
+		mockSavedRepo := new(MockSavedSearchRepository)
+		mockSavedRepo.On("GetOne", 2, 1).Return(saved, nil)
+
+		// Every match of the saved criteria is retrieved, not only the new ones
+		mockProducts := []*product.Product{{ID: 3, ClientID: 1, Type: newString("box"), Quantity: newInt(6)}}
+		mockProductRepo := new(MockProductRepository)
+		mockProductRepo.On("Search", mock.MatchedBy(func(srch search.Search) bool {
+			return srch.ClientID == 1 && srch.Type.Values[0] == "box" && srch.QuantityRange.Start == 5 &&
+				srch.AfterID == 0 && srch.Pagination.Limit == 2
+		})).Return(mockProducts, 1, nil)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.SAVED_SEARCH_REPOSITORY: mockSavedRepo,
+				database.PRODUCT_REPOSITORY:      mockProductRepo,
+				database.PRICING_REPOSITORY:      newMockPricingRepository(),
+			},
+		}
+		Init(db, config.ConfigInfo{})
+		r := gin.New()
+		r.GET("/path/:id", setClient(1, auth.CLIENT_ROLE), GetSavedSearchResults{}.Do)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/path/2?page_size=2", nil)
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var page productPage
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		assert.Len(t, page.Products, 1)
+		assert.Equal(t, "1", rec.Header().Get("X-Total-Count"))
+		mockProductRepo.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockSavedRepo := new(MockSavedSearchRepository)
+		mockSavedRepo.On("GetOne", 2, 1).Return(savedsearch.SavedSearch{}, errors.New("not found"))
+		mockProductRepo := new(MockProductRepository)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.SAVED_SEARCH_REPOSITORY: mockSavedRepo,
+				database.PRODUCT_REPOSITORY:      mockProductRepo,
+			},
+		}
+		Init(db, config.ConfigInfo{})
+
+		req, _ := http.NewRequest("GET", "/path", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Params = gin.Params{{Key: "id", Value: "2"}}
+		c.Set("id", 1)
+		GetSavedSearchResults{}.Do(c)
+
+		assert.NotEmpty(t, c.Errors)
+		assert.Contains(t, c.Errors[0].Error(), "not found")
+		mockProductRepo.AssertNotCalled(t, "Search", mock.Anything)
+	})
+}
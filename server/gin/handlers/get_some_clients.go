@@ -5,6 +5,7 @@ import (
 
 	"github.com/coffemanfp/docucentertest/client"
 	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/search"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,8 +14,8 @@ type GetSomeClients struct{}
 
 // Do is the method of the GetSomeClients struct that performs the action.
 func (gst GetSomeClients) Do(c *gin.Context) {
-	// Read the page parameter from the URL.
-	page, ok := readPagination(c)
+	// Read the pagination parameters from the URL.
+	pagination, ok := readPagination(c, search.NewClientSort, false)
 	if !ok {
 		return
 	}
@@ -25,22 +26,23 @@ func (gst GetSomeClients) Do(c *gin.Context) {
 		return
 	}
 
-	// Retrieve the list of clients using the repository and the specified page.
-	cs, ok := gst.get(c, repo, page)
+	// Retrieve the page of clients using the repository, and the total number of clients.
+	cs, total, ok := gst.get(c, repo, pagination)
 	if !ok {
 		return
 	}
 
-	// Return the list of clients as a JSON response.
+	// Return the list of clients as a JSON response, describing its page in the headers.
+	writePagination(c, pagination, total)
 	c.JSON(http.StatusOK, cs)
 }
 
 // get is a method of the GetSomeClients struct that retrieves a list of clients from the database.
-// It takes a gin.Context, a client repository, and a page number as parameters.
-// It returns a list of clients and a boolean indicating whether the operation was successful.
-func (gst GetSomeClients) get(c *gin.Context, repo database.ClientRepository, page int) (cs []*client.Client, ok bool) {
-	// Retrieve the list of clients from the repository using the specified page.
-	cs, err := repo.Get(page)
+// It takes a gin.Context, a client repository, and a pagination as parameters.
+// It returns a page of clients, the total number of clients and a boolean indicating whether the operation was successful.
+func (gst GetSomeClients) get(c *gin.Context, repo database.ClientRepository, pagination search.Pagination) (cs []*client.Client, total int, ok bool) {
+	// Retrieve the page of clients from the repository using the specified pagination.
+	cs, total, err := repo.Get(c.Request.Context(), pagination)
 	if err != nil {
 		// If there's an error, handle it and set ok to false.
 		handleError(c, err)
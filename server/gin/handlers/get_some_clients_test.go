@@ -13,6 +13,7 @@ import (
 	"github.com/coffemanfp/docucentertest/client"
 	"github.com/coffemanfp/docucentertest/config"
 	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/search"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -41,7 +42,7 @@ func TestGetSomeClients_Do(t *testing.T) {
 		}
 
 		mockRepo := new(MockClientRepository)
-		mockRepo.On("Get", mock.Anything).Return(mockClients, nil)
+		mockRepo.On("Get", mock.Anything).Return(mockClients, len(mockClients), nil)
 
 		// Create a mock context with a request parameter
 		req, _ := http.NewRequest("GET", "/path", nil)
@@ -55,7 +56,7 @@ func TestGetSomeClients_Do(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		// Set up the handler and execute the action
 		gc := GetSomeClients{}
 		gc.Do(c)
@@ -70,11 +71,17 @@ func TestGetSomeClients_Do(t *testing.T) {
 
 		// Compare the responseClient with the mockClient
 		assert.Equal(t, mockClients, responseClients)
+
+		// The default page is described in the headers
+		assert.Equal(t, "2", rec.Header().Get("X-Total-Count"))
+		assert.Equal(t, "1", rec.Header().Get("X-Page"))
+		assert.Equal(t, "20", rec.Header().Get("X-Page-Size"))
+		mockRepo.AssertCalled(t, "Get", search.Pagination{Limit: search.DEFAULT_PAGE_SIZE, Offset: 0})
 	})
 
 	t.Run("NotFound", func(t *testing.T) {
 		mockRepo := new(MockClientRepository)
-		mockRepo.On("Get", mock.Anything).Return([]*client.Client{}, errors.New("not found"))
+		mockRepo.On("Get", mock.Anything).Return([]*client.Client{}, 0, errors.New("not found"))
 
 		// Create a mock context with a request parameter
 		req, _ := http.NewRequest("GET", "/path", nil)
@@ -88,7 +95,7 @@ func TestGetSomeClients_Do(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		// Set up the handler and execute the action
 		gc := GetSomeClients{}
 		gc.Do(c)
@@ -97,4 +104,37 @@ func TestGetSomeClients_Do(t *testing.T) {
 		assert.NotEmpty(t, c.Errors)
 		assert.Contains(t, c.Errors[0].Error(), "not found")
 	})
+
+	t.Run("Sort", func(t *testing.T) {
+		sort := search.Sort{{Name: "surname"}, {Name: "created_at", Desc: true, Time: true}}
+		mockRepo := new(MockClientRepository)
+		mockRepo.On("Get", search.Pagination{Limit: search.DEFAULT_PAGE_SIZE, Sort: sort}).Return([]*client.Client{}, 0, nil)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.CLIENT_REPOSITORY: mockRepo,
+			},
+		}
+		Init(db, config.ConfigInfo{})
+
+		req, _ := http.NewRequest("GET", "/path?sort=surname,-created_at", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		gc := GetSomeClients{}
+		gc.Do(c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+
+		// Only the client fields can be sorted by
+		req, _ = http.NewRequest("GET", "/path?sort=shipping_price", nil)
+		rec = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(rec)
+		c.Request = req
+
+		gc.Do(c)
+		assert.NotEmpty(t, c.Errors)
+		assert.Contains(t, c.Errors[0].Error(), "shipping_price is not a sortable field")
+	})
 }
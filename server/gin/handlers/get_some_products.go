@@ -1,10 +1,11 @@
 package handlers
 
 import (
-	"net/http"
+	"time"
 
 	"github.com/coffemanfp/docucentertest/database"
 	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/search"
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,8 +15,14 @@ type GetSomeProducts struct{}
 // applies discounts to them, and sends the response in JSON format.
 // It takes a gin.Context as a parameter.
 func (gsp GetSomeProducts) Do(c *gin.Context) {
-	// Read the page number from the request URL.
-	page, ok := readPagination(c)
+	// Read the pagination from the request URL.
+	pagination, ok := readPagination(c, search.NewProductSort, true)
+	if !ok {
+		return
+	}
+
+	// Read the client ID the request is restricted to.
+	clientID, ok := readClientScope(c)
 	if !ok {
 		return
 	}
@@ -26,25 +33,37 @@ func (gsp GetSomeProducts) Do(c *gin.Context) {
 		return
 	}
 
-	// Retrieve the list of products from the database using the specified page.
-	ps, ok := gsp.getFromDB(c, repo, page)
+	// Retrieve the page of products from the database, and the total number of products.
+	ps, total, ok := gsp.getFromDB(c, repo, pagination, clientID)
+	if !ok {
+		return
+	}
+
+	// Build the pricing rules engine.
+	engine, ok := getPricingEngine(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the prices locked by the quotes of the products.
+	locked, ok := getLockedPricings(c, ps)
 	if !ok {
 		return
 	}
 
 	// Apply discounts to the products.
-	ps = gsp.generateDiscount(ps)
+	ps = gsp.generateDiscount(engine, locked, ps)
 
-	// Send the list of products with applied discounts in JSON format as the response.
-	c.JSON(http.StatusOK, ps)
+	// Send the page of products with applied discounts in JSON format as the response.
+	writeProducts(c, pagination, total, ps)
 }
 
 // getFromDB is a method of the GetSomeProducts struct that retrieves a list of products from the database.
-// It takes a gin.Context, a product repository, and a page number as parameters.
-// It returns a list of products and a boolean indicating whether the operation was successful.
-func (gsp GetSomeProducts) getFromDB(c *gin.Context, repo database.ProductRepository, page int) (ps []*product.Product, ok bool) {
-	// Retrieve the list of products from the repository using the specified page and client ID.
-	ps, err := repo.Get(page, c.GetInt("id"))
+// It takes a gin.Context, a product repository, a pagination and a client ID as parameters.
+// It returns a page of products, the total number of products and a boolean indicating whether the operation was successful.
+func (gsp GetSomeProducts) getFromDB(c *gin.Context, repo database.ProductRepository, pagination search.Pagination, clientID int) (ps []*product.Product, total int, ok bool) {
+	// Retrieve the page of products from the repository using the specified pagination and client ID.
+	ps, total, err := repo.Get(c.Request.Context(), pagination, clientID)
 	if err != nil {
 		// If there's an error, handle it and set ok to false.
 		handleError(c, err)
@@ -56,22 +75,18 @@ func (gsp GetSomeProducts) getFromDB(c *gin.Context, repo database.ProductReposi
 }
 
 // generateDiscount is a method of the GetSomeProducts struct that applies discounts to a list of products.
-// It takes a list of products as a parameter and returns the same list with applied discounts.
-func (gsp GetSomeProducts) generateDiscount(psR []*product.Product) (ps []*product.Product) {
+// It takes a pricing rules engine, the prices locked by quotes and a list of products as parameters and returns the same list with applied discounts.
+func (gsp GetSomeProducts) generateDiscount(engine product.RulesEngine, locked map[int]product.Pricing, psR []*product.Product) (ps []*product.Product) {
 	// Loop through the list of products and calculate discounts for each product.
 	ps = make([]*product.Product, len(psR))
+	now := time.Now()
 	for i, p := range psR {
-		var vault, port int
-		if p.Vault != nil {
-			vault = *p.Vault
-		}
-		if p.Port != nil {
-			port = *p.Port
-		}
-		// Create a discount generator based on the product's attributes.
-		discountGenerator := product.NewDiscountGenerator(vault, port, *p.Quantity, *p.ShippingPrice)
-		// Apply the discount generator to calculate the discount for the product.
-		p.Discount = discountGenerator.Generate()
+		// Create a discount generator based on the pricing rules, or the quote, and the product's attributes.
+		discountGenerator := newDiscountGenerator(engine, locked, *p, now)
+		// Apply the discount generator to calculate the itemised price and discount for the product.
+		pricing := discountGenerator.Pricing()
+		p.Discount = pricing.Discount
+		p.Pricing = &pricing
 		// Assign the modified product to the new list of products.
 		ps[i] = p
 	}
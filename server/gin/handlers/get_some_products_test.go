@@ -9,9 +9,12 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/coffemanfp/docucentertest/auth"
 	"github.com/coffemanfp/docucentertest/config"
 	"github.com/coffemanfp/docucentertest/database"
 	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/search"
+	sErrors "github.com/coffemanfp/docucentertest/server/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -44,7 +47,7 @@ func TestGetSomeProducts_Do(t *testing.T) {
 		}
 
 		mockRepo := new(MockProductRepository)
-		mockRepo.On("Get", mock.Anything, mock.Anything).Return(mockProducts, nil)
+		mockRepo.On("Get", mock.Anything, mock.Anything).Return(mockProducts, len(mockProducts), nil)
 
 		// Create a mock context with a request parameter
 		req, _ := http.NewRequest("GET", "/path", nil)
@@ -55,10 +58,11 @@ func TestGetSomeProducts_Do(t *testing.T) {
 		db := database.Database{
 			Repositories: map[database.RepositoryID]interface{}{
 				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		// Set up the handler and execute the action
 		gc := GetSomeProducts{}
 		gc.Do(c)
@@ -77,7 +81,7 @@ func TestGetSomeProducts_Do(t *testing.T) {
 
 	t.Run("NotFound", func(t *testing.T) {
 		mockRepo := new(MockProductRepository)
-		mockRepo.On("Get", mock.Anything, mock.Anything).Return([]*product.Product{}, errors.New("not found"))
+		mockRepo.On("Get", mock.Anything, mock.Anything).Return([]*product.Product{}, 0, errors.New("not found"))
 
 		// Create a mock context with a request parameter
 		req, _ := http.NewRequest("GET", "/path", nil)
@@ -88,10 +92,11 @@ func TestGetSomeProducts_Do(t *testing.T) {
 		db := database.Database{
 			Repositories: map[database.RepositoryID]interface{}{
 				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		// Set up the handler and execute the action
 		gc := GetSomeProducts{}
 		gc.Do(c)
@@ -100,4 +105,290 @@ func TestGetSomeProducts_Do(t *testing.T) {
 		assert.NotEmpty(t, c.Errors)
 		assert.Contains(t, c.Errors[0].Error(), "not found")
 	})
+	t.Run("ClientScope", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Get", mock.Anything, 7).Return([]*product.Product{}, 0, nil)
+
+		// The client_id parameter is ignored for clients
+		req, _ := http.NewRequest("GET", "/path?client_id=5", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Set("id", 7)
+		c.Set("role", auth.CLIENT_ROLE)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		gc := GetSomeProducts{}
+		gc.Do(c)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("PrivilegedScope", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Get", mock.Anything, 5).Return([]*product.Product{}, 0, nil)
+		mockRepo.On("Get", mock.Anything, database.ANY_CLIENT).Return([]*product.Product{}, 0, nil)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
+			},
+		}
+		Init(db, config.ConfigInfo{})
+
+		for _, path := range []string{"/path?client_id=5", "/path"} {
+			req, _ := http.NewRequest("GET", path, nil)
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = req
+			c.Set("id", 7)
+			c.Set("role", auth.ADMIN_ROLE)
+
+			gc := GetSomeProducts{}
+			gc.Do(c)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Get", search.Pagination{Limit: 10, Offset: 20}, mock.Anything).Return([]*product.Product{}, 45, nil)
+
+		req, _ := http.NewRequest("GET", "/path?page=3&page_size=10&client_id=5", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		gc := GetSomeProducts{}
+		gc.Do(c)
+
+		// The page is described in the headers, with links keeping the other parameters
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "45", rec.Header().Get("X-Total-Count"))
+		assert.Equal(t, "3", rec.Header().Get("X-Page"))
+		assert.Equal(t, "10", rec.Header().Get("X-Page-Size"))
+		assert.Equal(t, `</path?client_id=5&page=1&page_size=10>; rel="first", `+
+			`</path?client_id=5&page=2&page_size=10>; rel="prev", `+
+			`</path?client_id=5&page=4&page_size=10>; rel="next", `+
+			`</path?client_id=5&page=5&page_size=10>; rel="last"`, rec.Header().Get("Link"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("OffsetPagination", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Get", search.Pagination{Limit: 5, Offset: 0}, mock.Anything).Return([]*product.Product{}, 5, nil)
+
+		req, _ := http.NewRequest("GET", "/path?limit=5", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		gc := GetSomeProducts{}
+		gc.Do(c)
+
+		// A single page has no previous or next links
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `</path?limit=5&offset=0>; rel="first", </path?limit=5&offset=0>; rel="last"`, rec.Header().Get("Link"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidPagination", func(t *testing.T) {
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: new(MockProductRepository),
+			},
+		}
+		conf := config.ConfigInfo{}
+		conf.Server.MaxPageSize = 50
+		Init(db, conf)
+
+		for path, code := range map[string]int{
+			"/path?page=one":            http.StatusUnprocessableEntity,
+			"/path?page=-1":             http.StatusUnprocessableEntity,
+			"/path?page_size=51":        http.StatusUnprocessableEntity,
+			"/path?limit=51":            http.StatusUnprocessableEntity,
+			"/path?offset=-5":           http.StatusUnprocessableEntity,
+			"/path?page=2&offset=20":    http.StatusBadRequest,
+			"/path?page_size=5&limit=5": http.StatusBadRequest,
+			"/path?sort=password":       http.StatusUnprocessableEntity,
+			"/path?sort=port,-port":     http.StatusUnprocessableEntity,
+		} {
+			req, _ := http.NewRequest("GET", path, nil)
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = req
+
+			gc := GetSomeProducts{}
+			gc.Do(c)
+
+			assert.NotEmpty(t, c.Errors, path)
+			httpErr, ok := c.Errors[0].Err.(sErrors.HTTPError)
+			assert.True(t, ok, path)
+			assert.Equal(t, code, httpErr.Code, path)
+		}
+	})
+
+	t.Run("CursorPagination", func(t *testing.T) {
+		conf := config.ConfigInfo{}
+		conf.Server.SecretKey = "secret"
+		cursor := search.EncodeCursor(search.Cursor{ID: 3}, []byte("secret"))
+
+		mockProducts := []*product.Product{{ID: 4, ClientID: 1}, {ID: 6, ClientID: 1}}
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Get", search.Pagination{Limit: 2, After: &search.Cursor{ID: 3}}, mock.Anything).Return(mockProducts, 9, nil)
+
+		req, _ := http.NewRequest("GET", "/path?limit=2&cursor="+cursor, nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
+			},
+		}
+
+		Init(db, conf)
+		gc := GetSomeProducts{}
+		gc.Do(c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+
+		// The products stay an array and the cursor after the last one is sent in the headers
+		var ps []*product.Product
+		err := json.Unmarshal(rec.Body.Bytes(), &ps)
+		assert.NoError(t, err)
+		assert.Len(t, ps, 2)
+		nextCursor := rec.Header().Get("X-Next-Cursor")
+		next, err := search.DecodeCursor(nextCursor, []byte("secret"))
+		assert.NoError(t, err)
+		assert.Equal(t, search.Cursor{ID: 6}, next)
+		assert.Equal(t, "9", rec.Header().Get("X-Total-Count"))
+		assert.Contains(t, rec.Header().Get("Link"), "cursor="+nextCursor)
+	})
+
+	t.Run("LastCursorPage", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Get", search.Pagination{Limit: search.DEFAULT_PAGE_SIZE}, mock.Anything).Return([]*product.Product{{ID: 1}}, 1, nil)
+
+		// An empty cursor starts from the first product
+		req, _ := http.NewRequest("GET", "/path?cursor=", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		gc := GetSomeProducts{}
+		gc.Do(c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+
+		// A page that is not full is the last one
+		assert.Empty(t, rec.Header().Get("X-Next-Cursor"))
+		assert.Empty(t, rec.Header().Get("Link"))
+	})
+
+	t.Run("InvalidCursor", func(t *testing.T) {
+		conf := config.ConfigInfo{}
+		conf.Server.SecretKey = "secret"
+		forged := search.EncodeCursor(search.Cursor{ID: 3}, []byte("other"))
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: new(MockProductRepository),
+			},
+		}
+		Init(db, conf)
+
+		sorted := search.EncodeCursor(search.Cursor{Sort: "port", Values: []interface{}{1}, ID: 3}, []byte("secret"))
+		for path, code := range map[string]int{
+			"/path?cursor=" + sorted:     http.StatusUnprocessableEntity,
+			"/path?cursor=" + forged:     http.StatusUnprocessableEntity,
+			"/path?cursor=abc":           http.StatusUnprocessableEntity,
+			"/path?cursor=&page=2":       http.StatusBadRequest,
+			"/path?cursor=&offset=20":    http.StatusBadRequest,
+			"/path?cursor=&page_size=20": http.StatusBadRequest,
+		} {
+			req, _ := http.NewRequest("GET", path, nil)
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = req
+
+			gc := GetSomeProducts{}
+			gc.Do(c)
+
+			assert.NotEmpty(t, c.Errors, path)
+			httpErr, ok := c.Errors[0].Err.(sErrors.HTTPError)
+			assert.True(t, ok, path)
+			assert.Equal(t, code, httpErr.Code, path)
+		}
+	})
+
+	t.Run("Sort", func(t *testing.T) {
+		conf := config.ConfigInfo{}
+		conf.Server.SecretKey = "secret"
+		sort := search.Sort{{Name: "delivered_at", Desc: true, Time: true}, {Name: "shipping_price"}}
+
+		mockProducts := []*product.Product{{ID: 4, ClientID: 1, ShippingPrice: newFloat64(10)}}
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Get", search.Pagination{Limit: 1, Sort: sort}, mock.Anything).Return(mockProducts, 3, nil)
+
+		req, _ := http.NewRequest("GET", "/path?sort=-delivered_at,shipping_price&cursor=&limit=1", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
+			},
+		}
+
+		Init(db, conf)
+		gc := GetSomeProducts{}
+		gc.Do(c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+
+		// The next cursor is keyed on the sort fields of the last product
+		next, err := search.DecodeCursor(rec.Header().Get("X-Next-Cursor"), []byte("secret"))
+		assert.NoError(t, err)
+		assert.Equal(t, search.Cursor{Sort: "-delivered_at,shipping_price", Values: []interface{}{nil, 10.0}, ID: 4}, next)
+	})
 }
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/gin-gonic/gin"
+)
+
+// GetSomeSavedSearches is a struct representing the action to retrieve a list of saved searches.
+type GetSomeSavedSearches struct{}
+
+// Do is the method of the GetSomeSavedSearches struct that performs the action.
+func (gss GetSomeSavedSearches) Do(c *gin.Context) {
+	// Read the pagination parameters from the URL, the saved searches are always ordered by ID.
+	pagination, ok := readPagination(c, noSort, false)
+	if !ok {
+		return
+	}
+
+	// Read the client ID the request is restricted to.
+	clientID, ok := readClientScope(c)
+	if !ok {
+		return
+	}
+
+	// Get the saved search repository.
+	repo, ok := getSavedSearchRepository(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the page of saved searches using the repository, and the total number of saved searches.
+	ss, total, ok := gss.get(c, repo, pagination, clientID)
+	if !ok {
+		return
+	}
+
+	// Return the list of saved searches as a JSON response, describing its page in the headers.
+	writePagination(c, pagination, total)
+	c.JSON(http.StatusOK, ss)
+}
+
+// get is a method of the GetSomeSavedSearches struct that retrieves a page of saved searches of a client from the database.
+func (gss GetSomeSavedSearches) get(c *gin.Context, repo database.SavedSearchRepository, pagination search.Pagination, clientID int) (ss []savedsearch.SavedSearch, total int, ok bool) {
+	ss, total, err := repo.Get(c.Request.Context(), pagination, clientID)
+	if err != nil {
+		// If there's an error, handle it and set ok to false.
+		handleError(c, err)
+		return
+	}
+	// If successful, set ok to true.
+	ok = true
+	return
+}
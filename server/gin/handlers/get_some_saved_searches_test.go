@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSomeSavedSearches_Do(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockSavedSearchRepository)
+		mockRepo.On("Get", search.Pagination{Limit: 2, Offset: 2}, 1).
+			Return([]savedsearch.SavedSearch{{ID: 3, ClientID: 1, Name: "Boxes"}}, 3, nil)
+
+		// The client ID scope of the request is ignored for clients.
+		c, rec := newSavedSearchContext("GET", "/path?page=2&page_size=2&client_id=2", "", mockRepo, auth.CLIENT_ROLE)
+		GetSomeSavedSearches{}.Do(c)
+
+		assert.Empty(t, c.Errors)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "3", rec.Header().Get("X-Total-Count"))
+		var ss []savedsearch.SavedSearch
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &ss))
+		assert.Len(t, ss, 1)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AdminScope", func(t *testing.T) {
+		mockRepo := new(MockSavedSearchRepository)
+		mockRepo.On("Get", search.Pagination{Limit: 20}, 2).Return([]savedsearch.SavedSearch{}, 0, nil)
+		mockRepo.On("Get", search.Pagination{Limit: 20}, database.ANY_CLIENT).Return([]savedsearch.SavedSearch{}, 0, nil)
+
+		// Admins list the saved searches of the client of the scope, or of every client without one.
+		for _, target := range []string{"/path?client_id=2", "/path"} {
+			c, rec := newSavedSearchContext("GET", target, "", mockRepo, auth.ADMIN_ROLE)
+			GetSomeSavedSearches{}.Do(c)
+
+			assert.Empty(t, c.Errors, target)
+			assert.Equal(t, http.StatusOK, rec.Code, target)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		mockRepo := new(MockSavedSearchRepository)
+		mockRepo.On("Get", search.Pagination{Limit: 20}, 1).Return([]savedsearch.SavedSearch(nil), 0, errors.New("failed"))
+
+		c, _ := newSavedSearchContext("GET", "/path", "", mockRepo, auth.CLIENT_ROLE)
+		GetSomeSavedSearches{}.Do(c)
+
+		if assert.NotEmpty(t, c.Errors) {
+			assert.EqualError(t, c.Errors[0].Err, "failed")
+		}
+	})
+}
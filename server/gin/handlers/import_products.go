@@ -0,0 +1,430 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/coffemanfp/docucentertest/database"
+	dbErrors "github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/coffemanfp/docucentertest/jobs"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/search"
+	"github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/coffemanfp/docucentertest/spreadsheet"
+	"github.com/gin-gonic/gin"
+)
+
+// Limits of the product imports.
+const (
+	importBatchSize = 100      // Products inserted by every statement
+	maxImportRows   = 5000     // Rows of a spreadsheet, besides its header
+	maxImportSize   = 10 << 20 // Bytes of a spreadsheet, ten megabytes
+)
+
+// importAttempts is the number of times an import is tried while other requests create its guide numbers concurrently.
+const importAttempts = 3
+
+// ImportProducts is a struct that represents an import products operation.
+type ImportProducts struct{}
+
+// importReport is the response of an import, with the outcome of every row of the spreadsheet.
+type importReport struct {
+	DryRun  bool        `json:"dry_run"` // Whether the products were only validated, without creating them
+	Valid   int         `json:"valid"`   // Number of rows whose products were created, or would be without a dry run
+	Invalid int         `json:"invalid"` // Number of rows whose products were rejected
+	Rows    []importRow `json:"rows"`    // Outcome of every row, in the order of the spreadsheet
+}
+
+// importRow is the outcome of a row of an imported spreadsheet.
+type importRow struct {
+	Row         int    `json:"row"`                    // Number of the row in the spreadsheet, the header being the first one
+	GuideNumber string `json:"guide_number,omitempty"` // Guide number of the product of the row, if any
+	ID          int    `json:"id,omitempty"`           // ID of the created product, unless it was rejected or in a dry run
+	Error       string `json:"error,omitempty"`        // Reason the product was rejected, if it was
+}
+
+// pendingProduct is a valid product of an imported spreadsheet, waiting to be created.
+type pendingProduct struct {
+	row     int             // Index of the row of the product in the report
+	product product.Product // The validated product
+}
+
+// importParams are the parameters of an import job, see ImportProducts.Run.
+type importParams struct {
+	Format     spreadsheet.Format `json:"format"`     // Format of the spreadsheet, which is the input of the job
+	DryRun     bool               `json:"dry_run"`    // Whether the products are only validated, without creating them
+	Privileged bool               `json:"privileged"` // Whether the client had a privileged role when the job was queued
+}
+
+// Do is a method of the ImportProducts struct that handles the creation of the products of a spreadsheet.
+// The spreadsheet is either the "file" of a multipart form, whose format is told by its extension, or the whole
+// request body, whose format is told by its content type. The "format" query parameter overrides both.
+// Every row is validated like the products of CreateProduct, and the valid ones are created in batches inside a
+// single transaction. With the "dry_run" query parameter, the transaction is rolled back so nothing is created.
+// It responds with the outcome of every row, the created product IDs and the reasons of the rejected rows.
+// With the "async" query parameter, the spreadsheet is imported by a background job instead, see enqueueJob,
+// and the outcome of every row is its result.
+func (ip ImportProducts) Do(c *gin.Context) {
+	// Read whether the products must only be validated
+	dryRun, ok := readBoolFromURL(c, "dry_run", true)
+	if !ok {
+		return
+	}
+
+	// Read whether the spreadsheet must be imported by a background job
+	async, ok := readBoolFromURL(c, "async", true)
+	if !ok {
+		return
+	}
+
+	// Open the uploaded spreadsheet
+	body, format, ok := ip.openSpreadsheet(c)
+	if !ok {
+		return
+	}
+
+	// Queue the import of the whole spreadsheet
+	if async {
+		ip.enqueue(c, body, importParams{
+			Format:     format,
+			DryRun:     dryRun,
+			Privileged: getRole(c).IsPrivileged(),
+		})
+		return
+	}
+
+	reader, err := spreadsheet.NewReader(body, format)
+	if err != nil {
+		handleError(c, ip.readError(err))
+		return
+	}
+	defer reader.Close()
+
+	// Read, validate and create the product of every row
+	report, err := ip.importProducts(c.Request.Context(), reader, c.GetInt("id"), getRole(c).IsPrivileged(), dryRun, nil)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	// Respond with a 201 Created status only if any product was created
+	status := http.StatusOK
+	if !dryRun && report.Valid > 0 {
+		status = http.StatusCreated
+	}
+	c.JSON(status, report)
+}
+
+// enqueue is a method of the ImportProducts struct that queues the import of a spreadsheet as a background job.
+// The whole spreadsheet is read, so it is stored as the input of the job.
+func (ip ImportProducts) enqueue(c *gin.Context, body io.Reader, params importParams) {
+	input, err := io.ReadAll(body)
+	if err != nil {
+		handleError(c, ip.readError(err))
+		return
+	}
+	enqueueJob(c, jobs.IMPORT_PRODUCTS_KIND, params, input)
+}
+
+// Run is a method of the ImportProducts struct that runs an import job, see jobs.Runner.
+// The spreadsheet is imported for the client of the job like Do does, and the outcome of every row is its result.
+// An invalid spreadsheet fails the job for good, as retrying it would not fix it.
+func (ip ImportProducts) Run(ctx context.Context, job jobs.Job, progress func(percent int)) (result *jobs.Result, err error) {
+	var params importParams
+	err = job.ReadParams(&params)
+	if err != nil {
+		return
+	}
+
+	reader, err := spreadsheet.NewReader(bytes.NewReader(job.Input), params.Format)
+	if err != nil {
+		err = jobError(ip.readError(err))
+		return
+	}
+	defer reader.Close()
+
+	report, err := ip.importProducts(ctx, reader, job.ClientID, params.Privileged, params.DryRun, progress)
+	if err != nil {
+		err = jobError(err)
+		return
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return
+	}
+	result = &jobs.Result{Name: "import-report.json", ContentType: "application/json", Data: data}
+	return
+}
+
+// importProducts is a method of the ImportProducts struct that imports the products of a spreadsheet for a client.
+// The products are read and validated by readProducts, and the valid ones are created by saveProductsInDB.
+// It returns the report of the import, with the outcome of every row counted.
+func (ip ImportProducts) importProducts(ctx context.Context, reader spreadsheet.Reader, clientID int, privileged, dryRun bool, progress func(percent int)) (report importReport, err error) {
+	// Read and validate the product of every row
+	report, pending, err := ip.readProducts(reader, clientID, privileged)
+	if err != nil {
+		return
+	}
+	report.DryRun = dryRun
+
+	// Create the valid products, unless it is a dry run
+	report, err = ip.saveProductsInDB(ctx, report, pending, dryRun, progress)
+	if err != nil {
+		return
+	}
+
+	// Count the outcomes of the rows
+	for _, row := range report.Rows {
+		if row.Error == "" {
+			report.Valid++
+		} else {
+			report.Invalid++
+		}
+	}
+	return
+}
+
+// openSpreadsheet is a method of the ImportProducts struct that opens the uploaded spreadsheet, and reads its format.
+// The body of the request is limited to maxImportSize bytes.
+func (ip ImportProducts) openSpreadsheet(c *gin.Context) (body io.Reader, format spreadsheet.Format, ok bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	var err error
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		// Read the file of the form, whose format is told by its extension
+		file, fileErr := c.FormFile("file")
+		if fileErr != nil {
+			err = ip.readError(fileErr)
+			if err.(errors.HTTPError).Code != http.StatusRequestEntityTooLarge {
+				err = errors.NewHTTPError(http.StatusBadRequest, "invalid file: %s", fileErr)
+			}
+			handleError(c, err)
+			return
+		}
+		f, openErr := file.Open()
+		if openErr != nil {
+			handleError(c, openErr)
+			return
+		}
+		// The file is closed with the request, when its form is removed
+		body = f
+		format, err = spreadsheet.FormatOf(file.Filename)
+	} else {
+		// Read the body, whose format is told by its content type
+		body = c.Request.Body
+		format, err = spreadsheet.FormatOfContentType(c.ContentType())
+	}
+
+	// The format parameter overrides the one of the file
+	if v := c.Query("format"); v != "" {
+		format, err = spreadsheet.ParseFormat(v)
+	}
+	if err != nil {
+		err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
+// readProducts is a method of the ImportProducts struct that reads and validates the product of every row.
+// The rows are mapped to the products by the header of the spreadsheet, and the empty ones are skipped.
+// Clients always import products for themselves, and privileged roles can import them for any client with the
+// "client_id" column, using their own client ID if it is empty. The guide numbers can not be repeated.
+// It returns a report with the outcome of every rejected row, and the valid products pending to be created.
+// An invalid spreadsheet is reported with an HTTPError.
+func (ip ImportProducts) readProducts(reader spreadsheet.Reader, clientID int, privileged bool) (report importReport, pending []pendingProduct, err error) {
+	// Read the header from the first row
+	record, err := reader.Read()
+	if err == io.EOF {
+		err = fmt.Errorf("invalid header: the spreadsheet is empty")
+	}
+	if err != nil {
+		err = ip.readError(err)
+		return
+	}
+	header, err := spreadsheet.NewHeader(record)
+	if err != nil {
+		err = ip.readError(err)
+		return
+	}
+
+	report.Rows = make([]importRow, 0)
+	guideNumbers := make(map[string]int)
+	for {
+		record, err = reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			report, pending, err = importReport{}, nil, ip.readError(err)
+			return
+		}
+		if spreadsheet.IsEmpty(record) {
+			continue
+		}
+		if len(report.Rows) == maxImportRows {
+			report, pending = importReport{}, nil
+			err = errors.NewHTTPError(http.StatusUnprocessableEntity, "invalid spreadsheet: more than %d rows", maxImportRows)
+			return
+		}
+
+		// Read and validate the product of the row
+		n := reader.Row()
+		row := importRow{Row: n}
+		p, rowErr := header.Product(record)
+		if p.GuideNumber != nil {
+			row.GuideNumber = *p.GuideNumber
+		}
+		if rowErr == nil {
+			if p.ClientID == 0 || !privileged {
+				p.ClientID = clientID
+			}
+			p, rowErr = product.New(p)
+		}
+		if rowErr == nil {
+			if first, ok := guideNumbers[row.GuideNumber]; ok {
+				rowErr = fmt.Errorf("invalid guide number: guide number %s is repeated from row %d", row.GuideNumber, first)
+			}
+		}
+
+		if rowErr != nil {
+			row.Error = rowErr.Error()
+		} else {
+			guideNumbers[row.GuideNumber] = n
+			pending = append(pending, pendingProduct{row: len(report.Rows), product: p})
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	err = nil
+	return
+}
+
+// saveProductsInDB is a method of the ImportProducts struct that creates the pending products in the database.
+// They are inserted in batches of importBatchSize inside a single transaction, and nothing is inserted in a dry run.
+// The products whose guide numbers already exist are rejected instead, and the IDs of the rest are set in the report.
+// A guide number created by another request after it was checked fails the transaction, so the import is retried
+// up to importAttempts times, rejecting its row like the other existing ones.
+// The percentage of pending products saved is reported with progress after every batch, if it is not nil.
+func (ip ImportProducts) saveProductsInDB(ctx context.Context, report importReport, pending []pendingProduct, dryRun bool, progress func(percent int)) (r importReport, err error) {
+	var ids map[int]int
+	var rejected map[int]string
+	for attempt := 1; ; attempt++ {
+		ids, rejected, err = ip.createProducts(ctx, pending, dryRun, progress)
+		var dbErr dbErrors.Error
+		if attempt < importAttempts && stdErrors.As(err, &dbErr) && dbErr.Type == dbErrors.ALREADY_EXISTS {
+			continue
+		}
+		break
+	}
+	if err != nil {
+		return
+	}
+
+	// Set the outcome of the products in the report.
+	r = report
+	for row, reason := range rejected {
+		r.Rows[row].Error = reason
+	}
+	for row, id := range ids {
+		r.Rows[row].ID = id
+	}
+	return
+}
+
+// createProducts is a method of the ImportProducts struct that creates the pending products in a single transaction,
+// unless it is a dry run. It returns the IDs of the created products and the reasons of the rejected ones, by row.
+func (ip ImportProducts) createProducts(ctx context.Context, pending []pendingProduct, dryRun bool, progress func(percent int)) (ids map[int]int, rejected map[int]string, err error) {
+	ids = make(map[int]int, len(pending))
+	rejected = make(map[int]string)
+	err = dbManager.WithTx(ctx, func(tx database.Repositories) (err error) {
+		// Use the ProductRepository of the transaction to save the products.
+		repo, err := database.GetRepository[database.ProductRepository](tx, database.PRODUCT_REPOSITORY)
+		if err != nil {
+			return
+		}
+
+		for start := 0; start < len(pending); start += importBatchSize {
+			end := start + importBatchSize
+			if end > len(pending) {
+				end = len(pending)
+			}
+			batch := pending[start:end]
+
+			// Reject the products whose guide numbers already exist.
+			var existing map[string]bool
+			existing, err = ip.existingGuideNumbers(ctx, repo, batch)
+			if err != nil {
+				return
+			}
+			products := make([]product.Product, 0, len(batch))
+			rows := make([]int, 0, len(batch))
+			for _, pp := range batch {
+				if existing[*pp.product.GuideNumber] {
+					rejected[pp.row] = fmt.Sprintf("invalid guide number: guide number %s already exists", *pp.product.GuideNumber)
+					continue
+				}
+				products = append(products, pp.product)
+				rows = append(rows, pp.row)
+			}
+
+			// A dry run only validates the products, so it writes nothing.
+			if !dryRun {
+				var batchIDs []int
+				batchIDs, err = repo.CreateBatch(ctx, products)
+				if err != nil {
+					return
+				}
+				for i, id := range batchIDs {
+					ids[rows[i]] = id
+				}
+			}
+			if progress != nil {
+				progress(end * 100 / len(pending))
+			}
+		}
+		return
+	})
+	return
+}
+
+// existingGuideNumbers is a method of the ImportProducts struct that returns which guide numbers of a batch of
+// pending products already exist, for any client.
+func (ip ImportProducts) existingGuideNumbers(ctx context.Context, repo database.ProductRepository, batch []pendingProduct) (existing map[string]bool, err error) {
+	guideNumbers := make([]string, 0, len(batch))
+	for _, pp := range batch {
+		guideNumbers = append(guideNumbers, *pp.product.GuideNumber)
+	}
+
+	// Search the products with any of the guide numbers, which are unique.
+	ps, _, err := repo.Search(ctx, search.Search{
+		GuideNumber: search.StringFilter{Values: guideNumbers},
+		Pagination:  search.Pagination{Limit: len(guideNumbers)},
+	})
+	if err != nil {
+		return
+	}
+	existing = make(map[string]bool, len(ps))
+	for _, p := range ps {
+		existing[*p.GuideNumber] = true
+	}
+	return
+}
+
+// readError is a method of the ImportProducts struct that turns an error reading the spreadsheet into an HTTPError.
+// A spreadsheet larger than maxImportSize is rejected with a 413 Request Entity Too Large status.
+func (ip ImportProducts) readError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if stdErrors.As(err, &maxBytesErr) {
+		return errors.NewHTTPError(http.StatusRequestEntityTooLarge, "invalid spreadsheet: larger than %d bytes", maxImportSize)
+	}
+	return errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+}
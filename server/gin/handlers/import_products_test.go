@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	dbErrors "github.com/coffemanfp/docucentertest/database/errors"
+	"github.com/coffemanfp/docucentertest/jobs"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/search"
+	sErrors "github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/coffemanfp/docucentertest/spreadsheet"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const importCSV = `guide_number,type,quantity,joined_at,delivered_at,shipping_price,vehicle_plate,client_id
+ABC1234567,box,3,2024-03-05,2024-03-06,12.5,ABC-123,2
+DEF1234567,box,x,2024-03-05,2024-03-06,12.5,ABC-123,
+
+GHI1234567,box,1,2024-03-05,2024-03-06,12.5,ABC-123,
+ABC1234567,box,1,2024-03-05,2024-03-06,12.5,ABC-123,
+`
+
+// newImportRouter creates a router importing products for a client with the given role, on the given repository.
+func newImportRouter(mockRepo *MockProductRepository, role auth.Role) *gin.Engine {
+	db := database.Database{
+		Repositories: map[database.RepositoryID]interface{}{
+			database.PRODUCT_REPOSITORY: mockRepo,
+		},
+	}
+	db.Conn = MockTxConnector{repos: db.Repositories}
+
+	Init(db, config.ConfigInfo{})
+	r := gin.New()
+	r.POST("/path", setClient(1, role), ImportProducts{}.Do)
+	return r
+}
+
+func TestImportProducts_Do(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Search", mock.MatchedBy(func(s search.Search) bool {
+			return assert.ObjectsAreEqual([]string{"ABC1234567", "GHI1234567"}, s.GuideNumber.Values)
+		})).Return([]*product.Product{{GuideNumber: newString("GHI1234567")}}, 1, nil)
+		mockRepo.On("CreateBatch", mock.MatchedBy(func(ps []product.Product) bool {
+			return len(ps) == 1 && *ps[0].GuideNumber == "ABC1234567" && ps[0].ClientID == 1 &&
+				ps[0].Status == product.REGISTERED_STATUS
+		})).Return([]int{7}, nil)
+
+		r := newImportRouter(mockRepo, auth.CLIENT_ROLE)
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/path", strings.NewReader(importCSV))
+		req.Header.Set("Content-Type", "text/csv")
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var report importReport
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Equal(t, importReport{
+			Valid:   1,
+			Invalid: 3,
+			Rows: []importRow{
+				{Row: 2, GuideNumber: "ABC1234567", ID: 7},
+				{Row: 3, GuideNumber: "DEF1234567", Error: "invalid quantity: x is not an integer"},
+				{Row: 5, GuideNumber: "GHI1234567", Error: "invalid guide number: guide number GHI1234567 already exists"},
+				{Row: 6, GuideNumber: "ABC1234567", Error: "invalid guide number: guide number ABC1234567 is repeated from row 2"},
+			},
+		}, report)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("DryRunFromAForm", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Search", mock.Anything).Return([]*product.Product{}, 0, nil)
+
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		part, _ := w.CreateFormFile("file", "products.csv")
+		part.Write([]byte(importCSV))
+		w.Close()
+
+		r := newImportRouter(mockRepo, auth.OPERATOR_ROLE)
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/path?dry_run=true", &body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var report importReport
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 2, report.Valid)
+		assert.Equal(t, 2, report.Invalid)
+		assert.Zero(t, report.Rows[0].ID)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+	})
+
+	t.Run("CreatedConcurrently", func(t *testing.T) {
+		// Another request creates GHI1234567 after it was checked, so the import is retried to reject its row.
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Search", mock.Anything).Return([]*product.Product{}, 0, nil).Once()
+		mockRepo.On("CreateBatch", mock.MatchedBy(func(ps []product.Product) bool {
+			return len(ps) == 2
+		})).Return([]int(nil), dbErrors.NewError(dbErrors.ALREADY_EXISTS, "failed to insert product", "already exists")).Once()
+		mockRepo.On("Search", mock.Anything).Return([]*product.Product{{GuideNumber: newString("GHI1234567")}}, 1, nil).Once()
+		mockRepo.On("CreateBatch", mock.MatchedBy(func(ps []product.Product) bool {
+			return len(ps) == 1 && *ps[0].GuideNumber == "ABC1234567"
+		})).Return([]int{7}, nil).Once()
+
+		r := newImportRouter(mockRepo, auth.CLIENT_ROLE)
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/path", strings.NewReader(importCSV))
+		req.Header.Set("Content-Type", "text/csv")
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var report importReport
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Equal(t, 7, report.Rows[0].ID)
+		assert.Equal(t, "invalid guide number: guide number GHI1234567 already exists", report.Rows[2].Error)
+		mockRepo.AssertExpectations(t)
+	})
+
+	cases := map[string]struct {
+		contentType string
+		target      string
+		body        string
+		code        int
+	}{
+		"InvalidHeader":  {"text/csv", "/path", "guide_number,color\n", http.StatusUnprocessableEntity},
+		"EmptyFile":      {"text/csv", "/path", "", http.StatusUnprocessableEntity},
+		"InvalidFormat":  {"application/json", "/path", "{}", http.StatusUnprocessableEntity},
+		"FormatOverride": {"application/octet-stream", "/path?format=ods", importCSV, http.StatusUnprocessableEntity},
+		"InvalidDryRun":  {"text/csv", "/path?dry_run=maybe", importCSV, http.StatusUnprocessableEntity},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(MockProductRepository)
+			newImportRouter(mockRepo, auth.CLIENT_ROLE)
+
+			req, _ := http.NewRequest("POST", c.target, strings.NewReader(c.body))
+			req.Header.Set("Content-Type", c.contentType)
+			rec := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(rec)
+			ctx.Request = req
+			ctx.Set("id", 1)
+			ImportProducts{}.Do(ctx)
+
+			if assert.NotEmpty(t, ctx.Errors) {
+				httpErr, ok := ctx.Errors[0].Err.(sErrors.HTTPError)
+				assert.True(t, ok)
+				assert.Equal(t, c.code, httpErr.Code)
+			}
+			mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+		})
+	}
+}
+
+func TestImportProducts_Async(t *testing.T) {
+	mockJobRepo := new(MockJobRepository)
+	mockJobRepo.On("Create", mock.MatchedBy(func(j jobs.Job) bool {
+		var params importParams
+		return j.ClientID == 1 && j.Kind == jobs.IMPORT_PRODUCTS_KIND && string(j.Input) == importCSV &&
+			j.ReadParams(&params) == nil && params == importParams{Format: spreadsheet.CSV_FORMAT, DryRun: true}
+	})).Return(3, nil)
+
+	db := database.Database{
+		Repositories: map[database.RepositoryID]interface{}{
+			database.JOB_REPOSITORY: mockJobRepo,
+		},
+	}
+	Init(db, config.ConfigInfo{})
+	r := gin.New()
+	r.POST("/path", setClient(1, auth.CLIENT_ROLE), ImportProducts{}.Do)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/path?async=true&dry_run=true", strings.NewReader(importCSV))
+	req.Header.Set("Content-Type", "text/csv")
+	r.ServeHTTP(rec, req)
+
+	// The spreadsheet is only queued, so it is not even validated.
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "/v1/jobs/3", rec.Header().Get("Location"))
+	var j jobs.Job
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &j))
+	assert.Equal(t, 3, j.ID)
+	assert.Equal(t, jobs.QUEUED_STATUS, j.Status)
+	mockJobRepo.AssertExpectations(t)
+}
+
+func TestImportProducts_Run(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Search", mock.Anything).Return([]*product.Product{}, 0, nil)
+		mockRepo.On("CreateBatch", mock.MatchedBy(func(ps []product.Product) bool {
+			// The client of the job had no privileged role, so it can not import the products of others.
+			return len(ps) == 2 && ps[0].ClientID == 1 && ps[1].ClientID == 1
+		})).Return([]int{7, 8}, nil)
+		newImportRouter(mockRepo, auth.CLIENT_ROLE)
+
+		j, err := jobs.New(1, jobs.IMPORT_PRODUCTS_KIND, importParams{Format: spreadsheet.CSV_FORMAT}, []byte(importCSV), time.Now())
+		require.NoError(t, err)
+		percents := make([]int, 0)
+		result, err := ImportProducts{}.Run(context.Background(), j, func(percent int) {
+			percents = append(percents, percent)
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{100}, percents)
+		assert.Equal(t, "import-report.json", result.Name)
+		assert.Equal(t, "application/json", result.ContentType)
+		var report importReport
+		require.NoError(t, json.Unmarshal(result.Data, &report))
+		assert.Equal(t, 2, report.Valid)
+		assert.Equal(t, 2, report.Invalid)
+		assert.Equal(t, 7, report.Rows[0].ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidSpreadsheet", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		newImportRouter(mockRepo, auth.CLIENT_ROLE)
+
+		j, err := jobs.New(1, jobs.IMPORT_PRODUCTS_KIND, importParams{Format: spreadsheet.CSV_FORMAT}, []byte("guide_number,color\n"), time.Now())
+		require.NoError(t, err)
+		_, err = ImportProducts{}.Run(context.Background(), j, func(percent int) {})
+
+		// Retrying the job would not fix the spreadsheet.
+		assert.True(t, jobs.IsPermanent(err))
+		mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+	})
+}
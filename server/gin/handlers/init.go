@@ -6,11 +6,14 @@ import (
 )
 
 var db database.Repositories
+var dbManager database.Database
 var conf config.ConfigInfo
 
 // Init initializes the global database and configuration variables.
-// It sets the provided repositories and configuration information to be used throughout the application.
-func Init(newDb database.Repositories, newConf config.ConfigInfo) {
-	db = newDb
+// It sets the provided database repositories, the database manager used to run transactions,
+// and the configuration information to be used throughout the application.
+func Init(newDb database.Database, newConf config.ConfigInfo) {
+	db = newDb.Repositories
+	dbManager = newDb
 	conf = newConf
 }
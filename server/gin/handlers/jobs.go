@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/jobs"
+	"github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// jobsPath is the path of the job routes, which the jobs link to.
+const jobsPath = "/v1/jobs"
+
+// jobResponse represents a job as it is sent to the clients, with the link to download its result.
+type jobResponse struct {
+	jobs.Job
+	ResultURL string `json:"result_url,omitempty"` // URL of the result of a succeeded job, if it has any.
+}
+
+// newJobResponse creates the response of a job, linking to its result if it has any.
+func newJobResponse(j jobs.Job) (res jobResponse) {
+	res.Job = j
+	if j.Status == jobs.SUCCEEDED_STATUS && j.ResultName != "" {
+		res.ResultURL = fmt.Sprintf("%s/%d/result", jobsPath, j.ID)
+	}
+	return
+}
+
+// JobRunners returns the runners of every kind of background jobs, which run the operations of the handlers.
+// Init must be called before running any job.
+func JobRunners() map[jobs.Kind]jobs.Runner {
+	return map[jobs.Kind]jobs.Runner{
+		jobs.IMPORT_PRODUCTS_KIND: ImportProducts{},
+		jobs.EXPORT_PRODUCTS_KIND: ExportProducts{},
+	}
+}
+
+// enqueueJob queues a background job of the authenticated client running kind with the given parameters and input.
+// It responds with the queued job and a 202 Accepted status, its URL being in the Location header.
+func enqueueJob(c *gin.Context, kind jobs.Kind, params interface{}, input []byte) {
+	// Retrieve the job repository.
+	repo, ok := getJobRepository(c)
+	if !ok {
+		return
+	}
+
+	// Create the job, queued to run right away.
+	j, err := jobs.New(c.GetInt("id"), kind, params, input, time.Now().UTC())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	// Insert the job into the queue.
+	j.ID, err = repo.Create(c.Request.Context(), j)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	// Respond with the job, which is polled at its URL.
+	c.Header("Location", fmt.Sprintf("%s/%d", jobsPath, j.ID))
+	c.JSON(http.StatusAccepted, newJobResponse(j))
+}
+
+// jobError marks the errors of a job caused by its parameters or input as permanent, as retrying the job would not fix them.
+// They are the HTTPErrors with a 4xx status, that would be responded to a synchronous request.
+func jobError(err error) error {
+	if httpErr, ok := err.(errors.HTTPError); ok && httpErr.Code < http.StatusInternalServerError {
+		return jobs.Permanent(err)
+	}
+	return err
+}
+
+// readJobID reads the job ID from the URL parameter, and the client ID the request is restricted to.
+func readJobID(c *gin.Context) (id, clientID int, ok bool) {
+	id, ok = readIntFromURL(c, "id", false)
+	if !ok {
+		return
+	}
+	clientID, ok = readClientScope(c)
+	return
+}
@@ -15,8 +15,8 @@ type Login struct{}
 
 // Do performs the login process. It reads the client's credentials from the request,
 // searches for the credentials in the authentication repository, compares the provided
-// password with the hashed password from the database, generates an authentication token,
-// and responds with the generated token.
+// password with the hashed password from the database, generates an authentication token
+// and a refresh token, and responds with the generated tokens.
 func (l Login) Do(c *gin.Context) {
 	// Read the client credentials from the request data
 	client, ok := l.readCredentials(c)
@@ -42,15 +42,16 @@ func (l Login) Do(c *gin.Context) {
 		return
 	}
 
-	// Generate an authentication token using the retrieved client ID
-	token, ok := l.generateToken(c, id)
+	// Generate an authentication token and a refresh token using the retrieved client ID
+	token, refreshToken, ok := l.generateToken(c, repo, id)
 	if !ok {
 		return
 	}
 
-	// Respond with the generated token
+	// Respond with the generated tokens
 	c.JSON(http.StatusOK, gin.H{
-		"token": token,
+		"token":         token,
+		"refresh_token": refreshToken,
 	})
 }
 
@@ -75,7 +76,7 @@ func (l Login) getAuthRepository(c *gin.Context) (repo database.AuthRepository,
 // If the credentials are invalid, it returns an unauthorized error and false.
 func (l Login) searchCredentialsInDB(c *gin.Context, client client.Client, repo database.AuthRepository) (id int, hash string, ok bool) {
 	// Search for client credentials in the database and retrieve the client's ID and hashed password
-	id, hash, err := repo.GetIdAndHashedPassword(client.Auth)
+	id, hash, err := repo.GetIdAndHashedPassword(c.Request.Context(), client.Auth)
 	if err != nil {
 		// Return an unauthorized error if the credentials are invalid
 		err = errors.NewHTTPError(http.StatusUnauthorized, errors.UNAUTHORIZED_ERROR_MESSAGE)
@@ -101,18 +102,12 @@ func (l Login) comparePassword(c *gin.Context, hash, password string) (ok bool)
 	return
 }
 
-// generateToken generates a JWT token using the client's ID, JWT lifespan,
-// and secret key from the configuration settings.
+// generateToken generates a JWT token and a refresh token using the client's ID,
+// token lifespans, and secret key from the configuration settings.
+// The login starts a new refresh token family.
 // If token generation fails, it handles the error and returns false.
-// If token generation succeeds, it returns the generated token and true.
-func (l Login) generateToken(c *gin.Context, id int) (token string, ok bool) {
-	// Generate a JWT token using the client's ID and configuration settings
-	token, err := auth.GenerateToken(id, conf.Server.JWTLifespan, conf.Server.SecretKey)
-	if err != nil {
-		// Handle error if token generation fails
-		handleError(c, err)
-		return
-	}
-	ok = true
-	return
+// If token generation succeeds, it returns the generated tokens and true.
+func (l Login) generateToken(c *gin.Context, repo database.AuthRepository, id int) (token, refreshToken string, ok bool) {
+	// Generate the tokens using the client's ID and configuration settings
+	return generateTokens(c, repo, id, "")
 }
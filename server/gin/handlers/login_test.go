@@ -15,6 +15,7 @@ import (
 	"github.com/coffemanfp/docucentertest/database"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestLogin_Do(t *testing.T) {
@@ -33,6 +34,8 @@ func TestLogin_Do(t *testing.T) {
 		// Create a mock authentication repository
 		mockRepo := new(MockAuthRepository)
 		mockRepo.On("GetIdAndHashedPassword", mockClientCredentials.Auth).Return(1, "$2a$04$ELiP4j1x5NW2nSUEIyJWYui1NCZEpjCZ4ZOpods19haBxP.uPXA8y", nil)
+		mockRepo.On("GetRole", 1).Return(auth.CLIENT_ROLE, nil)
+		mockRepo.On("SaveRefreshToken", mock.Anything).Return(nil)
 
 		// Set up the handler and execute the action
 		login := Login{}
@@ -43,7 +46,7 @@ func TestLogin_Do(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		r := gin.New()
 		r.POST("/login", login.Do)
 
@@ -59,8 +62,9 @@ func TestLogin_Do(t *testing.T) {
 		err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
 		assert.NoError(t, err)
 
-		// Assert the generated token is present in the response
-		assert.NotNil(t, responseBody["token"])
+		// Assert the generated tokens are present in the response
+		assert.NotEmpty(t, responseBody["token"])
+		assert.NotEmpty(t, responseBody["refresh_token"])
 	})
 
 	t.Run("InvalidCredentials", func(t *testing.T) {
@@ -88,7 +92,7 @@ func TestLogin_Do(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		r := gin.New()
 		r.POST("/login", login.Do)
 
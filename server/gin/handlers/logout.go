@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// Logout represents the handler responsible for revoking the tokens of a session.
+type Logout struct{}
+
+// Do performs the logout process. It revokes the access token used to authorize the request
+// and, if provided, every refresh token of the same family as the given refresh token.
+func (l Logout) Do(c *gin.Context) {
+	// Read the optional refresh token from the request data
+	req, ok := l.readRefreshToken(c)
+	if !ok {
+		return
+	}
+
+	// Get the authentication repository to perform database operations
+	repo, ok := getAuthRepository(c)
+	if !ok {
+		return
+	}
+
+	// Revoke the access token used for this request
+	ok = l.revokeAccessToken(c, repo)
+	if !ok {
+		return
+	}
+
+	// Revoke the refresh token family, if a refresh token was provided
+	if req.RefreshToken != "" {
+		ok = l.revokeRefreshTokenFamily(c, repo, req.RefreshToken)
+		if !ok {
+			return
+		}
+	}
+
+	// Respond with a success status
+	c.Status(http.StatusOK)
+}
+
+// readRefreshToken reads the optional refresh token from the request data.
+// An empty body is accepted.
+func (l Logout) readRefreshToken(c *gin.Context) (req refreshRequest, ok bool) {
+	if c.Request.Body == nil || c.Request.ContentLength == 0 {
+		ok = true
+		return
+	}
+
+	err := c.ShouldBindJSON(&req)
+	if err != nil && err != io.EOF {
+		handleError(c, errors.NewHTTPError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	ok = true
+	return
+}
+
+// revokeAccessToken adds the ID of the access token saved by the authorization middleware to the revocation list.
+func (l Logout) revokeAccessToken(c *gin.Context, repo database.AuthRepository) (ok bool) {
+	var expiresAt *time.Time
+	if exp, exists := c.Get("exp"); exists {
+		t := exp.(time.Time)
+		expiresAt = &t
+	}
+
+	err := repo.RevokeToken(c.Request.Context(), c.GetString("jti"), expiresAt)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
+// revokeRefreshTokenFamily revokes every refresh token of the family of the given refresh token.
+// Refresh tokens owned by another client are rejected with an unauthorized error.
+func (l Logout) revokeRefreshTokenFamily(c *gin.Context, repo database.AuthRepository, token string) (ok bool) {
+	rt, err := repo.GetRefreshToken(c.Request.Context(), auth.HashRefreshToken(token))
+	if err != nil || rt.ClientID != c.GetInt("id") {
+		handleError(c, errors.NewHTTPError(http.StatusUnauthorized, errors.UNAUTHORIZED_ERROR_MESSAGE))
+		return
+	}
+
+	err = repo.RevokeRefreshTokenFamily(c.Request.Context(), rt.FamilyID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLogout_Do(t *testing.T) {
+	t.Run("AccessTokenOnly", func(t *testing.T) {
+		exp := time.Now().Add(time.Hour)
+
+		mockRepo := new(MockAuthRepository)
+		mockRepo.On("RevokeToken", "jti", &exp).Return(nil)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.AUTH_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		req, _ := http.NewRequest("POST", "/logout", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Set("id", 1)
+		c.Set("jti", "jti")
+		c.Set("exp", exp)
+
+		Logout{}.Do(c)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, c.Errors)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("WithRefreshToken", func(t *testing.T) {
+		token := "plain-refresh-token"
+		reqJSON, _ := json.Marshal(refreshRequest{RefreshToken: token})
+		rt := auth.RefreshToken{
+			ID:       1,
+			ClientID: 1,
+			FamilyID: "family",
+			Hash:     auth.HashRefreshToken(token),
+		}
+
+		mockRepo := new(MockAuthRepository)
+		mockRepo.On("RevokeToken", "jti", mock.Anything).Return(nil)
+		mockRepo.On("GetRefreshToken", rt.Hash).Return(rt, nil)
+		mockRepo.On("RevokeRefreshTokenFamily", rt.FamilyID).Return(nil)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.AUTH_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		req, _ := http.NewRequest("POST", "/logout", bytes.NewBuffer(reqJSON))
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Set("id", 1)
+		c.Set("jti", "jti")
+
+		Logout{}.Do(c)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, c.Errors)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ForeignRefreshToken", func(t *testing.T) {
+		token := "plain-refresh-token"
+		reqJSON, _ := json.Marshal(refreshRequest{RefreshToken: token})
+		rt := auth.RefreshToken{
+			ID:       1,
+			ClientID: 2,
+			FamilyID: "family",
+			Hash:     auth.HashRefreshToken(token),
+		}
+
+		mockRepo := new(MockAuthRepository)
+		mockRepo.On("RevokeToken", "jti", mock.Anything).Return(nil)
+		mockRepo.On("GetRefreshToken", rt.Hash).Return(rt, nil)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.AUTH_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		req, _ := http.NewRequest("POST", "/logout", bytes.NewBuffer(reqJSON))
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Set("id", 1)
+		c.Set("jti", "jti")
+
+		Logout{}.Do(c)
+
+		assert.NotEmpty(t, c.Errors)
+		mockRepo.AssertNotCalled(t, "RevokeRefreshTokenFamily", mock.Anything)
+	})
+}
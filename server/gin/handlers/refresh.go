@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// refreshRequest represents the body expected by the refresh and logout handlers.
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Refresh represents the handler responsible for exchanging a refresh token for new tokens.
+type Refresh struct{}
+
+// Do performs the token refresh. It reads the refresh token from the request, checks it
+// against the authentication repository, marks it as used and responds with a new access
+// token and a new refresh token of the same family.
+// If an already used refresh token is presented, the whole family is revoked.
+func (r Refresh) Do(c *gin.Context) {
+	// Read the refresh token from the request data
+	req, ok := r.readRefreshToken(c)
+	if !ok {
+		return
+	}
+
+	// Get the authentication repository to perform database operations
+	repo, ok := getAuthRepository(c)
+	if !ok {
+		return
+	}
+
+	// Search for the refresh token in the database
+	rt, ok := r.getRefreshTokenFromDB(c, repo, req.RefreshToken)
+	if !ok {
+		return
+	}
+
+	// Mark the refresh token as used, detecting if it was already used
+	ok = r.rotateRefreshToken(c, repo, rt)
+	if !ok {
+		return
+	}
+
+	// Generate new tokens within the same refresh token family
+	token, refreshToken, ok := generateTokens(c, repo, rt.ClientID, rt.FamilyID)
+	if !ok {
+		return
+	}
+
+	// Respond with the generated tokens
+	c.JSON(http.StatusOK, gin.H{
+		"token":         token,
+		"refresh_token": refreshToken,
+	})
+}
+
+// readRefreshToken reads and parses the refresh token from the request data.
+func (r Refresh) readRefreshToken(c *gin.Context) (req refreshRequest, ok bool) {
+	ok = readRequestData(c, &req)
+	if !ok {
+		return
+	}
+	if req.RefreshToken == "" {
+		ok = false
+		handleError(c, errors.NewHTTPError(http.StatusUnauthorized, errors.UNAUTHORIZED_ERROR_MESSAGE))
+	}
+	return
+}
+
+// getRefreshTokenFromDB searches for the refresh token in the database by its hash.
+// If the token is unknown, it returns an unauthorized error and false.
+func (r Refresh) getRefreshTokenFromDB(c *gin.Context, repo database.AuthRepository, token string) (rt auth.RefreshToken, ok bool) {
+	rt, err := repo.GetRefreshToken(c.Request.Context(), auth.HashRefreshToken(token))
+	if err != nil {
+		handleError(c, errors.NewHTTPError(http.StatusUnauthorized, errors.UNAUTHORIZED_ERROR_MESSAGE))
+		return
+	}
+	ok = true
+	return
+}
+
+// rotateRefreshToken marks the refresh token as used so it can't be presented again.
+// If the token was already used, the whole family is revoked, as the token is considered leaked.
+// Expired tokens are rejected with an unauthorized error.
+func (r Refresh) rotateRefreshToken(c *gin.Context, repo database.AuthRepository, rt auth.RefreshToken) (ok bool) {
+	if rt.IsRevoked() {
+		r.revokeFamily(c, repo, rt.FamilyID)
+		return
+	}
+
+	if rt.IsExpired(time.Now()) {
+		handleError(c, errors.NewHTTPError(http.StatusUnauthorized, errors.UNAUTHORIZED_ERROR_MESSAGE))
+		return
+	}
+
+	err := repo.RevokeRefreshToken(c.Request.Context(), rt.ID)
+	if err != nil {
+		// The token was used concurrently by another request
+		r.revokeFamily(c, repo, rt.FamilyID)
+		return
+	}
+	ok = true
+	return
+}
+
+// revokeFamily revokes every refresh token of the family and responds with an unauthorized error.
+func (r Refresh) revokeFamily(c *gin.Context, repo database.AuthRepository, familyID string) {
+	err := repo.RevokeRefreshTokenFamily(c.Request.Context(), familyID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	handleError(c, errors.NewHTTPError(http.StatusUnauthorized, errors.UNAUTHORIZED_ERROR_MESSAGE))
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRefresh_Do(t *testing.T) {
+	token := "plain-refresh-token"
+	reqJSON, _ := json.Marshal(refreshRequest{RefreshToken: token})
+
+	t.Run("Success", func(t *testing.T) {
+		rt := auth.RefreshToken{
+			ID:        1,
+			ClientID:  1,
+			FamilyID:  "family",
+			Hash:      auth.HashRefreshToken(token),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+
+		mockRepo := new(MockAuthRepository)
+		mockRepo.On("GetRefreshToken", rt.Hash).Return(rt, nil)
+		mockRepo.On("RevokeRefreshToken", rt.ID).Return(nil)
+		mockRepo.On("GetRole", rt.ClientID).Return(auth.CLIENT_ROLE, nil)
+		mockRepo.On("SaveRefreshToken", mock.MatchedBy(func(newRT auth.RefreshToken) bool {
+			return newRT.FamilyID == rt.FamilyID && newRT.ClientID == rt.ClientID && newRT.Hash != rt.Hash
+		})).Return(nil)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.AUTH_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		r := gin.New()
+		r.POST("/refresh", Refresh{}.Do)
+
+		req, _ := http.NewRequest("POST", "/refresh", bytes.NewBuffer(reqJSON))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var responseBody map[string]string
+		err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, responseBody["token"])
+		assert.NotEmpty(t, responseBody["refresh_token"])
+		assert.NotEqual(t, token, responseBody["refresh_token"])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ReuseDetected", func(t *testing.T) {
+		revokedAt := time.Now()
+		rt := auth.RefreshToken{
+			ID:        1,
+			ClientID:  1,
+			FamilyID:  "family",
+			Hash:      auth.HashRefreshToken(token),
+			ExpiresAt: time.Now().Add(time.Hour),
+			RevokedAt: &revokedAt,
+		}
+
+		mockRepo := new(MockAuthRepository)
+		mockRepo.On("GetRefreshToken", rt.Hash).Return(rt, nil)
+		mockRepo.On("RevokeRefreshTokenFamily", rt.FamilyID).Return(nil)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.AUTH_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		req, _ := http.NewRequest("POST", "/refresh", bytes.NewBuffer(reqJSON))
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		Refresh{}.Do(c)
+
+		assert.Empty(t, rec.Body)
+		assert.NotEmpty(t, c.Errors)
+		mockRepo.AssertCalled(t, "RevokeRefreshTokenFamily", rt.FamilyID)
+		mockRepo.AssertNotCalled(t, "SaveRefreshToken", mock.Anything)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		rt := auth.RefreshToken{
+			ID:        1,
+			ClientID:  1,
+			FamilyID:  "family",
+			Hash:      auth.HashRefreshToken(token),
+			ExpiresAt: time.Now().Add(-time.Hour),
+		}
+
+		mockRepo := new(MockAuthRepository)
+		mockRepo.On("GetRefreshToken", rt.Hash).Return(rt, nil)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.AUTH_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		req, _ := http.NewRequest("POST", "/refresh", bytes.NewBuffer(reqJSON))
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		Refresh{}.Do(c)
+
+		assert.Empty(t, rec.Body)
+		assert.NotEmpty(t, c.Errors)
+		mockRepo.AssertNotCalled(t, "RevokeRefreshToken", mock.Anything)
+	})
+
+	t.Run("UnknownToken", func(t *testing.T) {
+		mockRepo := new(MockAuthRepository)
+		mockRepo.On("GetRefreshToken", mock.Anything).Return(auth.RefreshToken{}, errors.New("not found"))
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.AUTH_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		req, _ := http.NewRequest("POST", "/refresh", bytes.NewBuffer(reqJSON))
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		Refresh{}.Do(c)
+
+		assert.Empty(t, rec.Body)
+		assert.NotEmpty(t, c.Errors)
+	})
+}
@@ -3,7 +3,6 @@ package handlers
 import (
 	"net/http"
 
-	"github.com/coffemanfp/docucentertest/auth"
 	"github.com/coffemanfp/docucentertest/client"
 	"github.com/coffemanfp/docucentertest/database"
 	"github.com/coffemanfp/docucentertest/server/errors"
@@ -14,8 +13,8 @@ import (
 type Register struct{}
 
 // Do performs the registration process. It reads the new client's data from the request,
-// creates a new client instance, registers the client in the database, generates an authentication token,
-// and responds with the generated token.
+// creates a new client instance, registers the client in the database, generates an authentication token
+// and a refresh token, and responds with the generated tokens.
 func (r Register) Do(c *gin.Context) {
 	// Read the new client's data from the request
 	client, ok := r.readClient(c)
@@ -41,15 +40,16 @@ func (r Register) Do(c *gin.Context) {
 		return
 	}
 
-	// Generate an authentication token using the generated client ID
-	token, ok := r.generateToken(c, id)
+	// Generate an authentication token and a refresh token using the generated client ID
+	token, refreshToken, ok := r.generateToken(c, repo, id)
 	if !ok {
 		return
 	}
 
-	// Respond with the generated token and a status indicating successful account creation
+	// Respond with the generated tokens and a status indicating successful account creation
 	c.JSON(http.StatusCreated, gin.H{
-		"token": token,
+		"token":         token,
+		"refresh_token": refreshToken,
 	})
 }
 
@@ -78,7 +78,7 @@ func (r Register) getAuthRepository(c *gin.Context) (repo database.AuthRepositor
 
 // registerClientInDB registers the new client in the database and retrieves the assigned ID.
 func (r Register) registerClientInDB(c *gin.Context, client client.Client, repo database.AuthRepository) (id int, ok bool) {
-	id, err := repo.Register(client)
+	id, err := repo.Register(c.Request.Context(), client)
 	if err != nil {
 		handleError(c, err)
 		return
@@ -87,13 +87,7 @@ func (r Register) registerClientInDB(c *gin.Context, client client.Client, repo
 	return
 }
 
-// generateToken generates an authentication token for the registered client's ID.
-func (r Register) generateToken(c *gin.Context, id int) (token string, ok bool) {
-	token, err := auth.GenerateToken(id, conf.Server.JWTLifespan, conf.Server.SecretKey)
-	if err != nil {
-		handleError(c, err)
-		return
-	}
-	ok = true
-	return
+// generateToken generates an authentication token and a refresh token for the registered client's ID.
+func (r Register) generateToken(c *gin.Context, repo database.AuthRepository, id int) (token, refreshToken string, ok bool) {
+	return generateTokens(c, repo, id, "")
 }
@@ -36,6 +36,8 @@ func TestRegister_Do(t *testing.T) {
 		// Create a mock authentication repository
 		mockRepo := new(MockAuthRepository)
 		mockRepo.On("Register", mock.Anything).Return(1, nil)
+		mockRepo.On("GetRole", 1).Return(auth.CLIENT_ROLE, nil)
+		mockRepo.On("SaveRefreshToken", mock.Anything).Return(nil)
 
 		// Set up the handler and execute the action
 		register := Register{}
@@ -46,7 +48,7 @@ func TestRegister_Do(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		r := gin.New()
 		r.POST("/register", register.Do)
 
@@ -62,8 +64,9 @@ func TestRegister_Do(t *testing.T) {
 		err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
 		assert.NoError(t, err)
 
-		// Assert the generated token is present in the response
-		assert.NotNil(t, responseBody["token"])
+		// Assert the generated tokens are present in the response
+		assert.NotEmpty(t, responseBody["token"])
+		assert.NotEmpty(t, responseBody["refresh_token"])
 	})
 
 	t.Run("InvalidData", func(t *testing.T) {
@@ -92,7 +95,7 @@ func TestRegister_Do(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		r := gin.New()
 		r.POST("/register", register.Do)
 
@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/coffemanfp/docucentertest/database"
 	"github.com/coffemanfp/docucentertest/product"
 	"github.com/coffemanfp/docucentertest/search"
+	"github.com/coffemanfp/docucentertest/server/errors"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,11 +17,30 @@ type Search struct{}
 // Do performs the product search based on the provided search parameters.
 func (s Search) Do(c *gin.Context) {
 	// Read the search parameters from the request
-	srch, ok := s.readSearch(c)
+	srch, _, ok := s.readSearch(c)
 	if !ok {
 		return
 	}
 
+	// Read the aggregations requested alongside the products
+	facets, ok := readFacets(c)
+	if !ok {
+		return
+	}
+
+	// Respond with the page of products matching the search
+	s.respond(c, srch, facets)
+}
+
+// respond searches the page of products matching srch, and responds with them and their discounts.
+// If any facets are requested, they are counted for every product matching srch and sent along with the page.
+func (s Search) respond(c *gin.Context, srch search.Search, facets []search.Facet) {
+	// The relevance of the products is not part of the cursors, so a ranked search can only be paginated with pages
+	if srch.Ranked() && isCursorPagination(c) {
+		handleError(c, errors.NewHTTPError(http.StatusBadRequest, "cursor param requires a sort param when searching with the q param"))
+		return
+	}
+
 	// Get the product repository
 	repo, ok := getProductRepository(c)
 	if !ok {
@@ -27,75 +48,160 @@ func (s Search) Do(c *gin.Context) {
 	}
 
 	// Perform the product search in the database
-	ps, ok := s.searchOnDB(c, repo, srch)
+	ps, total, ok := s.searchOnDB(c, repo, srch)
+	if !ok {
+		return
+	}
+
+	// Build the pricing rules engine
+	engine, ok := getPricingEngine(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the prices locked by the quotes of the search results
+	locked, ok := getLockedPricings(c, ps)
 	if !ok {
 		return
 	}
 
 	// Apply discount calculation to the search results
-	ps = s.generateDiscount(ps)
+	ps = s.generateDiscount(engine, locked, ps)
+
+	// Count the facets of every search result, regardless of the page
+	results, ok := s.facetsOnDB(c, repo, srch, facets)
+	if !ok {
+		return
+	}
 
-	// Respond with the search results
-	c.JSON(http.StatusOK, ps)
+	// Respond with the page of search results
+	writeProductPage(c, srch.Pagination, total, ps, results)
 }
-func (s Search) readSearch(c *gin.Context) (srch search.Search, ok bool) {
+
+// readSearch reads the search of the request from its query parameters.
+// It returns the search along with the criteria it was created from, which can be stored to run it again.
+func (s Search) readSearch(c *gin.Context) (srch search.Search, criteria search.Criteria, ok bool) {
 	// Read search parameters from query string
-	guideNumber := c.Query("guideNumber")
-	vehiclePlate := c.Query("vehiclePlate")
-	productType := c.Query("type")
-	startJoinedAt := c.Query("startJoinedAt")
-	endJoinedAt := c.Query("endJoinedAt")
-	startDeliveredAt := c.Query("startDeliveredAt")
-	endDeliveredAt := c.Query("endDeliveredAt")
-	clientID := c.GetInt("id")
-
-	// Read port and vault parameters from URL
-	port, ok := readIntFromURL(c, "port", true)
+	criteria = search.Criteria{
+		GuideNumber:      c.Query("guideNumber"),
+		VehiclePlate:     c.Query("vehiclePlate"),
+		Type:             c.Query("type"),
+		Port:             c.Query("port"),
+		Vault:            c.Query("vault"),
+		Status:           c.Query("status"),
+		StartJoinedAt:    c.Query("startJoinedAt"),
+		EndJoinedAt:      c.Query("endJoinedAt"),
+		StartDeliveredAt: c.Query("startDeliveredAt"),
+		EndDeliveredAt:   c.Query("endDeliveredAt"),
+		Joined:           c.Query("joined"),
+		Delivered:        c.Query("delivered"),
+		Query:            c.Query("q"),
+	}
+
+	// Read the client ID the search is restricted to
+	clientID, ok := readClientScope(c)
 	if !ok {
 		return
 	}
-	vault, ok := readIntFromURL(c, "vault", true)
+
+	// Read the page of results to retrieve
+	pagination, ok := readPagination(c, search.NewProductSort, true)
 	if !ok {
 		return
 	}
 
 	// Read price range parameters from URL
-	startPrice, ok := readFloatFromURL(c, "startPrice", true)
+	criteria.StartPrice, ok = readFloatFromURL(c, "startPrice", true)
 	if !ok {
 		return
 	}
-	endPrice, ok := readFloatFromURL(c, "endPrice", true)
+	criteria.EndPrice, ok = readFloatFromURL(c, "endPrice", true)
 	if !ok {
 		return
 	}
 
 	// Read quantity range parameters from URL
-	startQuantity, ok := readIntFromURL(c, "startQuantity", true)
+	criteria.StartQuantity, ok = readIntFromURL(c, "startQuantity", true)
 	if !ok {
 		return
 	}
-	endQuantity, ok := readIntFromURL(c, "endQuantity", true)
+	criteria.EndQuantity, ok = readIntFromURL(c, "endQuantity", true)
 	if !ok {
 		return
 	}
 
 	// Create a new Search object based on the collected parameters
-	srch, err := search.New(clientID, port, vault, guideNumber, productType, vehiclePlate, startPrice, endPrice, startQuantity,
-		endQuantity, startJoinedAt, endJoinedAt, startDeliveredAt, endDeliveredAt)
+	srch, ok = readCriteria(c, criteria, clientID)
+	if !ok {
+		return
+	}
+	srch.Pagination = pagination
+
+	// Return the constructed search object and the status of the operation
+	return
+}
+
+// readCriteria validates the search criteria and creates the search of the matching products of a client.
+// If they are invalid, it handles the error and returns ok as false.
+func readCriteria(c *gin.Context, criteria search.Criteria, clientID int) (srch search.Search, ok bool) {
+	srch, err := criteria.Search(clientID)
 	if err != nil {
-		// Handle errors by aborting the request and sending an error response
+		// Reject the invalid search criteria by aborting the request and sending an error response
+		err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 		handleError(c, err)
 		return
 	}
+	ok = true
+	return
+}
 
-	// Return the constructed search object and the status of the operation
+// readQuery normalizes the full-text query q of a search.
+// If it is invalid, it handles the error and returns ok as false.
+func readQuery(c *gin.Context, q string) (query string, ok bool) {
+	query, err := search.NewQuery(q)
+	if err != nil {
+		err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
+// readFacets reads the aggregations requested by the optional "facets" query parameter.
+// If it is invalid, it handles the error and returns ok as false.
+func readFacets(c *gin.Context) (facets []search.Facet, ok bool) {
+	facets, err := search.NewFacets(c.Query("facets"))
+	if err != nil {
+		err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
+func (s Search) facetsOnDB(c *gin.Context, repo database.ProductRepository, srch search.Search, facets []search.Facet) (results map[string]search.FacetResult, ok bool) {
+	// Nothing is counted if no facets were requested
+	if len(facets) == 0 {
+		ok = true
+		return
+	}
+
+	// Count the products matching the search criteria per value of every facet
+	results, err := repo.Facets(c.Request.Context(), srch, facets)
+	if err != nil {
+		// Handle errors by aborting the request and sending an error response
+		handleError(c, err)
+		return
+	}
 	ok = true
 	return
 }
 
-func (s Search) searchOnDB(c *gin.Context, repo database.ProductRepository, srch search.Search) (ps []*product.Product, ok bool) {
-	// Search for products in the database based on the given search criteria
-	ps, err := repo.Search(srch)
+func (s Search) searchOnDB(c *gin.Context, repo database.ProductRepository, srch search.Search) (ps []*product.Product, total int, ok bool) {
+	// Search for a page of products in the database based on the given search criteria, counting every match
+	ps, total, err := repo.Search(c.Request.Context(), srch)
 	if err != nil {
 		// Handle errors by aborting the request and sending an error response
 		handleError(c, err)
@@ -105,20 +211,16 @@ func (s Search) searchOnDB(c *gin.Context, repo database.ProductRepository, srch
 	return
 }
 
-func (s Search) generateDiscount(psR []*product.Product) (ps []*product.Product) {
+func (s Search) generateDiscount(engine product.RulesEngine, locked map[int]product.Pricing, psR []*product.Product) (ps []*product.Product) {
 	// Create a discount generator for each product and update the discount values
 	ps = make([]*product.Product, len(psR))
+	now := time.Now()
 	for i, p := range psR {
-		var vault, port int
-		if p.Vault != nil {
-			vault = *p.Vault
-		}
-		if p.Port != nil {
-			port = *p.Port
-		}
-		// Create a new discount generator and calculate the discount
-		discountGenerator := product.NewDiscountGenerator(vault, port, *p.Quantity, *p.ShippingPrice)
-		p.Discount = discountGenerator.Generate()
+		// Create a new discount generator and calculate the itemised price and discount
+		discountGenerator := newDiscountGenerator(engine, locked, *p, now)
+		pricing := discountGenerator.Pricing()
+		p.Discount = pricing.Discount
+		p.Pricing = &pricing
 		ps[i] = p
 	}
 	return
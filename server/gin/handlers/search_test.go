@@ -7,10 +7,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/coffemanfp/docucentertest/config"
 	"github.com/coffemanfp/docucentertest/database"
 	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/search"
+	sErrors "github.com/coffemanfp/docucentertest/server/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -43,7 +46,7 @@ func TestSearch_Do(t *testing.T) {
 		}
 
 		mockRepo := new(MockProductRepository)
-		mockRepo.On("Search", mock.Anything).Return([]*product.Product{mockProducts[0]}, nil)
+		mockRepo.On("Search", mock.Anything).Return([]*product.Product{mockProducts[0]}, 1, nil)
 
 		// Create a mock context with a request parameter
 		req, _ := http.NewRequest("GET", "/path?guideNumber=ASD234ASD5", nil)
@@ -54,10 +57,11 @@ func TestSearch_Do(t *testing.T) {
 		db := database.Database{
 			Repositories: map[database.RepositoryID]interface{}{
 				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		// Set up the handler and execute the action
 		gc := Search{}
 		gc.Do(c)
@@ -65,12 +69,224 @@ func TestSearch_Do(t *testing.T) {
 		// Assert the HTTP status code
 		assert.Equal(t, http.StatusOK, rec.Code)
 
-		// Decode the response body
-		var responseProducts []*product.Product
-		err := json.Unmarshal(rec.Body.Bytes(), &responseProducts)
+		// Decode the response body, always wrapped in a page
+		var page productPage
+		err := json.Unmarshal(rec.Body.Bytes(), &page)
 		assert.NoError(t, err)
 
-		// Compare the responseProduct with the mockProduct
-		assert.Equal(t, []*product.Product{mockProducts[0]}, responseProducts)
+		// Compare the products of the page with the mockProduct
+		assert.Equal(t, []*product.Product{mockProducts[0]}, page.Products)
+		assert.Empty(t, page.NextCursor)
+		assert.Empty(t, page.Facets)
+	})
+
+	t.Run("RichFilters", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Search", mock.MatchedBy(func(srch search.Search) bool {
+			return assert.ObjectsAreEqual(search.StringFilter{Prefixes: []string{"ABC"}}, srch.GuideNumber) &&
+				assert.ObjectsAreEqual(search.StringFilter{Values: []string{"box", "pallet"}}, srch.Type) &&
+				assert.ObjectsAreEqual(search.IntFilter{Values: []int{1, 2}, Not: true}, srch.Port) &&
+				assert.ObjectsAreEqual(search.IntFilter{Missing: true}, srch.Vault)
+		})).Return([]*product.Product{}, 0, nil)
+
+		req, _ := http.NewRequest("GET", "/path?guideNumber=ABC*&type=box,pallet&port=!1,2&vault=none", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		gc := Search{}
+		gc.Do(c)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("DatePeriods", func(t *testing.T) {
+		// The joined period spans the whole month, and the delivered range is only closed at the end of its day
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Search", mock.MatchedBy(func(srch search.Search) bool {
+			return srch.JoinedAtRange.Start.Equal(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)) &&
+				srch.JoinedAtRange.End.Equal(time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)) && srch.JoinedAtRange.EndExcluded &&
+				srch.DeliveredAtRange.Start.IsZero() &&
+				srch.DeliveredAtRange.End.Equal(time.Date(2024, time.April, 3, 0, 0, 0, 0, time.UTC)) && srch.DeliveredAtRange.EndExcluded
+		})).Return([]*product.Product{}, 0, nil)
+
+		req, _ := http.NewRequest("GET", "/path?joined=2024-03&endDeliveredAt=2024-04-02", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		gc := Search{}
+		gc.Do(c)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidFilter", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+
+		// Types can only be matched by value, so the prefix is rejected before searching
+		req, _ := http.NewRequest("GET", "/path?type=bo*", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		gc := Search{}
+		gc.Do(c)
+
+		assert.NotEmpty(t, c.Errors)
+		httpErr, ok := c.Errors[0].Err.(sErrors.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+		mockRepo.AssertNotCalled(t, "Search", mock.Anything)
+	})
+
+	t.Run("FullTextQuery", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Search", mock.MatchedBy(func(srch search.Search) bool {
+			return srch.Query == "abc-12 pallet" && srch.Type.Values[0] == "pallet"
+		})).Return([]*product.Product{}, 0, nil)
+
+		req, _ := http.NewRequest("GET", "/path?q=ABC-12%20Pallet&type=pallet", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		Search{}.Do(c)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("RankedCursor", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+
+		// The relevance can not be paginated with a cursor, only an explicit sort can
+		req, _ := http.NewRequest("GET", "/path?q=pallet&cursor=", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		Search{}.Do(c)
+
+		assert.NotEmpty(t, c.Errors)
+		httpErr, ok := c.Errors[0].Err.(sErrors.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		mockRepo.AssertNotCalled(t, "Search", mock.Anything)
+	})
+
+	t.Run("Facets", func(t *testing.T) {
+		mockProducts := []*product.Product{{ID: 3, ClientID: 1, Type: newString("box")}}
+		facets := map[string]search.FacetResult{
+			"type":           {Buckets: []search.FacetBucket{{Value: "box", Count: 4}}},
+			"shipping_price": {Buckets: []search.FacetBucket{{Value: 0.0, Count: 4}}, Interval: 50},
+		}
+
+		// The facets are counted for the same criteria as the page, and sent along with it
+		srch := search.Search{ClientID: 1, Type: search.StringFilter{Values: []string{"box"}}, Pagination: search.Pagination{Limit: 1}}
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("Search", srch).Return(mockProducts, 4, nil)
+		mockRepo.On("Facets", srch, []search.Facet{{Field: "type"}, {Field: "shipping_price", Interval: 50}}).Return(facets, nil)
+
+		req, _ := http.NewRequest("GET", "/path?type=box&page_size=1&facets=type,shipping_price:50", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Set("id", 1)
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+				database.PRICING_REPOSITORY: newMockPricingRepository(),
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		Search{}.Do(c)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "4", rec.Header().Get("X-Total-Count"))
+
+		var page struct {
+			Products []*product.Product `json:"products"`
+			Facets   map[string]struct {
+				Buckets []struct {
+					Value interface{} `json:"value"`
+					Count int         `json:"count"`
+				} `json:"buckets"`
+				Interval float64 `json:"interval"`
+			} `json:"facets"`
+		}
+		err := json.Unmarshal(rec.Body.Bytes(), &page)
+		assert.NoError(t, err)
+		assert.Len(t, page.Products, 1)
+		assert.Equal(t, "box", page.Facets["type"].Buckets[0].Value)
+		assert.Equal(t, 4, page.Facets["type"].Buckets[0].Count)
+		assert.Equal(t, 50.0, page.Facets["shipping_price"].Interval)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidFacets", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+
+		req, _ := http.NewRequest("GET", "/path?facets=type:10", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		Search{}.Do(c)
+
+		assert.NotEmpty(t, c.Errors)
+		httpErr, ok := c.Errors[0].Err.(sErrors.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+		mockRepo.AssertNotCalled(t, "Search", mock.Anything)
 	})
 }
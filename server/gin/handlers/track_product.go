@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/coffemanfp/docucentertest/tracking"
+	"github.com/gin-gonic/gin"
+)
+
+// TrackProduct represents the action of publicly tracking a shipment by its guide number.
+type TrackProduct struct{}
+
+// Do is a method of the TrackProduct struct that retrieves a product and its tracking timeline by guide number
+// and sends a redacted view of them as a JSON response, without price, client ID or vehicle plates.
+func (tp TrackProduct) Do(c *gin.Context) {
+	// Read the guide number from the request.
+	guideNumber, ok := tp.readGuideNumber(c)
+	if !ok {
+		return
+	}
+
+	// Get the product repository.
+	productRepo, ok := getProductRepository(c)
+	if !ok {
+		return
+	}
+
+	// Get the tracking repository.
+	trackingRepo, ok := getTrackingRepository(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the product from the database.
+	p, ok := tp.getProductFromDB(c, productRepo, guideNumber)
+	if !ok {
+		return
+	}
+
+	// Retrieve the tracking events of the product from the database.
+	es, ok := tp.getEventsFromDB(c, trackingRepo, p.ID)
+	if !ok {
+		return
+	}
+
+	// Return the redacted tracking view as JSON response.
+	c.JSON(http.StatusOK, tracking.NewPublicView(p, es))
+}
+
+// readGuideNumber is a method of the TrackProduct struct that reads and validates the guide number from the URL parameter.
+func (tp TrackProduct) readGuideNumber(c *gin.Context) (guideNumber string, ok bool) {
+	guideNumber = c.Param("guideNumber")
+	err := product.ValidateGuideNumber(&guideNumber)
+	if err != nil {
+		err = errors.NewHTTPError(http.StatusBadRequest, err.Error())
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
+// getProductFromDB is a method of the TrackProduct struct that retrieves a product by its guide number from the database.
+func (tp TrackProduct) getProductFromDB(c *gin.Context, repo database.ProductRepository, guideNumber string) (p product.Product, ok bool) {
+	p, err := repo.GetByGuideNumber(c.Request.Context(), guideNumber)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
+// getEventsFromDB is a method of the TrackProduct struct that retrieves the tracking events of a product from the database.
+func (tp TrackProduct) getEventsFromDB(c *gin.Context, repo database.TrackingRepository, id int) (es []*tracking.Event, ok bool) {
+	// The lookup is public, so the events are not restricted to any client.
+	es, err := repo.Get(c.Request.Context(), id, database.ANY_CLIENT)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/tracking"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTrackProduct_Do(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Create a mock product and events with sample data
+		mockProduct := product.Product{
+			ID:            3,
+			ClientID:      1,
+			GuideNumber:   newString("ABC1234567"),
+			Type:          newString("Electronics"),
+			VehiclePlate:  newString("ABC-123"),
+			Port:          newInt(2),
+			Quantity:      newInt(5),
+			ShippingPrice: newFloat64(123.12),
+			Status:        product.AT_PORT_STATUS,
+		}
+		mockEvents := []*tracking.Event{
+			{
+				ID:           1,
+				ProductID:    3,
+				RecordedAt:   time.Now().UTC(),
+				Port:         newInt(2),
+				VehiclePlate: newString("ABC-123"),
+				Note:         "Arrived at port",
+				RecordedBy:   1,
+			},
+		}
+
+		mockProductRepo := new(MockProductRepository)
+		mockProductRepo.On("GetByGuideNumber", "ABC1234567").Return(mockProduct, nil)
+		mockTrackingRepo := new(MockTrackingRepository)
+		mockTrackingRepo.On("Get", 3, database.ANY_CLIENT).Return(mockEvents, nil)
+
+		req, _ := http.NewRequest("GET", "/path/ABC1234567", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Params = []gin.Param{{Key: "guideNumber", Value: "ABC1234567"}}
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY:  mockProductRepo,
+				database.TRACKING_REPOSITORY: mockTrackingRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		TrackProduct{}.Do(c)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var view tracking.PublicView
+		err := json.Unmarshal(rec.Body.Bytes(), &view)
+		assert.NoError(t, err)
+		assert.Equal(t, tracking.NewPublicView(mockProduct, mockEvents).Events[0].Note, view.Events[0].Note)
+		assert.Equal(t, product.AT_PORT_STATUS, view.Status)
+		assert.NotContains(t, rec.Body.String(), "shipping_price")
+		assert.NotContains(t, rec.Body.String(), "client_id")
+		assert.NotContains(t, rec.Body.String(), "ABC-123")
+	})
+
+	t.Run("InvalidGuideNumber", func(t *testing.T) {
+		mockProductRepo := new(MockProductRepository)
+
+		req, _ := http.NewRequest("GET", "/path/short", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Params = []gin.Param{{Key: "guideNumber", Value: "short"}}
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY:  mockProductRepo,
+				database.TRACKING_REPOSITORY: new(MockTrackingRepository),
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		TrackProduct{}.Do(c)
+
+		assert.Empty(t, rec.Body)
+		assert.NotEmpty(t, c.Errors)
+		assert.Contains(t, c.Errors[0].Error(), "invalid guide number")
+		mockProductRepo.AssertNotCalled(t, "GetByGuideNumber", mock.Anything)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockProductRepo := new(MockProductRepository)
+		mockProductRepo.On("GetByGuideNumber", "ABC1234567").Return(product.Product{}, errors.New("not found"))
+
+		req, _ := http.NewRequest("GET", "/path/ABC1234567", nil)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Params = []gin.Param{{Key: "guideNumber", Value: "ABC1234567"}}
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY:  mockProductRepo,
+				database.TRACKING_REPOSITORY: new(MockTrackingRepository),
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		TrackProduct{}.Do(c)
+
+		assert.Empty(t, rec.Body)
+		assert.NotEmpty(t, c.Errors)
+		assert.Contains(t, c.Errors[0].Error(), "not found")
+	})
+}
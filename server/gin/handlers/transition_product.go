@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// TransitionProduct represents the action of moving a product to another lifecycle status.
+type TransitionProduct struct{}
+
+// Do is a method of the TransitionProduct struct that moves a product to the requested status.
+// It reads the product ID and the target status from the request, checks that the move is allowed
+// by the product lifecycle and saves the new status in the database.
+// Illegal moves are rejected with a 409 Conflict status.
+func (tp TransitionProduct) Do(c *gin.Context) {
+	// Read the product ID from the request.
+	id, ok := tp.readProductID(c)
+	if !ok {
+		return
+	}
+
+	// Read the target status from the request.
+	to, ok := tp.readStatus(c)
+	if !ok {
+		return
+	}
+
+	// Read the client ID the request is restricted to.
+	clientID, ok := readClientScope(c)
+	if !ok {
+		return
+	}
+
+	// Get the product repository.
+	repo, ok := getProductRepository(c)
+	if !ok {
+		return
+	}
+
+	// Retrieve the product to know its current status.
+	p, ok := tp.getProductFromDB(c, repo, id, clientID)
+	if !ok {
+		return
+	}
+
+	// Check that the product can move to the target status.
+	ok = tp.validateTransition(c, p.Status, to)
+	if !ok {
+		return
+	}
+
+	// Save the new status in the database.
+	ok = tp.updateStatusInDB(c, repo, p, to)
+	if !ok {
+		return
+	}
+
+	// Respond with the product in its new status.
+	p.Status = to
+	c.JSON(http.StatusOK, p)
+}
+
+// readProductID is a method of the TransitionProduct struct that reads the product ID from the URL parameter.
+func (tp TransitionProduct) readProductID(c *gin.Context) (id int, ok bool) {
+	return readIntFromURL(c, "id", false)
+}
+
+// readStatus is a method of the TransitionProduct struct that reads the target status from the request body.
+// Clients can only cancel their products, the other moves are reserved to the privileged roles.
+func (tp TransitionProduct) readStatus(c *gin.Context) (status product.Status, ok bool) {
+	var body struct {
+		Status product.Status `json:"status"`
+	}
+	ok = readRequestData(c, &body)
+	if !ok {
+		return
+	}
+	status = body.Status
+
+	err := product.ValidateStatus(status)
+	if err != nil {
+		ok = false
+		err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		handleError(c, err)
+		return
+	}
+
+	if !getRole(c).IsPrivileged() && status != product.CANCELLED_STATUS {
+		ok = false
+		err = errors.NewHTTPError(http.StatusForbidden, errors.FORBIDDEN_ERROR_MESSAGE)
+		handleError(c, err)
+	}
+	return
+}
+
+// getProductFromDB is a method of the TransitionProduct struct that retrieves the product from the database.
+func (tp TransitionProduct) getProductFromDB(c *gin.Context, repo database.ProductRepository, id, clientID int) (p product.Product, ok bool) {
+	p, err := repo.GetOne(c.Request.Context(), id, clientID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
+// validateTransition is a method of the TransitionProduct struct that checks the move against the product lifecycle.
+// If the move is illegal, it handles a 409 Conflict error and returns false.
+func (tp TransitionProduct) validateTransition(c *gin.Context, from, to product.Status) (ok bool) {
+	err := product.ValidateTransition(from, to)
+	if err != nil {
+		err = errors.NewHTTPError(http.StatusConflict, err.Error())
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
+
+// updateStatusInDB is a method of the TransitionProduct struct that saves the new status in the database.
+func (tp TransitionProduct) updateStatusInDB(c *gin.Context, repo database.ProductRepository, p product.Product, to product.Status) (ok bool) {
+	err := repo.UpdateStatus(c.Request.Context(), p.ID, p.ClientID, p.Status, to)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTransitionProduct_Do(t *testing.T) {
+	mockProduct := product.Product{
+		ID:          3,
+		ClientID:    1,
+		GuideNumber: newString("ABC1234567"),
+		Status:      product.REGISTERED_STATUS,
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetOne", 3, database.ANY_CLIENT).Return(mockProduct, nil)
+		mockRepo.On("UpdateStatus", 3, 1, product.REGISTERED_STATUS, product.IN_TRANSIT_STATUS).Return(nil)
+
+		bodyJSON, _ := json.Marshal(gin.H{"status": product.IN_TRANSIT_STATUS})
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		r := gin.New()
+		r.POST("/path/:id/transitions", setClient(2, auth.OPERATOR_ROLE), TransitionProduct{}.Do)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/path/3/transitions", bytes.NewBuffer(bodyJSON))
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var responseProduct product.Product
+		err := json.Unmarshal(rec.Body.Bytes(), &responseProduct)
+		assert.NoError(t, err)
+		assert.Equal(t, product.IN_TRANSIT_STATUS, responseProduct.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("IllegalTransition", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetOne", 3, database.ANY_CLIENT).Return(mockProduct, nil)
+
+		bodyJSON, _ := json.Marshal(gin.H{"status": product.DELIVERED_STATUS})
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		req, _ := http.NewRequest("POST", "/path/3/transitions", bytes.NewBuffer(bodyJSON))
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Params = []gin.Param{{Key: "id", Value: "3"}}
+		c.Set("id", 2)
+		c.Set("role", auth.ADMIN_ROLE)
+
+		TransitionProduct{}.Do(c)
+
+		if assert.NotEmpty(t, c.Errors) {
+			httpErr, ok := c.Errors[0].Err.(errors.HTTPError)
+			assert.True(t, ok)
+			assert.Equal(t, http.StatusConflict, httpErr.Code)
+		}
+		mockRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ClientCannotDispatch", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+
+		bodyJSON, _ := json.Marshal(gin.H{"status": product.IN_TRANSIT_STATUS})
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.PRODUCT_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		req, _ := http.NewRequest("POST", "/path/3/transitions", bytes.NewBuffer(bodyJSON))
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = req
+		c.Params = []gin.Param{{Key: "id", Value: "3"}}
+		c.Set("id", 1)
+		c.Set("role", auth.CLIENT_ROLE)
+
+		TransitionProduct{}.Do(c)
+
+		if assert.NotEmpty(t, c.Errors) {
+			httpErr, ok := c.Errors[0].Err.(errors.HTTPError)
+			assert.True(t, ok)
+			assert.Equal(t, http.StatusForbidden, httpErr.Code)
+		}
+		mockRepo.AssertNotCalled(t, "GetOne", mock.Anything, mock.Anything)
+	})
+}
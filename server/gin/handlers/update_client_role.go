@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateClientRole represents the action of changing the role of a client.
+type UpdateClientRole struct{}
+
+// Do is a method of the UpdateClientRole struct that changes the role of a client.
+// It reads the client ID from the URL and the new role from the request body, validates the role
+// and updates it in the database. The new role applies to the next token issued to the client.
+func (ucr UpdateClientRole) Do(c *gin.Context) {
+	// Read the client ID from the URL parameter.
+	id, ok := ucr.readClientID(c)
+	if !ok {
+		return
+	}
+
+	// Read and validate the new role from the request.
+	role, ok := ucr.readRole(c)
+	if !ok {
+		return
+	}
+
+	// Get the client repository.
+	repo, ok := getClientRepository(c)
+	if !ok {
+		return
+	}
+
+	// Update the role of the client in the database.
+	ok = ucr.updateRoleInDB(c, repo, id, role)
+	if !ok {
+		return
+	}
+
+	// Respond with a success status.
+	c.Status(http.StatusOK)
+}
+
+// readClientID is a method of the UpdateClientRole struct that reads the client ID from the URL parameter.
+func (ucr UpdateClientRole) readClientID(c *gin.Context) (id int, ok bool) {
+	return readIntFromURL(c, "id", false)
+}
+
+// readRole is a method of the UpdateClientRole struct that reads the new role from the request body and validates it.
+func (ucr UpdateClientRole) readRole(c *gin.Context) (role auth.Role, ok bool) {
+	var body struct {
+		Role auth.Role `json:"role"`
+	}
+	ok = readRequestData(c, &body)
+	if !ok {
+		return
+	}
+
+	err := auth.ValidateRole(body.Role)
+	if err != nil {
+		ok = false
+		err = errors.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		handleError(c, err)
+		return
+	}
+	role = body.Role
+	return
+}
+
+// updateRoleInDB is a method of the UpdateClientRole struct that updates the role of the client in the database.
+func (ucr UpdateClientRole) updateRoleInDB(c *gin.Context, repo database.ClientRepository, id int, role auth.Role) (ok bool) {
+	err := repo.UpdateRole(c.Request.Context(), id, role)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateClientRole_Do(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockClientRepository)
+		mockRepo.On("UpdateRole", 3, auth.OPERATOR_ROLE).Return(nil)
+
+		bodyJSON, _ := json.Marshal(gin.H{"role": auth.OPERATOR_ROLE})
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.CLIENT_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		r := gin.New()
+		r.PUT("/path/:id/role", UpdateClientRole{}.Do)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/path/3/role", bytes.NewBuffer(bodyJSON))
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidRole", func(t *testing.T) {
+		mockRepo := new(MockClientRepository)
+
+		bodyJSON, _ := json.Marshal(gin.H{"role": "root"})
+
+		db := database.Database{
+			Repositories: map[database.RepositoryID]interface{}{
+				database.CLIENT_REPOSITORY: mockRepo,
+			},
+		}
+
+		Init(db, config.ConfigInfo{})
+		r := gin.New()
+		r.PUT("/path/:id/role", UpdateClientRole{}.Do)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/path/3/role", bytes.NewBuffer(bodyJSON))
+		r.ServeHTTP(rec, req)
+
+		assert.Empty(t, rec.Body)
+		mockRepo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything)
+	})
+}
@@ -65,9 +65,8 @@ func (up UpdateProduct) updateProduct(c *gin.Context, id int, pr product.Product
 	}
 	// Assign the ID of the updated product
 	p.ID = id
-	// Assign the client ID from the context
-	p.ClientID = c.GetInt("id")
-	ok = true
+	// Assign the client ID the request is restricted to
+	p.ClientID, ok = readClientScope(c)
 	return
 }
 
@@ -78,7 +77,7 @@ func (up UpdateProduct) readProductID(c *gin.Context) (id int, ok bool) {
 
 func (up UpdateProduct) updateProductInDB(c *gin.Context, repo database.ProductRepository, p product.Product) (ok bool) {
 	// Update the product in the database using the provided data
-	err := repo.Update(p)
+	err := repo.Update(c.Request.Context(), p)
 	if err != nil {
 		// Handle the error and return a response
 		handleError(c, err)
@@ -35,7 +35,7 @@ func TestUpdateProduct_Do(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		r := gin.New()
 		r.PUT("/path/:id", ct.Do)
 
@@ -64,7 +64,7 @@ func TestUpdateProduct_Do(t *testing.T) {
 			},
 		}
 
-		Init(db.Repositories, config.ConfigInfo{})
+		Init(db, config.ConfigInfo{})
 		r := gin.New()
 		r.PUT("/path/3", ct.Do)
 
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/coffemanfp/docucentertest/database"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateSavedSearch is a struct that represents the action of replacing a saved search.
+type UpdateSavedSearch struct{}
+
+// Do replaces the name, criteria, schedule and webhook URL of a saved search with the ones of the request.
+// Its next run is scheduled again from the time of the request, and its runs and digests are kept.
+func (us UpdateSavedSearch) Do(c *gin.Context) {
+	// Read the saved search data from the request
+	var req savedSearchRequest
+	ok := readRequestData(c, &req)
+	if !ok {
+		return
+	}
+
+	// Read the saved search ID from the URL parameter, and the client ID the request is restricted to
+	id, clientID, ok := readSavedSearchID(c)
+	if !ok {
+		return
+	}
+
+	// Validate the saved search, only matching the ones of the client
+	s, ok := newSavedSearch(c, clientID, req)
+	if !ok {
+		return
+	}
+	s.ID = id
+
+	// Retrieve the saved search repository
+	repo, ok := getSavedSearchRepository(c)
+	if !ok {
+		return
+	}
+
+	// Update the saved search in the database
+	ok = us.updateSavedSearchInDB(c, repo, s)
+	if !ok {
+		return
+	}
+
+	// Respond with a success status
+	c.Status(http.StatusOK)
+}
+
+func (us UpdateSavedSearch) updateSavedSearchInDB(c *gin.Context, repo database.SavedSearchRepository, s savedsearch.SavedSearch) (ok bool) {
+	// Update the saved search in the database, a saved search of another client is not found
+	err := repo.Update(c.Request.Context(), s)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	ok = true
+	return
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/coffemanfp/docucentertest/auth"
+	"github.com/coffemanfp/docucentertest/savedsearch"
+	sErrors "github.com/coffemanfp/docucentertest/server/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateSavedSearch_Do(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Clients can not search the products of other clients, so the scope is forced to their own.
+		mockRepo := new(MockSavedSearchRepository)
+		mockRepo.On("Update", mock.MatchedBy(func(s savedsearch.SavedSearch) bool {
+			return s.ID == 3 && s.ClientID == 1 && s.ScopeID == 1 && s.Name == "Pallets" && s.Criteria.Type == "pallet" &&
+				s.NextRunAt != nil
+		})).Return(nil)
+
+		body := `{"name": "Pallets", "scope_id": 2, "criteria": {"type": "pallet"}, "schedule": "@daily"}`
+		c, rec := newSavedSearchContext("PUT", "/path/3", body, mockRepo, auth.CLIENT_ROLE)
+		UpdateSavedSearch{}.Do(c)
+
+		assert.Empty(t, c.Errors)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("OtherClient", func(t *testing.T) {
+		mockRepo := new(MockSavedSearchRepository)
+		mockRepo.On("Update", mock.MatchedBy(func(s savedsearch.SavedSearch) bool {
+			return s.ID == 3 && s.ClientID == 1
+		})).Return(errSavedSearchNotFound)
+
+		c, _ := newSavedSearchContext("PUT", "/path/3?client_id=2", `{"name": "Pallets", "criteria": {}}`, mockRepo, auth.CLIENT_ROLE)
+		UpdateSavedSearch{}.Do(c)
+
+		assertNotFound(t, c)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AdminScope", func(t *testing.T) {
+		// Admins update the saved searches of the client of the scope, searching the products of any client.
+		mockRepo := new(MockSavedSearchRepository)
+		mockRepo.On("Update", mock.MatchedBy(func(s savedsearch.SavedSearch) bool {
+			return s.ID == 3 && s.ClientID == 2 && s.ScopeID == 5
+		})).Return(nil)
+
+		body := `{"name": "Pallets", "scope_id": 5, "criteria": {"type": "pallet"}}`
+		c, rec := newSavedSearchContext("PUT", "/path/3?client_id=2", body, mockRepo, auth.ADMIN_ROLE)
+		UpdateSavedSearch{}.Do(c)
+
+		assert.Empty(t, c.Errors)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidData", func(t *testing.T) {
+		mockRepo := new(MockSavedSearchRepository)
+
+		c, _ := newSavedSearchContext("PUT", "/path/3", `{"name": "Boxes", "criteria": {}, "schedule": "every day"}`, mockRepo, auth.CLIENT_ROLE)
+		UpdateSavedSearch{}.Do(c)
+
+		if assert.NotEmpty(t, c.Errors) {
+			httpErr, ok := c.Errors[0].Err.(sErrors.HTTPError)
+			assert.True(t, ok)
+			assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+		}
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
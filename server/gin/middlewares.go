@@ -4,10 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/coffemanfp/docucentertest/auth"
 	"github.com/coffemanfp/docucentertest/config"
+	"github.com/coffemanfp/docucentertest/database"
 	dbErrors "github.com/coffemanfp/docucentertest/database/errors"
 	sErrors "github.com/coffemanfp/docucentertest/server/errors"
 	"github.com/gin-contrib/cors"
@@ -27,7 +30,7 @@ func newCors(conf config.ConfigInfo) gin.HandlerFunc {
 		// Define allowed HTTP headers, including custom ones like "Authorization"
 		AllowHeaders: []string{"*", "Authorization"},
 		// Define headers exposed to clients in responses
-		ExposeHeaders: []string{"Content-Length"},
+		ExposeHeaders: []string{"Content-Length", "X-Total-Count", "X-Page", "X-Page-Size", "Link"},
 		// Allow credentials (cookies, HTTP authentication) to be included in requests
 		AllowCredentials: true,
 		// Set the maximum amount of time that a preflight request can be cached
@@ -93,7 +96,8 @@ func structuredLogger(logger *zerolog.Logger) gin.HandlerFunc {
 }
 
 // authorize creates a Gin middleware that authorizes incoming requests based on a JWT token.
-func authorize(secretKey string) gin.HandlerFunc {
+// Tokens whose ID was revoked through the authentication repository are rejected.
+func authorize(secretKey string, repos database.Repositories) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Attempt to save and validate token content
 		err := saveTokenContent(c, secretKey)
@@ -105,11 +109,65 @@ func authorize(secretKey string) gin.HandlerFunc {
 			return
 		}
 
+		// Check if the token was revoked before its expiration
+		err = checkTokenRevocation(c, repos)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
 		// If token content validation succeeds, continue processing the request
 		c.Next()
 	}
 }
 
+// requireRoles creates a Gin middleware that only allows requests authorized with one of the provided roles.
+// It must be used after the authorize middleware, which saves the role of the token in the Gin context.
+func requireRoles(roles ...auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.MustGet("role").(auth.Role)
+		for _, r := range roles {
+			if r == role {
+				// If the role is allowed, continue processing the request
+				c.Next()
+				return
+			}
+		}
+
+		// If the role is not allowed, return a forbidden error
+		err := sErrors.NewHTTPError(http.StatusForbidden, sErrors.FORBIDDEN_ERROR_MESSAGE)
+		c.Error(err)
+		c.Abort()
+	}
+}
+
+// rateLimit creates a Gin middleware that allows up to limit requests per window and client IP.
+// Requests over the limit are rejected with a too many requests error.
+// A limit lower than one disables the rate limiting.
+func rateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	if limit < 1 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	rl := newRateLimiter(limit, window)
+	return func(c *gin.Context) {
+		if !rl.allow(c.ClientIP(), time.Now()) {
+			// If the client IP exceeded the limit, return a too many requests error
+			c.Header("Retry-After", strconv.Itoa(int(window.Seconds())))
+			err := sErrors.NewHTTPError(http.StatusTooManyRequests, sErrors.TOO_MANY_REQUESTS_ERROR_MESSAGE)
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		// If the client IP is within the limit, continue processing the request
+		c.Next()
+	}
+}
+
 // errorHandler creates a Gin middleware that handles errors and formats them into appropriate responses.
 func errorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -119,6 +177,12 @@ func errorHandler() gin.HandlerFunc {
 		for _, ginErr := range c.Errors {
 			var isInternal bool
 
+			// A response already started, such as a streamed export, can not be replaced, so the error is only logged
+			if c.Writer.Written() {
+				log.Error().Err(ginErr.Err).Msg("failed to complete a started response")
+				continue
+			}
+
 			// Check if the error is a custom database error
 			if err, ok := ginErr.Err.(dbErrors.Error); ok {
 				switch err.Type {
@@ -128,12 +192,24 @@ func errorHandler() gin.HandlerFunc {
 						"message": sErrors.ALREADY_EXISTS,
 					})
 
+				case dbErrors.CONFLICT:
+					// If the error type is CONFLICT, respond with a conflict status and message
+					c.JSON(http.StatusConflict, gin.H{
+						"message": sErrors.CONFLICT_ERROR_MESSAGE,
+					})
+
 				case dbErrors.NOT_FOUND:
 					// If the error type is NOT_FOUND, respond with a not found status and message
 					c.JSON(http.StatusNotFound, gin.H{
 						"message": sErrors.NOT_FOUND_ERROR_MESSAGE,
 					})
 
+				case dbErrors.TIMEOUT:
+					// If the error type is TIMEOUT, respond with a gateway timeout status and message
+					c.JSON(http.StatusGatewayTimeout, gin.H{
+						"message": sErrors.TIMEOUT_ERROR_MESSAGE,
+					})
+
 				case dbErrors.UNKNOWN:
 					isInternal = true
 				}
@@ -183,8 +259,8 @@ func readToken(c *gin.Context) (token string, err error) {
 	return
 }
 
-// saveTokenContent extracts and verifies the authentication token, and then extracts the account ID from the token's claims.
-// It sets the extracted account ID in the Gin context.
+// saveTokenContent extracts and verifies the authentication token, and then extracts the account ID and role from the token's claims.
+// It sets the extracted account ID and role in the Gin context.
 func saveTokenContent(c *gin.Context, secretKey string) (err error) {
 	// Read the authentication token from the request context
 	tokenS, err := readToken(c)
@@ -217,7 +293,47 @@ func saveTokenContent(c *gin.Context, secretKey string) (err error) {
 		return
 	}
 
-	// Set the extracted client ID in the Gin context for later use
+	// Extract the token ID from the claims, required to be able to revoke the token
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		err = errors.New("invalid token")
+		return
+	}
+
+	// Extract the role from the claims, tokens without a role get the least privileged one
+	role := auth.CLIENT_ROLE
+	if r, ok := claims["role"].(string); ok {
+		role = auth.Role(r)
+		if err = auth.ValidateRole(role); err != nil {
+			return
+		}
+	}
+
+	// Set the extracted client ID and role in the Gin context for later use
 	c.Set("id", int(id))
+	c.Set("role", role)
+	// Set the token ID and expiration in the Gin context, used to revoke the token on logout
+	c.Set("jti", jti)
+	if exp, ok := claims["exp"].(float64); ok {
+		c.Set("exp", time.Unix(int64(exp), 0))
+	}
+	return
+}
+
+// checkTokenRevocation checks if the token ID saved in the Gin context is in the revocation list.
+// It returns an unauthorized error if the token was revoked.
+func checkTokenRevocation(c *gin.Context, repos database.Repositories) (err error) {
+	repo, err := database.GetRepository[database.AuthRepository](repos, database.AUTH_REPOSITORY)
+	if err != nil {
+		return
+	}
+
+	revoked, err := repo.IsTokenRevoked(c.Request.Context(), c.GetString("jti"))
+	if err != nil {
+		return
+	}
+	if revoked {
+		err = sErrors.NewHTTPError(http.StatusUnauthorized, sErrors.UNAUTHORIZED_ERROR_MESSAGE)
+	}
 	return
 }
@@ -0,0 +1,57 @@
+package gin
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a fixed window rate limiter keyed by an arbitrary string, like the client IP.
+type rateLimiter struct {
+	limit     int                     // Maximum number of requests allowed per window and key.
+	window    time.Duration           // Duration of each window.
+	mu        sync.Mutex              // Guards the fields below.
+	counters  map[string]*rateCounter // Request counters of the current windows, by key.
+	lastPurge time.Time               // Last time the expired counters were removed.
+}
+
+// rateCounter holds the requests made by a key in its current window.
+type rateCounter struct {
+	start time.Time // Start of the window.
+	count int       // Number of requests made since the start of the window.
+}
+
+// newRateLimiter creates a new rateLimiter allowing limit requests per window and key.
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:     limit,
+		window:    window,
+		counters:  make(map[string]*rateCounter),
+		lastPurge: time.Now(),
+	}
+}
+
+// allow registers a request of the key at the given time and reports whether it is within the limit.
+func (rl *rateLimiter) allow(key string, now time.Time) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	// Remove the expired counters once per window, so the map does not grow with every seen key
+	if now.Sub(rl.lastPurge) >= rl.window {
+		for k, rc := range rl.counters {
+			if now.Sub(rc.start) >= rl.window {
+				delete(rl.counters, k)
+			}
+		}
+		rl.lastPurge = now
+	}
+
+	// Start a new window if the key has none or its window is over
+	rc, ok := rl.counters[key]
+	if !ok || now.Sub(rc.start) >= rl.window {
+		rc = &rateCounter{start: now}
+		rl.counters[key] = rc
+	}
+
+	rc.count++
+	return rc.count <= rl.limit
+}
@@ -0,0 +1,62 @@
+package spreadsheet
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Format is a file format the products can be read from or written to.
+type Format string
+
+// Formats of the spreadsheets.
+const (
+	CSV_FORMAT    Format = "csv"    // Comma-separated values, with a header row
+	XLSX_FORMAT   Format = "xlsx"   // Office Open XML workbook, read from its first sheet
+	NDJSON_FORMAT Format = "ndjson" // Newline-delimited JSON objects keyed by the header, only written
+)
+
+// contentTypes are the MIME types of the formats.
+var contentTypes = map[Format]string{
+	CSV_FORMAT:    "text/csv",
+	XLSX_FORMAT:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	NDJSON_FORMAT: "application/x-ndjson",
+}
+
+// ParseFormat parses the name of a format, ignoring the case.
+func ParseFormat(v string) (f Format, err error) {
+	f = Format(strings.ToLower(v))
+	if _, ok := contentTypes[f]; !ok {
+		err = fmt.Errorf("invalid format: unsupported format of %s", v)
+	}
+	return
+}
+
+// FormatOf returns the format of a file by the extension of its name.
+func FormatOf(filename string) (f Format, err error) {
+	ext := strings.TrimPrefix(filepath.Ext(filename), ".")
+	if ext == "" {
+		err = fmt.Errorf("invalid format: the format of %s can not be told by its extension", filename)
+		return
+	}
+	return ParseFormat(ext)
+}
+
+// FormatOfContentType returns the format of a MIME type, ignoring its parameters.
+func FormatOfContentType(contentType string) (f Format, err error) {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	for format, t := range contentTypes {
+		if strings.EqualFold(t, mediaType) {
+			f = format
+			return
+		}
+	}
+	err = fmt.Errorf("invalid format: unsupported content type of %s", contentType)
+	return
+}
+
+// ContentType returns the MIME type of the format.
+func (f Format) ContentType() string {
+	return contentTypes[f]
+}
@@ -0,0 +1,38 @@
+package spreadsheet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("XLSX")
+	assert.NoError(t, err)
+	assert.Equal(t, XLSX_FORMAT, f)
+
+	_, err = ParseFormat("ods")
+	assert.EqualError(t, err, "invalid format: unsupported format of ods")
+}
+
+func TestFormatOf(t *testing.T) {
+	f, err := FormatOf("products.CSV")
+	assert.NoError(t, err)
+	assert.Equal(t, CSV_FORMAT, f)
+
+	_, err = FormatOf("products")
+	assert.EqualError(t, err, "invalid format: the format of products can not be told by its extension")
+}
+
+func TestFormatOfContentType(t *testing.T) {
+	f, err := FormatOfContentType("text/csv; charset=utf-8")
+	assert.NoError(t, err)
+	assert.Equal(t, CSV_FORMAT, f)
+
+	f, err = FormatOfContentType(XLSX_FORMAT.ContentType())
+	assert.NoError(t, err)
+	assert.Equal(t, XLSX_FORMAT, f)
+
+	_, err = FormatOfContentType("application/json")
+	assert.EqualError(t, err, "invalid format: unsupported content type of application/json")
+}
@@ -0,0 +1,289 @@
+package spreadsheet
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/xuri/excelize/v2"
+)
+
+// Columns the products are read from, named like the JSON fields of a product.Product.
+const (
+	CLIENT_ID_COLUMN      = "client_id"
+	GUIDE_NUMBER_COLUMN   = "guide_number"
+	TYPE_COLUMN           = "type"
+	QUANTITY_COLUMN       = "quantity"
+	JOINED_AT_COLUMN      = "joined_at"
+	DELIVERED_AT_COLUMN   = "delivered_at"
+	SHIPPING_PRICE_COLUMN = "shipping_price"
+	VEHICLE_PLATE_COLUMN  = "vehicle_plate"
+	PORT_COLUMN           = "port"
+	VAULT_COLUMN          = "vault"
+)
+
+// Columns only written by the exports, as they are set by the server.
+const (
+	ID_COLUMN       = "id"
+	STATUS_COLUMN   = "status"
+	DISCOUNT_COLUMN = "discount" // Discount computed by the pricing rules, or locked by the quote of the product
+)
+
+// exportColumns are the columns the products are exported with, in order.
+var exportColumns = []string{
+	ID_COLUMN,
+	CLIENT_ID_COLUMN,
+	GUIDE_NUMBER_COLUMN,
+	TYPE_COLUMN,
+	QUANTITY_COLUMN,
+	JOINED_AT_COLUMN,
+	DELIVERED_AT_COLUMN,
+	SHIPPING_PRICE_COLUMN,
+	DISCOUNT_COLUMN,
+	VEHICLE_PLATE_COLUMN,
+	PORT_COLUMN,
+	VAULT_COLUMN,
+	STATUS_COLUMN,
+}
+
+// requiredColumns are the columns every product must have a value of, as the database requires them.
+var requiredColumns = []string{
+	GUIDE_NUMBER_COLUMN,
+	TYPE_COLUMN,
+	QUANTITY_COLUMN,
+	JOINED_AT_COLUMN,
+	DELIVERED_AT_COLUMN,
+	SHIPPING_PRICE_COLUMN,
+	VEHICLE_PLATE_COLUMN,
+}
+
+// timeLayouts are the layouts the time cells are read with, besides the serial dates of the spreadsheets.
+// The layouts without a time zone are read in UTC.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// setters set the value of a cell to the field of its column, which is empty if the cell is.
+var setters = map[string]func(p *product.Product, v string) error{
+	CLIENT_ID_COLUMN: func(p *product.Product, v string) (err error) {
+		id, err := parseInt(v, CLIENT_ID_COLUMN)
+		if id != nil {
+			p.ClientID = *id
+		}
+		return
+	},
+	GUIDE_NUMBER_COLUMN: func(p *product.Product, v string) (err error) {
+		p.GuideNumber = &v
+		return
+	},
+	TYPE_COLUMN: func(p *product.Product, v string) (err error) {
+		p.Type = &v
+		return
+	},
+	QUANTITY_COLUMN: func(p *product.Product, v string) (err error) {
+		p.Quantity, err = parseInt(v, QUANTITY_COLUMN)
+		return
+	},
+	JOINED_AT_COLUMN: func(p *product.Product, v string) (err error) {
+		p.JoinedAt, err = parseTime(v, JOINED_AT_COLUMN)
+		return
+	},
+	DELIVERED_AT_COLUMN: func(p *product.Product, v string) (err error) {
+		p.DeliveredAt, err = parseTime(v, DELIVERED_AT_COLUMN)
+		return
+	},
+	SHIPPING_PRICE_COLUMN: func(p *product.Product, v string) (err error) {
+		p.ShippingPrice, err = parseFloat(v, SHIPPING_PRICE_COLUMN)
+		return
+	},
+	VEHICLE_PLATE_COLUMN: func(p *product.Product, v string) (err error) {
+		p.VehiclePlate = &v
+		return
+	},
+	PORT_COLUMN: func(p *product.Product, v string) (err error) {
+		p.Port, err = parseInt(v, PORT_COLUMN)
+		return
+	},
+	VAULT_COLUMN: func(p *product.Product, v string) (err error) {
+		p.Vault, err = parseInt(v, VAULT_COLUMN)
+		return
+	},
+}
+
+// Header maps the columns of a spreadsheet to the fields of the products, read from its first row.
+type Header struct {
+	columns []string // Column of every cell, empty if the cell is ignored
+}
+
+// NewHeader reads the header of a spreadsheet from its first row.
+// Every cell must name a different column, ignoring the case and the surrounding spaces, and every required column
+// must be named. Empty cells are allowed, and their columns are ignored.
+func NewHeader(record []string) (h Header, err error) {
+	h.columns = make([]string, len(record))
+	seen := make(map[string]bool)
+	for i, cell := range record {
+		column := strings.ToLower(strings.TrimSpace(cell))
+		if column == "" {
+			continue
+		}
+		if _, ok := setters[column]; !ok {
+			err = fmt.Errorf("invalid header: unknown column %s", cell)
+			return
+		}
+		if seen[column] {
+			err = fmt.Errorf("invalid header: duplicated column %s", cell)
+			return
+		}
+		seen[column] = true
+		h.columns[i] = column
+	}
+	for _, column := range requiredColumns {
+		if !seen[column] {
+			err = fmt.Errorf("invalid header: missing column %s", column)
+			return
+		}
+	}
+	return
+}
+
+// Product reads a product from the cells of a row, only checking the required ones are not empty. See product.New.
+// The surrounding spaces of the cells are ignored, and the fields of the empty ones are left unset.
+func (h Header) Product(record []string) (p product.Product, err error) {
+	// The cells beyond the header must be empty.
+	for i := len(h.columns); i < len(record); i++ {
+		if strings.TrimSpace(record[i]) != "" {
+			err = fmt.Errorf("invalid row: cell %d is outside the header", i+1)
+			return
+		}
+	}
+
+	filled := make(map[string]bool, len(h.columns))
+	for i, column := range h.columns {
+		if column == "" || i >= len(record) {
+			continue
+		}
+		v := strings.TrimSpace(record[i])
+		if v == "" {
+			continue
+		}
+		err = setters[column](&p, v)
+		if err != nil {
+			return
+		}
+		filled[column] = true
+	}
+
+	for _, column := range requiredColumns {
+		if !filled[column] {
+			err = fmt.Errorf("invalid %s: %s cannot be empty", column, column)
+			return
+		}
+	}
+	return
+}
+
+// ProductWriter writes products to a spreadsheet, one row per product after a header row of the exportColumns.
+type ProductWriter struct {
+	w Writer
+}
+
+// NewProductWriter creates a ProductWriter of a spreadsheet in the given format, writing its header.
+func NewProductWriter(w io.Writer, format Format) (pw ProductWriter, err error) {
+	pw.w, err = NewWriter(w, format, exportColumns)
+	return
+}
+
+// Write writes the row of a product, leaving the cells of its missing fields empty.
+func (pw ProductWriter) Write(p product.Product) error {
+	return pw.w.Write([]interface{}{
+		p.ID,
+		p.ClientID,
+		valueOf(p.GuideNumber),
+		valueOf(p.Type),
+		valueOf(p.Quantity),
+		valueOf(p.JoinedAt),
+		valueOf(p.DeliveredAt),
+		valueOf(p.ShippingPrice),
+		p.Discount,
+		valueOf(p.VehiclePlate),
+		valueOf(p.Port),
+		valueOf(p.Vault),
+		string(p.Status),
+	})
+}
+
+// Close writes the rows not written yet. See Writer.Close.
+func (pw ProductWriter) Close() error {
+	return pw.w.Close()
+}
+
+// valueOf returns the value of a field of a product, or nil if it is missing.
+func valueOf[T any](v *T) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// IsEmpty reports whether every cell of a row is empty, so it can be skipped.
+func IsEmpty(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseInt parses the integer value of a cell of a column.
+// Spreadsheets can store integers as decimal numbers, so a number without a fractional part is accepted.
+func parseInt(v, column string) (i *int, err error) {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		f, floatErr := strconv.ParseFloat(v, 64)
+		if floatErr != nil || f != float64(int(f)) {
+			err = fmt.Errorf("invalid %s: %s is not an integer", column, v)
+			return
+		}
+		n, err = int(f), nil
+	}
+	i = &n
+	return
+}
+
+// parseFloat parses the decimal value of a cell of a column.
+func parseFloat(v, column string) (f *float64, err error) {
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		err = fmt.Errorf("invalid %s: %s is not a number", column, v)
+		return
+	}
+	f = &n
+	return
+}
+
+// parseTime parses the time value of a cell of a column, either formatted with one of the timeLayouts
+// or as the serial date number spreadsheets store dates with.
+func parseTime(v, column string) (t *time.Time, err error) {
+	for _, layout := range timeLayouts {
+		parsed, parseErr := time.Parse(layout, v)
+		if parseErr == nil {
+			t = &parsed
+			return
+		}
+	}
+	if serial, parseErr := strconv.ParseFloat(v, 64); parseErr == nil && serial > 0 {
+		parsed, _ := excelize.ExcelDateToTime(serial, false)
+		t = &parsed
+		return
+	}
+	err = fmt.Errorf("invalid %s: invalid %s time format of %s", column, column, v)
+	return
+}
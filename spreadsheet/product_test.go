@@ -0,0 +1,111 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testHeader = []string{"Guide_Number", " type ", "quantity", "joined_at", "delivered_at", "shipping_price", "vehicle_plate", "", "port"}
+
+func TestNewHeader(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		h, err := NewHeader(testHeader)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"guide_number", "type", "quantity", "joined_at", "delivered_at", "shipping_price", "vehicle_plate", "", "port"}, h.columns)
+	})
+
+	cases := map[string]struct {
+		record []string
+		err    string
+	}{
+		"UnknownColumn":    {append([]string{"color"}, testHeader...), "invalid header: unknown column color"},
+		"DuplicatedColumn": {append(testHeader, "TYPE"), "invalid header: duplicated column TYPE"},
+		"MissingColumn":    {testHeader[1:], "invalid header: missing column guide_number"},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewHeader(c.record)
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
+
+func TestHeader_Product(t *testing.T) {
+	h, err := NewHeader(testHeader)
+	require.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		p, err := h.Product([]string{"ABC1234567", "box", "3.0", "2024-03-05", "2024-03-06 10:30", " 12.5 ", "ABC-123", "ignored", ""})
+		require.NoError(t, err)
+
+		assert.Equal(t, "ABC1234567", *p.GuideNumber)
+		assert.Equal(t, "box", *p.Type)
+		assert.Equal(t, 3, *p.Quantity)
+		assert.Equal(t, time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC), *p.JoinedAt)
+		assert.Equal(t, time.Date(2024, time.March, 6, 10, 30, 0, 0, time.UTC), *p.DeliveredAt)
+		assert.Equal(t, 12.5, *p.ShippingPrice)
+		assert.Equal(t, "ABC-123", *p.VehiclePlate)
+		assert.Nil(t, p.Port)
+	})
+
+	t.Run("SerialDates", func(t *testing.T) {
+		p, err := h.Product([]string{"ABC1234567", "box", "3", "45356.5", "45357", "12.5", "ABC-123"})
+		require.NoError(t, err)
+
+		assert.Equal(t, time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC), *p.JoinedAt)
+		assert.Equal(t, time.Date(2024, time.March, 6, 0, 0, 0, 0, time.UTC), *p.DeliveredAt)
+	})
+
+	cases := map[string]struct {
+		record []string
+		err    string
+	}{
+		"NotAnInteger":     {[]string{"ABC1234567", "box", "3.5", "2024-03-05", "2024-03-06", "12.5", "ABC-123"}, "invalid quantity: 3.5 is not an integer"},
+		"NotANumber":       {[]string{"ABC1234567", "box", "3", "2024-03-05", "2024-03-06", "cheap", "ABC-123"}, "invalid shipping_price: cheap is not a number"},
+		"InvalidTime":      {[]string{"ABC1234567", "box", "3", "05/03/2024", "2024-03-06", "12.5", "ABC-123"}, "invalid joined_at: invalid joined_at time format of 05/03/2024"},
+		"MissingRequired":  {[]string{"ABC1234567", "box", "3", "2024-03-05", "", "12.5", "ABC-123"}, "invalid delivered_at: delivered_at cannot be empty"},
+		"ShortRow":         {[]string{"ABC1234567", "box", "3"}, "invalid joined_at: joined_at cannot be empty"},
+		"OutsideTheHeader": {[]string{"ABC1234567", "box", "3", "2024-03-05", "2024-03-06", "12.5", "ABC-123", "", "1", "extra"}, "invalid row: cell 10 is outside the header"},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := h.Product(c.record)
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	assert.True(t, IsEmpty([]string{"", " "}))
+	assert.True(t, IsEmpty(nil))
+	assert.False(t, IsEmpty([]string{"", "box"}))
+}
+
+func TestProductWriter(t *testing.T) {
+	joinedAt := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+	guideNumber, vehiclePlate := "ABC1234567", "ABC-123"
+	port := 2
+
+	var buf bytes.Buffer
+	w, err := NewProductWriter(&buf, CSV_FORMAT)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(product.Product{
+		ID:           7,
+		ClientID:     1,
+		GuideNumber:  &guideNumber,
+		JoinedAt:     &joinedAt,
+		VehiclePlate: &vehiclePlate,
+		Port:         &port,
+		Status:       product.REGISTERED_STATUS,
+		Discount:     12.5,
+	}))
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "id,client_id,guide_number,type,quantity,joined_at,delivered_at,shipping_price,discount,vehicle_plate,port,vault,status\n"+
+		"7,1,ABC1234567,,,2024-03-05T00:00:00Z,,,12.5,ABC-123,2,,REGISTERED\n", buf.String())
+}
@@ -0,0 +1,125 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Reader reads the records of a spreadsheet, one row at a time.
+type Reader interface {
+	// Read returns the cells of the next row, or io.EOF after the last one.
+	Read() (record []string, err error)
+
+	// Row returns the number of the row last read, starting from 1, counting the empty rows skipped by Read.
+	Row() int
+
+	// Close releases the resources of the reader.
+	Close() error
+}
+
+// NewReader creates a Reader of the rows of a spreadsheet in the given format.
+// XLSX workbooks are read from their first sheet, with their raw cell values.
+func NewReader(r io.Reader, format Format) (reader Reader, err error) {
+	switch format {
+	case CSV_FORMAT:
+		cr := csv.NewReader(r)
+		// Rows can have fewer cells than the header, the missing ones are empty.
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		reader = csvReader{r: cr}
+	case XLSX_FORMAT:
+		reader, err = newXLSXReader(r)
+	default:
+		err = fmt.Errorf("invalid format: unsupported format of %s", format)
+	}
+	return
+}
+
+// csvReader is a Reader of CSV files.
+type csvReader struct {
+	r *csv.Reader
+}
+
+// Read returns the cells of the next row.
+func (cr csvReader) Read() (record []string, err error) {
+	record, err = cr.r.Read()
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("invalid csv: %w", err)
+	}
+	return
+}
+
+// Row returns the number of the line the last row starts at.
+func (cr csvReader) Row() int {
+	line, _ := cr.r.FieldPos(0)
+	return line
+}
+
+// Close does nothing, the CSV reader holds no resources.
+func (cr csvReader) Close() error {
+	return nil
+}
+
+// xlsxReader is a Reader of the first sheet of an XLSX workbook.
+type xlsxReader struct {
+	f    *excelize.File
+	rows *excelize.Rows
+	row  int // Number of the row last read
+}
+
+// newXLSXReader opens an XLSX workbook to read the rows of its first sheet.
+func newXLSXReader(r io.Reader) (xr *xlsxReader, err error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		err = fmt.Errorf("invalid xlsx: %w", err)
+		return
+	}
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		f.Close()
+		err = fmt.Errorf("invalid xlsx: the workbook has no sheets")
+		return
+	}
+	rows, err := f.Rows(sheets[0])
+	if err != nil {
+		f.Close()
+		err = fmt.Errorf("invalid xlsx: %w", err)
+		return
+	}
+	xr = &xlsxReader{f: f, rows: rows}
+	return
+}
+
+// Read returns the raw values of the cells of the next row.
+func (xr *xlsxReader) Read() (record []string, err error) {
+	if !xr.rows.Next() {
+		err = xr.rows.Error()
+		if err == nil {
+			err = io.EOF
+		}
+		return
+	}
+	xr.row++
+	record, err = xr.rows.Columns(excelize.Options{RawCellValue: true})
+	if err != nil {
+		err = fmt.Errorf("invalid xlsx: %w", err)
+	}
+	return
+}
+
+// Row returns the number of the row last read.
+func (xr *xlsxReader) Row() int {
+	return xr.row
+}
+
+// Close closes the workbook and the iterator of its rows.
+func (xr *xlsxReader) Close() (err error) {
+	err = xr.rows.Close()
+	if closeErr := xr.f.Close(); err == nil {
+		err = closeErr
+	}
+	return
+}
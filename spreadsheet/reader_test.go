@@ -0,0 +1,93 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// readAll reads every record of a Reader.
+func readAll(t *testing.T, r Reader) (records [][]string) {
+	t.Helper()
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return
+		}
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	t.Run("CSV", func(t *testing.T) {
+		r, err := NewReader(strings.NewReader("guide_number, type\nABC1234567,box\nDEF1234567\n"), CSV_FORMAT)
+		require.NoError(t, err)
+		defer r.Close()
+
+		assert.Equal(t, [][]string{
+			{"guide_number", "type"},
+			{"ABC1234567", "box"},
+			{"DEF1234567"},
+		}, readAll(t, r))
+	})
+
+	t.Run("InvalidCSV", func(t *testing.T) {
+		r, err := NewReader(strings.NewReader("guide_number\n\"ABC"), CSV_FORMAT)
+		require.NoError(t, err)
+		defer r.Close()
+
+		_, err = r.Read()
+		assert.NoError(t, err)
+		_, err = r.Read()
+		assert.ErrorContains(t, err, "invalid csv: ")
+	})
+
+	t.Run("CSVRows", func(t *testing.T) {
+		r, err := NewReader(strings.NewReader("guide_number\n\n\"ABC\n1234567\"\nDEF1234567\n"), CSV_FORMAT)
+		require.NoError(t, err)
+		defer r.Close()
+
+		rows := make([]int, 0)
+		for _, err = r.Read(); err == nil; _, err = r.Read() {
+			rows = append(rows, r.Row())
+		}
+		assert.Equal(t, io.EOF, err)
+		// The empty line is skipped, and the rows are numbered by the line they start at.
+		assert.Equal(t, []int{1, 3, 5}, rows)
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		f := excelize.NewFile()
+		require.NoError(t, f.SetSheetRow("Sheet1", "A1", &[]interface{}{"guide_number", "quantity"}))
+		require.NoError(t, f.SetSheetRow("Sheet1", "A3", &[]interface{}{"ABC1234567", 3}))
+		var buf bytes.Buffer
+		require.NoError(t, f.Write(&buf))
+
+		r, err := NewReader(&buf, XLSX_FORMAT)
+		require.NoError(t, err)
+		defer r.Close()
+
+		assert.Equal(t, [][]string{
+			{"guide_number", "quantity"},
+			nil,
+			{"ABC1234567", "3"},
+		}, readAll(t, r))
+		assert.Equal(t, 3, r.Row())
+	})
+
+	t.Run("InvalidXLSX", func(t *testing.T) {
+		_, err := NewReader(strings.NewReader("not a workbook"), XLSX_FORMAT)
+		assert.ErrorContains(t, err, "invalid xlsx: ")
+	})
+
+	t.Run("UnsupportedFormat", func(t *testing.T) {
+		_, err := NewReader(strings.NewReader(""), Format("ods"))
+		assert.EqualError(t, err, "invalid format: unsupported format of ods")
+	})
+}
@@ -0,0 +1,167 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Writer writes the records of a spreadsheet, one row at a time, after its header.
+// The cells of a record are strings, integers, floats, times or nil if empty, in the order of the header.
+type Writer interface {
+	// Write writes the cells of the next row.
+	Write(record []interface{}) error
+
+	// Close writes the rows not written yet, and releases the resources of the writer.
+	// It does not close the underlying io.Writer.
+	Close() error
+}
+
+// NewWriter creates a Writer of the rows of a spreadsheet in the given format, with the given header.
+// CSV files and NDJSON lines are written as the rows are, while XLSX workbooks are only written when closed, their
+// rows being buffered in a temporary file once they grow large.
+func NewWriter(w io.Writer, format Format, header []string) (writer Writer, err error) {
+	switch format {
+	case CSV_FORMAT:
+		cw := csvWriter{w: csv.NewWriter(w)}
+		writer = cw
+		err = cw.w.Write(header)
+	case XLSX_FORMAT:
+		writer, err = newXLSXWriter(w, header)
+	case NDJSON_FORMAT:
+		writer = ndjsonWriter{w: bufio.NewWriter(w), header: header}
+	default:
+		err = fmt.Errorf("invalid format: unsupported format of %s", format)
+	}
+	return
+}
+
+// csvWriter is a Writer of CSV files.
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// Write writes the cells of the next row, formatting the times as RFC 3339 timestamps.
+func (cw csvWriter) Write(record []interface{}) error {
+	cells := make([]string, len(record))
+	for i, v := range record {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			cells[i] = v
+		case int:
+			cells[i] = strconv.Itoa(v)
+		case float64:
+			cells[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case time.Time:
+			cells[i] = v.Format(time.RFC3339)
+		default:
+			cells[i] = fmt.Sprint(v)
+		}
+	}
+	return cw.w.Write(cells)
+}
+
+// Close writes the buffered rows.
+func (cw csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// xlsxWriter is a Writer of an XLSX workbook with a single sheet.
+type xlsxWriter struct {
+	out  io.Writer
+	f    *excelize.File
+	sw   *excelize.StreamWriter
+	rows int // Number of rows written
+}
+
+// newXLSXWriter creates a workbook whose single sheet starts with the header.
+func newXLSXWriter(w io.Writer, header []string) (xw *xlsxWriter, err error) {
+	f := excelize.NewFile()
+	sw, err := f.NewStreamWriter(f.GetSheetName(0))
+	if err != nil {
+		f.Close()
+		return
+	}
+	xw = &xlsxWriter{out: w, f: f, sw: sw}
+
+	record := make([]interface{}, len(header))
+	for i, column := range header {
+		record[i] = column
+	}
+	err = xw.Write(record)
+	if err != nil {
+		f.Close()
+	}
+	return
+}
+
+// Write writes the cells of the next row to the sheet, the times as dates.
+func (xw *xlsxWriter) Write(record []interface{}) (err error) {
+	cell, err := excelize.CoordinatesToCellName(1, xw.rows+1)
+	if err != nil {
+		return
+	}
+	err = xw.sw.SetRow(cell, record)
+	if err != nil {
+		return
+	}
+	xw.rows++
+	return
+}
+
+// Close writes the workbook, and removes its temporary files.
+func (xw *xlsxWriter) Close() (err error) {
+	err = xw.sw.Flush()
+	if err == nil {
+		err = xw.f.Write(xw.out)
+	}
+	if closeErr := xw.f.Close(); err == nil {
+		err = closeErr
+	}
+	return
+}
+
+// ndjsonWriter is a Writer of newline-delimited JSON objects, keyed by the columns of the header.
+type ndjsonWriter struct {
+	w      *bufio.Writer
+	header []string
+}
+
+// Write writes the next row as a JSON object, with its keys in the order of the header.
+func (nw ndjsonWriter) Write(record []interface{}) (err error) {
+	nw.w.WriteByte('{')
+	for i, column := range nw.header {
+		if i > 0 {
+			nw.w.WriteByte(',')
+		}
+		var v interface{}
+		if i < len(record) {
+			v = record[i]
+		}
+		var key, value []byte
+		key, _ = json.Marshal(column)
+		value, err = json.Marshal(v)
+		if err != nil {
+			return
+		}
+		nw.w.Write(key)
+		nw.w.WriteByte(':')
+		nw.w.Write(value)
+	}
+	// The writes fail from the first error on, which is returned by the last one.
+	_, err = nw.w.WriteString("}\n")
+	return
+}
+
+// Close writes the buffered rows.
+func (nw ndjsonWriter) Close() error {
+	return nw.w.Flush()
+}
@@ -0,0 +1,63 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	writerHeader = []string{"guide_number", "quantity", "shipping_price", "joined_at", "vault"}
+	writerRecord = []interface{}{"ABC1234567", 3, 12.5, time.Date(2024, time.March, 5, 10, 30, 0, 0, time.UTC), nil}
+)
+
+// write writes the writerRecord in the given format.
+func write(t *testing.T, format Format) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, format, writerHeader)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(writerRecord))
+	require.NoError(t, w.Close())
+	return &buf
+}
+
+func TestNewWriter(t *testing.T) {
+	t.Run("CSV", func(t *testing.T) {
+		buf := write(t, CSV_FORMAT)
+		assert.Equal(t, "guide_number,quantity,shipping_price,joined_at,vault\nABC1234567,3,12.5,2024-03-05T10:30:00Z,\n", buf.String())
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		buf := write(t, NDJSON_FORMAT)
+		assert.Equal(t, `{"guide_number":"ABC1234567","quantity":3,"shipping_price":12.5,"joined_at":"2024-03-05T10:30:00Z","vault":null}`+"\n", buf.String())
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		buf := write(t, XLSX_FORMAT)
+
+		r, err := NewReader(buf, XLSX_FORMAT)
+		require.NoError(t, err)
+		defer r.Close()
+		records := readAll(t, r)
+		require.Len(t, records, 2)
+		assert.Equal(t, writerHeader, records[0])
+
+		// The times are written as serial dates, read back like the imported ones.
+		h, err := NewHeader([]string{"guide_number", "type", "quantity", "joined_at", "delivered_at", "shipping_price", "vehicle_plate"})
+		require.NoError(t, err)
+		p, err := h.Product([]string{records[1][0], "box", records[1][1], records[1][3], records[1][3], records[1][2], "ABC-123"})
+		require.NoError(t, err)
+		assert.Equal(t, 3, *p.Quantity)
+		assert.Equal(t, 12.5, *p.ShippingPrice)
+		assert.Equal(t, writerRecord[3], *p.JoinedAt)
+	})
+
+	t.Run("UnsupportedFormat", func(t *testing.T) {
+		_, err := NewWriter(&bytes.Buffer{}, Format("ods"), writerHeader)
+		assert.EqualError(t, err, "invalid format: unsupported format of ods")
+	})
+}
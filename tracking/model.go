@@ -0,0 +1,91 @@
+package tracking
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/coffemanfp/docucentertest/utils"
+)
+
+// maxNoteLength is the maximum number of characters allowed in the note of an event.
+const maxNoteLength = 500
+
+// Event represents a checkpoint a shipment passed through.
+type Event struct {
+	ID           int       `json:"id,omitempty"`            // Unique identifier for the event.
+	ProductID    int       `json:"product_id,omitempty"`    // Identifier of the tracked product.
+	RecordedAt   time.Time `json:"recorded_at,omitempty"`   // Timestamp when the shipment passed the checkpoint.
+	Port         *int      `json:"port,omitempty"`          // Port of the checkpoint, can be nil.
+	Vault        *int      `json:"vault,omitempty"`         // Vault of the checkpoint, can be nil.
+	VehiclePlate *string   `json:"vehicle_plate,omitempty"` // Plate of the vehicle carrying the shipment, can be nil.
+	Note         string    `json:"note,omitempty"`          // Free-text note about the checkpoint.
+	RecordedBy   int       `json:"recorded_by,omitempty"`   // Identifier of the client who recorded the event.
+}
+
+// New creates a new Event instance while validating certain fields.
+func New(eventR Event) (event Event, err error) {
+	if eventR.ProductID <= 0 {
+		err = fmt.Errorf("invalid product id or not provided: %d", eventR.ProductID)
+		return
+	}
+
+	if eventR.RecordedBy <= 0 {
+		err = fmt.Errorf("invalid recorder id or not provided: %d", eventR.RecordedBy)
+		return
+	}
+
+	err = validateLocation(eventR.Port, eventR.Vault)
+	if err != nil {
+		return
+	}
+
+	if eventR.VehiclePlate != nil {
+		err = product.ValidateVehiclePlate(eventR.VehiclePlate)
+		if err != nil {
+			return
+		}
+	}
+
+	// The characters of the note are counted as sent, before escaping them.
+	note := strings.TrimSpace(eventR.Note)
+	if utf8.RuneCountInString(note) > maxNoteLength {
+		err = fmt.Errorf("invalid note: note must have at most %d characters", maxNoteLength)
+		return
+	}
+
+	if eventR.RecordedAt.After(time.Now()) {
+		err = fmt.Errorf("invalid recorded at: the event cannot be recorded in the future: %s", eventR.RecordedAt)
+		return
+	}
+
+	event = eventR // Assign the validated event to the result.
+	event.Note = utils.RemoveSpaceAndConvertSpecialChars(note)
+
+	// Events without a timestamp are recorded at the current time.
+	if event.RecordedAt.IsZero() {
+		event.RecordedAt = time.Now()
+	}
+	return
+}
+
+// validateLocation validates the location of a checkpoint, which is either a port or a vault.
+func validateLocation(port, vault *int) (err error) {
+	if port == nil && vault == nil {
+		err = fmt.Errorf("invalid location: a port or a vault must be provided")
+		return
+	}
+	if port != nil && vault != nil {
+		err = fmt.Errorf("invalid location: a checkpoint cannot be both a port and a vault")
+		return
+	}
+
+	if port != nil {
+		err = product.ValidatePort(*port)
+	} else {
+		err = product.ValidateVault(*vault)
+	}
+	return
+}
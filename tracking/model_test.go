@@ -0,0 +1,102 @@
+package tracking
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEvent(t *testing.T) {
+	port := 3
+	vault := 2
+	invalidPort := -1
+	plate := "ABC-123"
+	invalidPlate := "123-ABC"
+
+	t.Run("ValidEvent", func(t *testing.T) {
+		eventR := Event{
+			ProductID:    1,
+			Port:         &port,
+			VehiclePlate: &plate,
+			Note:         "  Held at customs  ",
+			RecordedBy:   2,
+		}
+
+		event, err := New(eventR)
+		assert.NoError(t, err)
+		assert.Equal(t, "Held at customs", event.Note)
+		assert.WithinDuration(t, time.Now(), event.RecordedAt, time.Second)
+		assert.Equal(t, &port, event.Port)
+	})
+
+	t.Run("KeepsRecordedAt", func(t *testing.T) {
+		recordedAt := time.Now().Add(-time.Hour)
+		event, err := New(Event{ProductID: 1, Vault: &vault, RecordedBy: 2, RecordedAt: recordedAt})
+		assert.NoError(t, err)
+		assert.Equal(t, recordedAt, event.RecordedAt)
+	})
+
+	t.Run("InvalidProductID", func(t *testing.T) {
+		event, err := New(Event{Port: &port, RecordedBy: 2})
+		assert.EqualError(t, err, "invalid product id or not provided: 0")
+		assert.Empty(t, event)
+	})
+
+	t.Run("InvalidRecorder", func(t *testing.T) {
+		event, err := New(Event{ProductID: 1, Port: &port})
+		assert.EqualError(t, err, "invalid recorder id or not provided: 0")
+		assert.Empty(t, event)
+	})
+
+	t.Run("MissingLocation", func(t *testing.T) {
+		event, err := New(Event{ProductID: 1, RecordedBy: 2})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid location")
+		assert.Empty(t, event)
+	})
+
+	t.Run("PortAndVault", func(t *testing.T) {
+		event, err := New(Event{ProductID: 1, RecordedBy: 2, Port: &port, Vault: &vault})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid location")
+		assert.Empty(t, event)
+	})
+
+	t.Run("InvalidPort", func(t *testing.T) {
+		event, err := New(Event{ProductID: 1, RecordedBy: 2, Port: &invalidPort})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid port")
+		assert.Empty(t, event)
+	})
+
+	t.Run("InvalidVehiclePlate", func(t *testing.T) {
+		event, err := New(Event{ProductID: 1, RecordedBy: 2, Port: &port, VehiclePlate: &invalidPlate})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid vehicle plate format")
+		assert.Empty(t, event)
+	})
+
+	t.Run("NoteTooLong", func(t *testing.T) {
+		event, err := New(Event{ProductID: 1, RecordedBy: 2, Port: &port, Note: strings.Repeat("a", 501)})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid note")
+		assert.Empty(t, event)
+	})
+
+	t.Run("NoteCountsCharacters", func(t *testing.T) {
+		// Multi-byte and escaped characters count once each.
+		note := strings.Repeat("ñ", 250) + strings.Repeat("&", 250)
+		event, err := New(Event{ProductID: 1, RecordedBy: 2, Port: &port, Note: note})
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Repeat("ñ", 250)+strings.Repeat("&amp;", 250), event.Note)
+	})
+
+	t.Run("FutureRecordedAt", func(t *testing.T) {
+		event, err := New(Event{ProductID: 1, Vault: &vault, RecordedBy: 2, RecordedAt: time.Now().Add(time.Hour)})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid recorded at")
+		assert.Empty(t, event)
+	})
+}
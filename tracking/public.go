@@ -0,0 +1,52 @@
+package tracking
+
+import (
+	"time"
+
+	"github.com/coffemanfp/docucentertest/product"
+)
+
+// PublicView represents the redacted tracking information of a shipment, safe to be shown without authentication.
+type PublicView struct {
+	GuideNumber string         `json:"guide_number"`           // Guide number of the shipment.
+	Type        string         `json:"type,omitempty"`         // Type of the shipped product.
+	Status      product.Status `json:"status,omitempty"`       // Stage of the shipment lifecycle.
+	JoinedAt    *time.Time     `json:"joined_at,omitempty"`    // Timestamp when the shipment was joined, can be nil.
+	DeliveredAt *time.Time     `json:"delivered_at,omitempty"` // Timestamp when the shipment was delivered, can be nil.
+	Events      []*PublicEvent `json:"events"`                 // Tracking timeline of the shipment.
+}
+
+// PublicEvent represents the redacted view of a tracking event, without the vehicle plate and the recorder.
+type PublicEvent struct {
+	RecordedAt time.Time `json:"recorded_at"`     // Timestamp when the shipment passed the checkpoint.
+	Port       *int      `json:"port,omitempty"`  // Port of the checkpoint, can be nil.
+	Vault      *int      `json:"vault,omitempty"` // Vault of the checkpoint, can be nil.
+	Note       string    `json:"note,omitempty"`  // Free-text note about the checkpoint.
+}
+
+// NewPublicView creates the redacted tracking view of a product and its events.
+// The price, client ID and vehicle plates are never copied into the view.
+func NewPublicView(p product.Product, es []*Event) (view PublicView) {
+	view = PublicView{
+		Status:      p.Status,
+		JoinedAt:    p.JoinedAt,
+		DeliveredAt: p.DeliveredAt,
+		Events:      make([]*PublicEvent, 0, len(es)),
+	}
+	if p.GuideNumber != nil {
+		view.GuideNumber = *p.GuideNumber
+	}
+	if p.Type != nil {
+		view.Type = *p.Type
+	}
+
+	for _, e := range es {
+		view.Events = append(view.Events, &PublicEvent{
+			RecordedAt: e.RecordedAt,
+			Port:       e.Port,
+			Vault:      e.Vault,
+			Note:       e.Note,
+		})
+	}
+	return
+}
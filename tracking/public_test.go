@@ -0,0 +1,67 @@
+package tracking
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/coffemanfp/docucentertest/product"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPublicView(t *testing.T) {
+	guideNumber := "ABC1234567"
+	productType := "Electronics"
+	plate := "ABC-123"
+	price := 150.0
+	port := 3
+	joinedAt := time.Now().Add(-time.Hour).UTC()
+
+	p := product.Product{
+		ID:            1,
+		ClientID:      2,
+		GuideNumber:   &guideNumber,
+		Type:          &productType,
+		JoinedAt:      &joinedAt,
+		ShippingPrice: &price,
+		VehiclePlate:  &plate,
+		Port:          &port,
+		Status:        product.IN_TRANSIT_STATUS,
+	}
+	es := []*Event{
+		{
+			ID:           1,
+			ProductID:    1,
+			RecordedAt:   joinedAt,
+			Port:         &port,
+			VehiclePlate: &plate,
+			Note:         "Left the warehouse",
+			RecordedBy:   4,
+		},
+	}
+
+	t.Run("RedactedFields", func(t *testing.T) {
+		view := NewPublicView(p, es)
+		assert.Equal(t, guideNumber, view.GuideNumber)
+		assert.Equal(t, productType, view.Type)
+		assert.Equal(t, product.IN_TRANSIT_STATUS, view.Status)
+		assert.Equal(t, &joinedAt, view.JoinedAt)
+		assert.Len(t, view.Events, 1)
+		assert.Equal(t, "Left the warehouse", view.Events[0].Note)
+		assert.Equal(t, &port, view.Events[0].Port)
+
+		b, err := json.Marshal(view)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(b), "shipping_price")
+		assert.NotContains(t, string(b), "client_id")
+		assert.NotContains(t, string(b), "vehicle_plate")
+		assert.NotContains(t, string(b), "recorded_by")
+		assert.NotContains(t, string(b), plate)
+	})
+
+	t.Run("NoEvents", func(t *testing.T) {
+		view := NewPublicView(p, nil)
+		assert.NotNil(t, view.Events)
+		assert.Empty(t, view.Events)
+	})
+}